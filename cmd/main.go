@@ -5,14 +5,35 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/YurcheuskiRadzivon/booking-system/internal/config"
+	bookingModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	folioModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/folio"
+	loyaltyModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/loyalty"
+	paymentModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/payment"
+	webhookModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/webhook"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/server"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/service/admin"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/service/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/calendar"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/channel"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/exchange"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/folio"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/frontdesk"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/guest"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/housekeeping"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/idempotency"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/live"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/loyalty"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/service/notification"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/payment"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/report"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/waitlist"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/webhook"
 )
 
 func main() {
@@ -36,7 +57,39 @@ func run(cfg *config.Config, ctx context.Context) {
 
 	log.Println("Connected to database")
 
-	bookingSvc, err := booking.NewService(ctx, repo)
+	location, err := time.LoadLocation(cfg.Hotel.Timezone)
+	if err != nil {
+		log.Fatalf("Hotel timezone error: %v", err)
+	}
+
+	policy := bookingModel.StayPolicy{
+		CheckInTime:      cfg.Hotel.CheckInTime,
+		CheckOutTime:     cfg.Hotel.CheckOutTime,
+		EarlyCheckInTime: cfg.Hotel.EarlyCheckInTime,
+		LateCheckOutTime: cfg.Hotel.LateCheckOutTime,
+		EarlyCheckInFee:  cfg.Hotel.EarlyCheckInFee,
+		LateCheckOutFee:  cfg.Hotel.LateCheckOutFee,
+	}
+
+	loyaltyPolicy := loyaltyModel.Policy{
+		EarnRate:        cfg.Loyalty.EarnRate,
+		PointValue:      cfg.Loyalty.PointValue,
+		ExpiryDays:      cfg.Loyalty.ExpiryDays,
+		QualifyingDays:  cfg.Loyalty.QualifyingDays,
+		SilverThreshold: cfg.Loyalty.SilverThreshold,
+		GoldThreshold:   cfg.Loyalty.GoldThreshold,
+		SilverDiscount:  cfg.Loyalty.SilverDiscount,
+		GoldDiscount:    cfg.Loyalty.GoldDiscount,
+	}
+
+	taxPolicy := folioModel.TaxPolicy{
+		VATPercent:  cfg.Tax.VATPercent,
+		VATIncluded: cfg.Tax.VATIncluded,
+		CityTax:     cfg.Tax.CityTax,
+		Currency:    cfg.Payment.Currency,
+	}
+
+	bookingSvc, err := booking.NewService(ctx, repo, location, policy, loyaltyPolicy)
 	if err != nil {
 		log.Fatalf("Booking service error: %v", err)
 	}
@@ -50,13 +103,148 @@ func run(cfg *config.Config, ctx context.Context) {
 
 	notificationSvc.StartWorker(ctx)
 
-	adminSvc, err := admin.NewService(ctx, repo)
+	adminSvc, err := admin.NewService(ctx, repo, location)
 	if err != nil {
 		log.Fatalf("Admin service error: %v", err)
 	}
 	log.Println("Admin service initialized")
 
-	srv := server.New(cfg.HTTP.PORT, bookingSvc, notificationSvc, adminSvc)
+	loyaltySvc, err := loyalty.NewService(ctx, repo, location, loyaltyPolicy)
+	if err != nil {
+		log.Fatalf("Loyalty service error: %v", err)
+	}
+	log.Println("Loyalty service initialized")
+
+	loyaltySvc.StartExpiryWorker(ctx)
+
+	folioSvc, err := folio.NewService(ctx, repo, location, taxPolicy, cfg.Hotel.Name)
+	if err != nil {
+		log.Fatalf("Folio service error: %v", err)
+	}
+	log.Println("Folio service initialized")
+
+	frontdeskSvc, err := frontdesk.NewService(ctx, repo, location, frontdesk.Policy{
+		NoShowPenaltyNights: cfg.Hotel.NoShowPenaltyNights,
+		NoShowRunTime:       cfg.Hotel.NoShowRunTime,
+	}, loyaltySvc, folioSvc)
+	if err != nil {
+		log.Fatalf("Front desk service error: %v", err)
+	}
+	log.Println("Front desk service initialized")
+
+	frontdeskSvc.StartNoShowWorker(ctx)
+
+	housekeepingSvc, err := housekeeping.NewService(ctx, repo, location)
+	if err != nil {
+		log.Fatalf("Housekeeping service error: %v", err)
+	}
+	log.Println("Housekeeping service initialized")
+
+	guestSvc, err := guest.NewService(ctx, repo)
+	if err != nil {
+		log.Fatalf("Guest service error: %v", err)
+	}
+	log.Println("Guest service initialized")
+
+	idempotencySvc, err := idempotency.NewService(ctx, repo, cfg.Idempotency.TTL)
+	if err != nil {
+		log.Fatalf("Idempotency service error: %v", err)
+	}
+	log.Println("Idempotency service initialized")
+
+	idempotencySvc.StartPurgeWorker(ctx)
+
+	var gateway payment.Gateway
+	switch cfg.Payment.Provider {
+	case "fake":
+		gateway = payment.NewFakeGateway(cfg.Payment.WebhookSecret)
+	default:
+		log.Fatalf("Payment provider %q is not supported", cfg.Payment.Provider)
+	}
+
+	paymentSvc, err := payment.NewService(ctx, repo, gateway, paymentModel.Policy{
+		DepositPercent: cfg.Payment.DepositPercent,
+		Currency:       cfg.Payment.Currency,
+	}, taxPolicy)
+	if err != nil {
+		log.Fatalf("Payment service error: %v", err)
+	}
+	log.Printf("Payment service initialized (%s gateway)", gateway.Name())
+
+	reportSvc, err := report.NewService(ctx, repo, location)
+	if err != nil {
+		log.Fatalf("Report service error: %v", err)
+	}
+	log.Println("Report service initialized")
+
+	exchangeSvc, err := exchange.NewService(ctx, repo, location)
+	if err != nil {
+		log.Fatalf("Exchange service error: %v", err)
+	}
+	log.Println("Exchange service initialized")
+
+	calendarSvc, err := calendar.NewService(ctx, repo, location, cfg.Hotel.Name, cfg.Calendar.SyncInterval)
+	if err != nil {
+		log.Fatalf("Calendar service error: %v", err)
+	}
+	log.Println("Calendar service initialized")
+
+	calendarSvc.StartSyncWorker(ctx)
+
+	var channels []channel.OTAChannel
+	for _, name := range cfg.Channel.Enabled {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case "mock":
+			channels = append(channels, channel.NewFileChannel(name, cfg.Channel.MockDir))
+		default:
+			log.Fatalf("Channel %q is not supported", name)
+		}
+	}
+
+	channelSvc, err := channel.NewService(ctx, repo, bookingSvc, notificationSvc, location, channels, cfg.Channel.SyncInterval, cfg.Channel.HorizonDays)
+	if err != nil {
+		log.Fatalf("Channel service error: %v", err)
+	}
+	log.Printf("Channel service initialized (%d channels)", len(channels))
+
+	channelSvc.StartSyncWorker(ctx)
+
+	webhookPolicy := webhookModel.Policy{
+		MaxAttempts: cfg.Webhook.MaxAttempts,
+		Backoff:     cfg.Webhook.Backoff,
+		Timeout:     cfg.Webhook.Timeout,
+	}
+	webhookSvc, err := webhook.NewService(ctx, repo, notificationSvc.GetBroker(), webhookPolicy, cfg.Webhook.PollInterval)
+	if err != nil {
+		log.Fatalf("Webhook service error: %v", err)
+	}
+	log.Println("Webhook service initialized")
+
+	webhookSvc.StartWorker(ctx)
+
+	liveSvc, err := live.NewService(ctx, notificationSvc.GetBroker(), cfg.Live.HistorySize)
+	if err != nil {
+		log.Fatalf("Live updates service error: %v", err)
+	}
+	log.Println("Live updates service initialized")
+
+	liveSvc.StartWorker(ctx)
+
+	waitlistSvc, err := waitlist.NewService(ctx, repo, bookingSvc, loyaltySvc, notificationSvc, waitlist.Config{
+		HoldDuration: cfg.Waitlist.HoldDuration,
+		TierPriority: cfg.Waitlist.TierPriority,
+		HoldURL:      cfg.Waitlist.HoldURL,
+		ScanInterval: cfg.Waitlist.ScanInterval,
+	})
+	if err != nil {
+		log.Fatalf("Waitlist service error: %v", err)
+	}
+	log.Println("Waitlist service initialized")
+
+	waitlistSvc.StartWorker(ctx)
+
+	srv := server.New(cfg.HTTP.PORT, bookingSvc, notificationSvc, adminSvc, frontdeskSvc, housekeepingSvc, guestSvc, loyaltySvc, idempotencySvc, paymentSvc, folioSvc, reportSvc, exchangeSvc, calendarSvc, channelSvc, webhookSvc, liveSvc, waitlistSvc)
 	srv.RegisterRoutes()
 	srv.Start()
 
@@ -0,0 +1,96 @@
+package apperr
+
+import (
+	"errors"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+// Kind classifies a domain error; the server derives the HTTP status from it.
+type Kind string
+
+const (
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindValidation   Kind = "validation"
+	KindForbidden    Kind = "forbidden"
+	KindUnavailable  Kind = "unavailable"
+	KindPrecondition Kind = "precondition"
+	KindInternal     Kind = "internal"
+)
+
+// Error is a domain error. Code is a stable identifier and Message is safe to
+// show to clients; the wrapped cause is for logs only.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Err     error
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func Unavailable(code, message string) *Error {
+	return New(KindUnavailable, code, message)
+}
+
+func PreconditionFailed(code, message string) *Error {
+	return New(KindPrecondition, code, message)
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches any error of the same kind and code, so a wrapped copy still
+// matches the sentinel it was made from.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// KindOf returns the kind of err. Field validation errors are KindValidation
+// and anything unclassified is KindInternal.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return KindValidation
+	}
+	return KindInternal
+}
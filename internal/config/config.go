@@ -1,14 +1,26 @@
 package config
 
 import (
+	"time"
+
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
 )
 
 type (
 	Config struct {
-		HTTP     HTTP
-		Database Database
+		HTTP        HTTP
+		Database    Database
+		Hotel       Hotel
+		Loyalty     Loyalty
+		Idempotency Idempotency
+		Payment     Payment
+		Tax         Tax
+		Calendar    Calendar
+		Channel     Channel
+		Webhook     Webhook
+		Live        Live
+		Waitlist    Waitlist
 	}
 
 	HTTP struct {
@@ -18,6 +30,94 @@ type (
 	Database struct {
 		ConnectionString string `env:"DATABASE_URL,required"`
 	}
+
+	Hotel struct {
+		Name             string  `env:"HOTEL_NAME" envDefault:"Отель"`
+		Timezone         string  `env:"HOTEL_TIMEZONE" envDefault:"Europe/Moscow"`
+		CheckInTime      string  `env:"HOTEL_CHECK_IN_TIME" envDefault:"14:00"`
+		CheckOutTime     string  `env:"HOTEL_CHECK_OUT_TIME" envDefault:"12:00"`
+		EarlyCheckInTime string  `env:"HOTEL_EARLY_CHECK_IN_TIME" envDefault:"10:00"`
+		LateCheckOutTime string  `env:"HOTEL_LATE_CHECK_OUT_TIME" envDefault:"16:00"`
+		EarlyCheckInFee  float64 `env:"HOTEL_EARLY_CHECK_IN_FEE" envDefault:"1000"`
+		LateCheckOutFee  float64 `env:"HOTEL_LATE_CHECK_OUT_FEE" envDefault:"1000"`
+
+		NoShowPenaltyNights int    `env:"HOTEL_NO_SHOW_PENALTY_NIGHTS" envDefault:"1"`
+		NoShowRunTime       string `env:"HOTEL_NO_SHOW_RUN_TIME" envDefault:"03:00"`
+	}
+
+	Loyalty struct {
+		EarnRate        float64 `env:"LOYALTY_EARN_RATE" envDefault:"0.05"`
+		PointValue      float64 `env:"LOYALTY_POINT_VALUE" envDefault:"1"`
+		ExpiryDays      int     `env:"LOYALTY_EXPIRY_DAYS" envDefault:"730"`
+		QualifyingDays  int     `env:"LOYALTY_QUALIFYING_DAYS" envDefault:"365"`
+		SilverThreshold int     `env:"LOYALTY_SILVER_THRESHOLD" envDefault:"1000"`
+		GoldThreshold   int     `env:"LOYALTY_GOLD_THRESHOLD" envDefault:"5000"`
+		SilverDiscount  float64 `env:"LOYALTY_SILVER_DISCOUNT" envDefault:"0.05"`
+		GoldDiscount    float64 `env:"LOYALTY_GOLD_DISCOUNT" envDefault:"0.1"`
+	}
+
+	Idempotency struct {
+		// TTL is how long a response is replayed for retries of its request.
+		TTL time.Duration `env:"IDEMPOTENCY_TTL" envDefault:"24h"`
+	}
+
+	Payment struct {
+		Provider       string  `env:"PAYMENT_PROVIDER" envDefault:"fake"`
+		DepositPercent float64 `env:"PAYMENT_DEPOSIT_PERCENT" envDefault:"30"`
+		Currency       string  `env:"PAYMENT_CURRENCY" envDefault:"RUB"`
+		// WebhookSecret signs gateway webhooks; without it they are rejected.
+		WebhookSecret string `env:"PAYMENT_WEBHOOK_SECRET"`
+	}
+
+	Tax struct {
+		VATPercent float64 `env:"TAX_VAT_PERCENT" envDefault:"20"`
+		// VATIncluded means room prices already contain VAT.
+		VATIncluded bool `env:"TAX_VAT_INCLUDED" envDefault:"true"`
+		// CityTax is charged per guest per night.
+		CityTax float64 `env:"TAX_CITY_TAX" envDefault:"100"`
+	}
+
+	Calendar struct {
+		// SyncInterval is how often external iCal feeds are downloaded.
+		SyncInterval time.Duration `env:"CALENDAR_SYNC_INTERVAL" envDefault:"30m"`
+	}
+
+	Channel struct {
+		// Enabled lists the OTA channels to sync with; "mock" is a
+		// file-based channel for local testing.
+		Enabled      []string      `env:"CHANNELS" envSeparator:"," envDefault:"mock"`
+		SyncInterval time.Duration `env:"CHANNEL_SYNC_INTERVAL" envDefault:"5m"`
+		// HorizonDays is how far ahead availability and rates are pushed.
+		HorizonDays int `env:"CHANNEL_HORIZON_DAYS" envDefault:"365"`
+		// MockDir holds the inventory and reservations of the mock channel.
+		MockDir string `env:"CHANNEL_MOCK_DIR" envDefault:"channel-mock"`
+	}
+
+	Webhook struct {
+		// MaxAttempts is how many times a delivery is tried before it is
+		// marked failed; retries wait Backoff, doubling each time.
+		MaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+		Backoff     time.Duration `env:"WEBHOOK_BACKOFF" envDefault:"30s"`
+		Timeout     time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+		// PollInterval is how often due retries are looked for.
+		PollInterval time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"5s"`
+	}
+
+	Live struct {
+		// HistorySize is how many recent events are kept to replay to
+		// clients that reconnect to the event stream.
+		HistorySize int `env:"LIVE_HISTORY_SIZE" envDefault:"500"`
+	}
+
+	Waitlist struct {
+		// HoldDuration is how long a freed room is held for the next guest.
+		HoldDuration time.Duration `env:"WAITLIST_HOLD_DURATION" envDefault:"4h"`
+		// TierPriority serves guests of higher loyalty tiers first.
+		TierPriority bool `env:"WAITLIST_TIER_PRIORITY" envDefault:"false"`
+		// HoldURL is the page hold links sent to guests open.
+		HoldURL      string        `env:"WAITLIST_HOLD_URL" envDefault:"http://localhost:8080/ui/index.html"`
+		ScanInterval time.Duration `env:"WAITLIST_SCAN_INTERVAL" envDefault:"1m"`
+	}
 )
 
 func NewConfig() (*Config, error) {
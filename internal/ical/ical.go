@@ -0,0 +1,142 @@
+// Package ical writes and reads the subset of iCalendar (RFC 5545) used to
+// exchange room bookings: VCALENDAR objects holding VEVENTs.
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ContentType = "text/calendar; charset=utf-8"
+
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	// maxLineOctets is the longest content line before it must be folded.
+	maxLineOctets = 75
+)
+
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+// Event is a VEVENT. An all-day event spans the dates [Start, End); the
+// times of a timed event are written in UTC.
+type Event struct {
+	UID      string
+	Sequence int64
+	// Stamp is when the event was last changed; it defaults to now.
+	Stamp       time.Time
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Summary     string
+	Description string
+	Location    string
+	Status      string
+	// Transparent events do not make their time busy.
+	Transparent bool
+}
+
+type Calendar struct {
+	ProdID string
+	// Name is shown by clients that display the calendar as a subscription.
+	Name   string
+	Method string
+	Events []Event
+}
+
+// Marshal encodes the calendar with CRLF line endings and folded lines.
+func (c Calendar) Marshal() []byte {
+	var buf bytes.Buffer
+	w := &writer{w: &buf}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", c.ProdID)
+	w.line("CALSCALE", "GREGORIAN")
+	if c.Method != "" {
+		w.line("METHOD", c.Method)
+	}
+	if c.Name != "" {
+		w.line("X-WR-CALNAME", escape(c.Name))
+	}
+	for _, e := range c.Events {
+		w.event(e)
+	}
+	w.line("END", "VCALENDAR")
+	return buf.Bytes()
+}
+
+type writer struct {
+	w io.Writer
+}
+
+func (w *writer) event(e Event) {
+	w.line("BEGIN", "VEVENT")
+	w.line("UID", e.UID)
+	stamp := e.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	w.line("DTSTAMP", formatUTC(stamp))
+	if e.AllDay {
+		w.line("DTSTART;VALUE=DATE", e.Start.Format(dateLayout))
+		w.line("DTEND;VALUE=DATE", e.End.Format(dateLayout))
+	} else {
+		w.line("DTSTART", formatUTC(e.Start))
+		w.line("DTEND", formatUTC(e.End))
+	}
+	if e.Sequence > 0 {
+		w.line("SEQUENCE", fmt.Sprint(e.Sequence))
+	}
+	w.line("SUMMARY", escape(e.Summary))
+	if e.Description != "" {
+		w.line("DESCRIPTION", escape(e.Description))
+	}
+	if e.Location != "" {
+		w.line("LOCATION", escape(e.Location))
+	}
+	if e.Status != "" {
+		w.line("STATUS", e.Status)
+	}
+	if e.Transparent {
+		w.line("TRANSP", "TRANSPARENT")
+	} else {
+		w.line("TRANSP", "OPAQUE")
+	}
+	w.line("END", "VEVENT")
+}
+
+// line writes "name:value" folded into lines of at most 75 octets, never
+// splitting a UTF-8 sequence.
+func (w *writer) line(name, value string) {
+	s := name + ":" + value
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		io.WriteString(w.w, s[:cut]+"\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space that counts toward the limit.
+		limit = maxLineOctets - 1
+	}
+	io.WriteString(w.w, s+"\r\n")
+}
+
+func formatUTC(t time.Time) string {
+	return t.UTC().Format(dateTimeLayout) + "Z"
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
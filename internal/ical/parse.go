@@ -0,0 +1,247 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLineLength guards against unbounded content lines in foreign feeds.
+const maxLineLength = 64 * 1024
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the VEVENTs of a calendar. Floating times and times in an
+// unknown TZID are taken in loc. Properties the package does not model are
+// ignored, and so are events without a start.
+func Parse(r io.Reader, loc *time.Location) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var current *Event
+	var duration time.Duration
+	depth := 0
+	for _, line := range lines {
+		p, ok := parseProperty(line)
+		if !ok {
+			continue
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT"):
+			current, duration, depth = &Event{}, 0, 0
+			continue
+		case current == nil:
+			continue
+		case p.name == "BEGIN":
+			// Nested components such as VALARM have their own properties.
+			depth++
+			continue
+		case p.name == "END" && depth > 0:
+			depth--
+			continue
+		case p.name == "END" && strings.EqualFold(p.value, "VEVENT"):
+			if !current.Start.IsZero() {
+				if current.End.IsZero() {
+					current.End = defaultEnd(*current, duration)
+				}
+				events = append(events, *current)
+			}
+			current = nil
+			continue
+		case depth > 0:
+			continue
+		}
+
+		switch p.name {
+		case "UID":
+			current.UID = p.value
+		case "SUMMARY":
+			current.Summary = unescape(p.value)
+		case "DESCRIPTION":
+			current.Description = unescape(p.value)
+		case "LOCATION":
+			current.Location = unescape(p.value)
+		case "STATUS":
+			current.Status = strings.ToUpper(p.value)
+		case "TRANSP":
+			current.Transparent = strings.EqualFold(p.value, "TRANSPARENT")
+		case "SEQUENCE":
+			current.Sequence, _ = strconv.ParseInt(p.value, 10, 64)
+		case "DTSTAMP":
+			current.Stamp, _, _ = parseTime(p, loc)
+		case "DTSTART":
+			t, allDay, err := parseTime(p, loc)
+			if err != nil {
+				return nil, err
+			}
+			current.Start, current.AllDay = t, allDay
+		case "DTEND":
+			t, _, err := parseTime(p, loc)
+			if err != nil {
+				return nil, err
+			}
+			current.End = t
+		case "DURATION":
+			d, err := parseDuration(p.value)
+			if err != nil {
+				return nil, err
+			}
+			duration = d
+		}
+	}
+	return events, nil
+}
+
+// defaultEnd applies DURATION, or the RFC 5545 defaults: one day for an
+// all-day event and no length for a timed one.
+func defaultEnd(e Event, duration time.Duration) time.Time {
+	if duration > 0 {
+		return e.Start.Add(duration)
+	}
+	if e.AllDay {
+		return e.Start.AddDate(0, 0, 1)
+	}
+	return e.Start
+}
+
+// unfold joins continuation lines, which start with a space or a tab.
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 4096), maxLineLength)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ical: %w", err)
+	}
+	return lines, nil
+}
+
+// parseProperty splits "NAME;PARAM=value:content", where parameter values
+// may be quoted and contain colons.
+func parseProperty(line string) (property, bool) {
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return property{}, false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	p := property{name: strings.ToUpper(parts[0]), params: make(map[string]string), value: line[colon+1:]}
+	for _, param := range parts[1:] {
+		if key, value, ok := strings.Cut(param, "="); ok {
+			p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return p, true
+}
+
+// parseTime reads a DATE or DATE-TIME value and reports whether it was a date.
+func parseTime(p property, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(p.value)
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, time.UTC)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("ical: invalid %s %q", p.name, value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeLayout, strings.TrimSuffix(value, "Z"))
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("ical: invalid %s %q", p.name, value)
+		}
+		return t, false, nil
+	}
+
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("ical: invalid %s %q", p.name, value)
+	}
+	return t, false, nil
+}
+
+// parseDuration reads a positive dur-value such as P1D, PT12H or P2W.
+func parseDuration(value string) (time.Duration, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(value), "+")
+	if !strings.HasPrefix(s, "P") {
+		return 0, fmt.Errorf("ical: invalid DURATION %q", value)
+	}
+	s = s[1:]
+
+	var total time.Duration
+	inTime := false
+	number := ""
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, fmt.Errorf("ical: invalid DURATION %q", value)
+		}
+		number = ""
+		switch {
+		case r == 'W' && !inTime:
+			total += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			total += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			total += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			total += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			total += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("ical: invalid DURATION %q", value)
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("ical: invalid DURATION %q", value)
+	}
+	return total, nil
+}
+
+var unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
@@ -0,0 +1,62 @@
+package booking
+
+type Restriction string
+
+const (
+	RestrictionBooked       Restriction = "booked"
+	RestrictionOutOfOrder   Restriction = "out_of_order"
+	RestrictionOutOfService Restriction = "out_of_service"
+	RestrictionPast         Restriction = "past"
+)
+
+// MaxAvailabilityNights caps the range of a single availability request.
+const MaxAvailabilityNights = 366
+
+type AvailabilityRequest struct {
+	From     Date     `json:"from"`
+	To       Date     `json:"to"`
+	RoomType RoomType `json:"room_type,omitempty"`
+}
+
+// RoomNight is the state of one room on one night as read from the database.
+type RoomNight struct {
+	Room              Room
+	Date              Date
+	Booked            bool
+	MaintenanceKind   MaintenanceKind
+	MaintenanceReason string
+}
+
+type NightAvailability struct {
+	Date        Date        `json:"date"`
+	Available   bool        `json:"available"`
+	Price       float64     `json:"price"`
+	PriceReason string      `json:"price_reason"`
+	Restriction Restriction `json:"restriction,omitempty"`
+	Note        string      `json:"note,omitempty"`
+}
+
+type RoomAvailability struct {
+	Room   Room                `json:"room"`
+	Nights []NightAvailability `json:"nights"`
+}
+
+type RoomTypeNight struct {
+	Date       Date    `json:"date"`
+	Available  int     `json:"available"`
+	Total      int     `json:"total"`
+	MinPrice   float64 `json:"min_price"`
+	Restricted int     `json:"restricted"`
+}
+
+type RoomTypeAvailability struct {
+	RoomType RoomType        `json:"room_type"`
+	Nights   []RoomTypeNight `json:"nights"`
+}
+
+type AvailabilityCalendar struct {
+	From      Date                   `json:"from"`
+	To        Date                   `json:"to"`
+	Rooms     []RoomAvailability     `json:"rooms"`
+	RoomTypes []RoomTypeAvailability `json:"room_types"`
+}
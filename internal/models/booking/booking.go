@@ -1,17 +1,49 @@
 package booking
 
 import (
+	"slices"
 	"time"
 )
 
 type BookingStatus string
 
 const (
-	BookingStatusPending   BookingStatus = "pending"
-	BookingStatusConfirmed BookingStatus = "confirmed"
-	BookingStatusCancelled BookingStatus = "cancelled"
+	BookingStatusPending    BookingStatus = "pending"
+	BookingStatusConfirmed  BookingStatus = "confirmed"
+	BookingStatusCancelled  BookingStatus = "cancelled"
+	BookingStatusCheckedIn  BookingStatus = "checked_in"
+	BookingStatusCheckedOut BookingStatus = "checked_out"
+	BookingStatusNoShow     BookingStatus = "no_show"
 )
 
+func (s BookingStatus) IsValid() bool {
+	switch s {
+	case BookingStatusPending, BookingStatusConfirmed, BookingStatusCancelled,
+		BookingStatusCheckedIn, BookingStatusCheckedOut, BookingStatusNoShow:
+		return true
+	}
+	return false
+}
+
+// statusChanges lists the statuses staff may move a booking to by hand.
+// Check-in, checkout and no-show have flows of their own, and bookings
+// that reached them are not reopened.
+var statusChanges = map[BookingStatus][]BookingStatus{
+	BookingStatusPending:   {BookingStatusConfirmed, BookingStatusCancelled},
+	BookingStatusConfirmed: {BookingStatusPending, BookingStatusCancelled},
+	BookingStatusCancelled: {BookingStatusPending, BookingStatusConfirmed},
+}
+
+// CanBecome reports whether a booking in status s may be moved to status.
+func (s BookingStatus) CanBecome(status BookingStatus) bool {
+	return slices.Contains(statusChanges[s], status)
+}
+
+// HoldsRoom reports whether a booking in status s occupies its room.
+func (s BookingStatus) HoldsRoom() bool {
+	return s != BookingStatusCancelled && s != BookingStatusNoShow
+}
+
 type GuestInfo struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
@@ -19,15 +51,40 @@ type GuestInfo struct {
 }
 
 type Booking struct {
-	ID        int64         `json:"id" db:"id"`
-	StartDate time.Time     `json:"start_date" db:"start_date"`
-	EndDate   time.Time     `json:"end_date" db:"end_date"`
-	RoomID    int64         `json:"room_id" db:"room_id"`
-	GuestInfo GuestInfo     `json:"guest_info" db:"guest_info"`
-	Price     float64       `json:"price" db:"price"`
-	Status    BookingStatus `json:"status" db:"status"`
-	CreatedAt time.Time     `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time     `json:"updated_at" db:"updated_at"`
+	ID             int64           `json:"id" db:"id"`
+	StartDate      Date            `json:"start_date" db:"start_date"`
+	EndDate        Date            `json:"end_date" db:"end_date"`
+	RoomID         int64           `json:"room_id" db:"room_id"`
+	GuestInfo      GuestInfo       `json:"guest_info" db:"guest_info"`
+	GuestID        *int64          `json:"guest_id,omitempty" db:"guest_id"`
+	Guests         int             `json:"guests" db:"guests"`
+	Price          float64         `json:"price" db:"price"`
+	Status         BookingStatus   `json:"status" db:"status"`
+	EarlyCheckIn   bool            `json:"early_check_in" db:"early_check_in"`
+	LateCheckOut   bool            `json:"late_check_out" db:"late_check_out"`
+	CheckInTime    string          `json:"check_in_time" db:"check_in_time"`
+	CheckOutTime   string          `json:"check_out_time" db:"check_out_time"`
+	CheckedInAt    *time.Time      `json:"checked_in_at,omitempty" db:"checked_in_at"`
+	CheckedOutAt   *time.Time      `json:"checked_out_at,omitempty" db:"checked_out_at"`
+	CheckInDetails *CheckInDetails `json:"check_in_details,omitempty" db:"check_in_details"`
+	Penalty        float64         `json:"penalty,omitempty" db:"penalty"`
+	PointsRedeemed int             `json:"points_redeemed,omitempty" db:"points_redeemed"`
+	PointsAmount   float64         `json:"points_amount,omitempty" db:"points_amount"`
+	Version        int64           `json:"version" db:"version"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// Nights is the length of the stay.
+func (b Booking) Nights() int {
+	return b.StartDate.DaysUntil(b.EndDate)
+}
+
+// CheckInDetails is captured by the front desk when the guest arrives.
+type CheckInDetails struct {
+	DocumentType   string   `json:"document_type"`
+	DocumentNumber string   `json:"document_number"`
+	KeyCards       []string `json:"key_cards,omitempty"`
 }
 
 type BookingWithRoom struct {
@@ -37,9 +94,31 @@ type BookingWithRoom struct {
 
 type CreateBookingRequest struct {
 	RoomID    int64     `json:"room_id"`
-	StartDate time.Time `json:"start_date"`
-	EndDate   time.Time `json:"end_date"`
+	StartDate Date      `json:"start_date"`
+	EndDate   Date      `json:"end_date"`
 	GuestInfo GuestInfo `json:"guest_info"`
+	// Guests defaults to one.
+	Guests int `json:"guests"`
+	// RedeemPoints pays part of the price with the guest's loyalty points.
+	RedeemPoints int `json:"redeem_points"`
+	// Total replaces the calculated price, e.g. with what a channel charged
+	// the guest. Clients can't set it.
+	Total float64 `json:"-"`
+	// Confirmed creates the booking confirmed, for stays already paid
+	// elsewhere. Clients can't set it.
+	Confirmed bool `json:"-"`
+	StayOptions
+}
+
+// MoveBookingRequest moves a booking to another room and/or dates. Zero
+// fields keep the booking's current value; when only StartDate is given the
+// length of stay is preserved.
+type MoveBookingRequest struct {
+	RoomID    int64 `json:"room_id"`
+	StartDate Date  `json:"start_date"`
+	EndDate   Date  `json:"end_date"`
+	// Total replaces the recalculated price, as in CreateBookingRequest.
+	Total float64 `json:"-"`
 }
 
 type BookingResponse struct {
@@ -47,3 +126,18 @@ type BookingResponse struct {
 	Room   Room `json:"room"`
 	Nights int  `json:"nights"`
 }
+
+// BookingSearchQuery is a normalized front-desk search: Text is matched
+// against guest name and email, Phone against the digits of the guest phone
+// and ID against the booking ID.
+type BookingSearchQuery struct {
+	Text  string
+	Phone string
+	ID    int64
+	Limit int
+}
+
+type BookingSearchResult struct {
+	BookingWithRoom
+	Rank float64 `json:"rank"`
+}
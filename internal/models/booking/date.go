@@ -0,0 +1,171 @@
+package booking
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day without a time of day or location. Internally it is
+// kept at UTC midnight so that day arithmetic is never affected by DST.
+type Date struct {
+	t time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{t: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateOf returns the calendar day of t as seen in t's own location.
+func DateOf(t time.Time) Date {
+	if t.IsZero() {
+		return Date{}
+	}
+	y, m, d := t.Date()
+	return NewDate(y, m, d)
+}
+
+// Today returns the current calendar day in the given location.
+func Today(loc *time.Location) Date {
+	return DateOf(time.Now().In(loc))
+}
+
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return DateOf(t), nil
+}
+
+func (d Date) IsZero() bool {
+	return d.t.IsZero()
+}
+
+func (d Date) Year() int {
+	return d.t.Year()
+}
+
+func (d Date) Month() time.Month {
+	return d.t.Month()
+}
+
+func (d Date) Day() int {
+	return d.t.Day()
+}
+
+func (d Date) Weekday() time.Weekday {
+	return d.t.Weekday()
+}
+
+func (d Date) AddDays(n int) Date {
+	return Date{t: d.t.AddDate(0, 0, n)}
+}
+
+// DaysUntil returns the number of calendar days from d to other.
+func (d Date) DaysUntil(other Date) int {
+	return int(other.t.Sub(d.t).Hours() / 24)
+}
+
+func (d Date) Before(other Date) bool {
+	return d.t.Before(other.t)
+}
+
+func (d Date) After(other Date) bool {
+	return d.t.After(other.t)
+}
+
+func (d Date) Equal(other Date) bool {
+	return d.t.Equal(other.t)
+}
+
+// In returns the instant at which the day starts in the given location.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.t.Year(), d.t.Month(), d.t.Day(), 0, 0, 0, 0, loc)
+}
+
+func (d Date) Format(layout string) string {
+	return d.t.Format(layout)
+}
+
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.t.Format(DateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts "YYYY-MM-DD" as well as RFC 3339 timestamps sent by
+// older clients; for the latter the date is taken as written, ignoring the offset.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*d = Date{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*d = Date{}
+		return nil
+	}
+
+	if parsed, err := ParseDate(s); err == nil {
+		*d = parsed
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return fmt.Errorf("invalid date %q: use YYYY-MM-DD", s)
+	}
+	*d = DateOf(t)
+	return nil
+}
+
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.String(), nil
+}
+
+func (d *Date) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = DateOf(v)
+	case string:
+		return d.scanString(v)
+	case []byte:
+		return d.scanString(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+	return nil
+}
+
+func (d *Date) scanString(s string) error {
+	if len(s) > len(DateLayout) {
+		s = s[:len(DateLayout)]
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
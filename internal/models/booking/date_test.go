@@ -0,0 +1,157 @@
+package booking
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+// In 2026 New York springs forward on March 8 and falls back on November 1.
+func newYork(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	return loc
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Date
+		wantErr bool
+	}{
+		{in: "2026-03-08", want: NewDate(2026, time.March, 8)},
+		{in: "2026-11-01", want: NewDate(2026, time.November, 1)},
+		{in: "2026-02-30", wantErr: true},
+		{in: "08.03.2026", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseDate(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseDate(%q) = %v, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDate(%q): %v", tt.in, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseDate(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+			if got.String() != tt.in {
+				t.Errorf("String() = %q, want %q", got.String(), tt.in)
+			}
+		})
+	}
+}
+
+func TestDateOfAcrossDST(t *testing.T) {
+	ny := newYork(t)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want Date
+	}{
+		{"before spring forward", time.Date(2026, time.March, 8, 1, 30, 0, 0, ny), NewDate(2026, time.March, 8)},
+		{"after spring forward", time.Date(2026, time.March, 8, 3, 30, 0, 0, ny), NewDate(2026, time.March, 8)},
+		{"end of short day", time.Date(2026, time.March, 8, 23, 59, 0, 0, ny), NewDate(2026, time.March, 8)},
+		// 05:30 UTC is 01:30 EDT, 06:30 UTC is 01:30 EST: the hour that repeats.
+		{"first 01:30 of fall back", time.Date(2026, time.November, 1, 5, 30, 0, 0, time.UTC).In(ny), NewDate(2026, time.November, 1)},
+		{"second 01:30 of fall back", time.Date(2026, time.November, 1, 6, 30, 0, 0, time.UTC).In(ny), NewDate(2026, time.November, 1)},
+		{"end of long day", time.Date(2026, time.November, 1, 23, 59, 0, 0, ny), NewDate(2026, time.November, 1)},
+		// Late evening in New York is already the next day in UTC.
+		{"evening before spring forward", time.Date(2026, time.March, 7, 22, 0, 0, 0, ny), NewDate(2026, time.March, 7)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DateOf(tt.at); !got.Equal(tt.want) {
+				t.Errorf("DateOf(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToday(t *testing.T) {
+	for _, loc := range []*time.Location{time.UTC, newYork(t), time.FixedZone("UTC+14", 14*60*60)} {
+		t.Run(loc.String(), func(t *testing.T) {
+			before := DateOf(time.Now().In(loc))
+			got := Today(loc)
+			after := DateOf(time.Now().In(loc))
+
+			// The day may roll over between the calls.
+			if !got.Equal(before) && !got.Equal(after) {
+				t.Errorf("Today(%v) = %v, want %v", loc, got, before)
+			}
+		})
+	}
+}
+
+func TestDayArithmeticAcrossDST(t *testing.T) {
+	ny := newYork(t)
+
+	tests := []struct {
+		name       string
+		from, to   Date
+		days       int
+		dayLengths time.Duration
+	}{
+		{"spring forward night", NewDate(2026, time.March, 8), NewDate(2026, time.March, 9), 1, 23 * time.Hour},
+		{"stay over spring forward", NewDate(2026, time.March, 6), NewDate(2026, time.March, 10), 4, 4*24*time.Hour - time.Hour},
+		{"fall back night", NewDate(2026, time.November, 1), NewDate(2026, time.November, 2), 1, 25 * time.Hour},
+		{"stay over fall back", NewDate(2026, time.October, 30), NewDate(2026, time.November, 3), 4, 4*24*time.Hour + time.Hour},
+		{"month over spring forward", NewDate(2026, time.March, 1), NewDate(2026, time.April, 1), 31, 31*24*time.Hour - time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.from.DaysUntil(tt.to); got != tt.days {
+				t.Errorf("DaysUntil = %d, want %d", got, tt.days)
+			}
+			if got := tt.to.DaysUntil(tt.from); got != -tt.days {
+				t.Errorf("reverse DaysUntil = %d, want %d", got, -tt.days)
+			}
+			if got := tt.from.AddDays(tt.days); !got.Equal(tt.to) {
+				t.Errorf("AddDays(%d) = %v, want %v", tt.days, got, tt.to)
+			}
+			if got := tt.to.AddDays(-tt.days); !got.Equal(tt.from) {
+				t.Errorf("AddDays(%d) = %v, want %v", -tt.days, got, tt.from)
+			}
+
+			// The wall-clock length differs, the number of nights does not.
+			if got := tt.to.In(ny).Sub(tt.from.In(ny)); got != tt.dayLengths {
+				t.Errorf("local length = %v, want %v", got, tt.dayLengths)
+			}
+			if got := DateOf(tt.from.In(ny)); !got.Equal(tt.from) {
+				t.Errorf("DateOf(In) = %v, want %v", got, tt.from)
+			}
+		})
+	}
+}
+
+func TestDateFromLocalTimestamps(t *testing.T) {
+	ny := newYork(t)
+
+	var d Date
+	if err := json.Unmarshal([]byte(`"2026-03-08T00:00:00-05:00"`), &d); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if want := NewDate(2026, time.March, 8); !d.Equal(want) {
+		t.Errorf("unmarshal = %v, want %v", d, want)
+	}
+
+	if err := d.Scan(time.Date(2026, time.November, 1, 0, 0, 0, 0, ny)); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if want := NewDate(2026, time.November, 1); !d.Equal(want) {
+		t.Errorf("scan = %v, want %v", d, want)
+	}
+}
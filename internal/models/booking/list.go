@@ -0,0 +1,67 @@
+package booking
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// PageRequest holds keyset pagination and sorting parameters. Cursor is the
+// opaque next_cursor of the previous page.
+type PageRequest struct {
+	Limit  int       `json:"limit"`
+	Cursor string    `json:"cursor"`
+	Sort   string    `json:"sort"`
+	Order  SortOrder `json:"order"`
+}
+
+// Normalize clamps the limit and fills in the default sort.
+func (p *PageRequest) Normalize(defaultSort string, defaultOrder SortOrder) {
+	if p.Limit <= 0 {
+		p.Limit = DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		p.Limit = MaxPageLimit
+	}
+	if p.Sort == "" {
+		p.Sort = defaultSort
+	}
+	if p.Order != SortAsc && p.Order != SortDesc {
+		p.Order = defaultOrder
+	}
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int    `json:"total"`
+}
+
+type BookingFilter struct {
+	Status     BookingStatus
+	RoomID     int64
+	GuestEmail string
+	// From and To select bookings whose stay overlaps [From, To).
+	From     Date
+	To       Date
+	MinPrice float64
+	MaxPrice float64
+	PageRequest
+}
+
+type RoomFilter struct {
+	RoomType    RoomType
+	Status      RoomStatus
+	MinCapacity int
+	MinPrice    float64
+	MaxPrice    float64
+	// Archived lists archived rooms instead of the rooms in service.
+	Archived bool
+	PageRequest
+}
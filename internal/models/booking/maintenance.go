@@ -0,0 +1,54 @@
+package booking
+
+import (
+	"time"
+)
+
+type MaintenanceKind string
+
+const (
+	// MaintenanceKindOutOfOrder rooms are physically unusable and are
+	// removed from inventory.
+	MaintenanceKindOutOfOrder MaintenanceKind = "out_of_order"
+	// MaintenanceKindOutOfService rooms are usable but deliberately not sold,
+	// e.g. for minor works or a VIP hold.
+	MaintenanceKindOutOfService MaintenanceKind = "out_of_service"
+	// MaintenanceKindExternal windows are imported from an external
+	// calendar feed, e.g. a stay sold by another channel.
+	MaintenanceKindExternal MaintenanceKind = "external"
+)
+
+// MaintenanceWindow blocks a room from StartDate up to, but not including,
+// EndDate, the same way a booking occupies its nights.
+type MaintenanceWindow struct {
+	ID        int64           `json:"id" db:"id"`
+	RoomID    int64           `json:"room_id" db:"room_id"`
+	StartDate Date            `json:"start_date" db:"start_date"`
+	EndDate   Date            `json:"end_date" db:"end_date"`
+	Kind      MaintenanceKind `json:"kind" db:"kind"`
+	Reason    string          `json:"reason" db:"reason"`
+	// FeedID is the calendar feed an external window was imported from.
+	FeedID    *int64    `json:"feed_id,omitempty" db:"ical_feed_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateMaintenanceWindowRequest struct {
+	RoomID    int64           `json:"room_id"`
+	StartDate Date            `json:"start_date"`
+	EndDate   Date            `json:"end_date"`
+	Kind      MaintenanceKind `json:"kind"`
+	Reason    string          `json:"reason"`
+	DryRun    bool            `json:"dry_run"`
+}
+
+type RelocationProposal struct {
+	Booking    Booking `json:"booking"`
+	Candidates []Room  `json:"candidates"`
+}
+
+type MaintenanceWindowResult struct {
+	Window    MaintenanceWindow    `json:"window"`
+	Created   bool                 `json:"created"`
+	Conflicts []RelocationProposal `json:"conflicts"`
+	Warning   string               `json:"warning,omitempty"`
+}
@@ -0,0 +1,105 @@
+package booking
+
+import (
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+// RoomPatch is a partial room update: nil fields keep their current value.
+type RoomPatch struct {
+	RoomNumber  *string     `json:"room_number"`
+	RoomType    *RoomType   `json:"room_type"`
+	BasePrice   *float64    `json:"base_price"`
+	Capacity    *int        `json:"capacity"`
+	Status      *RoomStatus `json:"status"`
+	Description *string     `json:"description"`
+}
+
+// Patch returns the patch that replaces every editable field with the values
+// of r, which is how a full update (PUT) is applied.
+func (r Room) Patch() RoomPatch {
+	return RoomPatch{
+		RoomNumber:  &r.RoomNumber,
+		RoomType:    &r.RoomType,
+		BasePrice:   &r.BasePrice,
+		Capacity:    &r.Capacity,
+		Status:      &r.Status,
+		Description: &r.Description,
+	}
+}
+
+// Apply writes the present fields to room and validates the result.
+func (p RoomPatch) Apply(room *Room) error {
+	if p.RoomNumber != nil {
+		room.RoomNumber = *p.RoomNumber
+	}
+	if p.RoomType != nil {
+		room.RoomType = *p.RoomType
+	}
+	if p.BasePrice != nil {
+		room.BasePrice = *p.BasePrice
+	}
+	if p.Capacity != nil {
+		room.Capacity = *p.Capacity
+	}
+	if p.Status != nil {
+		room.Status = *p.Status
+	}
+	if p.Description != nil {
+		room.Description = *p.Description
+	}
+	return room.Validate()
+}
+
+type SpecialDatePatch struct {
+	Date        *Date    `json:"date"`
+	Name        *string  `json:"name"`
+	Coefficient *float64 `json:"coefficient"`
+}
+
+func (r CreateSpecialDateRequest) Patch() SpecialDatePatch {
+	return SpecialDatePatch{Date: &r.Date, Name: &r.Name, Coefficient: &r.Coefficient}
+}
+
+func (p SpecialDatePatch) Apply(sd *SpecialDate) error {
+	if p.Date != nil {
+		sd.Date = *p.Date
+	}
+	if p.Name != nil {
+		sd.Name = *p.Name
+	}
+	if p.Coefficient != nil {
+		sd.Coefficient = *p.Coefficient
+	}
+	return sd.Validate()
+}
+
+type GuestInfoPatch struct {
+	Name  *string `json:"name"`
+	Email *string `json:"email"`
+	Phone *string `json:"phone"`
+}
+
+// BookingPatch corrects the guest details of a booking. Dates and rooms are
+// changed with MoveBookingRequest, which re-checks availability and price.
+type BookingPatch struct {
+	GuestInfo *GuestInfoPatch `json:"guest_info"`
+}
+
+func (p BookingPatch) Apply(b *Booking) error {
+	if p.GuestInfo == nil {
+		return nil
+	}
+	if p.GuestInfo.Name != nil {
+		b.GuestInfo.Name = *p.GuestInfo.Name
+	}
+	if p.GuestInfo.Email != nil {
+		b.GuestInfo.Email = *p.GuestInfo.Email
+	}
+	if p.GuestInfo.Phone != nil {
+		b.GuestInfo.Phone = *p.GuestInfo.Phone
+	}
+
+	var v validation.Validator
+	b.GuestInfo.validate(&v, "guest_info.")
+	return v.Err()
+}
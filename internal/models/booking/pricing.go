@@ -15,28 +15,32 @@ const (
 
 type PricingAlgorithm struct {
 	ID            int64         `json:"id" db:"id"`
-	Date          time.Time     `json:"date" db:"date"`
+	Date          Date          `json:"date" db:"date"`
 	AlgorithmType AlgorithmType `json:"algorithm_type" db:"algorithm_type"`
 }
 
 type SpecialDate struct {
 	ID          int64     `json:"id" db:"id"`
-	Date        time.Time `json:"date" db:"date"`
+	Date        Date      `json:"date" db:"date"`
 	Name        string    `json:"name" db:"name"`
 	Coefficient float64   `json:"coefficient" db:"coefficient"`
+	Version     int64     `json:"version" db:"version"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
 type CreateSpecialDateRequest struct {
-	Date        string  `json:"date"`
+	Date        Date    `json:"date"`
 	Name        string  `json:"name"`
 	Coefficient float64 `json:"coefficient"`
 }
 
 type PriceCalculationRequest struct {
-	RoomID   int64     `json:"room_id"`
-	CheckIn  time.Time `json:"check_in"`
-	CheckOut time.Time `json:"check_out"`
+	RoomID   int64 `json:"room_id"`
+	CheckIn  Date  `json:"check_in"`
+	CheckOut Date  `json:"check_out"`
+	// GuestEmail applies the guest's loyalty tier discount when set.
+	GuestEmail string `json:"guest_email,omitempty"`
+	StayOptions
 }
 
 type PriceCalculationResponse struct {
@@ -44,10 +48,24 @@ type PriceCalculationResponse struct {
 	TotalPrice     float64        `json:"total_price"`
 	Nights         int            `json:"nights"`
 	DailyBreakdown []DayPriceInfo `json:"daily_breakdown"`
+	Extras         []ExtraCharge  `json:"extras,omitempty"`
+}
+
+// Discount is a percentage taken off the room nights, e.g. a loyalty tier.
+type Discount struct {
+	Code string
+	Name string
+	Rate float64
+}
+
+type ExtraCharge struct {
+	Code  string  `json:"code"`
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
 }
 
 type DayPriceInfo struct {
-	Date        string  `json:"date"`
+	Date        Date    `json:"date"`
 	BasePrice   float64 `json:"base_price"`
 	Coefficient float64 `json:"coefficient"`
 	Reason      string  `json:"reason"`
@@ -16,21 +16,51 @@ const (
 type RoomStatus string
 
 const (
-	RoomStatusAvailable   RoomStatus = "available"
-	RoomStatusOccupied    RoomStatus = "occupied"
+	RoomStatusAvailable RoomStatus = "available"
+	RoomStatusOccupied  RoomStatus = "occupied"
+	// Deprecated: block the room with a MaintenanceWindow instead, which
+	// only removes it from inventory for the affected dates.
 	RoomStatusMaintenance RoomStatus = "maintenance"
 )
 
+// HousekeepingStatus is the physical state of a room, independent of whether
+// it is sold or occupied.
+type HousekeepingStatus string
+
+const (
+	HousekeepingStatusClean      HousekeepingStatus = "clean"
+	HousekeepingStatusDirty      HousekeepingStatus = "dirty"
+	HousekeepingStatusInspected  HousekeepingStatus = "inspected"
+	HousekeepingStatusOutOfOrder HousekeepingStatus = "out_of_order"
+)
+
+func (s HousekeepingStatus) IsValid() bool {
+	switch s {
+	case HousekeepingStatusClean, HousekeepingStatusDirty, HousekeepingStatusInspected, HousekeepingStatusOutOfOrder:
+		return true
+	}
+	return false
+}
+
 type Room struct {
-	ID          int64      `json:"id" db:"id"`
-	RoomNumber  string     `json:"room_number" db:"room_number"`
-	RoomType    RoomType   `json:"room_type" db:"room_type"`
-	BasePrice   float64    `json:"base_price" db:"base_price"`
-	Capacity    int        `json:"capacity" db:"capacity"`
-	Status      RoomStatus `json:"status" db:"status"`
-	Description string     `json:"description" db:"description"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ID                 int64              `json:"id" db:"id"`
+	RoomNumber         string             `json:"room_number" db:"room_number"`
+	RoomType           RoomType           `json:"room_type" db:"room_type"`
+	BasePrice          float64            `json:"base_price" db:"base_price"`
+	Capacity           int                `json:"capacity" db:"capacity"`
+	Status             RoomStatus         `json:"status" db:"status"`
+	HousekeepingStatus HousekeepingStatus `json:"housekeeping_status" db:"housekeeping_status"`
+	Description        string             `json:"description" db:"description"`
+	ArchivedAt         *time.Time         `json:"archived_at,omitempty" db:"archived_at"`
+	Version            int64              `json:"version" db:"version"`
+	CreatedAt          time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// IsArchived reports whether the room was taken out of service. Archived
+// rooms are no longer sold but keep their booking history.
+func (r Room) IsArchived() bool {
+	return r.ArchivedAt != nil
 }
 
 type RoomTypeInfo struct {
@@ -46,10 +76,22 @@ type RoomTypeInfo struct {
 }
 
 type RoomSearchRequest struct {
-	CheckIn  time.Time `json:"check_in"`
-	CheckOut time.Time `json:"check_out"`
-	RoomType RoomType  `json:"room_type,omitempty"`
-	Capacity int       `json:"capacity,omitempty"`
+	CheckIn  Date     `json:"check_in"`
+	CheckOut Date     `json:"check_out"`
+	RoomType RoomType `json:"room_type,omitempty"`
+	Capacity int      `json:"capacity,omitempty"`
+}
+
+// Relocation moves one booking of a room being archived to another room.
+type Relocation struct {
+	BookingID int64 `json:"booking_id"`
+	RoomID    int64 `json:"room_id"`
+}
+
+// ArchiveRoomRequest carries the relocation plan for the room's current and
+// upcoming bookings; every such booking must be relocated.
+type ArchiveRoomRequest struct {
+	Relocations []Relocation `json:"relocations"`
 }
 
 type RoomWithAvailability struct {
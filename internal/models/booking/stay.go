@@ -0,0 +1,37 @@
+package booking
+
+const (
+	ExtraEarlyCheckIn = "early_check_in"
+	ExtraLateCheckOut = "late_check_out"
+)
+
+type StayOptions struct {
+	EarlyCheckIn bool `json:"early_check_in"`
+	LateCheckOut bool `json:"late_check_out"`
+}
+
+// StayPolicy holds the hotel's standard arrival and departure times together
+// with the times and fees of the early check-in and late checkout upsells.
+// Times are "HH:MM" in the hotel timezone.
+type StayPolicy struct {
+	CheckInTime      string  `json:"check_in_time"`
+	CheckOutTime     string  `json:"check_out_time"`
+	EarlyCheckInTime string  `json:"early_check_in_time"`
+	LateCheckOutTime string  `json:"late_check_out_time"`
+	EarlyCheckInFee  float64 `json:"early_check_in_fee"`
+	LateCheckOutFee  float64 `json:"late_check_out_fee"`
+}
+
+func (p StayPolicy) ArrivalTime(earlyCheckIn bool) string {
+	if earlyCheckIn {
+		return p.EarlyCheckInTime
+	}
+	return p.CheckInTime
+}
+
+func (p StayPolicy) DepartureTime(lateCheckOut bool) string {
+	if lateCheckOut {
+		return p.LateCheckOutTime
+	}
+	return p.CheckOutTime
+}
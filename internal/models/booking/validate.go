@@ -0,0 +1,181 @@
+package booking
+
+import (
+	"fmt"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+const (
+	MaxNameLength        = 200
+	MaxDescriptionLength = 2000
+	// MaxStayNights caps a single booking; longer stays are split.
+	MaxStayNights = 365
+)
+
+var (
+	roomTypes        = []RoomType{RoomTypeStandard, RoomTypeDeluxe, RoomTypeSuite, RoomTypeFamily}
+	roomStatuses     = []RoomStatus{RoomStatusAvailable, RoomStatusOccupied, RoomStatusMaintenance}
+	maintenanceKinds = []MaintenanceKind{MaintenanceKindOutOfOrder, MaintenanceKindOutOfService}
+)
+
+// validateStay checks a [start, end) range of nights. With required unset a
+// missing bound is accepted and only the order of the given bounds is checked;
+// a zero maxNights does not limit the length.
+func validateStay(v *validation.Validator, startField, endField string, start, end Date, required bool, maxNights int) {
+	if required {
+		v.Check(!start.IsZero(), startField, validation.CodeRequired, "is required")
+		v.Check(!end.IsZero(), endField, validation.CodeRequired, "is required")
+	}
+	if start.IsZero() || end.IsZero() {
+		return
+	}
+	if !end.After(start) {
+		v.Add(endField, validation.CodeDateOrder, "must be after "+startField)
+		return
+	}
+	v.Check(maxNights == 0 || start.DaysUntil(end) <= maxNights, endField, validation.CodeOutOfRange, "range is too long")
+}
+
+func (g GuestInfo) validate(v *validation.Validator, prefix string) {
+	v.Required(prefix+"name", g.Name)
+	v.MaxLength(prefix+"name", g.Name, MaxNameLength)
+	v.Required(prefix+"email", g.Email)
+	v.Email(prefix+"email", g.Email)
+	v.Phone(prefix+"phone", g.Phone)
+}
+
+func (r CreateBookingRequest) Validate() error {
+	var v validation.Validator
+	v.Check(r.RoomID > 0, "room_id", validation.CodeRequired, "is required")
+	validateStay(&v, "start_date", "end_date", r.StartDate, r.EndDate, true, MaxStayNights)
+	r.GuestInfo.validate(&v, "guest_info.")
+	v.NonNegative("guests", float64(r.Guests))
+	v.NonNegative("redeem_points", float64(r.RedeemPoints))
+	return v.Err()
+}
+
+func (r MoveBookingRequest) Validate() error {
+	var v validation.Validator
+	v.NonNegative("room_id", float64(r.RoomID))
+	validateStay(&v, "start_date", "end_date", r.StartDate, r.EndDate, false, MaxStayNights)
+	return v.Err()
+}
+
+func (r PriceCalculationRequest) Validate() error {
+	var v validation.Validator
+	v.Check(r.RoomID > 0, "room_id", validation.CodeRequired, "is required")
+	validateStay(&v, "check_in", "check_out", r.CheckIn, r.CheckOut, true, MaxStayNights)
+	v.Email("guest_email", r.GuestEmail)
+	return v.Err()
+}
+
+func (r RoomSearchRequest) Validate() error {
+	var v validation.Validator
+	validateStay(&v, "check_in", "check_out", r.CheckIn, r.CheckOut, true, MaxStayNights)
+	validation.OneOf(&v, "room_type", r.RoomType, roomTypes...)
+	v.NonNegative("capacity", float64(r.Capacity))
+	return v.Err()
+}
+
+func (r AvailabilityRequest) Validate() error {
+	var v validation.Validator
+	validateStay(&v, "from", "to", r.From, r.To, true, MaxAvailabilityNights)
+	validation.OneOf(&v, "room_type", r.RoomType, roomTypes...)
+	return v.Err()
+}
+
+// Validate checks a room submitted by an administrator.
+func (r Room) Validate() error {
+	var v validation.Validator
+	v.Required("room_number", r.RoomNumber)
+	v.MaxLength("room_number", r.RoomNumber, 20)
+	v.Required("room_type", string(r.RoomType))
+	validation.OneOf(&v, "room_type", r.RoomType, roomTypes...)
+	v.Positive("base_price", r.BasePrice)
+	v.Range("capacity", float64(r.Capacity), 1, 20)
+	validation.OneOf(&v, "status", r.Status, roomStatuses...)
+	if r.HousekeepingStatus != "" {
+		v.Check(r.HousekeepingStatus.IsValid(), "housekeeping_status", validation.CodeUnknownValue, "has an unknown value")
+	}
+	v.MaxLength("description", r.Description, MaxDescriptionLength)
+	return v.Err()
+}
+
+func (r ArchiveRoomRequest) Validate() error {
+	var v validation.Validator
+	for i, rel := range r.Relocations {
+		field := fmt.Sprintf("relocations[%d]", i)
+		v.Check(rel.BookingID > 0, field+".booking_id", validation.CodeRequired, "is required")
+		v.Check(rel.RoomID > 0, field+".room_id", validation.CodeRequired, "is required")
+	}
+	return v.Err()
+}
+
+func (sd SpecialDate) Validate() error {
+	var v validation.Validator
+	v.Check(!sd.Date.IsZero(), "date", validation.CodeRequired, "is required")
+	v.Required("name", sd.Name)
+	v.MaxLength("name", sd.Name, MaxNameLength)
+	v.Range("coefficient", sd.Coefficient, 0.1, 10)
+	return v.Err()
+}
+
+func (r CreateSpecialDateRequest) Validate() error {
+	return SpecialDate{Date: r.Date, Name: r.Name, Coefficient: r.Coefficient}.Validate()
+}
+
+func (r CreateMaintenanceWindowRequest) Validate() error {
+	var v validation.Validator
+	v.Check(r.RoomID > 0, "room_id", validation.CodeRequired, "is required")
+	validateStay(&v, "start_date", "end_date", r.StartDate, r.EndDate, true, MaxAvailabilityNights)
+	v.Required("kind", string(r.Kind))
+	validation.OneOf(&v, "kind", r.Kind, maintenanceKinds...)
+	v.MaxLength("reason", r.Reason, MaxDescriptionLength)
+	return v.Err()
+}
+
+func (r CheckInDetails) Validate() error {
+	var v validation.Validator
+	v.MaxLength("document_type", r.DocumentType, 50)
+	v.MaxLength("document_number", r.DocumentNumber, 50)
+	for _, card := range r.KeyCards {
+		v.Required("key_cards", card)
+	}
+	return v.Err()
+}
+
+func (p PageRequest) Validate() error {
+	var v validation.Validator
+	p.validate(&v)
+	return v.Err()
+}
+
+func (p PageRequest) validate(v *validation.Validator) {
+	v.Range("limit", float64(p.Limit), 0, MaxPageLimit)
+	validation.OneOf(v, "order", p.Order, SortAsc, SortDesc)
+}
+
+func (f BookingFilter) Validate() error {
+	var v validation.Validator
+	v.Check(f.Status == "" || f.Status.IsValid(), "status", validation.CodeUnknownValue, "has an unknown value")
+	v.Email("email", f.GuestEmail)
+	validateStay(&v, "from", "to", f.From, f.To, false, 0)
+	v.NonNegative("min_price", f.MinPrice)
+	v.NonNegative("max_price", f.MaxPrice)
+	v.Check(f.MaxPrice == 0 || f.MaxPrice >= f.MinPrice, "max_price", validation.CodeOutOfRange, "must not be less than min_price")
+	f.PageRequest.validate(&v)
+	return v.Err()
+}
+
+func (f RoomFilter) Validate() error {
+	var v validation.Validator
+	validation.OneOf(&v, "room_type", f.RoomType, roomTypes...)
+	validation.OneOf(&v, "status", f.Status, roomStatuses...)
+	v.NonNegative("capacity", float64(f.MinCapacity))
+	v.NonNegative("min_price", f.MinPrice)
+	v.NonNegative("max_price", f.MaxPrice)
+	v.Check(f.MaxPrice == 0 || f.MaxPrice >= f.MinPrice, "max_price", validation.CodeOutOfRange, "must not be less than min_price")
+	f.PageRequest.validate(&v)
+	return v.Err()
+}
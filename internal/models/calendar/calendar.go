@@ -0,0 +1,77 @@
+package calendar
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+const MaxFeedNameLength = 100
+
+var errUnsupportedURL = errors.New("feed URL must be http(s) or webcal")
+
+// Feed is an external iCal calendar, e.g. of a booking channel, whose events
+// block the nights of a room. Its events are stored as maintenance windows
+// of the external kind and replaced on every sync.
+type Feed struct {
+	ID           int64      `json:"id" db:"id"`
+	RoomID       int64      `json:"room_id" db:"room_id"`
+	Name         string     `json:"name" db:"name"`
+	URL          string     `json:"url" db:"url"`
+	LastSyncedAt *time.Time `json:"last_synced_at,omitempty" db:"last_synced_at"`
+	// LastError is the reason the last sync failed; the blocks of the
+	// previous successful sync are kept meanwhile.
+	LastError string    `json:"last_error,omitempty" db:"last_error"`
+	Blocks    int       `json:"blocks" db:"blocks"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type CreateFeedRequest struct {
+	RoomID int64  `json:"room_id"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+}
+
+func (r CreateFeedRequest) Validate() error {
+	var v validation.Validator
+	v.Check(r.RoomID > 0, "room_id", validation.CodeRequired, "is required")
+	v.Required("name", r.Name)
+	v.MaxLength("name", r.Name, MaxFeedNameLength)
+	v.Required("url", r.URL)
+	if r.URL != "" {
+		_, err := FetchURL(r.URL)
+		v.Check(err == nil, "url", validation.CodeInvalid, "must be an http(s) or webcal URL")
+	}
+	return v.Err()
+}
+
+// FetchURL returns the URL a feed is downloaded from; webcal links are
+// fetched over HTTPS.
+func FetchURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "webcal", "webcals":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return "", errUnsupportedURL
+	}
+	if u.Host == "" {
+		return "", errUnsupportedURL
+	}
+	return u.String(), nil
+}
+
+// RoomCalendar is what the admin needs to connect a room to channels: the
+// secret path of its export feed and the feeds imported into it.
+type RoomCalendar struct {
+	RoomID     int64  `json:"room_id"`
+	ExportPath string `json:"export_path"`
+	Feeds      []Feed `json:"feeds"`
+}
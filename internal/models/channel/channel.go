@@ -0,0 +1,158 @@
+package channel
+
+import (
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+const MaxCodeLength = 50
+
+// Mapping links one of our room types to the room and rate plan it is sold
+// as on a channel. Channels sell room types, not individual rooms: incoming
+// reservations are assigned to a free room of the type.
+type Mapping struct {
+	ID        int64            `json:"id" db:"id"`
+	Channel   string           `json:"channel" db:"channel"`
+	RoomType  booking.RoomType `json:"room_type" db:"room_type"`
+	RoomCode  string           `json:"room_code" db:"room_code"`
+	RateCode  string           `json:"rate_code" db:"rate_code"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+}
+
+type CreateMappingRequest struct {
+	Channel  string           `json:"channel"`
+	RoomType booking.RoomType `json:"room_type"`
+	RoomCode string           `json:"room_code"`
+	RateCode string           `json:"rate_code"`
+}
+
+func (r CreateMappingRequest) Validate() error {
+	var v validation.Validator
+	v.Required("channel", r.Channel)
+	validation.OneOf(&v, "room_type", r.RoomType, booking.RoomTypeStandard, booking.RoomTypeDeluxe, booking.RoomTypeSuite, booking.RoomTypeFamily)
+	v.Required("room_code", r.RoomCode)
+	v.MaxLength("room_code", r.RoomCode, MaxCodeLength)
+	v.MaxLength("rate_code", r.RateCode, MaxCodeLength)
+	return v.Err()
+}
+
+// Inventory is what a channel is told about one mapped room type on one
+// night: how many rooms it may sell, at what rate and whether sales are
+// closed. Rooms taken out of order or service close the night (StopSell);
+// a sold-out night just has nothing available.
+type Inventory struct {
+	RoomCode  string       `json:"room_code" db:"room_code"`
+	RateCode  string       `json:"rate_code" db:"rate_code"`
+	Date      booking.Date `json:"date" db:"date"`
+	Available int          `json:"available" db:"available"`
+	Price     float64      `json:"price" db:"price"`
+	StopSell  bool         `json:"stop_sell" db:"stop_sell"`
+}
+
+// Key identifies the room, rate and night the inventory is about.
+func (i Inventory) Key() string {
+	return i.RoomCode + "|" + i.RateCode + "|" + i.Date.String()
+}
+
+type ReservationStatus string
+
+const (
+	ReservationStatusNew       ReservationStatus = "new"
+	ReservationStatusModified  ReservationStatus = "modified"
+	ReservationStatusCancelled ReservationStatus = "cancelled"
+)
+
+// Reservation is a reservation as a channel reports it. Channels report the
+// current state of a reservation on every pull, so the same Ref comes back
+// until it is past.
+type Reservation struct {
+	Ref      string            `json:"ref"`
+	Status   ReservationStatus `json:"status"`
+	RoomCode string            `json:"room_code"`
+	CheckIn  booking.Date      `json:"check_in"`
+	CheckOut booking.Date      `json:"check_out"`
+	Guest    booking.GuestInfo `json:"guest"`
+	Guests   int               `json:"guests"`
+	// Total is the amount the channel charged the guest.
+	Total float64 `json:"total"`
+}
+
+// ImportState is how a channel reservation ended up on our side.
+type ImportState string
+
+const (
+	ImportStateBooked    ImportState = "booked"
+	ImportStateCancelled ImportState = "cancelled"
+	// ImportStateConflict means the reservation could not be placed, e.g.
+	// it overlaps direct bookings that took every room of the type. It
+	// waits for an admin to assign a room or reject it.
+	ImportStateConflict ImportState = "conflict"
+	ImportStateRejected ImportState = "rejected"
+)
+
+func (s ImportState) IsValid() bool {
+	switch s {
+	case ImportStateBooked, ImportStateCancelled, ImportStateConflict, ImportStateRejected:
+		return true
+	}
+	return false
+}
+
+// ImportedReservation is a channel reservation with the booking it became.
+type ImportedReservation struct {
+	ID        int64             `json:"id" db:"id"`
+	Channel   string            `json:"channel" db:"channel"`
+	Ref       string            `json:"ref" db:"ref"`
+	Status    ReservationStatus `json:"status" db:"status"`
+	RoomCode  string            `json:"room_code" db:"room_code"`
+	CheckIn   booking.Date      `json:"check_in" db:"check_in"`
+	CheckOut  booking.Date      `json:"check_out" db:"check_out"`
+	Guest     booking.GuestInfo `json:"guest" db:"guest_info"`
+	Guests    int               `json:"guests" db:"guests"`
+	Total     float64           `json:"total" db:"total"`
+	BookingID *int64            `json:"booking_id,omitempty" db:"booking_id"`
+	State     ImportState       `json:"state" db:"state"`
+	// Conflict explains why the reservation is in the conflict state and
+	// lists the bookings in its way.
+	Conflict string `json:"conflict,omitempty" db:"conflict"`
+	// Fingerprint changes whenever the channel changes the reservation, so
+	// unchanged reservations are skipped on later pulls.
+	Fingerprint string    `json:"-" db:"fingerprint"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ResolveRequest places a conflicting reservation into the given room, e.g.
+// after the direct booking in its way was moved.
+type ResolveRequest struct {
+	RoomID int64 `json:"room_id"`
+}
+
+func (r ResolveRequest) Validate() error {
+	var v validation.Validator
+	v.Check(r.RoomID > 0, "room_id", validation.CodeRequired, "is required")
+	return v.Err()
+}
+
+// SyncResult summarizes one sync run of a channel.
+type SyncResult struct {
+	Channel    string    `json:"channel"`
+	Pulled     int       `json:"pulled"`
+	Booked     int       `json:"booked"`
+	Modified   int       `json:"modified"`
+	Cancelled  int       `json:"cancelled"`
+	Conflicts  int       `json:"conflicts"`
+	Pushed     int       `json:"pushed"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// Status describes a configured channel for the admin.
+type Status struct {
+	Channel   string      `json:"channel"`
+	Mappings  []Mapping   `json:"mappings"`
+	Conflicts int         `json:"conflicts"`
+	LastSync  *SyncResult `json:"last_sync,omitempty"`
+	LastError string      `json:"last_error,omitempty"`
+}
@@ -0,0 +1,32 @@
+package exchange
+
+import (
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+// MaxImportRows caps the number of data rows in one import file.
+const MaxImportRows = 5000
+
+// RowError lists what is wrong with one row of an import file. Row is the
+// spreadsheet row number, the header being row 1.
+type RowError struct {
+	Row    int               `json:"row"`
+	Errors validation.Errors `json:"errors"`
+}
+
+// ImportResult reports what an import did, or would do for a dry run. An
+// import with row errors changes nothing.
+type ImportResult struct {
+	DryRun bool `json:"dry_run"`
+	// Rows counts the non-blank data rows of the file.
+	Rows      int        `json:"rows"`
+	Created   int        `json:"created"`
+	Updated   int        `json:"updated"`
+	Unchanged int        `json:"unchanged"`
+	Errors    []RowError `json:"errors"`
+}
+
+// Applied reports whether the import was written.
+func (r ImportResult) Applied() bool {
+	return !r.DryRun && len(r.Errors) == 0
+}
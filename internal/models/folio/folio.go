@@ -0,0 +1,156 @@
+package folio
+
+import (
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/payment"
+)
+
+type ChargeType string
+
+const (
+	ChargeRoomNight ChargeType = "room_night"
+	ChargeExtra     ChargeType = "extra"
+)
+
+// Charge is a line posted to a booking's folio. Discounts are extras with a
+// negative amount.
+type Charge struct {
+	ID          int64         `json:"id" db:"id"`
+	BookingID   int64         `json:"booking_id" db:"booking_id"`
+	Type        ChargeType    `json:"type" db:"type"`
+	Description string        `json:"description" db:"description"`
+	ServiceDate *booking.Date `json:"service_date,omitempty" db:"service_date"`
+	Amount      float64       `json:"amount" db:"amount"`
+	CreatedAt   time.Time     `json:"created_at" db:"created_at"`
+}
+
+// StayCharges turns the price of a stay into folio charges: one per night
+// and one per extra. They add up to price.TotalPrice.
+func StayCharges(bookingID int64, price booking.PriceCalculationResponse) []Charge {
+	charges := make([]Charge, 0, len(price.DailyBreakdown)+len(price.Extras))
+	for _, day := range price.DailyBreakdown {
+		date := day.Date
+		charges = append(charges, Charge{
+			BookingID:   bookingID,
+			Type:        ChargeRoomNight,
+			Description: "Проживание (" + day.Reason + ")",
+			ServiceDate: &date,
+			Amount:      day.DayPrice,
+		})
+	}
+	for _, extra := range price.Extras {
+		charges = append(charges, Charge{
+			BookingID:   bookingID,
+			Type:        ChargeExtra,
+			Description: extra.Name,
+			Amount:      extra.Price,
+		})
+	}
+	return charges
+}
+
+type TaxPolicy struct {
+	VATPercent float64
+	// VATIncluded means prices already contain VAT; otherwise it is added.
+	VATIncluded bool
+	// CityTax is charged per guest per night on top of the price.
+	CityTax  float64
+	Currency string
+}
+
+// PaymentLine is money received for a booking.
+type PaymentLine struct {
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`
+}
+
+// Folio is the account of a booking: what was charged, the taxes on it and
+// what the guest has paid.
+type Folio struct {
+	BookingID int64    `json:"booking_id"`
+	Charges   []Charge `json:"charges"`
+	Subtotal  float64  `json:"subtotal"`
+	VAT       float64  `json:"vat"`
+	VATRate   float64  `json:"vat_rate"`
+	// VATIncluded means VAT is part of Subtotal rather than added to it.
+	VATIncluded bool          `json:"vat_included"`
+	CityTax     float64       `json:"city_tax"`
+	Total       float64       `json:"total"`
+	Payments    []PaymentLine `json:"payments"`
+	Paid        float64       `json:"paid"`
+	Balance     float64       `json:"balance"`
+	Currency    string        `json:"currency"`
+}
+
+// Build computes the folio of b. Bookings made before charges were posted
+// get a single charge for their price.
+func Build(b booking.Booking, charges []Charge, payments []payment.Payment, policy TaxPolicy) Folio {
+	if len(charges) == 0 {
+		charges = []Charge{{
+			BookingID:   b.ID,
+			Type:        ChargeRoomNight,
+			Description: "Проживание",
+			ServiceDate: &b.StartDate,
+			Amount:      b.Price,
+		}}
+	}
+
+	f := Folio{
+		BookingID:   b.ID,
+		Charges:     charges,
+		VATRate:     policy.VATPercent,
+		VATIncluded: policy.VATIncluded,
+		Payments:    []PaymentLine{},
+		Currency:    policy.Currency,
+	}
+	for _, c := range charges {
+		f.Subtotal += c.Amount
+	}
+	f.Subtotal = payment.Round(f.Subtotal)
+
+	if policy.VATIncluded {
+		f.VAT = payment.Round(f.Subtotal * policy.VATPercent / (100 + policy.VATPercent))
+		f.Total = f.Subtotal
+	} else {
+		f.VAT = payment.Round(f.Subtotal * policy.VATPercent / 100)
+		f.Total = f.Subtotal + f.VAT
+	}
+	f.CityTax = payment.Round(policy.CityTax * float64(max(b.Guests, 1)*b.Nights()))
+	f.Total = payment.Round(f.Total + f.CityTax)
+
+	if b.PointsAmount > 0 {
+		f.Payments = append(f.Payments, PaymentLine{Date: b.CreatedAt, Description: "Баллы лояльности", Amount: b.PointsAmount})
+	}
+	for _, p := range payments {
+		if p.Paid() > 0 {
+			f.Payments = append(f.Payments, PaymentLine{Date: p.UpdatedAt, Description: "Оплата картой", Amount: p.Paid()})
+		}
+	}
+	for _, p := range f.Payments {
+		f.Paid += p.Amount
+	}
+	f.Paid = payment.Round(f.Paid)
+	f.Balance = payment.Round(f.Total - f.Paid)
+	return f
+}
+
+// Invoice is the folio of a checked-out booking, frozen under a number from
+// a gap-free yearly sequence.
+type Invoice struct {
+	ID         int64     `json:"id" db:"id"`
+	Number     string    `json:"number" db:"number"`
+	Year       int       `json:"year" db:"year"`
+	Sequence   int       `json:"sequence" db:"sequence"`
+	BookingID  int64     `json:"booking_id" db:"booking_id"`
+	GuestName  string    `json:"guest_name" db:"guest_name"`
+	GuestEmail string    `json:"guest_email" db:"guest_email"`
+	Total      float64   `json:"total" db:"total"`
+	VAT        float64   `json:"vat" db:"vat"`
+	CityTax    float64   `json:"city_tax" db:"city_tax"`
+	Currency   string    `json:"currency" db:"currency"`
+	Folio      Folio     `json:"folio" db:"folio"`
+	IssuedAt   time.Time `json:"issued_at" db:"issued_at"`
+}
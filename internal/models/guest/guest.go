@@ -0,0 +1,72 @@
+package guest
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+)
+
+type Guest struct {
+	ID              int64             `json:"id" db:"id"`
+	Name            string            `json:"name" db:"name"`
+	Email           string            `json:"email" db:"email"`
+	Phone           string            `json:"phone" db:"phone"`
+	NormalizedEmail string            `json:"-" db:"normalized_email"`
+	NormalizedPhone string            `json:"-" db:"normalized_phone"`
+	Preferences     map[string]string `json:"preferences" db:"preferences"`
+	Notes           string            `json:"notes" db:"notes"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// Profile is a guest together with their stay history and value to the hotel.
+type Profile struct {
+	Guest
+	Stays         []booking.BookingWithRoom `json:"stays"`
+	StayCount     int                       `json:"stay_count"`
+	Nights        int                       `json:"nights"`
+	LifetimeValue float64                   `json:"lifetime_value"`
+	UpcomingValue float64                   `json:"upcoming_value"`
+	LastStay      *booking.Date             `json:"last_stay,omitempty"`
+}
+
+type Filter struct {
+	Query string
+	booking.PageRequest
+}
+
+type UpdateGuestRequest struct {
+	Name        *string           `json:"name"`
+	Phone       *string           `json:"phone"`
+	Preferences map[string]string `json:"preferences"`
+	Notes       *string           `json:"notes"`
+}
+
+// MergeRequest folds the guest SourceID into TargetID: bookings move to the
+// target and the source record is deleted.
+type MergeRequest struct {
+	SourceID int64 `json:"source_id"`
+	TargetID int64 `json:"target_id"`
+}
+
+type DuplicateCandidate struct {
+	Guest     Guest  `json:"guest"`
+	Duplicate Guest  `json:"duplicate"`
+	Reason    string `json:"reason"`
+}
+
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone keeps only the digits of a phone number.
+func NormalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+}
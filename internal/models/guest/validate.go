@@ -0,0 +1,39 @@
+package guest
+
+import (
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+const (
+	MaxPreferences = 50
+	MaxNotesLength = 4000
+)
+
+func (r UpdateGuestRequest) Validate() error {
+	var v validation.Validator
+	if r.Name != nil {
+		v.Required("name", *r.Name)
+		v.MaxLength("name", *r.Name, booking.MaxNameLength)
+	}
+	if r.Phone != nil {
+		v.Phone("phone", *r.Phone)
+	}
+	v.Check(len(r.Preferences) <= MaxPreferences, "preferences", validation.CodeOutOfRange, "has too many entries")
+	for key, value := range r.Preferences {
+		v.Required("preferences", key)
+		v.MaxLength("preferences."+key, value, booking.MaxNameLength)
+	}
+	if r.Notes != nil {
+		v.MaxLength("notes", *r.Notes, MaxNotesLength)
+	}
+	return v.Err()
+}
+
+func (r MergeRequest) Validate() error {
+	var v validation.Validator
+	v.Check(r.SourceID > 0, "source_id", validation.CodeRequired, "is required")
+	v.Check(r.TargetID > 0, "target_id", validation.CodeRequired, "is required")
+	v.Check(r.SourceID != r.TargetID, "target_id", validation.CodeInvalid, "must differ from source_id")
+	return v.Err()
+}
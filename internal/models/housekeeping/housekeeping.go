@@ -0,0 +1,66 @@
+package housekeeping
+
+import (
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+)
+
+type TaskType string
+
+const (
+	TaskTypeCleaning   TaskType = "cleaning"
+	TaskTypeInspection TaskType = "inspection"
+	TaskTypeRepair     TaskType = "repair"
+)
+
+type TaskStatus string
+
+const (
+	TaskStatusOpen       TaskStatus = "open"
+	TaskStatusInProgress TaskStatus = "in_progress"
+	TaskStatusDone       TaskStatus = "done"
+)
+
+type Task struct {
+	ID          int64        `json:"id" db:"id"`
+	RoomID      int64        `json:"room_id" db:"room_id"`
+	BookingID   *int64       `json:"booking_id,omitempty" db:"booking_id"`
+	Type        TaskType     `json:"type" db:"type"`
+	Status      TaskStatus   `json:"status" db:"status"`
+	Assignee    string       `json:"assignee" db:"assignee"`
+	Notes       string       `json:"notes" db:"notes"`
+	DueDate     booking.Date `json:"due_date" db:"due_date"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+type TaskWithRoom struct {
+	Task
+	Room booking.Room `json:"room"`
+}
+
+type TaskFilter struct {
+	Assignee string
+	Status   TaskStatus
+	DueDate  booking.Date
+}
+
+type CreateTaskRequest struct {
+	RoomID   int64        `json:"room_id"`
+	Type     TaskType     `json:"type"`
+	Assignee string       `json:"assignee"`
+	Notes    string       `json:"notes"`
+	DueDate  booking.Date `json:"due_date"`
+}
+
+type UpdateTaskRequest struct {
+	Status   TaskStatus `json:"status,omitempty"`
+	Assignee *string    `json:"assignee,omitempty"`
+	Notes    *string    `json:"notes,omitempty"`
+}
+
+type UpdateRoomStatusRequest struct {
+	Status booking.HousekeepingStatus `json:"status"`
+}
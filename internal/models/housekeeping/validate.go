@@ -0,0 +1,40 @@
+package housekeeping
+
+import (
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+var (
+	taskTypes    = []TaskType{TaskTypeCleaning, TaskTypeInspection, TaskTypeRepair}
+	taskStatuses = []TaskStatus{TaskStatusOpen, TaskStatusInProgress, TaskStatusDone}
+)
+
+func (r CreateTaskRequest) Validate() error {
+	var v validation.Validator
+	v.Check(r.RoomID > 0, "room_id", validation.CodeRequired, "is required")
+	v.Required("type", string(r.Type))
+	validation.OneOf(&v, "type", r.Type, taskTypes...)
+	v.MaxLength("assignee", r.Assignee, booking.MaxNameLength)
+	v.MaxLength("notes", r.Notes, booking.MaxDescriptionLength)
+	return v.Err()
+}
+
+func (r UpdateTaskRequest) Validate() error {
+	var v validation.Validator
+	validation.OneOf(&v, "status", r.Status, taskStatuses...)
+	if r.Assignee != nil {
+		v.MaxLength("assignee", *r.Assignee, booking.MaxNameLength)
+	}
+	if r.Notes != nil {
+		v.MaxLength("notes", *r.Notes, booking.MaxDescriptionLength)
+	}
+	return v.Err()
+}
+
+func (r UpdateRoomStatusRequest) Validate() error {
+	var v validation.Validator
+	v.Required("status", string(r.Status))
+	v.Check(r.Status == "" || r.Status.IsValid(), "status", validation.CodeUnknownValue, "has an unknown value")
+	return v.Err()
+}
@@ -0,0 +1,28 @@
+package idempotency
+
+import (
+	"time"
+)
+
+// MaxKeyLength bounds the Idempotency-Key header.
+const MaxKeyLength = 255
+
+// Record is a request made with an idempotency key and, once it has been
+// handled, the response that is replayed when the client retries it.
+// Fingerprint identifies the request (method, path and body) so that a key
+// reused for a different request can be told apart from a retry.
+type Record struct {
+	Key         string    `db:"key"`
+	Fingerprint string    `db:"fingerprint"`
+	StatusCode  int       `db:"status_code"`
+	ContentType string    `db:"content_type"`
+	Body        []byte    `db:"body"`
+	CreatedAt   time.Time `db:"created_at"`
+	ExpiresAt   time.Time `db:"expires_at"`
+}
+
+// Completed reports whether the first request has finished; until then the
+// record only reserves the key.
+func (r Record) Completed() bool {
+	return r.StatusCode != 0
+}
@@ -0,0 +1,59 @@
+package live
+
+import (
+	"strings"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/notification"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+// EventTypeReset tells a reconnecting client that the events it missed are
+// no longer buffered and it has to reload what it shows.
+const EventTypeReset notification.EventType = "reset"
+
+type Role string
+
+const (
+	// RoleAdmin receives every booking and room event.
+	RoleAdmin Role = "admin"
+	// RoleGuest receives events of the bookings made with its email only.
+	RoleGuest Role = "guest"
+)
+
+// Event is a lifecycle event numbered for the event stream. IDs grow by one
+// per event and restart with the server.
+type Event struct {
+	ID        int64                  `json:"id"`
+	Type      notification.EventType `json:"type"`
+	Data      map[string]any         `json:"data,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	// GuestEmail is the email of the booking the event is about, if any.
+	GuestEmail string `json:"-"`
+}
+
+// Filter decides which events a client receives.
+type Filter struct {
+	Role  Role
+	Email string
+}
+
+func (f Filter) Validate() error {
+	var v validation.Validator
+	v.Required("role", string(f.Role))
+	validation.OneOf(&v, "role", f.Role, RoleAdmin, RoleGuest)
+	if f.Role == RoleGuest {
+		v.Required("email", f.Email)
+	}
+	return v.Err()
+}
+
+func (f Filter) Allows(e Event) bool {
+	switch f.Role {
+	case RoleAdmin:
+		return true
+	case RoleGuest:
+		return e.GuestEmail != "" && strings.EqualFold(e.GuestEmail, f.Email)
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package loyalty
+
+import (
+	"math"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+)
+
+type Tier string
+
+const (
+	TierMember Tier = "member"
+	TierSilver Tier = "silver"
+	TierGold   Tier = "gold"
+)
+
+type EntryType string
+
+const (
+	EntryTypeEarn   EntryType = "earn"
+	EntryTypeRedeem EntryType = "redeem"
+	EntryTypeRefund EntryType = "refund"
+	EntryTypeAdjust EntryType = "adjust"
+	EntryTypeExpire EntryType = "expire"
+)
+
+// LedgerEntry is one movement of points. Positive entries keep the number of
+// points not yet spent or expired in Remaining; redemptions consume them
+// oldest-expiring first.
+type LedgerEntry struct {
+	ID        int64         `json:"id" db:"id"`
+	GuestID   int64         `json:"guest_id" db:"guest_id"`
+	BookingID *int64        `json:"booking_id,omitempty" db:"booking_id"`
+	Type      EntryType     `json:"type" db:"type"`
+	Points    int           `json:"points" db:"points"`
+	Remaining int           `json:"remaining" db:"remaining"`
+	ExpiresAt *booking.Date `json:"expires_at,omitempty" db:"expires_at"`
+	Reason    string        `json:"reason" db:"reason"`
+	Actor     string        `json:"actor,omitempty" db:"actor"`
+	CreatedAt time.Time     `json:"created_at" db:"created_at"`
+}
+
+type Account struct {
+	GuestID          int64         `json:"guest_id"`
+	Balance          int           `json:"balance"`
+	BalanceValue     float64       `json:"balance_value"`
+	Tier             Tier          `json:"tier"`
+	Discount         float64       `json:"discount"`
+	QualifyingPoints int           `json:"qualifying_points"`
+	NextTier         Tier          `json:"next_tier,omitempty"`
+	PointsToNextTier int           `json:"points_to_next_tier,omitempty"`
+	Entries          []LedgerEntry `json:"entries"`
+}
+
+type AdjustRequest struct {
+	Points int    `json:"points"`
+	Reason string `json:"reason"`
+	Actor  string `json:"actor"`
+}
+
+type Policy struct {
+	// EarnRate is the number of points earned per 1 RUB of booking price.
+	EarnRate float64
+	// PointValue is how many RUB one point is worth when redeemed.
+	PointValue float64
+	// ExpiryDays is how long earned points stay valid.
+	ExpiryDays int
+	// QualifyingDays is the window of earned points that decides the tier.
+	QualifyingDays  int
+	SilverThreshold int
+	GoldThreshold   int
+	SilverDiscount  float64
+	GoldDiscount    float64
+}
+
+func (p Policy) PointsFor(price float64) int {
+	return int(math.Floor(price * p.EarnRate))
+}
+
+func (p Policy) Value(points int) float64 {
+	return math.Round(float64(points)*p.PointValue*100) / 100
+}
+
+func (p Policy) ExpiresOn(earned booking.Date) booking.Date {
+	return earned.AddDays(p.ExpiryDays)
+}
+
+func (p Policy) Tier(qualifyingPoints int) Tier {
+	switch {
+	case qualifyingPoints >= p.GoldThreshold:
+		return TierGold
+	case qualifyingPoints >= p.SilverThreshold:
+		return TierSilver
+	}
+	return TierMember
+}
+
+// Discount is the share of the room price a tier saves, e.g. 0.1 for 10%.
+func (p Policy) Discount(tier Tier) float64 {
+	switch tier {
+	case TierGold:
+		return p.GoldDiscount
+	case TierSilver:
+		return p.SilverDiscount
+	}
+	return 0
+}
+
+func (p Policy) NextTier(qualifyingPoints int) (Tier, int) {
+	switch {
+	case qualifyingPoints < p.SilverThreshold:
+		return TierSilver, p.SilverThreshold - qualifyingPoints
+	case qualifyingPoints < p.GoldThreshold:
+		return TierGold, p.GoldThreshold - qualifyingPoints
+	}
+	return "", 0
+}
@@ -0,0 +1,16 @@
+package loyalty
+
+import (
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+func (r AdjustRequest) Validate() error {
+	var v validation.Validator
+	v.Check(r.Points != 0, "points", validation.CodeRequired, "must not be zero")
+	v.Required("reason", r.Reason)
+	v.MaxLength("reason", r.Reason, booking.MaxDescriptionLength)
+	v.Required("actor", r.Actor)
+	v.MaxLength("actor", r.Actor, booking.MaxNameLength)
+	return v.Err()
+}
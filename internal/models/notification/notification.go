@@ -10,6 +10,10 @@ const (
 	NotificationChannelEmail NotificationChannel = "email"
 	NotificationChannelSMS   NotificationChannel = "sms"
 	NotificationChannelViber NotificationChannel = "viber"
+	// NotificationChannelLifecycle carries booking and room events to
+	// webhooks and live UI updates instead of a guest; Data holds the booking
+	// and its room, or only the room for room events.
+	NotificationChannelLifecycle NotificationChannel = "lifecycle"
 )
 
 type EventType string
@@ -18,8 +22,24 @@ const (
 	EventTypeBookingCreated   EventType = "booking_created"
 	EventTypeBookingConfirmed EventType = "booking_confirmed"
 	EventTypeBookingCancelled EventType = "booking_cancelled"
+	EventTypeBookingModified  EventType = "booking_modified"
+	EventTypeCheckedOut       EventType = "checked_out"
+	// EventTypeBookingStatusChanged covers the status changes that have no
+	// event of their own, such as check-in and no-show.
+	EventTypeBookingStatusChanged EventType = "booking_status_changed"
+	EventTypeRoomStatusChanged    EventType = "room_status_changed"
+	EventTypeWaitlistHoldExpired  EventType = "waitlist_hold_expired"
 )
 
+// LifecycleEvents are the booking events webhooks can subscribe to.
+var LifecycleEvents = []EventType{
+	EventTypeBookingCreated,
+	EventTypeBookingConfirmed,
+	EventTypeBookingCancelled,
+	EventTypeBookingModified,
+	EventTypeCheckedOut,
+}
+
 type NotificationType struct {
 	ID      int64  `json:"id" db:"id"`
 	Name    string `json:"name" db:"name"`
@@ -27,14 +47,22 @@ type NotificationType struct {
 }
 
 type NotificationEvent struct {
-	ID        string              `json:"id"`
-	Type      EventType           `json:"type"`
-	Channel   NotificationChannel `json:"channel"`
-	Recipient string              `json:"recipient"`
-	Subject   string              `json:"subject"`
-	Message   string              `json:"message"`
-	Data      map[string]any      `json:"data,omitempty"`
-	CreatedAt time.Time           `json:"created_at"`
+	ID          string              `json:"id"`
+	Type        EventType           `json:"type"`
+	Channel     NotificationChannel `json:"channel"`
+	Recipient   string              `json:"recipient"`
+	Subject     string              `json:"subject"`
+	Message     string              `json:"message"`
+	Data        map[string]any      `json:"data,omitempty"`
+	Attachments []Attachment        `json:"attachments,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+// Attachment is a file sent along with an email.
+type Attachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"-"`
 }
 
 type SendNotificationRequest struct {
@@ -0,0 +1,133 @@
+package payment
+
+import (
+	"math"
+	"time"
+)
+
+type Status string
+
+const (
+	// StatusAuthorized holds funds on the guest's card that are not taken yet.
+	StatusAuthorized Status = "authorized"
+	// StatusCaptured means the authorized funds (or part of them) were taken.
+	StatusCaptured Status = "captured"
+	StatusRefunded Status = "refunded"
+	StatusVoided   Status = "voided"
+	StatusFailed   Status = "failed"
+)
+
+type TransactionType string
+
+const (
+	TransactionAuthorize TransactionType = "authorize"
+	TransactionCapture   TransactionType = "capture"
+	TransactionRefund    TransactionType = "refund"
+	TransactionVoid      TransactionType = "void"
+)
+
+// Payment is one card authorization for a booking and what was later
+// captured and refunded from it.
+type Payment struct {
+	ID             int64         `json:"id" db:"id"`
+	BookingID      int64         `json:"booking_id" db:"booking_id"`
+	Provider       string        `json:"provider" db:"provider"`
+	ProviderRef    string        `json:"provider_ref" db:"provider_ref"`
+	Status         Status        `json:"status" db:"status"`
+	Amount         float64       `json:"amount" db:"amount"`
+	CapturedAmount float64       `json:"captured_amount" db:"captured_amount"`
+	RefundedAmount float64       `json:"refunded_amount" db:"refunded_amount"`
+	Currency       string        `json:"currency" db:"currency"`
+	CreatedAt      time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at" db:"updated_at"`
+	Transactions   []Transaction `json:"transactions,omitempty"`
+}
+
+// Paid is the money kept from this payment.
+func (p Payment) Paid() float64 {
+	return Round(p.CapturedAmount - p.RefundedAmount)
+}
+
+// Transaction is one call to the gateway, successful or not.
+type Transaction struct {
+	ID          int64           `json:"id" db:"id"`
+	PaymentID   int64           `json:"payment_id" db:"payment_id"`
+	Type        TransactionType `json:"type" db:"type"`
+	Amount      float64         `json:"amount" db:"amount"`
+	Succeeded   bool            `json:"succeeded" db:"succeeded"`
+	ProviderRef string          `json:"provider_ref,omitempty" db:"provider_ref"`
+	Error       string          `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
+
+// Summary is the payment state of a booking.
+type Summary struct {
+	BookingID int64 `json:"booking_id"`
+	// Total is the part of the folio total payable in money, i.e. after
+	// loyalty points.
+	Total      float64   `json:"total"`
+	DepositDue float64   `json:"deposit_due"`
+	Paid       float64   `json:"paid"`
+	Authorized float64   `json:"authorized"`
+	Balance    float64   `json:"balance"`
+	Payments   []Payment `json:"payments"`
+}
+
+type CreatePaymentRequest struct {
+	// PaymentMethod is the card token issued by the gateway's client SDK.
+	PaymentMethod string `json:"payment_method"`
+	// Amount defaults to the deposit that is still due, or else the balance.
+	Amount float64 `json:"amount"`
+	// Capture takes the money right away instead of only authorizing it.
+	Capture bool `json:"capture"`
+}
+
+type CaptureRequest struct {
+	// Amount defaults to the whole uncaptured authorization.
+	Amount float64 `json:"amount"`
+}
+
+type RefundRequest struct {
+	// Amount defaults to everything captured and not yet refunded.
+	Amount float64 `json:"amount"`
+}
+
+// Result is returned by the operations that may confirm the booking.
+type Result struct {
+	Payment Payment `json:"payment"`
+	// Confirmed is set when this operation confirmed the booking.
+	Confirmed bool `json:"confirmed"`
+}
+
+type WebhookEventType string
+
+const (
+	EventCaptured WebhookEventType = "payment.captured"
+	EventRefunded WebhookEventType = "payment.refunded"
+	EventVoided   WebhookEventType = "payment.voided"
+	EventFailed   WebhookEventType = "payment.failed"
+)
+
+// WebhookEvent is a gateway notification about a payment that changed on the
+// gateway's side, e.g. a capture or refund made from its dashboard.
+type WebhookEvent struct {
+	ID          string           `json:"id"`
+	Type        WebhookEventType `json:"type"`
+	ProviderRef string           `json:"provider_ref"`
+	Amount      float64          `json:"amount"`
+}
+
+type Policy struct {
+	// DepositPercent of the booking total must be paid to confirm a booking.
+	DepositPercent float64
+	Currency       string
+}
+
+func (p Policy) Deposit(total float64) float64 {
+	return Round(total * p.DepositPercent / 100)
+}
+
+// Round rounds amount to kopecks.
+func Round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
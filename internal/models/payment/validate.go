@@ -0,0 +1,25 @@
+package payment
+
+import (
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+func (r CreatePaymentRequest) Validate() error {
+	var v validation.Validator
+	v.Required("payment_method", r.PaymentMethod)
+	v.MaxLength("payment_method", r.PaymentMethod, 255)
+	v.NonNegative("amount", r.Amount)
+	return v.Err()
+}
+
+func (r CaptureRequest) Validate() error {
+	var v validation.Validator
+	v.NonNegative("amount", r.Amount)
+	return v.Err()
+}
+
+func (r RefundRequest) Validate() error {
+	var v validation.Validator
+	v.NonNegative("amount", r.Amount)
+	return v.Err()
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"math"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+)
+
+// MaxRangeDays caps the period of a report.
+const MaxRangeDays = 366
+
+// Metrics are the standard hotel KPIs over some set of room nights. Room
+// revenue is allocated to the nights of a stay from its price breakdown.
+type Metrics struct {
+	// RoomNightsAvailable excludes rooms that were out of order or archived.
+	RoomNightsAvailable int     `json:"room_nights_available"`
+	RoomNightsSold      int     `json:"room_nights_sold"`
+	CancelledNights     int     `json:"cancelled_nights"`
+	RoomRevenue         float64 `json:"room_revenue"`
+	// Occupancy is the percentage of available room nights sold.
+	Occupancy float64 `json:"occupancy"`
+	// ADR is the average daily rate: room revenue per room night sold.
+	ADR float64 `json:"adr"`
+	// RevPAR is room revenue per available room night.
+	RevPAR float64 `json:"revpar"`
+}
+
+// Derive fills in the ratios from the counts.
+func (m *Metrics) Derive() {
+	m.RoomRevenue = round(m.RoomRevenue)
+	m.Occupancy, m.ADR, m.RevPAR = 0, 0, 0
+	if m.RoomNightsAvailable > 0 {
+		m.Occupancy = round(float64(m.RoomNightsSold) * 100 / float64(m.RoomNightsAvailable))
+		m.RevPAR = round(m.RoomRevenue / float64(m.RoomNightsAvailable))
+	}
+	if m.RoomNightsSold > 0 {
+		m.ADR = round(m.RoomRevenue / float64(m.RoomNightsSold))
+	}
+}
+
+// Add accumulates the counts of other; call Derive afterwards.
+func (m *Metrics) Add(other Metrics) {
+	m.RoomNightsAvailable += other.RoomNightsAvailable
+	m.RoomNightsSold += other.RoomNightsSold
+	m.CancelledNights += other.CancelledNights
+	m.RoomRevenue += other.RoomRevenue
+}
+
+type DailyMetrics struct {
+	Date booking.Date `json:"date"`
+	Metrics
+}
+
+type RoomTypeMetrics struct {
+	RoomType booking.RoomType `json:"room_type"`
+	Metrics
+}
+
+// Report covers the stay dates [From, To).
+type Report[T any] struct {
+	From  booking.Date `json:"from"`
+	To    booking.Date `json:"to"`
+	Rows  []T          `json:"rows"`
+	Total Metrics      `json:"total"`
+}
+
+// PickupCounts compare the room nights on the books for a stay date with
+// the same stay date a year earlier, as they stood at the same point in time.
+type PickupCounts struct {
+	// OnBooks is the room nights sold so far.
+	OnBooks int `json:"on_books"`
+	// Pickup is the part of OnBooks booked within the pickup window.
+	Pickup          int `json:"pickup"`
+	LastYearOnBooks int `json:"last_year_on_books"`
+	LastYearPickup  int `json:"last_year_pickup"`
+}
+
+func (c *PickupCounts) Add(other PickupCounts) {
+	c.OnBooks += other.OnBooks
+	c.Pickup += other.Pickup
+	c.LastYearOnBooks += other.LastYearOnBooks
+	c.LastYearPickup += other.LastYearPickup
+}
+
+type Pickup struct {
+	Date booking.Date `json:"date"`
+	PickupCounts
+}
+
+type PickupReport struct {
+	From booking.Date `json:"from"`
+	To   booking.Date `json:"to"`
+	// WindowDays is how far back bookings count as pickup.
+	WindowDays int          `json:"window_days"`
+	Rows       []Pickup     `json:"rows"`
+	Total      PickupCounts `json:"total"`
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,90 @@
+package waitlist
+
+import (
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+type Status string
+
+const (
+	StatusWaiting Status = "waiting"
+	// StatusOffered means a room is held for the guest as a pending booking
+	// until HoldExpiresAt.
+	StatusOffered   Status = "offered"
+	StatusBooked    Status = "booked"
+	StatusExpired   Status = "expired"
+	StatusCancelled Status = "cancelled"
+)
+
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusWaiting, StatusOffered, StatusBooked, StatusExpired, StatusCancelled:
+		return true
+	}
+	return false
+}
+
+// Entry is a guest waiting for a room of a type to free up for their dates.
+// Entries are served first come, first served, optionally ranking guests of
+// higher loyalty tiers first.
+type Entry struct {
+	ID        int64             `json:"id" db:"id"`
+	RoomType  booking.RoomType  `json:"room_type" db:"room_type"`
+	CheckIn   booking.Date      `json:"check_in" db:"check_in"`
+	CheckOut  booking.Date      `json:"check_out" db:"check_out"`
+	Guests    int               `json:"guests" db:"guests"`
+	GuestInfo booking.GuestInfo `json:"guest_info" db:"guest_info"`
+	Status    Status            `json:"status" db:"status"`
+	// BookingID is the booking holding the room once an offer is made.
+	BookingID *int64 `json:"booking_id,omitempty" db:"booking_id"`
+	// HoldToken is the secret of the hold link sent to the guest.
+	HoldToken     string     `json:"-" db:"hold_token"`
+	HoldExpiresAt *time.Time `json:"hold_expires_at,omitempty" db:"hold_expires_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// IsOpen reports whether the entry still waits for or holds a room.
+func (e Entry) IsOpen() bool {
+	return e.Status == StatusWaiting || e.Status == StatusOffered
+}
+
+type JoinRequest struct {
+	RoomType  booking.RoomType  `json:"room_type"`
+	CheckIn   booking.Date      `json:"check_in"`
+	CheckOut  booking.Date      `json:"check_out"`
+	Guests    int               `json:"guests"`
+	GuestInfo booking.GuestInfo `json:"guest_info"`
+}
+
+func (r JoinRequest) Validate() error {
+	var v validation.Validator
+	v.Required("room_type", string(r.RoomType))
+	validation.OneOf(&v, "room_type", r.RoomType, booking.RoomTypeStandard, booking.RoomTypeDeluxe, booking.RoomTypeSuite, booking.RoomTypeFamily)
+	v.Check(!r.CheckIn.IsZero(), "check_in", validation.CodeRequired, "is required")
+	v.Check(!r.CheckOut.IsZero(), "check_out", validation.CodeRequired, "is required")
+	if !r.CheckIn.IsZero() && !r.CheckOut.IsZero() {
+		if r.CheckOut.After(r.CheckIn) {
+			v.Check(r.CheckIn.DaysUntil(r.CheckOut) <= booking.MaxStayNights, "check_out", validation.CodeOutOfRange, "range is too long")
+		} else {
+			v.Add("check_out", validation.CodeDateOrder, "must be after check_in")
+		}
+	}
+	v.NonNegative("guests", float64(r.Guests))
+	v.Required("guest_info.name", r.GuestInfo.Name)
+	v.MaxLength("guest_info.name", r.GuestInfo.Name, booking.MaxNameLength)
+	v.Required("guest_info.email", r.GuestInfo.Email)
+	v.Email("guest_info.email", r.GuestInfo.Email)
+	v.Phone("guest_info.phone", r.GuestInfo.Phone)
+	return v.Err()
+}
+
+// Hold is what the hold link shows: the entry and the booking holding the
+// room for it.
+type Hold struct {
+	Entry   Entry                   `json:"entry"`
+	Booking booking.BookingWithRoom `json:"booking"`
+}
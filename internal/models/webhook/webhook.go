@@ -0,0 +1,135 @@
+package webhook
+
+import (
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/notification"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+const (
+	MinSecretLength = 16
+	MaxSecretLength = 200
+
+	// EventTypeTest is sent by the "send test event" action only; it cannot
+	// be subscribed to.
+	EventTypeTest notification.EventType = "webhook_test"
+)
+
+// Subscription asks for booking events of the listed types to be POSTed to
+// URL, signed with Secret.
+type Subscription struct {
+	ID     int64                    `json:"id" db:"id"`
+	URL    string                   `json:"url" db:"url"`
+	Events []notification.EventType `json:"events" db:"events"`
+	// Secret is never sent back to clients once stored.
+	Secret    string    `json:"-" db:"secret"`
+	Active    bool      `json:"active" db:"active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Wants reports whether the subscription receives events of the type.
+func (s Subscription) Wants(eventType notification.EventType) bool {
+	if !s.Active {
+		return false
+	}
+	for _, e := range s.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateSubscriptionRequest struct {
+	URL    string                   `json:"url"`
+	Events []notification.EventType `json:"events"`
+	Secret string                   `json:"secret"`
+}
+
+func (r CreateSubscriptionRequest) Validate() error {
+	var v validation.Validator
+	v.Required("url", r.URL)
+	if r.URL != "" {
+		u, err := url.Parse(r.URL)
+		v.Check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "url", validation.CodeInvalid, "must be an http(s) URL")
+	}
+	v.Check(len(r.Events) > 0, "events", validation.CodeRequired, "is required")
+	for _, e := range r.Events {
+		validation.OneOf(&v, "events", e, notification.LifecycleEvents...)
+	}
+	v.Check(len(r.Secret) >= MinSecretLength, "secret", validation.CodeOutOfRange, "must be at least 16 characters")
+	v.MaxLength("secret", r.Secret, MaxSecretLength)
+	return v.Err()
+}
+
+// UpdateSubscriptionRequest pauses or resumes a subscription.
+type UpdateSubscriptionRequest struct {
+	Active *bool `json:"active"`
+}
+
+func (r UpdateSubscriptionRequest) Validate() error {
+	var v validation.Validator
+	v.Check(r.Active != nil, "active", validation.CodeRequired, "is required")
+	return v.Err()
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	// DeliveryStatusFailed means every attempt failed; no more are made.
+	DeliveryStatusFailed DeliveryStatus = "failed"
+)
+
+// Delivery is one event on its way to one subscription, and the log of how
+// sending it went.
+type Delivery struct {
+	ID             int64                  `json:"id" db:"id"`
+	SubscriptionID int64                  `json:"subscription_id" db:"subscription_id"`
+	EventID        string                 `json:"event_id" db:"event_id"`
+	EventType      notification.EventType `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage        `json:"payload" db:"payload"`
+	Status         DeliveryStatus         `json:"status" db:"status"`
+	Attempts       int                    `json:"attempts" db:"attempts"`
+	NextAttemptAt  *time.Time             `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	// ResponseStatus is the HTTP status of the last attempt, 0 when the
+	// endpoint could not be reached.
+	ResponseStatus int        `json:"response_status,omitempty" db:"response_status"`
+	LastError      string     `json:"last_error,omitempty" db:"last_error"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Payload is the JSON body POSTed for an event.
+type Payload struct {
+	ID        string                 `json:"id"`
+	Type      notification.EventType `json:"type"`
+	CreatedAt time.Time              `json:"created_at"`
+	Data      map[string]any         `json:"data"`
+}
+
+// Policy controls delivery retries.
+type Policy struct {
+	MaxAttempts int
+	// Backoff is the delay before the first retry; it doubles after each
+	// failed attempt.
+	Backoff time.Duration
+	Timeout time.Duration
+}
+
+// maxRetryDelay caps the backoff so that a long outage is retried at least
+// daily.
+const maxRetryDelay = 24 * time.Hour
+
+// RetryDelay is how long to wait after the given number of failed attempts.
+func (p Policy) RetryDelay(attempts int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
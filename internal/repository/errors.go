@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrNotFound      = apperr.NotFound("not_found", "record not found")
+	ErrDuplicate     = apperr.Conflict("duplicate", "record already exists")
+	ErrReferenced    = apperr.Conflict("referenced", "record is still referenced by other records")
+	ErrInvalidRef    = apperr.Validation("invalid_reference", "referenced record does not exist")
+	ErrConstraint    = apperr.Validation("constraint_violation", "value violates a data constraint")
+	ErrUnavailable   = apperr.Unavailable("database_unavailable", "database is temporarily unavailable")
+	ErrSerialization = apperr.Conflict("concurrent_update", "record was changed concurrently, retry the request")
+	ErrStaleVersion  = apperr.PreconditionFailed("version_mismatch", "record was changed by someone else, reload it and retry")
+	ErrRoomTaken     = apperr.Conflict("room_taken", "room was booked for these dates meanwhile, reload and retry")
+)
+
+// wrapError translates driver errors into domain errors: missing rows,
+// constraint violations and connection failures. Anything else, including
+// errors that are already classified, is returned unchanged.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound.Wrap(err)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation", "exclusion_violation":
+			return ErrDuplicate.Wrap(err)
+		case "foreign_key_violation":
+			// The same code is raised for inserting a dangling reference and
+			// for deleting a row something still points to.
+			if strings.Contains(pqErr.Detail, "is still referenced") {
+				return ErrReferenced.Wrap(err)
+			}
+			return ErrInvalidRef.Wrap(err)
+		case "check_violation", "not_null_violation", "string_data_right_truncation", "numeric_value_out_of_range":
+			return ErrConstraint.Wrap(err)
+		case "serialization_failure", "deadlock_detected":
+			return ErrSerialization.Wrap(err)
+		}
+		switch pqErr.Code.Class() {
+		case "08", "53", "57":
+			return ErrUnavailable.Wrap(err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr) {
+		return ErrUnavailable.Wrap(err)
+	}
+	return err
+}
+
+// execOne runs a statement that must affect exactly one row identified by
+// its ID and reports ErrNotFound when there was none.
+func execOne(ctx context.Context, db dbtx, query string, args ...any) error {
+	res, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return wrapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return wrapError(err)
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CheckVersion reports ErrStaleVersion when a client asked to change the
+// given version of a record but it is at another one. A zero expected
+// version makes the request unconditional.
+func CheckVersion(current, expected int64) error {
+	if expected != 0 && current != expected {
+		return ErrStaleVersion
+	}
+	return nil
+}
+
+// updateVersioned runs an UPDATE ... RETURNING guarded by "version = $n" and
+// scans the returned row into dest. When no row matched it tells a missing
+// record (ErrNotFound) from one that has moved on (ErrStaleVersion).
+func updateVersioned(ctx context.Context, db dbtx, table string, id int64, query string, args []any, dest ...any) error {
+	err := db.QueryRowContext(ctx, query, args...).Scan(dest...)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return wrapError(err)
+	}
+
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return wrapError(err)
+	}
+	if exists {
+		return ErrStaleVersion
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+)
+
+var (
+	ErrInvalidCursor = apperr.Validation("invalid_cursor", "invalid pagination cursor")
+	ErrInvalidSort   = apperr.Validation("invalid_sort", "invalid sort field")
+)
+
+// conditions accumulates WHERE clauses; every "?" in a clause is replaced by
+// the next positional placeholder.
+type conditions struct {
+	clauses []string
+	args    []any
+}
+
+func (c *conditions) add(clause string, args ...any) {
+	for _, arg := range args {
+		c.args = append(c.args, arg)
+		clause = strings.Replace(clause, "?", "$"+strconv.Itoa(len(c.args)), 1)
+	}
+	c.clauses = append(c.clauses, clause)
+}
+
+func (c conditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+func (c conditions) clone() conditions {
+	return conditions{
+		clauses: append([]string(nil), c.clauses...),
+		args:    append([]any(nil), c.args...),
+	}
+}
+
+// sortKey is a column a list can be ordered by: its SQL expression, the type
+// the cursor value is cast to, and how to read that value from a row.
+type sortKey[T any] struct {
+	expr  string
+	cast  string
+	value func(T) string
+}
+
+type cursor struct {
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	var c cursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// listQuery describes a paginated list: the SELECT and COUNT statements
+// without WHERE, the available sort keys and how rows are loaded.
+type listQuery[T any] struct {
+	selectQuery string
+	countQuery  string
+	idExpr      string
+	id          func(T) int64
+	keys        map[string]sortKey[T]
+	load        func(ctx context.Context, db dbtx, query string, args ...any) ([]T, error)
+}
+
+// paginate runs a keyset-paginated query: rows strictly after the cursor in
+// (sort key, id) order, one extra row fetched to know whether a next page exists.
+func paginate[T any](ctx context.Context, db dbtx, q listQuery[T], cond conditions, page booking.PageRequest) (*booking.Page[T], error) {
+	key, ok := q.keys[page.Sort]
+	if !ok {
+		return nil, ErrInvalidSort
+	}
+
+	result := &booking.Page[T]{Items: []T{}}
+	if err := db.QueryRowContext(ctx, q.countQuery+cond.where(), cond.args...).Scan(&result.Total); err != nil {
+		return nil, wrapError(err)
+	}
+
+	direction, op := "ASC", ">"
+	if page.Order == booking.SortDesc {
+		direction, op = "DESC", "<"
+	}
+
+	paged := cond.clone()
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		paged.add(fmt.Sprintf("(%s, %s) %s (?::%s, ?)", key.expr, q.idExpr, op, key.cast), c.Value, c.ID)
+	}
+
+	query := fmt.Sprintf("%s%s ORDER BY %s %s, %s %s LIMIT %d",
+		q.selectQuery, paged.where(), key.expr, direction, q.idExpr, direction, page.Limit+1)
+	items, err := q.load(ctx, db, query, paged.args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	if len(items) > page.Limit {
+		items = items[:page.Limit]
+		last := items[len(items)-1]
+		result.NextCursor = encodeCursor(cursor{Value: key.value(last), ID: q.id(last)})
+	}
+	if items != nil {
+		result.Items = items
+	}
+	return result, nil
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
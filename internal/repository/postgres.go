@@ -4,16 +4,19 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/models/notification"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type postgresRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func NewPostgresRepository(connectionString string) (Repository, error) {
@@ -34,7 +37,10 @@ func NewPostgresRepository(connectionString string) (Repository, error) {
 }
 
 func (r *postgresRepository) Close() error {
-	return r.db.Close()
+	if pool, ok := r.db.(*sql.DB); ok {
+		return pool.Close()
+	}
+	return nil
 }
 
 func (r *postgresRepository) Room() RoomRepository {
@@ -53,444 +59,711 @@ func (r *postgresRepository) SpecialDate() SpecialDateRepository {
 	return &specialDateRepository{db: r.db}
 }
 
+func (r *postgresRepository) Housekeeping() HousekeepingRepository {
+	return &housekeepingRepository{db: r.db}
+}
+
+func (r *postgresRepository) Maintenance() MaintenanceRepository {
+	return &maintenanceRepository{db: r.db}
+}
+
+func (r *postgresRepository) Guest() GuestRepository {
+	return &guestRepository{db: r.db}
+}
+
+func (r *postgresRepository) Loyalty() LoyaltyRepository {
+	return &loyaltyRepository{db: r.db}
+}
+
+func (r *postgresRepository) Idempotency() IdempotencyRepository {
+	return &idempotencyRepository{db: r.db}
+}
+
+func (r *postgresRepository) Payment() PaymentRepository {
+	return &paymentRepository{db: r.db}
+}
+
+func (r *postgresRepository) Folio() FolioRepository {
+	return &folioRepository{db: r.db}
+}
+
+func (r *postgresRepository) Report() ReportRepository {
+	return &reportRepository{db: r.db}
+}
+
+func (r *postgresRepository) Calendar() CalendarRepository {
+	return &calendarRepository{db: r.db}
+}
+
+func (r *postgresRepository) Channel() ChannelRepository {
+	return &channelRepository{db: r.db}
+}
+
+func (r *postgresRepository) Webhook() WebhookRepository {
+	return &webhookRepository{db: r.db}
+}
+
+func (r *postgresRepository) Waitlist() WaitlistRepository {
+	return &waitlistRepository{db: r.db}
+}
+
 type roomRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func (r *roomRepository) GetAll(ctx context.Context) ([]booking.Room, error) {
-	query := `SELECT id, room_number, room_type, base_price, capacity, status, description, created_at, updated_at FROM rooms ORDER BY room_number`
-	rows, err := r.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+	query := `SELECT ` + roomColumns + ` FROM rooms WHERE archived_at IS NULL ORDER BY room_number`
+	return queryRooms(ctx, r.db, query)
+}
 
-	var rooms []booking.Room
-	for rows.Next() {
-		var room booking.Room
-		err := rows.Scan(&room.ID, &room.RoomNumber, &room.RoomType, &room.BasePrice, &room.Capacity, &room.Status, &room.Description, &room.CreatedAt, &room.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		rooms = append(rooms, room)
+// LockAll is GetAll locking the rooms until the transaction ends, so it is
+// meant for a repository of Repository.Transaction.
+func (r *roomRepository) LockAll(ctx context.Context) ([]booking.Room, error) {
+	query := `SELECT ` + roomColumns + ` FROM rooms WHERE archived_at IS NULL ORDER BY id FOR UPDATE`
+	return queryRooms(ctx, r.db, query)
+}
+
+var roomList = listQuery[booking.Room]{
+	selectQuery: `SELECT ` + roomColumns + ` FROM rooms`,
+	countQuery:  `SELECT COUNT(*) FROM rooms`,
+	idExpr:      "id",
+	id:          func(room booking.Room) int64 { return room.ID },
+	keys: map[string]sortKey[booking.Room]{
+		"id":          {"id", "bigint", func(room booking.Room) string { return strconv.FormatInt(room.ID, 10) }},
+		"room_number": {"room_number", "text", func(room booking.Room) string { return room.RoomNumber }},
+		"base_price":  {"base_price", "numeric", func(room booking.Room) string { return formatNumber(room.BasePrice) }},
+		"capacity":    {"capacity", "int", func(room booking.Room) string { return strconv.Itoa(room.Capacity) }},
+		"created_at":  {"created_at", "timestamp", func(room booking.Room) string { return formatTimestamp(room.CreatedAt) }},
+	},
+	load: queryRooms,
+}
+
+func (r *roomRepository) List(ctx context.Context, filter booking.RoomFilter) (*booking.Page[booking.Room], error) {
+	var cond conditions
+	if filter.Archived {
+		cond.add("archived_at IS NOT NULL")
+	} else {
+		cond.add("archived_at IS NULL")
+	}
+	if filter.RoomType != "" {
+		cond.add("room_type = ?", filter.RoomType)
+	}
+	if filter.Status != "" {
+		cond.add("status = ?", filter.Status)
+	}
+	if filter.MinCapacity > 0 {
+		cond.add("capacity >= ?", filter.MinCapacity)
+	}
+	if filter.MinPrice > 0 {
+		cond.add("base_price >= ?", filter.MinPrice)
 	}
-	return rooms, nil
+	if filter.MaxPrice > 0 {
+		cond.add("base_price <= ?", filter.MaxPrice)
+	}
+	return paginate(ctx, r.db, roomList, cond, filter.PageRequest)
 }
 
 func (r *roomRepository) GetByID(ctx context.Context, id int64) (*booking.Room, error) {
-	query := `SELECT id, room_number, room_type, base_price, capacity, status, description, created_at, updated_at FROM rooms WHERE id = $1`
+	query := `SELECT ` + roomColumns + ` FROM rooms WHERE id = $1`
 	var room booking.Room
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&room.ID, &room.RoomNumber, &room.RoomType, &room.BasePrice, &room.Capacity, &room.Status, &room.Description, &room.CreatedAt, &room.UpdatedAt)
+	err := scanRoom(r.db.QueryRowContext(ctx, query, id), &room)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, err
+		return nil, wrapError(err)
 	}
 	return &room, nil
 }
 
 func (r *roomRepository) GetByNumber(ctx context.Context, roomNumber string) (*booking.Room, error) {
-	query := `SELECT id, room_number, room_type, base_price, capacity, status, description, created_at, updated_at FROM rooms WHERE room_number = $1`
+	query := `SELECT ` + roomColumns + ` FROM rooms WHERE room_number = $1 AND archived_at IS NULL`
 	var room booking.Room
-	err := r.db.QueryRowContext(ctx, query, roomNumber).Scan(&room.ID, &room.RoomNumber, &room.RoomType, &room.BasePrice, &room.Capacity, &room.Status, &room.Description, &room.CreatedAt, &room.UpdatedAt)
+	err := scanRoom(r.db.QueryRowContext(ctx, query, roomNumber), &room)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, err
+		return nil, wrapError(err)
 	}
 	return &room, nil
 }
 
-func (r *roomRepository) GetAvailable(ctx context.Context, checkIn, checkOut time.Time) ([]booking.Room, error) {
+func (r *roomRepository) GetAvailable(ctx context.Context, checkIn, checkOut booking.Date) ([]booking.Room, error) {
 	query := `
-		SELECT id, room_number, room_type, base_price, capacity, status, description, created_at, updated_at 
+		SELECT ` + roomColumns + `
 		FROM rooms 
-		WHERE status = 'available' 
+		WHERE archived_at IS NULL
 		AND id NOT IN (
 			SELECT room_id FROM bookings 
-			WHERE status != 'cancelled'
+			WHERE status NOT IN ('cancelled', 'no_show')
 			AND start_date < $2 AND end_date > $1
 		)
+		AND id NOT IN (
+			SELECT room_id FROM maintenance_windows
+			WHERE start_date < $2 AND end_date > $1
+		)
 		ORDER BY room_type, room_number
 	`
-	rows, err := r.db.QueryContext(ctx, query, checkIn, checkOut)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var rooms []booking.Room
-	for rows.Next() {
-		var room booking.Room
-		err := rows.Scan(&room.ID, &room.RoomNumber, &room.RoomType, &room.BasePrice, &room.Capacity, &room.Status, &room.Description, &room.CreatedAt, &room.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		rooms = append(rooms, room)
-	}
-	return rooms, nil
+	return queryRooms(ctx, r.db, query, checkIn, checkOut)
 }
 
-func (r *roomRepository) GetAvailableByType(ctx context.Context, roomType booking.RoomType, checkIn, checkOut time.Time) ([]booking.Room, error) {
+func (r *roomRepository) GetAvailableByType(ctx context.Context, roomType booking.RoomType, checkIn, checkOut booking.Date) ([]booking.Room, error) {
 	query := `
-		SELECT id, room_number, room_type, base_price, capacity, status, description, created_at, updated_at 
+		SELECT ` + roomColumns + `
 		FROM rooms 
-		WHERE status = 'available' 
-		AND room_type = $1
+		WHERE room_type = $1
+		AND archived_at IS NULL
 		AND id NOT IN (
 			SELECT room_id FROM bookings 
-			WHERE status != 'cancelled'
+			WHERE status NOT IN ('cancelled', 'no_show')
 			AND start_date < $3 AND end_date > $2
 		)
+		AND id NOT IN (
+			SELECT room_id FROM maintenance_windows
+			WHERE start_date < $3 AND end_date > $2
+		)
 		ORDER BY room_number
 	`
-	rows, err := r.db.QueryContext(ctx, query, roomType, checkIn, checkOut)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var rooms []booking.Room
-	for rows.Next() {
-		var room booking.Room
-		err := rows.Scan(&room.ID, &room.RoomNumber, &room.RoomType, &room.BasePrice, &room.Capacity, &room.Status, &room.Description, &room.CreatedAt, &room.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		rooms = append(rooms, room)
-	}
-	return rooms, nil
+	return queryRooms(ctx, r.db, query, roomType, checkIn, checkOut)
 }
 
-func (r *roomRepository) GetAvailableByCapacity(ctx context.Context, capacity int, checkIn, checkOut time.Time) ([]booking.Room, error) {
+func (r *roomRepository) GetAvailableByCapacity(ctx context.Context, capacity int, checkIn, checkOut booking.Date) ([]booking.Room, error) {
 	query := `
-		SELECT id, room_number, room_type, base_price, capacity, status, description, created_at, updated_at 
+		SELECT ` + roomColumns + `
 		FROM rooms 
-		WHERE status = 'available' 
-		AND capacity >= $1
+		WHERE capacity >= $1
+		AND archived_at IS NULL
 		AND id NOT IN (
 			SELECT room_id FROM bookings 
-			WHERE status != 'cancelled'
+			WHERE status NOT IN ('cancelled', 'no_show')
 			AND start_date < $3 AND end_date > $2
 		)
+		AND id NOT IN (
+			SELECT room_id FROM maintenance_windows
+			WHERE start_date < $3 AND end_date > $2
+		)
 		ORDER BY capacity, room_number
 	`
-	rows, err := r.db.QueryContext(ctx, query, capacity, checkIn, checkOut)
+	return queryRooms(ctx, r.db, query, capacity, checkIn, checkOut)
+}
+
+// GetNightlyAvailability returns one row per room and night in [from, to),
+// joining bookings and maintenance windows against a generated calendar.
+func (r *roomRepository) GetNightlyAvailability(ctx context.Context, roomType booking.RoomType, from, to booking.Date) ([]booking.RoomNight, error) {
+	query := `
+		SELECT ` + qualify("r", roomColumns) + `, d.night::date,
+			EXISTS (
+				SELECT 1 FROM bookings b
+				WHERE b.room_id = r.id
+				AND b.status NOT IN ('cancelled', 'no_show')
+				AND b.start_date <= d.night AND b.end_date > d.night
+			),
+			m.kind, m.reason
+		FROM rooms r
+		CROSS JOIN generate_series($1::date, $2::date - 1, interval '1 day') AS d(night)
+		LEFT JOIN LATERAL (
+			SELECT kind, reason FROM maintenance_windows mw
+			WHERE mw.room_id = r.id
+			AND mw.start_date <= d.night AND mw.end_date > d.night
+			ORDER BY mw.kind
+			LIMIT 1
+		) m ON true
+		WHERE r.archived_at IS NULL
+		AND ($3::text = '' OR r.room_type = $3::text)
+		ORDER BY r.room_type, r.room_number, d.night
+	`
+	rows, err := r.db.QueryContext(ctx, query, from, to, roomType)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer rows.Close()
 
-	var rooms []booking.Room
+	var nights []booking.RoomNight
 	for rows.Next() {
-		var room booking.Room
-		err := rows.Scan(&room.ID, &room.RoomNumber, &room.RoomType, &room.BasePrice, &room.Capacity, &room.Status, &room.Description, &room.CreatedAt, &room.UpdatedAt)
-		if err != nil {
-			return nil, err
+		var n booking.RoomNight
+		var kind, reason sql.NullString
+		dest := append(roomFields(&n.Room), &n.Date, &n.Booked, &kind, &reason)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, wrapError(err)
 		}
-		rooms = append(rooms, room)
+		n.MaintenanceKind = booking.MaintenanceKind(kind.String)
+		n.MaintenanceReason = reason.String
+		nights = append(nights, n)
 	}
-	return rooms, nil
+	return nights, wrapError(rows.Err())
 }
 
 func (r *roomRepository) Create(ctx context.Context, room *booking.Room) error {
 	query := `
 		INSERT INTO rooms (room_number, room_type, base_price, capacity, status, description)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at
+		RETURNING id, housekeeping_status, version, created_at, updated_at
 	`
-	return r.db.QueryRowContext(ctx, query, room.RoomNumber, room.RoomType, room.BasePrice, room.Capacity, room.Status, room.Description).
-		Scan(&room.ID, &room.CreatedAt, &room.UpdatedAt)
+	return wrapError(r.db.QueryRowContext(ctx, query, room.RoomNumber, room.RoomType, room.BasePrice, room.Capacity, room.Status, room.Description).
+		Scan(&room.ID, &room.HousekeepingStatus, &room.Version, &room.CreatedAt, &room.UpdatedAt))
 }
 
+// Update writes the editable fields of the room if it is still at
+// room.Version and reloads the row with its new version.
 func (r *roomRepository) Update(ctx context.Context, room *booking.Room) error {
 	query := `
 		UPDATE rooms 
-		SET room_number = $1, room_type = $2, base_price = $3, capacity = $4, status = $5, description = $6, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $7
-	`
-	_, err := r.db.ExecContext(ctx, query, room.RoomNumber, room.RoomType, room.BasePrice, room.Capacity, room.Status, room.Description, room.ID)
-	return err
+		SET room_number = $1, room_type = $2, base_price = $3, capacity = $4, status = $5, description = $6,
+			version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7 AND version = $8
+		RETURNING ` + roomColumns
+	args := []any{room.RoomNumber, room.RoomType, room.BasePrice, room.Capacity, room.Status, room.Description, room.ID, room.Version}
+	return updateVersioned(ctx, r.db, "rooms", room.ID, query, args, roomFields(room)...)
+}
+
+// Archive takes the room out of service after moving the given bookings to
+// their new rooms, all in one transaction.
+func (r *roomRepository) Archive(ctx context.Context, id int64, relocations []booking.Relocation) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	// The plan was checked before the transaction began, so the target rooms
+	// and their bookings are locked and every move is checked again.
+	targets := make([]int64, 0, len(relocations))
+	for _, rel := range relocations {
+		targets = append(targets, rel.RoomID)
+	}
+	for _, query := range []string{
+		`SELECT id FROM rooms WHERE id = ANY($1) ORDER BY id FOR UPDATE`,
+		`SELECT id FROM bookings WHERE room_id = ANY($1) AND status NOT IN ('cancelled', 'no_show') ORDER BY id FOR UPDATE`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, pq.Array(targets)); err != nil {
+			return wrapError(err)
+		}
+	}
+
+	for _, rel := range relocations {
+		err := execOne(ctx, tx, `
+			UPDATE bookings b SET room_id = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP
+			WHERE b.id = $2 AND b.room_id = $3
+			AND NOT EXISTS (
+				SELECT 1 FROM bookings o
+				WHERE o.room_id = $1 AND o.id <> b.id
+				AND o.status NOT IN ('cancelled', 'no_show')
+				AND o.start_date < b.end_date AND o.end_date > b.start_date)
+			AND NOT EXISTS (
+				SELECT 1 FROM maintenance_windows m
+				WHERE m.room_id = $1
+				AND m.start_date < b.end_date AND m.end_date > b.start_date)
+		`, rel.RoomID, rel.BookingID, id)
+		if errors.Is(err, ErrNotFound) {
+			return ErrRoomTaken
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE rooms SET archived_at = CURRENT_TIMESTAMP, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND archived_at IS NULL`, id)
+	if err != nil {
+		return wrapError(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return wrapError(err)
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return wrapError(tx.Commit())
 }
 
-func (r *roomRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM rooms WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+func (r *roomRepository) Restore(ctx context.Context, id int64) error {
+	query := `UPDATE rooms SET archived_at = NULL, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND archived_at IS NOT NULL`
+	return execOne(ctx, r.db, query, id)
 }
 
 func (r *roomRepository) UpdateStatus(ctx context.Context, id int64, status booking.RoomStatus) error {
-	query := `UPDATE rooms SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	query := `UPDATE rooms SET status = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, status, id)
+	return wrapError(err)
+}
+
+func (r *roomRepository) UpdateHousekeepingStatus(ctx context.Context, id int64, status booking.HousekeepingStatus) error {
+	query := `UPDATE rooms SET housekeeping_status = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
 	_, err := r.db.ExecContext(ctx, query, status, id)
-	return err
+	return wrapError(err)
 }
 
 type bookingRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func (r *bookingRepository) GetAll(ctx context.Context) ([]booking.Booking, error) {
-	query := `SELECT id, start_date, end_date, room_id, guest_info, price, status, created_at, updated_at FROM bookings ORDER BY created_at DESC`
-	rows, err := r.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var bookings []booking.Booking
-	for rows.Next() {
-		var b booking.Booking
-		var guestInfoJSON []byte
-		err := rows.Scan(&b.ID, &b.StartDate, &b.EndDate, &b.RoomID, &guestInfoJSON, &b.Price, &b.Status, &b.CreatedAt, &b.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		json.Unmarshal(guestInfoJSON, &b.GuestInfo)
-		bookings = append(bookings, b)
-	}
-	return bookings, nil
+	query := `SELECT ` + bookingColumns + ` FROM bookings ORDER BY created_at DESC`
+	return queryBookings(ctx, r.db, query)
 }
 
 func (r *bookingRepository) GetAllWithRooms(ctx context.Context) ([]booking.BookingWithRoom, error) {
 	query := `
-		SELECT 
-			b.id, b.start_date, b.end_date, b.room_id, b.guest_info, b.price, b.status, b.created_at, b.updated_at,
-			r.id, r.room_number, r.room_type, r.base_price, r.capacity, r.status, r.description, r.created_at, r.updated_at
+		SELECT ` + bookingWithRoomColumns + `
 		FROM bookings b
 		JOIN rooms r ON b.room_id = r.id
 		ORDER BY b.created_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+	return queryBookingsWithRooms(ctx, r.db, query)
+}
 
-	var bookings []booking.BookingWithRoom
-	for rows.Next() {
-		var b booking.BookingWithRoom
-		var guestInfoJSON []byte
-		err := rows.Scan(
-			&b.Booking.ID, &b.Booking.StartDate, &b.Booking.EndDate, &b.Booking.RoomID, &guestInfoJSON, &b.Booking.Price, &b.Booking.Status, &b.Booking.CreatedAt, &b.Booking.UpdatedAt,
-			&b.Room.ID, &b.Room.RoomNumber, &b.Room.RoomType, &b.Room.BasePrice, &b.Room.Capacity, &b.Room.Status, &b.Room.Description, &b.Room.CreatedAt, &b.Room.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		json.Unmarshal(guestInfoJSON, &b.Booking.GuestInfo)
-		bookings = append(bookings, b)
+var bookingList = listQuery[booking.BookingWithRoom]{
+	selectQuery: `SELECT ` + bookingWithRoomColumns + ` FROM bookings b JOIN rooms r ON b.room_id = r.id`,
+	countQuery:  `SELECT COUNT(*) FROM bookings b JOIN rooms r ON b.room_id = r.id`,
+	idExpr:      "b.id",
+	id:          func(b booking.BookingWithRoom) int64 { return b.ID },
+	keys: map[string]sortKey[booking.BookingWithRoom]{
+		"id":         {"b.id", "bigint", func(b booking.BookingWithRoom) string { return strconv.FormatInt(b.ID, 10) }},
+		"created_at": {"b.created_at", "timestamp", func(b booking.BookingWithRoom) string { return formatTimestamp(b.CreatedAt) }},
+		"start_date": {"b.start_date", "date", func(b booking.BookingWithRoom) string { return b.StartDate.String() }},
+		"price":      {"b.price", "numeric", func(b booking.BookingWithRoom) string { return formatNumber(b.Price) }},
+	},
+	load: queryBookingsWithRooms,
+}
+
+func (r *bookingRepository) List(ctx context.Context, filter booking.BookingFilter) (*booking.Page[booking.BookingWithRoom], error) {
+	var cond conditions
+	if filter.Status != "" {
+		cond.add("b.status = ?", filter.Status)
+	}
+	if filter.RoomID > 0 {
+		cond.add("b.room_id = ?", filter.RoomID)
+	}
+	if filter.GuestEmail != "" {
+		cond.add("LOWER(b.guest_info->>'email') = LOWER(?)", filter.GuestEmail)
 	}
-	return bookings, nil
+	if !filter.From.IsZero() {
+		cond.add("b.end_date > ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		cond.add("b.start_date < ?", filter.To)
+	}
+	if filter.MinPrice > 0 {
+		cond.add("b.price >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		cond.add("b.price <= ?", filter.MaxPrice)
+	}
+	return paginate(ctx, r.db, bookingList, cond, filter.PageRequest)
 }
 
 func (r *bookingRepository) GetByID(ctx context.Context, id int64) (*booking.Booking, error) {
-	query := `SELECT id, start_date, end_date, room_id, guest_info, price, status, created_at, updated_at FROM bookings WHERE id = $1`
+	return r.getByID(ctx, `SELECT `+bookingColumns+` FROM bookings WHERE id = $1`, id)
+}
+
+// Lock reads a booking and locks its row until the transaction ends, so it
+// is meant for a repository of Repository.Transaction.
+func (r *bookingRepository) Lock(ctx context.Context, id int64) (*booking.Booking, error) {
+	return r.getByID(ctx, `SELECT `+bookingColumns+` FROM bookings WHERE id = $1 FOR UPDATE`, id)
+}
+
+func (r *bookingRepository) getByID(ctx context.Context, query string, id int64) (*booking.Booking, error) {
 	var b booking.Booking
-	var guestInfoJSON []byte
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&b.ID, &b.StartDate, &b.EndDate, &b.RoomID, &guestInfoJSON, &b.Price, &b.Status, &b.CreatedAt, &b.UpdatedAt)
+	err := scanBooking(r.db.QueryRowContext(ctx, query, id), &b)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, err
+		return nil, wrapError(err)
 	}
-	json.Unmarshal(guestInfoJSON, &b.GuestInfo)
 	return &b, nil
 }
 
 func (r *bookingRepository) GetByRoomID(ctx context.Context, roomID int64) ([]booking.Booking, error) {
-	query := `SELECT id, start_date, end_date, room_id, guest_info, price, status, created_at, updated_at FROM bookings WHERE room_id = $1 ORDER BY start_date`
-	rows, err := r.db.QueryContext(ctx, query, roomID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE room_id = $1 ORDER BY start_date`
+	return queryBookings(ctx, r.db, query, roomID)
+}
 
-	var bookings []booking.Booking
-	for rows.Next() {
-		var b booking.Booking
-		var guestInfoJSON []byte
-		err := rows.Scan(&b.ID, &b.StartDate, &b.EndDate, &b.RoomID, &guestInfoJSON, &b.Price, &b.Status, &b.CreatedAt, &b.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		json.Unmarshal(guestInfoJSON, &b.GuestInfo)
-		bookings = append(bookings, b)
-	}
-	return bookings, nil
+func (r *bookingRepository) GetByGuestWithRooms(ctx context.Context, guestID int64) ([]booking.BookingWithRoom, error) {
+	query := `
+		SELECT ` + bookingWithRoomColumns + `
+		FROM bookings b
+		JOIN rooms r ON b.room_id = r.id
+		WHERE b.guest_id = $1
+		ORDER BY b.start_date DESC
+	`
+	return queryBookingsWithRooms(ctx, r.db, query, guestID)
 }
 
 func (r *bookingRepository) GetByStatus(ctx context.Context, status booking.BookingStatus) ([]booking.Booking, error) {
-	query := `SELECT id, start_date, end_date, room_id, guest_info, price, status, created_at, updated_at FROM bookings WHERE status = $1 ORDER BY created_at DESC`
-	rows, err := r.db.QueryContext(ctx, query, status)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var bookings []booking.Booking
-	for rows.Next() {
-		var b booking.Booking
-		var guestInfoJSON []byte
-		err := rows.Scan(&b.ID, &b.StartDate, &b.EndDate, &b.RoomID, &guestInfoJSON, &b.Price, &b.Status, &b.CreatedAt, &b.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		json.Unmarshal(guestInfoJSON, &b.GuestInfo)
-		bookings = append(bookings, b)
-	}
-	return bookings, nil
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE status = $1 ORDER BY created_at DESC`
+	return queryBookings(ctx, r.db, query, status)
 }
 
 func (r *bookingRepository) GetByStatusWithRooms(ctx context.Context, status booking.BookingStatus) ([]booking.BookingWithRoom, error) {
 	query := `
-		SELECT 
-			b.id, b.start_date, b.end_date, b.room_id, b.guest_info, b.price, b.status, b.created_at, b.updated_at,
-			r.id, r.room_number, r.room_type, r.base_price, r.capacity, r.status, r.description, r.created_at, r.updated_at
+		SELECT ` + bookingWithRoomColumns + `
 		FROM bookings b
 		JOIN rooms r ON b.room_id = r.id
 		WHERE b.status = $1
 		ORDER BY b.created_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, query, status)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var bookings []booking.BookingWithRoom
-	for rows.Next() {
-		var b booking.BookingWithRoom
-		var guestInfoJSON []byte
-		err := rows.Scan(
-			&b.Booking.ID, &b.Booking.StartDate, &b.Booking.EndDate, &b.Booking.RoomID, &guestInfoJSON, &b.Booking.Price, &b.Booking.Status, &b.Booking.CreatedAt, &b.Booking.UpdatedAt,
-			&b.Room.ID, &b.Room.RoomNumber, &b.Room.RoomType, &b.Room.BasePrice, &b.Room.Capacity, &b.Room.Status, &b.Room.Description, &b.Room.CreatedAt, &b.Room.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		json.Unmarshal(guestInfoJSON, &b.Booking.GuestInfo)
-		bookings = append(bookings, b)
-	}
-	return bookings, nil
+	return queryBookingsWithRooms(ctx, r.db, query, status)
 }
 
 func (r *bookingRepository) GetByEmail(ctx context.Context, email string) ([]booking.Booking, error) {
-	query := `SELECT id, start_date, end_date, room_id, guest_info, price, status, created_at, updated_at FROM bookings WHERE guest_info->>'email' = $1 ORDER BY created_at DESC`
-	rows, err := r.db.QueryContext(ctx, query, email)
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE guest_info->>'email' = $1 ORDER BY created_at DESC`
+	return queryBookings(ctx, r.db, query, email)
+}
+
+// Search ranks bookings by how well the guest name, email or phone matches
+// using pg_trgm word similarity; substring and exact ID matches rank first.
+func (r *bookingRepository) Search(ctx context.Context, q booking.BookingSearchQuery) ([]booking.BookingSearchResult, error) {
+	query := `
+		SELECT ` + bookingWithRoomColumns + `, GREATEST(
+			CASE WHEN b.id = $3 THEN 3 ELSE 0 END,
+			CASE WHEN $1 = '' THEN 0 ELSE word_similarity($1, LOWER(b.guest_info->>'name'))
+				+ CASE WHEN LOWER(b.guest_info->>'name') LIKE $4 THEN 1 ELSE 0 END END,
+			CASE WHEN $1 = '' THEN 0 ELSE word_similarity($1, LOWER(b.guest_info->>'email'))
+				+ CASE WHEN LOWER(b.guest_info->>'email') LIKE $4 THEN 1 ELSE 0 END END,
+			CASE WHEN $2 <> '' AND REGEXP_REPLACE(b.guest_info->>'phone', '\D', '', 'g') LIKE $5 THEN 2 ELSE 0 END
+		) AS rank
+		FROM bookings b
+		JOIN rooms r ON b.room_id = r.id
+		WHERE b.id = $3
+		OR ($1 <> '' AND (
+			$1 <% LOWER(b.guest_info->>'name')
+			OR $1 <% LOWER(b.guest_info->>'email')
+			OR LOWER(b.guest_info->>'name') LIKE $4
+			OR LOWER(b.guest_info->>'email') LIKE $4
+		))
+		OR ($2 <> '' AND REGEXP_REPLACE(b.guest_info->>'phone', '\D', '', 'g') LIKE $5)
+		ORDER BY rank DESC, b.start_date DESC
+		LIMIT $6
+	`
+	text := strings.ToLower(q.Text)
+	rows, err := r.db.QueryContext(ctx, query, text, q.Phone, q.ID, containsPattern(text), containsPattern(q.Phone), q.Limit)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer rows.Close()
 
-	var bookings []booking.Booking
+	var results []booking.BookingSearchResult
 	for rows.Next() {
-		var b booking.Booking
-		var guestInfoJSON []byte
-		err := rows.Scan(&b.ID, &b.StartDate, &b.EndDate, &b.RoomID, &guestInfoJSON, &b.Price, &b.Status, &b.CreatedAt, &b.UpdatedAt)
-		if err != nil {
-			return nil, err
+		var res booking.BookingSearchResult
+		var raw bookingJSON
+		dest := append(bookingFields(&res.Booking, &raw), roomFields(&res.Room)...)
+		if err := rows.Scan(append(dest, &res.Rank)...); err != nil {
+			return nil, wrapError(err)
+		}
+		if err := raw.decode(&res.Booking); err != nil {
+			return nil, wrapError(err)
 		}
-		json.Unmarshal(guestInfoJSON, &b.GuestInfo)
-		bookings = append(bookings, b)
+		results = append(results, res)
 	}
-	return bookings, nil
+	return results, wrapError(rows.Err())
 }
 
-func (r *bookingRepository) GetActiveForRoom(ctx context.Context, roomID int64, checkIn, checkOut time.Time) ([]booking.Booking, error) {
+// containsPattern builds a LIKE pattern matching s anywhere, with LIKE
+// wildcards in s escaped.
+func containsPattern(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(s) + "%"
+}
+
+func (r *bookingRepository) GetActiveForRoom(ctx context.Context, roomID int64, checkIn, checkOut booking.Date) ([]booking.Booking, error) {
 	query := `
-		SELECT id, start_date, end_date, room_id, guest_info, price, status, created_at, updated_at 
+		SELECT ` + bookingColumns + `
 		FROM bookings 
 		WHERE room_id = $1 
-		AND status != 'cancelled'
+		AND status NOT IN ('cancelled', 'no_show')
 		AND start_date < $3 AND end_date > $2
 	`
-	rows, err := r.db.QueryContext(ctx, query, roomID, checkIn, checkOut)
+	return queryBookings(ctx, r.db, query, roomID, checkIn, checkOut)
+}
+
+func (r *bookingRepository) GetInRangeWithRooms(ctx context.Context, from, to booking.Date) ([]booking.BookingWithRoom, error) {
+	query := `
+		SELECT ` + bookingWithRoomColumns + `
+		FROM bookings b
+		JOIN rooms r ON b.room_id = r.id
+		WHERE b.start_date < $2 AND b.end_date > $1
+		AND b.status NOT IN ('cancelled', 'no_show')
+		ORDER BY r.room_number, b.start_date
+	`
+	return queryBookingsWithRooms(ctx, r.db, query, from, to)
+}
+
+func (r *bookingRepository) GetArrivalsWithRooms(ctx context.Context, date booking.Date) ([]booking.BookingWithRoom, error) {
+	query := `
+		SELECT ` + bookingWithRoomColumns + `
+		FROM bookings b
+		JOIN rooms r ON b.room_id = r.id
+		WHERE b.start_date = $1
+		AND b.status NOT IN ('cancelled', 'no_show')
+		ORDER BY b.check_in_time, r.room_number
+	`
+	return queryBookingsWithRooms(ctx, r.db, query, date)
+}
+
+func (r *bookingRepository) GetDeparturesWithRooms(ctx context.Context, date booking.Date) ([]booking.BookingWithRoom, error) {
+	query := `
+		SELECT ` + bookingWithRoomColumns + `
+		FROM bookings b
+		JOIN rooms r ON b.room_id = r.id
+		WHERE b.end_date = $1
+		AND b.status NOT IN ('cancelled', 'no_show')
+		ORDER BY b.check_out_time, r.room_number
+	`
+	return queryBookingsWithRooms(ctx, r.db, query, date)
+}
+
+func (r *bookingRepository) GetArrivalForRoom(ctx context.Context, roomID int64, date booking.Date, exceptID int64) (*booking.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE room_id = $1 AND start_date = $2 AND id <> $3 AND status NOT IN ('cancelled', 'no_show') LIMIT 1`
+	var b booking.Booking
+	err := scanBooking(r.db.QueryRowContext(ctx, query, roomID, date, exceptID), &b)
 	if err != nil {
-		return nil, err
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, wrapError(err)
 	}
-	defer rows.Close()
+	return &b, nil
+}
 
-	var bookings []booking.Booking
-	for rows.Next() {
-		var b booking.Booking
-		var guestInfoJSON []byte
-		err := rows.Scan(&b.ID, &b.StartDate, &b.EndDate, &b.RoomID, &guestInfoJSON, &b.Price, &b.Status, &b.CreatedAt, &b.UpdatedAt)
-		if err != nil {
-			return nil, err
+func (r *bookingRepository) GetDepartureForRoom(ctx context.Context, roomID int64, date booking.Date, exceptID int64) (*booking.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE room_id = $1 AND end_date = $2 AND id <> $3 AND status NOT IN ('cancelled', 'no_show') LIMIT 1`
+	var b booking.Booking
+	err := scanBooking(r.db.QueryRowContext(ctx, query, roomID, date, exceptID), &b)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
 		}
-		json.Unmarshal(guestInfoJSON, &b.GuestInfo)
-		bookings = append(bookings, b)
+		return nil, wrapError(err)
 	}
-	return bookings, nil
+	return &b, nil
 }
 
 func (r *bookingRepository) Create(ctx context.Context, b *booking.Booking) error {
 	guestInfoJSON, err := json.Marshal(b.GuestInfo)
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 	query := `
-		INSERT INTO bookings (start_date, end_date, room_id, guest_info, price, status)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at
+		INSERT INTO bookings (start_date, end_date, room_id, guest_info, guest_id, guests, price, status, early_check_in, late_check_out, check_in_time, check_out_time, points_redeemed, points_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, version, created_at, updated_at
 	`
-	return r.db.QueryRowContext(ctx, query, b.StartDate, b.EndDate, b.RoomID, guestInfoJSON, b.Price, b.Status).
-		Scan(&b.ID, &b.CreatedAt, &b.UpdatedAt)
+	return wrapError(r.db.QueryRowContext(ctx, query, b.StartDate, b.EndDate, b.RoomID, guestInfoJSON, b.GuestID, b.Guests, b.Price, b.Status, b.EarlyCheckIn, b.LateCheckOut, b.CheckInTime, b.CheckOutTime, b.PointsRedeemed, b.PointsAmount).
+		Scan(&b.ID, &b.Version, &b.CreatedAt, &b.UpdatedAt))
 }
 
+// Update writes the booking if it is still at b.Version; on success b carries
+// the new version. UpdateStatus, CheckIn and CheckOut are guarded the same way.
 func (r *bookingRepository) Update(ctx context.Context, b *booking.Booking) error {
 	guestInfoJSON, err := json.Marshal(b.GuestInfo)
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 	query := `
 		UPDATE bookings 
-		SET start_date = $1, end_date = $2, room_id = $3, guest_info = $4, price = $5, status = $6, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $7
+		SET start_date = $1, end_date = $2, room_id = $3, guest_info = $4, price = $5, status = $6,
+			early_check_in = $7, late_check_out = $8, check_in_time = $9, check_out_time = $10,
+			version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $11 AND version = $12
+		RETURNING version, updated_at
 	`
-	_, err = r.db.ExecContext(ctx, query, b.StartDate, b.EndDate, b.RoomID, guestInfoJSON, b.Price, b.Status, b.ID)
-	return err
+	args := []any{b.StartDate, b.EndDate, b.RoomID, guestInfoJSON, b.Price, b.Status, b.EarlyCheckIn, b.LateCheckOut, b.CheckInTime, b.CheckOutTime, b.ID, b.Version}
+	return updateVersioned(ctx, r.db, "bookings", b.ID, query, args, &b.Version, &b.UpdatedAt)
 }
 
-func (r *bookingRepository) UpdateStatus(ctx context.Context, id int64, status booking.BookingStatus) error {
-	query := `UPDATE bookings SET status = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
-	_, err := r.db.ExecContext(ctx, query, status, id)
-	return err
+func (r *bookingRepository) UpdateStatus(ctx context.Context, b *booking.Booking, status booking.BookingStatus) error {
+	query := `
+		UPDATE bookings SET status = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND version = $3
+		RETURNING version, updated_at
+	`
+	return updateVersioned(ctx, r.db, "bookings", b.ID, query, []any{status, b.ID, b.Version}, &b.Version, &b.UpdatedAt)
+}
+
+func (r *bookingRepository) GetNoShowCandidates(ctx context.Context, before booking.Date) ([]booking.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + `
+		FROM bookings
+		WHERE status = 'confirmed'
+		AND checked_in_at IS NULL
+		AND start_date < $1
+		ORDER BY start_date, id
+	`
+	return queryBookings(ctx, r.db, query, before)
+}
+
+func (r *bookingRepository) CheckIn(ctx context.Context, b *booking.Booking, details *booking.CheckInDetails, at time.Time) error {
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return wrapError(err)
+	}
+	query := `
+		UPDATE bookings
+		SET status = 'checked_in', checked_in_at = $1, check_in_details = $2, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND version = $4
+		RETURNING version, updated_at
+	`
+	return updateVersioned(ctx, r.db, "bookings", b.ID, query, []any{at, detailsJSON, b.ID, b.Version}, &b.Version, &b.UpdatedAt)
+}
+
+func (r *bookingRepository) CheckOut(ctx context.Context, b *booking.Booking, at time.Time) error {
+	query := `
+		UPDATE bookings
+		SET status = 'checked_out', checked_out_at = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND version = $3
+		RETURNING version, updated_at
+	`
+	return updateVersioned(ctx, r.db, "bookings", b.ID, query, []any{at, b.ID, b.Version}, &b.Version, &b.UpdatedAt)
+}
+
+func (r *bookingRepository) MarkNoShow(ctx context.Context, id int64, penalty float64) error {
+	query := `UPDATE bookings SET status = 'no_show', penalty = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND status = 'confirmed'`
+	_, err := r.db.ExecContext(ctx, query, penalty, id)
+	return wrapError(err)
 }
 
 func (r *bookingRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM bookings WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return execOne(ctx, r.db, query, id)
 }
 
-func (r *bookingRepository) IsRoomAvailable(ctx context.Context, roomID int64, checkIn, checkOut time.Time) (bool, error) {
+func (r *bookingRepository) IsRoomAvailable(ctx context.Context, roomID int64, checkIn, checkOut booking.Date) (bool, error) {
+	return r.IsRoomAvailableExcept(ctx, roomID, checkIn, checkOut, 0)
+}
+
+// IsRoomAvailableExcept is IsRoomAvailable ignoring the booking with the given
+// ID, so a booking can be checked against its own new dates or room.
+func (r *bookingRepository) IsRoomAvailableExcept(ctx context.Context, roomID int64, checkIn, checkOut booking.Date, bookingID int64) (bool, error) {
 	query := `
-		SELECT COUNT(*) FROM bookings 
-		WHERE room_id = $1 
-		AND status != 'cancelled'
-		AND start_date < $3 AND end_date > $2
+		SELECT
+			(SELECT COUNT(*) FROM bookings 
+			WHERE room_id = $1 
+			AND id <> $4
+			AND status NOT IN ('cancelled', 'no_show')
+			AND start_date < $3 AND end_date > $2)
+			+
+			(SELECT COUNT(*) FROM maintenance_windows
+			WHERE room_id = $1
+			AND start_date < $3 AND end_date > $2)
 	`
 	var count int
-	err := r.db.QueryRowContext(ctx, query, roomID, checkIn, checkOut).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, roomID, checkIn, checkOut, bookingID).Scan(&count)
 	if err != nil {
-		return false, err
+		return false, wrapError(err)
 	}
 	return count == 0, nil
 }
 
 type notificationRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func (r *notificationRepository) GetAll(ctx context.Context) ([]notification.NotificationType, error) {
 	query := `SELECT id, name, message FROM notification_types ORDER BY name`
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer rows.Close()
 
@@ -499,7 +772,7 @@ func (r *notificationRepository) GetAll(ctx context.Context) ([]notification.Not
 		var nt notification.NotificationType
 		err := rows.Scan(&nt.ID, &nt.Name, &nt.Message)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(err)
 		}
 		types = append(types, nt)
 	}
@@ -514,7 +787,7 @@ func (r *notificationRepository) GetByID(ctx context.Context, id int64) (*notifi
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, err
+		return nil, wrapError(err)
 	}
 	return &nt, nil
 }
@@ -527,46 +800,45 @@ func (r *notificationRepository) GetByName(ctx context.Context, name string) (*n
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, err
+		return nil, wrapError(err)
 	}
 	return &nt, nil
 }
 
 func (r *notificationRepository) Create(ctx context.Context, nt *notification.NotificationType) error {
 	query := `INSERT INTO notification_types (name, message) VALUES ($1, $2) RETURNING id`
-	return r.db.QueryRowContext(ctx, query, nt.Name, nt.Message).Scan(&nt.ID)
+	return wrapError(r.db.QueryRowContext(ctx, query, nt.Name, nt.Message).Scan(&nt.ID))
 }
 
 func (r *notificationRepository) Update(ctx context.Context, nt *notification.NotificationType) error {
 	query := `UPDATE notification_types SET name = $1, message = $2 WHERE id = $3`
 	_, err := r.db.ExecContext(ctx, query, nt.Name, nt.Message, nt.ID)
-	return err
+	return wrapError(err)
 }
 
 func (r *notificationRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM notification_types WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return execOne(ctx, r.db, query, id)
 }
 
 type specialDateRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
 func (r *specialDateRepository) GetAll(ctx context.Context) ([]booking.SpecialDate, error) {
-	query := `SELECT id, date, name, coefficient, created_at FROM special_dates ORDER BY date`
+	query := `SELECT ` + specialDateColumns + ` FROM special_dates ORDER BY date`
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer rows.Close()
 
 	var dates []booking.SpecialDate
 	for rows.Next() {
 		var sd booking.SpecialDate
-		err := rows.Scan(&sd.ID, &sd.Date, &sd.Name, &sd.Coefficient, &sd.CreatedAt)
+		err := rows.Scan(specialDateFields(&sd)...)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(err)
 		}
 		dates = append(dates, sd)
 	}
@@ -574,45 +846,45 @@ func (r *specialDateRepository) GetAll(ctx context.Context) ([]booking.SpecialDa
 }
 
 func (r *specialDateRepository) GetByID(ctx context.Context, id int64) (*booking.SpecialDate, error) {
-	query := `SELECT id, date, name, coefficient, created_at FROM special_dates WHERE id = $1`
+	query := `SELECT ` + specialDateColumns + ` FROM special_dates WHERE id = $1`
 	var sd booking.SpecialDate
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&sd.ID, &sd.Date, &sd.Name, &sd.Coefficient, &sd.CreatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(specialDateFields(&sd)...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, err
+		return nil, wrapError(err)
 	}
 	return &sd, nil
 }
 
-func (r *specialDateRepository) GetByDate(ctx context.Context, date time.Time) (*booking.SpecialDate, error) {
-	query := `SELECT id, date, name, coefficient, created_at FROM special_dates WHERE date = $1`
+func (r *specialDateRepository) GetByDate(ctx context.Context, date booking.Date) (*booking.SpecialDate, error) {
+	query := `SELECT ` + specialDateColumns + ` FROM special_dates WHERE date = $1`
 	var sd booking.SpecialDate
-	err := r.db.QueryRowContext(ctx, query, date).Scan(&sd.ID, &sd.Date, &sd.Name, &sd.Coefficient, &sd.CreatedAt)
+	err := r.db.QueryRowContext(ctx, query, date).Scan(specialDateFields(&sd)...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
-		return nil, err
+		return nil, wrapError(err)
 	}
 	return &sd, nil
 }
 
-func (r *specialDateRepository) GetByDateRange(ctx context.Context, start, end time.Time) ([]booking.SpecialDate, error) {
-	query := `SELECT id, date, name, coefficient, created_at FROM special_dates WHERE date >= $1 AND date <= $2 ORDER BY date`
+func (r *specialDateRepository) GetByDateRange(ctx context.Context, start, end booking.Date) ([]booking.SpecialDate, error) {
+	query := `SELECT ` + specialDateColumns + ` FROM special_dates WHERE date >= $1 AND date <= $2 ORDER BY date`
 	rows, err := r.db.QueryContext(ctx, query, start, end)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	defer rows.Close()
 
 	var dates []booking.SpecialDate
 	for rows.Next() {
 		var sd booking.SpecialDate
-		err := rows.Scan(&sd.ID, &sd.Date, &sd.Name, &sd.Coefficient, &sd.CreatedAt)
+		err := rows.Scan(specialDateFields(&sd)...)
 		if err != nil {
-			return nil, err
+			return nil, wrapError(err)
 		}
 		dates = append(dates, sd)
 	}
@@ -620,18 +892,21 @@ func (r *specialDateRepository) GetByDateRange(ctx context.Context, start, end t
 }
 
 func (r *specialDateRepository) Create(ctx context.Context, sd *booking.SpecialDate) error {
-	query := `INSERT INTO special_dates (date, name, coefficient) VALUES ($1, $2, $3) RETURNING id, created_at`
-	return r.db.QueryRowContext(ctx, query, sd.Date, sd.Name, sd.Coefficient).Scan(&sd.ID, &sd.CreatedAt)
+	query := `INSERT INTO special_dates (date, name, coefficient) VALUES ($1, $2, $3) RETURNING id, version, created_at`
+	return wrapError(r.db.QueryRowContext(ctx, query, sd.Date, sd.Name, sd.Coefficient).Scan(&sd.ID, &sd.Version, &sd.CreatedAt))
 }
 
+// Update writes the special date if it is still at sd.Version.
 func (r *specialDateRepository) Update(ctx context.Context, sd *booking.SpecialDate) error {
-	query := `UPDATE special_dates SET date = $1, name = $2, coefficient = $3 WHERE id = $4`
-	_, err := r.db.ExecContext(ctx, query, sd.Date, sd.Name, sd.Coefficient, sd.ID)
-	return err
+	query := `
+		UPDATE special_dates SET date = $1, name = $2, coefficient = $3, version = version + 1
+		WHERE id = $4 AND version = $5
+		RETURNING version
+	`
+	return updateVersioned(ctx, r.db, "special_dates", sd.ID, query, []any{sd.Date, sd.Name, sd.Coefficient, sd.ID, sd.Version}, &sd.Version)
 }
 
 func (r *specialDateRepository) Delete(ctx context.Context, id int64) error {
 	query := `DELETE FROM special_dates WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	return execOne(ctx, r.db, query, id)
 }
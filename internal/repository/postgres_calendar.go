@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/calendar"
+)
+
+const feedColumns = `f.id, f.room_id, f.name, f.url, f.last_synced_at, f.last_error,
+	(SELECT COUNT(*) FROM maintenance_windows mw WHERE mw.ical_feed_id = f.id), f.created_at`
+
+type calendarRepository struct {
+	db dbtx
+}
+
+func feedFields(f *calendar.Feed) []any {
+	return []any{&f.ID, &f.RoomID, &f.Name, &f.URL, &f.LastSyncedAt, &f.LastError, &f.Blocks, &f.CreatedAt}
+}
+
+// GetExportToken returns the secret of the room's export feed, or "" when
+// none was issued yet.
+func (r *calendarRepository) GetExportToken(ctx context.Context, roomID int64) (string, error) {
+	var token string
+	err := r.db.QueryRowContext(ctx, `SELECT token FROM ical_export_tokens WHERE room_id = $1`, roomID).Scan(&token)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return token, wrapError(err)
+}
+
+// SetExportToken issues or replaces the secret of the room's export feed.
+func (r *calendarRepository) SetExportToken(ctx context.Context, roomID int64, token string) error {
+	query := `
+		INSERT INTO ical_export_tokens (room_id, token) VALUES ($1, $2)
+		ON CONFLICT (room_id) DO UPDATE SET token = EXCLUDED.token, created_at = CURRENT_TIMESTAMP
+	`
+	_, err := r.db.ExecContext(ctx, query, roomID, token)
+	return wrapError(err)
+}
+
+// GetRoomIDByToken returns 0 when no room has the token.
+func (r *calendarRepository) GetRoomIDByToken(ctx context.Context, token string) (int64, error) {
+	var roomID int64
+	err := r.db.QueryRowContext(ctx, `SELECT room_id FROM ical_export_tokens WHERE token = $1`, token).Scan(&roomID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return roomID, wrapError(err)
+}
+
+func (r *calendarRepository) queryFeeds(ctx context.Context, query string, args ...any) ([]calendar.Feed, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var feeds []calendar.Feed
+	for rows.Next() {
+		var f calendar.Feed
+		if err := rows.Scan(feedFields(&f)...); err != nil {
+			return nil, wrapError(err)
+		}
+		feeds = append(feeds, f)
+	}
+	return feeds, wrapError(rows.Err())
+}
+
+// GetFeeds returns the feeds of a room, or of all rooms for roomID 0.
+func (r *calendarRepository) GetFeeds(ctx context.Context, roomID int64) ([]calendar.Feed, error) {
+	query := `SELECT ` + feedColumns + ` FROM ical_feeds f WHERE $1 = 0 OR f.room_id = $1 ORDER BY f.room_id, f.id`
+	return r.queryFeeds(ctx, query, roomID)
+}
+
+func (r *calendarRepository) GetFeedByID(ctx context.Context, id int64) (*calendar.Feed, error) {
+	query := `SELECT ` + feedColumns + ` FROM ical_feeds f WHERE f.id = $1`
+	var f calendar.Feed
+	err := r.db.QueryRowContext(ctx, query, id).Scan(feedFields(&f)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, wrapError(err)
+	}
+	return &f, nil
+}
+
+func (r *calendarRepository) CreateFeed(ctx context.Context, f *calendar.Feed) error {
+	query := `INSERT INTO ical_feeds (room_id, name, url) VALUES ($1, $2, $3) RETURNING id, created_at`
+	return wrapError(r.db.QueryRowContext(ctx, query, f.RoomID, f.Name, f.URL).Scan(&f.ID, &f.CreatedAt))
+}
+
+// DeleteFeed removes the feed together with the dates it blocked.
+func (r *calendarRepository) DeleteFeed(ctx context.Context, id int64) error {
+	return execOne(ctx, r.db, `DELETE FROM ical_feeds WHERE id = $1`, id)
+}
+
+// ReplaceFeedBlocks swaps the windows imported from the feed for the given
+// ones and marks the feed as synced, in one transaction.
+func (r *calendarRepository) ReplaceFeedBlocks(ctx context.Context, feedID int64, windows []booking.MaintenanceWindow, syncedAt time.Time) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE ical_feeds SET last_synced_at = $2, last_error = '' WHERE id = $1`, feedID, syncedAt)
+	if err != nil {
+		return wrapError(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return wrapError(err)
+	} else if n == 0 {
+		return ErrNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM maintenance_windows WHERE ical_feed_id = $1`, feedID); err != nil {
+		return wrapError(err)
+	}
+	for i := range windows {
+		w := &windows[i]
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO maintenance_windows (room_id, start_date, end_date, kind, reason, ical_feed_id)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id, created_at`,
+			w.RoomID, w.StartDate, w.EndDate, w.Kind, w.Reason, feedID).Scan(&w.ID, &w.CreatedAt)
+		if err != nil {
+			return wrapError(err)
+		}
+	}
+	return wrapError(tx.Commit())
+}
+
+func (r *calendarRepository) SetFeedError(ctx context.Context, feedID int64, message string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE ical_feeds SET last_error = $2 WHERE id = $1`, feedID, message)
+	return wrapError(err)
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/channel"
+)
+
+const (
+	mappingColumns     = `id, channel, room_type, room_code, rate_code, created_at`
+	reservationColumns = `id, channel, ref, status, room_code, check_in, check_out, guest_info, guests, total, booking_id, state, conflict, fingerprint, created_at, updated_at`
+)
+
+type channelRepository struct {
+	db dbtx
+}
+
+func mappingFields(m *channel.Mapping) []any {
+	return []any{&m.ID, &m.Channel, &m.RoomType, &m.RoomCode, &m.RateCode, &m.CreatedAt}
+}
+
+func scanReservation(row rowScanner) (*channel.ImportedReservation, error) {
+	var r channel.ImportedReservation
+	var guestInfo []byte
+	err := row.Scan(&r.ID, &r.Channel, &r.Ref, &r.Status, &r.RoomCode, &r.CheckIn, &r.CheckOut, &guestInfo, &r.Guests, &r.Total,
+		&r.BookingID, &r.State, &r.Conflict, &r.Fingerprint, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(guestInfo, &r.Guest); err != nil {
+		return nil, wrapError(err)
+	}
+	return &r, nil
+}
+
+// GetMappings returns the mappings of a channel, or of all channels for "".
+func (r *channelRepository) GetMappings(ctx context.Context, channelName string) ([]channel.Mapping, error) {
+	query := `SELECT ` + mappingColumns + ` FROM channel_mappings WHERE $1 = '' OR channel = $1 ORDER BY channel, room_type`
+	rows, err := r.db.QueryContext(ctx, query, channelName)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var mappings []channel.Mapping
+	for rows.Next() {
+		var m channel.Mapping
+		if err := rows.Scan(mappingFields(&m)...); err != nil {
+			return nil, wrapError(err)
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, wrapError(rows.Err())
+}
+
+func (r *channelRepository) GetMappingByID(ctx context.Context, id int64) (*channel.Mapping, error) {
+	query := `SELECT ` + mappingColumns + ` FROM channel_mappings WHERE id = $1`
+	var m channel.Mapping
+	err := r.db.QueryRowContext(ctx, query, id).Scan(mappingFields(&m)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, wrapError(err)
+	}
+	return &m, nil
+}
+
+func (r *channelRepository) CreateMapping(ctx context.Context, m *channel.Mapping) error {
+	query := `
+		INSERT INTO channel_mappings (channel, room_type, room_code, rate_code)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	return wrapError(r.db.QueryRowContext(ctx, query, m.Channel, m.RoomType, m.RoomCode, m.RateCode).Scan(&m.ID, &m.CreatedAt))
+}
+
+// DeleteMapping removes the mapping and forgets the inventory pushed for it,
+// so that mapping the room again pushes it in full.
+func (r *channelRepository) DeleteMapping(ctx context.Context, id int64) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	var m channel.Mapping
+	err = tx.QueryRowContext(ctx, `DELETE FROM channel_mappings WHERE id = $1 RETURNING channel, room_code, rate_code`, id).
+		Scan(&m.Channel, &m.RoomCode, &m.RateCode)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrNotFound
+		}
+		return wrapError(err)
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM channel_inventory WHERE channel = $1 AND room_code = $2 AND rate_code = $3`,
+		m.Channel, m.RoomCode, m.RateCode)
+	if err != nil {
+		return wrapError(err)
+	}
+	return wrapError(tx.Commit())
+}
+
+// GetInventory returns what was last pushed to the channel from the given
+// date on.
+func (r *channelRepository) GetInventory(ctx context.Context, channelName string, from booking.Date) ([]channel.Inventory, error) {
+	query := `
+		SELECT room_code, rate_code, date, available, price, stop_sell
+		FROM channel_inventory
+		WHERE channel = $1 AND date >= $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, channelName, from)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var inventory []channel.Inventory
+	for rows.Next() {
+		var i channel.Inventory
+		if err := rows.Scan(&i.RoomCode, &i.RateCode, &i.Date, &i.Available, &i.Price, &i.StopSell); err != nil {
+			return nil, wrapError(err)
+		}
+		inventory = append(inventory, i)
+	}
+	return inventory, wrapError(rows.Err())
+}
+
+// SaveInventory records inventory the channel has accepted and drops the
+// past nights, in one transaction.
+func (r *channelRepository) SaveInventory(ctx context.Context, channelName string, inventory []channel.Inventory, today booking.Date) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM channel_inventory WHERE channel = $1 AND date < $2`, channelName, today); err != nil {
+		return wrapError(err)
+	}
+	for _, i := range inventory {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO channel_inventory (channel, room_code, rate_code, date, available, price, stop_sell)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (channel, room_code, rate_code, date) DO UPDATE SET
+				available = EXCLUDED.available,
+				price = EXCLUDED.price,
+				stop_sell = EXCLUDED.stop_sell,
+				pushed_at = CURRENT_TIMESTAMP`,
+			channelName, i.RoomCode, i.RateCode, i.Date, i.Available, i.Price, i.StopSell)
+		if err != nil {
+			return wrapError(err)
+		}
+	}
+	return wrapError(tx.Commit())
+}
+
+// GetReservation returns nil when the reservation was never pulled.
+func (r *channelRepository) GetReservation(ctx context.Context, channelName, ref string) (*channel.ImportedReservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM channel_reservations WHERE channel = $1 AND ref = $2`
+	res, err := scanReservation(r.db.QueryRowContext(ctx, query, channelName, ref))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return res, wrapError(err)
+}
+
+func (r *channelRepository) GetReservationByID(ctx context.Context, id int64) (*channel.ImportedReservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM channel_reservations WHERE id = $1`
+	res, err := scanReservation(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return res, wrapError(err)
+}
+
+// GetReservations returns the reservations in the given state, or in any
+// state for "", newest first.
+func (r *channelRepository) GetReservations(ctx context.Context, state channel.ImportState) ([]channel.ImportedReservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM channel_reservations WHERE $1 = '' OR state = $1 ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, query, state)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var reservations []channel.ImportedReservation
+	for rows.Next() {
+		res, err := scanReservation(rows)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		reservations = append(reservations, *res)
+	}
+	return reservations, wrapError(rows.Err())
+}
+
+// SaveReservation inserts the reservation or updates the one pulled before
+// under the same channel and ref.
+func (r *channelRepository) SaveReservation(ctx context.Context, res *channel.ImportedReservation) error {
+	guestInfo, err := json.Marshal(res.Guest)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO channel_reservations
+			(channel, ref, status, room_code, check_in, check_out, guest_info, guests, total, booking_id, state, conflict, fingerprint)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (channel, ref) DO UPDATE SET
+			status = EXCLUDED.status,
+			room_code = EXCLUDED.room_code,
+			check_in = EXCLUDED.check_in,
+			check_out = EXCLUDED.check_out,
+			guest_info = EXCLUDED.guest_info,
+			guests = EXCLUDED.guests,
+			total = EXCLUDED.total,
+			booking_id = EXCLUDED.booking_id,
+			state = EXCLUDED.state,
+			conflict = EXCLUDED.conflict,
+			fingerprint = EXCLUDED.fingerprint,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING id, created_at, updated_at
+	`
+	err = r.db.QueryRowContext(ctx, query, res.Channel, res.Ref, res.Status, res.RoomCode, res.CheckIn, res.CheckOut, guestInfo,
+		res.Guests, res.Total, res.BookingID, res.State, res.Conflict, res.Fingerprint).Scan(&res.ID, &res.CreatedAt, &res.UpdatedAt)
+	return wrapError(err)
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/folio"
+)
+
+const (
+	chargeColumns  = `id, booking_id, type, description, service_date, amount, created_at`
+	invoiceColumns = `id, number, year, sequence, booking_id, guest_name, guest_email, total, vat, city_tax, currency, folio, issued_at`
+)
+
+type folioRepository struct {
+	db dbtx
+}
+
+func (r *folioRepository) GetCharges(ctx context.Context, bookingID int64) ([]folio.Charge, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+chargeColumns+` FROM folio_charges WHERE booking_id = $1 ORDER BY service_date NULLS LAST, id`, bookingID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var charges []folio.Charge
+	for rows.Next() {
+		var c folio.Charge
+		if err := rows.Scan(&c.ID, &c.BookingID, &c.Type, &c.Description, &c.ServiceDate, &c.Amount, &c.CreatedAt); err != nil {
+			return nil, wrapError(err)
+		}
+		charges = append(charges, c)
+	}
+	return charges, wrapError(rows.Err())
+}
+
+// ReplaceCharges swaps the booking's charges for the given ones, e.g. after
+// the stay was moved and priced again.
+func (r *folioRepository) ReplaceCharges(ctx context.Context, bookingID int64, charges []folio.Charge) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM folio_charges WHERE booking_id = $1`, bookingID); err != nil {
+		return wrapError(err)
+	}
+	for i := range charges {
+		c := &charges[i]
+		c.BookingID = bookingID
+		query := `
+			INSERT INTO folio_charges (booking_id, type, description, service_date, amount)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, created_at
+		`
+		if err := tx.QueryRowContext(ctx, query, c.BookingID, c.Type, c.Description, c.ServiceDate, c.Amount).Scan(&c.ID, &c.CreatedAt); err != nil {
+			return wrapError(err)
+		}
+	}
+
+	return wrapError(tx.Commit())
+}
+
+func (r *folioRepository) GetInvoiceByBookingID(ctx context.Context, bookingID int64) (*folio.Invoice, error) {
+	var inv folio.Invoice
+	var raw []byte
+	err := r.db.QueryRowContext(ctx, `SELECT `+invoiceColumns+` FROM invoices WHERE booking_id = $1`, bookingID).
+		Scan(&inv.ID, &inv.Number, &inv.Year, &inv.Sequence, &inv.BookingID, &inv.GuestName, &inv.GuestEmail, &inv.Total, &inv.VAT, &inv.CityTax, &inv.Currency, &raw, &inv.IssuedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if err := json.Unmarshal(raw, &inv.Folio); err != nil {
+		return nil, wrapError(err)
+	}
+	return &inv, nil
+}
+
+// CreateInvoice numbers the invoice with the next number of inv.Year and
+// stores it. The counter row stays locked until the invoice is committed, so
+// numbers are handed out in order and a failed insert gives its number back.
+func (r *folioRepository) CreateInvoice(ctx context.Context, inv *folio.Invoice) error {
+	folioJSON, err := json.Marshal(inv.Folio)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO invoice_counters (year, last_number) VALUES ($1, 1)
+		ON CONFLICT (year) DO UPDATE SET last_number = invoice_counters.last_number + 1
+		RETURNING last_number
+	`
+	if err := tx.QueryRowContext(ctx, query, inv.Year).Scan(&inv.Sequence); err != nil {
+		return wrapError(err)
+	}
+	inv.Number = fmt.Sprintf("%d-%06d", inv.Year, inv.Sequence)
+
+	query = `
+		INSERT INTO invoices (number, year, sequence, booking_id, guest_name, guest_email, total, vat, city_tax, currency, folio)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, issued_at
+	`
+	if err := tx.QueryRowContext(ctx, query, inv.Number, inv.Year, inv.Sequence, inv.BookingID, inv.GuestName, inv.GuestEmail, inv.Total, inv.VAT, inv.CityTax, inv.Currency, folioJSON).
+		Scan(&inv.ID, &inv.IssuedAt); err != nil {
+		return wrapError(err)
+	}
+
+	return wrapError(tx.Commit())
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/guest"
+)
+
+const guestColumns = `id, name, email, phone, normalized_email, normalized_phone, preferences, notes, created_at, updated_at`
+
+type guestRepository struct {
+	db dbtx
+}
+
+func scanGuest(row rowScanner, g *guest.Guest) error {
+	var preferences []byte
+	if err := row.Scan(&g.ID, &g.Name, &g.Email, &g.Phone, &g.NormalizedEmail, &g.NormalizedPhone, &preferences, &g.Notes, &g.CreatedAt, &g.UpdatedAt); err != nil {
+		return wrapError(err)
+	}
+	return json.Unmarshal(preferences, &g.Preferences)
+}
+
+func queryGuests(ctx context.Context, db dbtx, query string, args ...any) ([]guest.Guest, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var guests []guest.Guest
+	for rows.Next() {
+		var g guest.Guest
+		if err := scanGuest(rows, &g); err != nil {
+			return nil, wrapError(err)
+		}
+		guests = append(guests, g)
+	}
+	return guests, wrapError(rows.Err())
+}
+
+var guestList = listQuery[guest.Guest]{
+	selectQuery: `SELECT ` + guestColumns + ` FROM guests`,
+	countQuery:  `SELECT COUNT(*) FROM guests`,
+	idExpr:      "id",
+	id:          func(g guest.Guest) int64 { return g.ID },
+	keys: map[string]sortKey[guest.Guest]{
+		"id":         {"id", "bigint", func(g guest.Guest) string { return strconv.FormatInt(g.ID, 10) }},
+		"name":       {"name", "text", func(g guest.Guest) string { return g.Name }},
+		"created_at": {"created_at", "timestamp", func(g guest.Guest) string { return formatTimestamp(g.CreatedAt) }},
+	},
+	load: queryGuests,
+}
+
+func (r *guestRepository) List(ctx context.Context, filter guest.Filter) (*booking.Page[guest.Guest], error) {
+	var cond conditions
+	if filter.Query != "" {
+		cond.add("(name ILIKE ? OR email ILIKE ? OR normalized_phone LIKE ?)",
+			containsPattern(filter.Query), containsPattern(filter.Query), containsPattern(guest.NormalizePhone(filter.Query)))
+	}
+	return paginate(ctx, r.db, guestList, cond, filter.PageRequest)
+}
+
+func (r *guestRepository) getOne(ctx context.Context, where string, arg any) (*guest.Guest, error) {
+	query := `SELECT ` + guestColumns + ` FROM guests WHERE ` + where + ` ORDER BY id LIMIT 1`
+	var g guest.Guest
+	err := scanGuest(r.db.QueryRowContext(ctx, query, arg), &g)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, wrapError(err)
+	}
+	return &g, nil
+}
+
+func (r *guestRepository) GetByID(ctx context.Context, id int64) (*guest.Guest, error) {
+	return r.getOne(ctx, "id = $1", id)
+}
+
+func (r *guestRepository) GetByNormalizedEmail(ctx context.Context, email string) (*guest.Guest, error) {
+	return r.getByContact(ctx, "normalized_email", "email", email)
+}
+
+func (r *guestRepository) GetByNormalizedPhone(ctx context.Context, phone string) (*guest.Guest, error) {
+	return r.getByContact(ctx, "normalized_phone", "phone", phone)
+}
+
+// getByContact finds the guest whose own email or phone is value and falls
+// back to the contacts kept from guests merged into another one.
+func (r *guestRepository) getByContact(ctx context.Context, column, kind, value string) (*guest.Guest, error) {
+	g, err := r.getOne(ctx, column+" = $1", value)
+	if g != nil || err != nil {
+		return g, err
+	}
+	return r.getOne(ctx, "id = (SELECT guest_id FROM guest_contacts WHERE kind = '"+kind+"' AND value = $1)", value)
+}
+
+// GetDuplicates returns pairs of guests that share a phone number or a name
+// and are therefore likely the same person.
+func (r *guestRepository) GetDuplicates(ctx context.Context, limit int) ([]guest.DuplicateCandidate, error) {
+	query := `
+		SELECT ` + qualify("a", guestColumns) + `, ` + qualify("d", guestColumns) + `,
+			CASE WHEN a.normalized_phone <> '' AND a.normalized_phone = d.normalized_phone THEN 'phone' ELSE 'name' END
+		FROM guests a
+		JOIN guests d ON d.id > a.id
+		AND ((a.normalized_phone <> '' AND a.normalized_phone = d.normalized_phone) OR LOWER(a.name) = LOWER(d.name))
+		ORDER BY a.id, d.id
+		LIMIT $1
+	`
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var candidates []guest.DuplicateCandidate
+	for rows.Next() {
+		var c guest.DuplicateCandidate
+		var prefs, dupPrefs []byte
+		a, d := &c.Guest, &c.Duplicate
+		err := rows.Scan(
+			&a.ID, &a.Name, &a.Email, &a.Phone, &a.NormalizedEmail, &a.NormalizedPhone, &prefs, &a.Notes, &a.CreatedAt, &a.UpdatedAt,
+			&d.ID, &d.Name, &d.Email, &d.Phone, &d.NormalizedEmail, &d.NormalizedPhone, &dupPrefs, &d.Notes, &d.CreatedAt, &d.UpdatedAt,
+			&c.Reason,
+		)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		if err := json.Unmarshal(prefs, &a.Preferences); err != nil {
+			return nil, wrapError(err)
+		}
+		if err := json.Unmarshal(dupPrefs, &d.Preferences); err != nil {
+			return nil, wrapError(err)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, wrapError(rows.Err())
+}
+
+func (r *guestRepository) Create(ctx context.Context, g *guest.Guest) error {
+	preferences, err := marshalPreferences(g.Preferences)
+	if err != nil {
+		return wrapError(err)
+	}
+	query := `
+		INSERT INTO guests (name, email, phone, normalized_email, normalized_phone, preferences, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+	return wrapError(r.db.QueryRowContext(ctx, query, g.Name, g.Email, g.Phone, g.NormalizedEmail, g.NormalizedPhone, preferences, g.Notes).
+		Scan(&g.ID, &g.CreatedAt, &g.UpdatedAt))
+}
+
+func (r *guestRepository) Update(ctx context.Context, g *guest.Guest) error {
+	preferences, err := marshalPreferences(g.Preferences)
+	if err != nil {
+		return wrapError(err)
+	}
+	query := `
+		UPDATE guests
+		SET name = $1, email = $2, phone = $3, normalized_email = $4, normalized_phone = $5, preferences = $6, notes = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+	`
+	_, err = r.db.ExecContext(ctx, query, g.Name, g.Email, g.Phone, g.NormalizedEmail, g.NormalizedPhone, preferences, g.Notes, g.ID)
+	return wrapError(err)
+}
+
+// Merge moves every booking and loyalty entry of sourceID to targetID, folds
+// the source's contact details, preferences and notes into the target, keeps
+// the source's email and phone as contacts of the target and deletes the
+// source, all in one transaction.
+func (r *guestRepository) Merge(ctx context.Context, sourceID, targetID int64) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	var source guest.Guest
+	if err := scanGuest(tx.QueryRowContext(ctx, `SELECT `+guestColumns+` FROM guests WHERE id = $1 FOR UPDATE`, sourceID), &source); err != nil {
+		return wrapError(err)
+	}
+	sourcePreferences, err := marshalPreferences(source.Preferences)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE bookings SET guest_id = $1, version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE guest_id = $2`, targetID, sourceID); err != nil {
+		return wrapError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE loyalty_ledger SET guest_id = $1 WHERE guest_id = $2`, targetID, sourceID); err != nil {
+		return wrapError(err)
+	}
+	// The source's email and phone keep resolving to the target, so the next
+	// booking made with them does not bring the duplicate back.
+	if _, err := tx.ExecContext(ctx, `UPDATE guest_contacts SET guest_id = $1 WHERE guest_id = $2`, targetID, sourceID); err != nil {
+		return wrapError(err)
+	}
+	query := `
+		INSERT INTO guest_contacts (guest_id, kind, value)
+		SELECT $1, c.kind, c.value FROM (VALUES ('email', $2::text), ('phone', $3::text)) AS c(kind, value)
+		WHERE c.value <> ''
+		ON CONFLICT (kind, value) DO UPDATE SET guest_id = EXCLUDED.guest_id
+	`
+	if _, err := tx.ExecContext(ctx, query, targetID, source.NormalizedEmail, source.NormalizedPhone); err != nil {
+		return wrapError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM guests WHERE id = $1`, sourceID); err != nil {
+		return wrapError(err)
+	}
+
+	query = `
+		UPDATE guests
+		SET email = CASE WHEN email = '' THEN $1 ELSE email END,
+			normalized_email = CASE WHEN normalized_email = '' THEN $2 ELSE normalized_email END,
+			phone = CASE WHEN phone = '' THEN $3 ELSE phone END,
+			normalized_phone = CASE WHEN normalized_phone = '' THEN $4 ELSE normalized_phone END,
+			preferences = $5::jsonb || preferences,
+			notes = CONCAT_WS(E'\n', NULLIF(notes, ''), NULLIF($6, '')),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+	`
+	if _, err := tx.ExecContext(ctx, query, source.Email, source.NormalizedEmail, source.Phone, source.NormalizedPhone, sourcePreferences, source.Notes, targetID); err != nil {
+		return wrapError(err)
+	}
+
+	return wrapError(tx.Commit())
+}
+
+func marshalPreferences(preferences map[string]string) ([]byte, error) {
+	if preferences == nil {
+		preferences = map[string]string{}
+	}
+	return json.Marshal(preferences)
+}
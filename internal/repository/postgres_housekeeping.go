@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/housekeeping"
+)
+
+const taskColumns = `id, room_id, booking_id, type, status, assignee, notes, due_date, completed_at, created_at, updated_at`
+
+func taskFields(t *housekeeping.Task) []any {
+	return []any{&t.ID, &t.RoomID, &t.BookingID, &t.Type, &t.Status, &t.Assignee, &t.Notes, &t.DueDate, &t.CompletedAt, &t.CreatedAt, &t.UpdatedAt}
+}
+
+type housekeepingRepository struct {
+	db dbtx
+}
+
+func (r *housekeepingRepository) GetTasks(ctx context.Context, filter housekeeping.TaskFilter) ([]housekeeping.TaskWithRoom, error) {
+	var conditions []string
+	var args []any
+
+	if filter.Assignee != "" {
+		args = append(args, filter.Assignee)
+		conditions = append(conditions, fmt.Sprintf("t.assignee = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("t.status = $%d", len(args)))
+	}
+	if !filter.DueDate.IsZero() {
+		args = append(args, filter.DueDate)
+		conditions = append(conditions, fmt.Sprintf("t.due_date = $%d", len(args)))
+	}
+
+	query := `
+		SELECT ` + qualify("t", taskColumns) + `, ` + qualify("r", roomColumns) + `
+		FROM housekeeping_tasks t
+		JOIN rooms r ON t.room_id = r.id
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY t.due_date, t.status, r.room_number"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var tasks []housekeeping.TaskWithRoom
+	for rows.Next() {
+		var t housekeeping.TaskWithRoom
+		dest := append(taskFields(&t.Task), roomFields(&t.Room)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, wrapError(err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, wrapError(rows.Err())
+}
+
+func (r *housekeepingRepository) GetTaskByID(ctx context.Context, id int64) (*housekeeping.Task, error) {
+	query := `SELECT ` + taskColumns + ` FROM housekeeping_tasks WHERE id = $1`
+	var t housekeeping.Task
+	err := r.db.QueryRowContext(ctx, query, id).Scan(taskFields(&t)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, wrapError(err)
+	}
+	return &t, nil
+}
+
+func (r *housekeepingRepository) CreateTask(ctx context.Context, t *housekeeping.Task) error {
+	query := `
+		INSERT INTO housekeeping_tasks (room_id, booking_id, type, status, assignee, notes, due_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+	return wrapError(r.db.QueryRowContext(ctx, query, t.RoomID, t.BookingID, t.Type, t.Status, t.Assignee, t.Notes, t.DueDate).
+		Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt))
+}
+
+func (r *housekeepingRepository) UpdateTask(ctx context.Context, t *housekeeping.Task) error {
+	query := `
+		UPDATE housekeeping_tasks
+		SET status = $1, assignee = $2, notes = $3, completed_at = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+	`
+	_, err := r.db.ExecContext(ctx, query, t.Status, t.Assignee, t.Notes, t.CompletedAt, t.ID)
+	return wrapError(err)
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/idempotency"
+)
+
+type idempotencyRepository struct {
+	db dbtx
+}
+
+// Reserve stores rec as a pending record kept for ttl unless the key is
+// already held by a record that has not expired yet; that record is returned
+// instead. An expired record is replaced.
+func (r *idempotencyRepository) Reserve(ctx context.Context, rec *idempotency.Record, ttl time.Duration) (*idempotency.Record, error) {
+	query := `
+		INSERT INTO idempotency_keys (key, fingerprint, expires_at)
+		VALUES ($1, $2, CURRENT_TIMESTAMP + $3 * INTERVAL '1 second')
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, content_type = NULL, body = NULL,
+			created_at = CURRENT_TIMESTAMP, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= CURRENT_TIMESTAMP
+		RETURNING created_at, expires_at
+	`
+	err := r.db.QueryRowContext(ctx, query, rec.Key, rec.Fingerprint, ttl.Seconds()).Scan(&rec.CreatedAt, &rec.ExpiresAt)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, wrapError(err)
+	}
+
+	var existing idempotency.Record
+	var status sql.NullInt64
+	var contentType sql.NullString
+	err = r.db.QueryRowContext(ctx,
+		`SELECT key, fingerprint, status_code, content_type, body, created_at, expires_at FROM idempotency_keys WHERE key = $1`, rec.Key).
+		Scan(&existing.Key, &existing.Fingerprint, &status, &contentType, &existing.Body, &existing.CreatedAt, &existing.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The holder released the key between the two statements.
+		return nil, ErrSerialization
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	existing.StatusCode = int(status.Int64)
+	existing.ContentType = contentType.String
+	return &existing, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	query := `UPDATE idempotency_keys SET status_code = $1, content_type = $2, body = $3 WHERE key = $4`
+	return execOne(ctx, r.db, query, statusCode, contentType, body, key)
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND status_code IS NULL`, key)
+	return wrapError(err)
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= CURRENT_TIMESTAMP`)
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	n, err := res.RowsAffected()
+	return n, wrapError(err)
+}
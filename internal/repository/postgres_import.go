@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+)
+
+// Import writes the rooms in one transaction: rooms without an ID are
+// created, the others updated if still at their version. Any failure
+// rolls the whole import back.
+func (r *roomRepository) Import(ctx context.Context, rooms []booking.Room) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	for i := range rooms {
+		room := &rooms[i]
+		if room.ID == 0 {
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO rooms (room_number, room_type, base_price, capacity, status, description)
+				VALUES ($1, $2, $3, $4, $5, $6)
+				RETURNING `+roomColumns,
+				room.RoomNumber, room.RoomType, room.BasePrice, room.Capacity, room.Status, room.Description).
+				Scan(roomFields(room)...)
+		} else {
+			err = tx.QueryRowContext(ctx, `
+				UPDATE rooms
+				SET room_number = $1, room_type = $2, base_price = $3, capacity = $4, status = $5, description = $6,
+					version = version + 1, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $7 AND version = $8 AND archived_at IS NULL
+				RETURNING `+roomColumns,
+				room.RoomNumber, room.RoomType, room.BasePrice, room.Capacity, room.Status, room.Description, room.ID, room.Version).
+				Scan(roomFields(room)...)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrStaleVersion
+		}
+		if err != nil {
+			return wrapError(err)
+		}
+	}
+	return wrapError(tx.Commit())
+}
+
+// Import writes the special dates in one transaction, like the rooms import.
+func (r *specialDateRepository) Import(ctx context.Context, dates []booking.SpecialDate) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	for i := range dates {
+		sd := &dates[i]
+		if sd.ID == 0 {
+			err = tx.QueryRowContext(ctx,
+				`INSERT INTO special_dates (date, name, coefficient) VALUES ($1, $2, $3) RETURNING `+specialDateColumns,
+				sd.Date, sd.Name, sd.Coefficient).
+				Scan(specialDateFields(sd)...)
+		} else {
+			err = tx.QueryRowContext(ctx, `
+				UPDATE special_dates SET date = $1, name = $2, coefficient = $3, version = version + 1
+				WHERE id = $4 AND version = $5
+				RETURNING `+specialDateColumns,
+				sd.Date, sd.Name, sd.Coefficient, sd.ID, sd.Version).
+				Scan(specialDateFields(sd)...)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrStaleVersion
+		}
+		if err != nil {
+			return wrapError(err)
+		}
+	}
+	return wrapError(tx.Commit())
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/loyalty"
+)
+
+var ErrInsufficientPoints = apperr.Conflict("not_enough_points", "not enough loyalty points")
+
+const loyaltyColumns = `id, guest_id, booking_id, type, points, remaining, expires_at, reason, actor, created_at`
+
+type loyaltyRepository struct {
+	db dbtx
+}
+
+func (r *loyaltyRepository) GetEntries(ctx context.Context, guestID int64) ([]loyalty.LedgerEntry, error) {
+	query := `SELECT ` + loyaltyColumns + ` FROM loyalty_ledger WHERE guest_id = $1 ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, query, guestID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var entries []loyalty.LedgerEntry
+	for rows.Next() {
+		var e loyalty.LedgerEntry
+		if err := rows.Scan(&e.ID, &e.GuestID, &e.BookingID, &e.Type, &e.Points, &e.Remaining, &e.ExpiresAt, &e.Reason, &e.Actor, &e.CreatedAt); err != nil {
+			return nil, wrapError(err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, wrapError(rows.Err())
+}
+
+func (r *loyaltyRepository) GetBalance(ctx context.Context, guestID int64) (int, error) {
+	query := `SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE guest_id = $1`
+	var balance int
+	err := r.db.QueryRowContext(ctx, query, guestID).Scan(&balance)
+	return balance, wrapError(err)
+}
+
+// GetEarnedSince sums the points earned from stays since the given date; it
+// is what qualifies a guest for a tier.
+func (r *loyaltyRepository) GetEarnedSince(ctx context.Context, guestID int64, since booking.Date) (int, error) {
+	query := `SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE guest_id = $1 AND type = 'earn' AND created_at >= $2`
+	var earned int
+	err := r.db.QueryRowContext(ctx, query, guestID, since).Scan(&earned)
+	return earned, wrapError(err)
+}
+
+// AddEntry records a ledger entry. An earn entry for a booking that already
+// earned points is silently skipped and leaves e.ID at zero.
+func (r *loyaltyRepository) AddEntry(ctx context.Context, e *loyalty.LedgerEntry) error {
+	query := `
+		INSERT INTO loyalty_ledger (guest_id, booking_id, type, points, remaining, expires_at, reason, actor)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT DO NOTHING
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, e.GuestID, e.BookingID, e.Type, e.Points, e.Remaining, e.ExpiresAt, e.Reason, e.Actor).
+		Scan(&e.ID, &e.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return wrapError(err)
+}
+
+// Redeem spends -e.Points points from the guest's unexpired positive entries,
+// soonest-expiring first, and records e, all in one transaction.
+func (r *loyaltyRepository) Redeem(ctx context.Context, e *loyalty.LedgerEntry) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, remaining FROM loyalty_ledger
+		WHERE guest_id = $1 AND remaining > 0
+		ORDER BY expires_at NULLS LAST, id
+		FOR UPDATE
+	`, e.GuestID)
+	if err != nil {
+		return wrapError(err)
+	}
+
+	type lot struct {
+		id        int64
+		remaining int
+	}
+	var lots []lot
+	for rows.Next() {
+		var l lot
+		if err := rows.Scan(&l.id, &l.remaining); err != nil {
+			rows.Close()
+			return wrapError(err)
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return wrapError(err)
+	}
+
+	needed := -e.Points
+	for _, l := range lots {
+		if needed == 0 {
+			break
+		}
+		spent := min(l.remaining, needed)
+		if _, err := tx.ExecContext(ctx, `UPDATE loyalty_ledger SET remaining = remaining - $1 WHERE id = $2`, spent, l.id); err != nil {
+			return wrapError(err)
+		}
+		needed -= spent
+	}
+	if needed > 0 {
+		return ErrInsufficientPoints
+	}
+
+	query := `
+		INSERT INTO loyalty_ledger (guest_id, booking_id, type, points, reason, actor)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	if err := tx.QueryRowContext(ctx, query, e.GuestID, e.BookingID, e.Type, e.Points, e.Reason, e.Actor).Scan(&e.ID, &e.CreatedAt); err != nil {
+		return wrapError(err)
+	}
+
+	return wrapError(tx.Commit())
+}
+
+// ExpirePoints writes off every unspent lot that expired on or before asOf
+// and returns the number of expired lots.
+func (r *loyaltyRepository) ExpirePoints(ctx context.Context, asOf booking.Date) (int64, error) {
+	query := `
+		WITH expired AS (
+			UPDATE loyalty_ledger l
+			SET remaining = 0
+			FROM (
+				SELECT id, remaining FROM loyalty_ledger
+				WHERE expires_at <= $1 AND remaining > 0
+				FOR UPDATE
+			) old
+			WHERE l.id = old.id
+			RETURNING l.id, l.guest_id, old.remaining
+		)
+		INSERT INTO loyalty_ledger (guest_id, type, points, reason)
+		SELECT guest_id, 'expire', -remaining, 'Istechenie ballov #' || id FROM expired
+	`
+	result, err := r.db.ExecContext(ctx, query, asOf)
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return result.RowsAffected()
+}
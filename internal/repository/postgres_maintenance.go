@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+)
+
+const maintenanceColumns = `id, room_id, start_date, end_date, kind, reason, ical_feed_id, created_at`
+
+func maintenanceFields(w *booking.MaintenanceWindow) []any {
+	return []any{&w.ID, &w.RoomID, &w.StartDate, &w.EndDate, &w.Kind, &w.Reason, &w.FeedID, &w.CreatedAt}
+}
+
+type maintenanceRepository struct {
+	db dbtx
+}
+
+func (r *maintenanceRepository) query(ctx context.Context, query string, args ...any) ([]booking.MaintenanceWindow, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var windows []booking.MaintenanceWindow
+	for rows.Next() {
+		var w booking.MaintenanceWindow
+		if err := rows.Scan(maintenanceFields(&w)...); err != nil {
+			return nil, wrapError(err)
+		}
+		windows = append(windows, w)
+	}
+	return windows, wrapError(rows.Err())
+}
+
+func (r *maintenanceRepository) GetAll(ctx context.Context) ([]booking.MaintenanceWindow, error) {
+	query := `SELECT ` + maintenanceColumns + ` FROM maintenance_windows ORDER BY start_date, room_id`
+	return r.query(ctx, query)
+}
+
+func (r *maintenanceRepository) GetByRoomID(ctx context.Context, roomID int64) ([]booking.MaintenanceWindow, error) {
+	query := `SELECT ` + maintenanceColumns + ` FROM maintenance_windows WHERE room_id = $1 ORDER BY start_date`
+	return r.query(ctx, query, roomID)
+}
+
+func (r *maintenanceRepository) GetByID(ctx context.Context, id int64) (*booking.MaintenanceWindow, error) {
+	query := `SELECT ` + maintenanceColumns + ` FROM maintenance_windows WHERE id = $1`
+	var w booking.MaintenanceWindow
+	err := r.db.QueryRowContext(ctx, query, id).Scan(maintenanceFields(&w)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, wrapError(err)
+	}
+	return &w, nil
+}
+
+func (r *maintenanceRepository) GetOverlapping(ctx context.Context, from, to booking.Date) ([]booking.MaintenanceWindow, error) {
+	query := `SELECT ` + maintenanceColumns + ` FROM maintenance_windows WHERE start_date < $2 AND end_date > $1 ORDER BY start_date, room_id`
+	return r.query(ctx, query, from, to)
+}
+
+func (r *maintenanceRepository) Create(ctx context.Context, w *booking.MaintenanceWindow) error {
+	query := `
+		INSERT INTO maintenance_windows (room_id, start_date, end_date, kind, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return wrapError(r.db.QueryRowContext(ctx, query, w.RoomID, w.StartDate, w.EndDate, w.Kind, w.Reason).Scan(&w.ID, &w.CreatedAt))
+}
+
+func (r *maintenanceRepository) Delete(ctx context.Context, id int64) error {
+	query := `DELETE FROM maintenance_windows WHERE id = $1`
+	return execOne(ctx, r.db, query, id)
+}
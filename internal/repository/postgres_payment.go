@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/payment"
+)
+
+const (
+	paymentColumns     = `id, booking_id, provider, provider_ref, status, amount, captured_amount, refunded_amount, currency, created_at, updated_at`
+	transactionColumns = `id, payment_id, type, amount, succeeded, provider_ref, error, created_at`
+)
+
+type paymentRepository struct {
+	db dbtx
+}
+
+func scanPayment(row rowScanner, p *payment.Payment) error {
+	return row.Scan(&p.ID, &p.BookingID, &p.Provider, &p.ProviderRef, &p.Status, &p.Amount, &p.CapturedAmount, &p.RefundedAmount, &p.Currency, &p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *paymentRepository) getOne(ctx context.Context, query string, args ...any) (*payment.Payment, error) {
+	var p payment.Payment
+	err := scanPayment(r.db.QueryRowContext(ctx, query, args...), &p)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return &p, nil
+}
+
+func (r *paymentRepository) GetByID(ctx context.Context, id int64) (*payment.Payment, error) {
+	return r.getOne(ctx, `SELECT `+paymentColumns+` FROM payments WHERE id = $1`, id)
+}
+
+// Lock reads a payment and locks its row until the transaction ends, so it
+// is meant for a repository of Repository.Transaction.
+func (r *paymentRepository) Lock(ctx context.Context, id int64) (*payment.Payment, error) {
+	return r.getOne(ctx, `SELECT `+paymentColumns+` FROM payments WHERE id = $1 FOR UPDATE`, id)
+}
+
+func (r *paymentRepository) GetByProviderRef(ctx context.Context, provider, ref string) (*payment.Payment, error) {
+	return r.getOne(ctx, `SELECT `+paymentColumns+` FROM payments WHERE provider = $1 AND provider_ref = $2`, provider, ref)
+}
+
+func (r *paymentRepository) GetByBookingID(ctx context.Context, bookingID int64) ([]payment.Payment, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+paymentColumns+` FROM payments WHERE booking_id = $1 ORDER BY created_at, id`, bookingID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var payments []payment.Payment
+	for rows.Next() {
+		var p payment.Payment
+		if err := scanPayment(rows, &p); err != nil {
+			return nil, wrapError(err)
+		}
+		payments = append(payments, p)
+	}
+	return payments, wrapError(rows.Err())
+}
+
+func (r *paymentRepository) GetTransactionsByBookingID(ctx context.Context, bookingID int64) ([]payment.Transaction, error) {
+	query := `
+		SELECT ` + qualify("t", transactionColumns) + `
+		FROM payment_transactions t
+		JOIN payments p ON p.id = t.payment_id
+		WHERE p.booking_id = $1
+		ORDER BY t.created_at, t.id
+	`
+	rows, err := r.db.QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var transactions []payment.Transaction
+	for rows.Next() {
+		var t payment.Transaction
+		if err := rows.Scan(&t.ID, &t.PaymentID, &t.Type, &t.Amount, &t.Succeeded, &t.ProviderRef, &t.Error, &t.CreatedAt); err != nil {
+			return nil, wrapError(err)
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, wrapError(rows.Err())
+}
+
+func (r *paymentRepository) Create(ctx context.Context, p *payment.Payment) error {
+	query := `
+		INSERT INTO payments (booking_id, provider, provider_ref, status, amount, captured_amount, refunded_amount, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+	return wrapError(r.db.QueryRowContext(ctx, query, p.BookingID, p.Provider, p.ProviderRef, p.Status, p.Amount, p.CapturedAmount, p.RefundedAmount, p.Currency).
+		Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt))
+}
+
+func (r *paymentRepository) Update(ctx context.Context, p *payment.Payment) error {
+	query := `
+		UPDATE payments
+		SET status = $1, captured_amount = $2, refunded_amount = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING updated_at
+	`
+	return wrapError(r.db.QueryRowContext(ctx, query, p.Status, p.CapturedAmount, p.RefundedAmount, p.ID).Scan(&p.UpdatedAt))
+}
+
+func (r *paymentRepository) AddTransaction(ctx context.Context, t *payment.Transaction) error {
+	query := `
+		INSERT INTO payment_transactions (payment_id, type, amount, succeeded, provider_ref, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	return wrapError(r.db.QueryRowContext(ctx, query, t.PaymentID, t.Type, t.Amount, t.Succeeded, t.ProviderRef, t.Error).Scan(&t.ID, &t.CreatedAt))
+}
+
+// RecordWebhookEvent remembers a gateway event and reports false when it was
+// already recorded, i.e. the gateway delivered it again.
+func (r *paymentRepository) RecordWebhookEvent(ctx context.Context, provider string, event payment.WebhookEvent) (bool, error) {
+	query := `
+		INSERT INTO payment_webhook_events (provider, event_id, type)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	res, err := r.db.ExecContext(ctx, query, provider, event.ID, event.Type)
+	if err != nil {
+		return false, wrapError(err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, wrapError(err)
+}
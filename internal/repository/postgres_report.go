@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/report"
+)
+
+// Bookings that sell their nights; cancelled ones are counted separately.
+const soldStatuses = `('confirmed', 'checked_in', 'checked_out')`
+
+// reportCTE lays out the stay dates [$1, $2) as days, the sellable room
+// nights as inventory, and the booked nights with the revenue of each. A
+// night's revenue is its room_night folio charge; bookings made before
+// charges were posted have their price spread evenly over the stay.
+const reportCTE = `
+	WITH days AS (
+		SELECT d::date AS day FROM generate_series($1::date, $2::date - 1, INTERVAL '1 day') d
+	),
+	inventory AS (
+		SELECT days.day, r.id AS room_id, r.room_type
+		FROM days
+		CROSS JOIN rooms r
+		WHERE (r.created_at IS NULL OR r.created_at::date <= days.day)
+		AND (r.archived_at IS NULL OR r.archived_at::date > days.day)
+		AND NOT EXISTS (
+			SELECT 1 FROM maintenance_windows m
+			WHERE m.room_id = r.id AND m.kind = 'out_of_order'
+			AND m.start_date <= days.day AND m.end_date > days.day
+		)
+	),
+	nights AS (
+		SELECT n.day::date AS day, b.room_id, b.status,
+			COALESCE(c.amount, b.price / GREATEST(b.end_date - b.start_date, 1)) AS revenue
+		FROM bookings b
+		CROSS JOIN LATERAL generate_series(GREATEST(b.start_date, $1::date), LEAST(b.end_date, $2::date) - 1, INTERVAL '1 day') AS n(day)
+		LEFT JOIN folio_charges c ON c.booking_id = b.id AND c.type = 'room_night' AND c.service_date = n.day::date
+		WHERE b.start_date < $2 AND b.end_date > $1
+	)
+`
+
+type reportRepository struct {
+	db dbtx
+}
+
+func (r *reportRepository) GetDailyMetrics(ctx context.Context, from, to booking.Date) ([]report.DailyMetrics, error) {
+	query := reportCTE + `,
+	available AS (
+		SELECT day, COUNT(*) AS room_nights FROM inventory GROUP BY day
+	),
+	booked AS (
+		SELECT day,
+			COUNT(*) FILTER (WHERE status IN ` + soldStatuses + `) AS sold,
+			COUNT(*) FILTER (WHERE status = 'cancelled') AS cancelled,
+			COALESCE(SUM(revenue) FILTER (WHERE status IN ` + soldStatuses + `), 0) AS revenue
+		FROM nights
+		GROUP BY day
+	)
+	SELECT days.day, COALESCE(a.room_nights, 0), COALESCE(b.sold, 0), COALESCE(b.cancelled, 0), COALESCE(b.revenue, 0)
+	FROM days
+	LEFT JOIN available a ON a.day = days.day
+	LEFT JOIN booked b ON b.day = days.day
+	ORDER BY days.day
+	`
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var result []report.DailyMetrics
+	for rows.Next() {
+		var m report.DailyMetrics
+		if err := rows.Scan(&m.Date, &m.RoomNightsAvailable, &m.RoomNightsSold, &m.CancelledNights, &m.RoomRevenue); err != nil {
+			return nil, wrapError(err)
+		}
+		result = append(result, m)
+	}
+	return result, wrapError(rows.Err())
+}
+
+func (r *reportRepository) GetRoomTypeMetrics(ctx context.Context, from, to booking.Date) ([]report.RoomTypeMetrics, error) {
+	query := reportCTE + `,
+	available AS (
+		SELECT room_type, COUNT(*) AS room_nights FROM inventory GROUP BY room_type
+	),
+	booked AS (
+		SELECT r.room_type,
+			COUNT(*) FILTER (WHERE n.status IN ` + soldStatuses + `) AS sold,
+			COUNT(*) FILTER (WHERE n.status = 'cancelled') AS cancelled,
+			COALESCE(SUM(n.revenue) FILTER (WHERE n.status IN ` + soldStatuses + `), 0) AS revenue
+		FROM nights n
+		JOIN rooms r ON r.id = n.room_id
+		GROUP BY r.room_type
+	)
+	SELECT COALESCE(a.room_type, b.room_type), COALESCE(a.room_nights, 0), COALESCE(b.sold, 0), COALESCE(b.cancelled, 0), COALESCE(b.revenue, 0)
+	FROM available a
+	FULL JOIN booked b ON b.room_type = a.room_type
+	ORDER BY 1
+	`
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var result []report.RoomTypeMetrics
+	for rows.Next() {
+		var m report.RoomTypeMetrics
+		if err := rows.Scan(&m.RoomType, &m.RoomNightsAvailable, &m.RoomNightsSold, &m.CancelledNights, &m.RoomRevenue); err != nil {
+			return nil, wrapError(err)
+		}
+		result = append(result, m)
+	}
+	return result, wrapError(rows.Err())
+}
+
+// GetPickup counts, per stay date, the room nights on the books now and those
+// booked within the last windowDays, next to the same figures for the stay
+// date a year earlier as of a year ago. Without a status history, bookings
+// cancelled since then are not counted last year either.
+func (r *reportRepository) GetPickup(ctx context.Context, from, to booking.Date, windowDays int) ([]report.Pickup, error) {
+	query := `
+		WITH days AS (
+			SELECT d::date AS day, (d - INTERVAL '1 year')::date AS last_year
+			FROM generate_series($1::date, $2::date - 1, INTERVAL '1 day') d
+		)
+		SELECT days.day,
+			COUNT(b.id) FILTER (WHERE b.start_date <= days.day AND b.end_date > days.day),
+			COUNT(b.id) FILTER (WHERE b.start_date <= days.day AND b.end_date > days.day
+				AND b.created_at > CURRENT_TIMESTAMP - $3 * INTERVAL '1 day'),
+			COUNT(b.id) FILTER (WHERE b.start_date <= days.last_year AND b.end_date > days.last_year
+				AND b.created_at <= CURRENT_TIMESTAMP - INTERVAL '1 year'),
+			COUNT(b.id) FILTER (WHERE b.start_date <= days.last_year AND b.end_date > days.last_year
+				AND b.created_at <= CURRENT_TIMESTAMP - INTERVAL '1 year'
+				AND b.created_at > CURRENT_TIMESTAMP - INTERVAL '1 year' - $3 * INTERVAL '1 day')
+		FROM days
+		LEFT JOIN bookings b ON b.status IN ` + soldStatuses + `
+			AND ((b.start_date <= days.day AND b.end_date > days.day)
+				OR (b.start_date <= days.last_year AND b.end_date > days.last_year))
+		GROUP BY days.day
+		ORDER BY days.day
+	`
+	rows, err := r.db.QueryContext(ctx, query, from, to, windowDays)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var result []report.Pickup
+	for rows.Next() {
+		var p report.Pickup
+		if err := rows.Scan(&p.Date, &p.OnBooks, &p.Pickup, &p.LastYearOnBooks, &p.LastYearPickup); err != nil {
+			return nil, wrapError(err)
+		}
+		result = append(result, p)
+	}
+	return result, wrapError(rows.Err())
+}
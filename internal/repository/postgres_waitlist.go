@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/waitlist"
+)
+
+const waitlistColumns = `id, room_type, check_in, check_out, guests, guest_info, status, booking_id, hold_token, hold_expires_at, created_at, updated_at`
+
+type waitlistRepository struct {
+	db dbtx
+}
+
+func scanWaitlistEntry(row rowScanner) (*waitlist.Entry, error) {
+	var e waitlist.Entry
+	var guestInfo []byte
+	err := row.Scan(&e.ID, &e.RoomType, &e.CheckIn, &e.CheckOut, &e.Guests, &guestInfo, &e.Status, &e.BookingID,
+		&e.HoldToken, &e.HoldExpiresAt, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(guestInfo, &e.GuestInfo); err != nil {
+		return nil, wrapError(err)
+	}
+	return &e, nil
+}
+
+func (r *waitlistRepository) Create(ctx context.Context, e *waitlist.Entry) error {
+	guestInfo, err := json.Marshal(e.GuestInfo)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO waitlist_entries (room_type, check_in, check_out, guests, guest_info, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+	err = r.db.QueryRowContext(ctx, query, e.RoomType, e.CheckIn, e.CheckOut, e.Guests, guestInfo, e.Status).
+		Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+	return wrapError(err)
+}
+
+// GetByID returns nil when there is no such entry.
+func (r *waitlistRepository) GetByID(ctx context.Context, id int64) (*waitlist.Entry, error) {
+	e, err := scanWaitlistEntry(r.db.QueryRowContext(ctx, `SELECT `+waitlistColumns+` FROM waitlist_entries WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return e, wrapError(err)
+}
+
+// GetByHoldToken returns nil when no entry was offered with the token.
+func (r *waitlistRepository) GetByHoldToken(ctx context.Context, token string) (*waitlist.Entry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist_entries WHERE hold_token = $1 AND hold_token <> ''`
+	e, err := scanWaitlistEntry(r.db.QueryRowContext(ctx, query, token))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return e, wrapError(err)
+}
+
+// List returns the entries in the given status, or in any status for "",
+// in the order they joined.
+func (r *waitlistRepository) List(ctx context.Context, status waitlist.Status) ([]waitlist.Entry, error) {
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist_entries WHERE $1 = '' OR status = $1 ORDER BY created_at, id`
+	rows, err := r.db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var entries []waitlist.Entry
+	for rows.Next() {
+		e, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		entries = append(entries, *e)
+	}
+	return entries, wrapError(rows.Err())
+}
+
+// Update saves the status and hold of an entry.
+func (r *waitlistRepository) Update(ctx context.Context, e *waitlist.Entry) error {
+	query := `
+		UPDATE waitlist_entries
+		SET status = $2, booking_id = $3, hold_token = $4, hold_expires_at = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, e.ID, e.Status, e.BookingID, e.HoldToken, e.HoldExpiresAt).Scan(&e.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return wrapError(err)
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/notification"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/webhook"
+)
+
+const (
+	subscriptionColumns = `id, url, events, secret, active, created_at`
+	deliveryColumns     = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, response_status, last_error, delivered_at, created_at`
+)
+
+type webhookRepository struct {
+	db dbtx
+}
+
+func scanSubscription(row rowScanner) (*webhook.Subscription, error) {
+	var s webhook.Subscription
+	var events []string
+	if err := row.Scan(&s.ID, &s.URL, pq.Array(&events), &s.Secret, &s.Active, &s.CreatedAt); err != nil {
+		return nil, err
+	}
+	s.Events = make([]notification.EventType, len(events))
+	for i, e := range events {
+		s.Events[i] = notification.EventType(e)
+	}
+	return &s, nil
+}
+
+func scanDelivery(row rowScanner) (*webhook.Delivery, error) {
+	var d webhook.Delivery
+	err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts, &d.NextAttemptAt,
+		&d.ResponseStatus, &d.LastError, &d.DeliveredAt, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *webhookRepository) GetSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions ORDER BY id`)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var subs []webhook.Subscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		subs = append(subs, *s)
+	}
+	return subs, wrapError(rows.Err())
+}
+
+func (r *webhookRepository) GetSubscriptionByID(ctx context.Context, id int64) (*webhook.Subscription, error) {
+	s, err := scanSubscription(r.db.QueryRowContext(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return s, wrapError(err)
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, s *webhook.Subscription) error {
+	events := make([]string, len(s.Events))
+	for i, e := range s.Events {
+		events[i] = string(e)
+	}
+	query := `
+		INSERT INTO webhook_subscriptions (url, events, secret, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	return wrapError(r.db.QueryRowContext(ctx, query, s.URL, pq.Array(events), s.Secret, s.Active).Scan(&s.ID, &s.CreatedAt))
+}
+
+func (r *webhookRepository) SetSubscriptionActive(ctx context.Context, id int64, active bool) error {
+	return execOne(ctx, r.db, `UPDATE webhook_subscriptions SET active = $2 WHERE id = $1`, id, active)
+}
+
+// DeleteSubscription also drops the subscription's delivery log.
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	return execOne(ctx, r.db, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+}
+
+func (r *webhookRepository) CreateDelivery(ctx context.Context, d *webhook.Delivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	return wrapError(r.db.QueryRowContext(ctx, query, d.SubscriptionID, d.EventID, d.EventType, []byte(d.Payload), d.Status, d.Attempts, d.NextAttemptAt).
+		Scan(&d.ID, &d.CreatedAt))
+}
+
+// GetDueDeliveries returns pending deliveries of active subscriptions whose
+// next attempt is due, oldest first. Paused subscriptions keep theirs
+// pending without taking up the batch.
+func (r *webhookRepository) GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]webhook.Delivery, error) {
+	query := `
+		SELECT ` + qualify("d", deliveryColumns) + `
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON s.id = d.subscription_id AND s.active
+		WHERE d.status = 'pending' AND d.next_attempt_at <= $1
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT $2
+	`
+	return r.queryDeliveries(ctx, query, now, limit)
+}
+
+// GetDeliveries returns the delivery log of a subscription, newest first.
+func (r *webhookRepository) GetDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]webhook.Delivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+	return r.queryDeliveries(ctx, query, subscriptionID, limit)
+}
+
+func (r *webhookRepository) queryDeliveries(ctx context.Context, query string, args ...any) ([]webhook.Delivery, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var deliveries []webhook.Delivery
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		deliveries = append(deliveries, *d)
+	}
+	return deliveries, wrapError(rows.Err())
+}
+
+// UpdateDelivery records the outcome of an attempt.
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, d *webhook.Delivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, response_status = $5, last_error = $6, delivered_at = $7
+		WHERE id = $1
+	`
+	return execOne(ctx, r.db, query, d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.ResponseStatus, d.LastError, d.DeliveredAt)
+}
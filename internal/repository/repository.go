@@ -5,7 +5,18 @@ import (
 	"time"
 
 	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/calendar"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/channel"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/folio"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/guest"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/housekeeping"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/idempotency"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/loyalty"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/models/notification"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/payment"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/report"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/waitlist"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/webhook"
 )
 
 type Repository interface {
@@ -13,36 +24,69 @@ type Repository interface {
 	Booking() BookingRepository
 	Notification() NotificationRepository
 	SpecialDate() SpecialDateRepository
+	Housekeeping() HousekeepingRepository
+	Maintenance() MaintenanceRepository
+	Guest() GuestRepository
+	Loyalty() LoyaltyRepository
+	Idempotency() IdempotencyRepository
+	Payment() PaymentRepository
+	Folio() FolioRepository
+	Report() ReportRepository
+	Calendar() CalendarRepository
+	Channel() ChannelRepository
+	Webhook() WebhookRepository
+	Waitlist() WaitlistRepository
+	Transaction(ctx context.Context, fn func(repo Repository) error) error
 	Close() error
 }
 
 type RoomRepository interface {
 	GetAll(ctx context.Context) ([]booking.Room, error)
+	LockAll(ctx context.Context) ([]booking.Room, error)
+	List(ctx context.Context, filter booking.RoomFilter) (*booking.Page[booking.Room], error)
 	GetByID(ctx context.Context, id int64) (*booking.Room, error)
 	GetByNumber(ctx context.Context, roomNumber string) (*booking.Room, error)
-	GetAvailable(ctx context.Context, checkIn, checkOut time.Time) ([]booking.Room, error)
-	GetAvailableByType(ctx context.Context, roomType booking.RoomType, checkIn, checkOut time.Time) ([]booking.Room, error)
-	GetAvailableByCapacity(ctx context.Context, capacity int, checkIn, checkOut time.Time) ([]booking.Room, error)
+	GetAvailable(ctx context.Context, checkIn, checkOut booking.Date) ([]booking.Room, error)
+	GetAvailableByType(ctx context.Context, roomType booking.RoomType, checkIn, checkOut booking.Date) ([]booking.Room, error)
+	GetAvailableByCapacity(ctx context.Context, capacity int, checkIn, checkOut booking.Date) ([]booking.Room, error)
+	GetNightlyAvailability(ctx context.Context, roomType booking.RoomType, from, to booking.Date) ([]booking.RoomNight, error)
 	Create(ctx context.Context, room *booking.Room) error
 	Update(ctx context.Context, room *booking.Room) error
-	Delete(ctx context.Context, id int64) error
+	Archive(ctx context.Context, id int64, relocations []booking.Relocation) error
+	Restore(ctx context.Context, id int64) error
+	Import(ctx context.Context, rooms []booking.Room) error
 	UpdateStatus(ctx context.Context, id int64, status booking.RoomStatus) error
+	UpdateHousekeepingStatus(ctx context.Context, id int64, status booking.HousekeepingStatus) error
 }
 
 type BookingRepository interface {
 	GetAll(ctx context.Context) ([]booking.Booking, error)
 	GetAllWithRooms(ctx context.Context) ([]booking.BookingWithRoom, error)
+	List(ctx context.Context, filter booking.BookingFilter) (*booking.Page[booking.BookingWithRoom], error)
 	GetByID(ctx context.Context, id int64) (*booking.Booking, error)
+	Lock(ctx context.Context, id int64) (*booking.Booking, error)
 	GetByRoomID(ctx context.Context, roomID int64) ([]booking.Booking, error)
 	GetByStatus(ctx context.Context, status booking.BookingStatus) ([]booking.Booking, error)
 	GetByStatusWithRooms(ctx context.Context, status booking.BookingStatus) ([]booking.BookingWithRoom, error)
 	GetByEmail(ctx context.Context, email string) ([]booking.Booking, error)
-	GetActiveForRoom(ctx context.Context, roomID int64, checkIn, checkOut time.Time) ([]booking.Booking, error)
+	GetByGuestWithRooms(ctx context.Context, guestID int64) ([]booking.BookingWithRoom, error)
+	Search(ctx context.Context, q booking.BookingSearchQuery) ([]booking.BookingSearchResult, error)
+	GetActiveForRoom(ctx context.Context, roomID int64, checkIn, checkOut booking.Date) ([]booking.Booking, error)
+	GetInRangeWithRooms(ctx context.Context, from, to booking.Date) ([]booking.BookingWithRoom, error)
+	GetArrivalsWithRooms(ctx context.Context, date booking.Date) ([]booking.BookingWithRoom, error)
+	GetDeparturesWithRooms(ctx context.Context, date booking.Date) ([]booking.BookingWithRoom, error)
+	GetArrivalForRoom(ctx context.Context, roomID int64, date booking.Date, exceptID int64) (*booking.Booking, error)
+	GetDepartureForRoom(ctx context.Context, roomID int64, date booking.Date, exceptID int64) (*booking.Booking, error)
 	Create(ctx context.Context, b *booking.Booking) error
 	Update(ctx context.Context, b *booking.Booking) error
-	UpdateStatus(ctx context.Context, id int64, status booking.BookingStatus) error
+	UpdateStatus(ctx context.Context, b *booking.Booking, status booking.BookingStatus) error
+	GetNoShowCandidates(ctx context.Context, before booking.Date) ([]booking.Booking, error)
+	CheckIn(ctx context.Context, b *booking.Booking, details *booking.CheckInDetails, at time.Time) error
+	CheckOut(ctx context.Context, b *booking.Booking, at time.Time) error
+	MarkNoShow(ctx context.Context, id int64, penalty float64) error
 	Delete(ctx context.Context, id int64) error
-	IsRoomAvailable(ctx context.Context, roomID int64, checkIn, checkOut time.Time) (bool, error)
+	IsRoomAvailable(ctx context.Context, roomID int64, checkIn, checkOut booking.Date) (bool, error)
+	IsRoomAvailableExcept(ctx context.Context, roomID int64, checkIn, checkOut booking.Date, bookingID int64) (bool, error)
 }
 
 type NotificationRepository interface {
@@ -57,9 +101,124 @@ type NotificationRepository interface {
 type SpecialDateRepository interface {
 	GetAll(ctx context.Context) ([]booking.SpecialDate, error)
 	GetByID(ctx context.Context, id int64) (*booking.SpecialDate, error)
-	GetByDate(ctx context.Context, date time.Time) (*booking.SpecialDate, error)
-	GetByDateRange(ctx context.Context, start, end time.Time) ([]booking.SpecialDate, error)
+	GetByDate(ctx context.Context, date booking.Date) (*booking.SpecialDate, error)
+	GetByDateRange(ctx context.Context, start, end booking.Date) ([]booking.SpecialDate, error)
 	Create(ctx context.Context, sd *booking.SpecialDate) error
 	Update(ctx context.Context, sd *booking.SpecialDate) error
 	Delete(ctx context.Context, id int64) error
+	Import(ctx context.Context, dates []booking.SpecialDate) error
+}
+
+type HousekeepingRepository interface {
+	GetTasks(ctx context.Context, filter housekeeping.TaskFilter) ([]housekeeping.TaskWithRoom, error)
+	GetTaskByID(ctx context.Context, id int64) (*housekeeping.Task, error)
+	CreateTask(ctx context.Context, task *housekeeping.Task) error
+	UpdateTask(ctx context.Context, task *housekeeping.Task) error
+}
+
+type MaintenanceRepository interface {
+	GetAll(ctx context.Context) ([]booking.MaintenanceWindow, error)
+	GetByRoomID(ctx context.Context, roomID int64) ([]booking.MaintenanceWindow, error)
+	GetByID(ctx context.Context, id int64) (*booking.MaintenanceWindow, error)
+	GetOverlapping(ctx context.Context, from, to booking.Date) ([]booking.MaintenanceWindow, error)
+	Create(ctx context.Context, w *booking.MaintenanceWindow) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type GuestRepository interface {
+	List(ctx context.Context, filter guest.Filter) (*booking.Page[guest.Guest], error)
+	GetByID(ctx context.Context, id int64) (*guest.Guest, error)
+	GetByNormalizedEmail(ctx context.Context, email string) (*guest.Guest, error)
+	GetByNormalizedPhone(ctx context.Context, phone string) (*guest.Guest, error)
+	GetDuplicates(ctx context.Context, limit int) ([]guest.DuplicateCandidate, error)
+	Create(ctx context.Context, g *guest.Guest) error
+	Update(ctx context.Context, g *guest.Guest) error
+	Merge(ctx context.Context, sourceID, targetID int64) error
+}
+
+type LoyaltyRepository interface {
+	GetEntries(ctx context.Context, guestID int64) ([]loyalty.LedgerEntry, error)
+	GetBalance(ctx context.Context, guestID int64) (int, error)
+	GetEarnedSince(ctx context.Context, guestID int64, since booking.Date) (int, error)
+	AddEntry(ctx context.Context, e *loyalty.LedgerEntry) error
+	Redeem(ctx context.Context, e *loyalty.LedgerEntry) error
+	ExpirePoints(ctx context.Context, asOf booking.Date) (int64, error)
+}
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, rec *idempotency.Record, ttl time.Duration) (*idempotency.Record, error)
+	Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type PaymentRepository interface {
+	GetByID(ctx context.Context, id int64) (*payment.Payment, error)
+	Lock(ctx context.Context, id int64) (*payment.Payment, error)
+	GetByProviderRef(ctx context.Context, provider, ref string) (*payment.Payment, error)
+	GetByBookingID(ctx context.Context, bookingID int64) ([]payment.Payment, error)
+	GetTransactionsByBookingID(ctx context.Context, bookingID int64) ([]payment.Transaction, error)
+	Create(ctx context.Context, p *payment.Payment) error
+	Update(ctx context.Context, p *payment.Payment) error
+	AddTransaction(ctx context.Context, t *payment.Transaction) error
+	RecordWebhookEvent(ctx context.Context, provider string, event payment.WebhookEvent) (bool, error)
+}
+
+type FolioRepository interface {
+	GetCharges(ctx context.Context, bookingID int64) ([]folio.Charge, error)
+	ReplaceCharges(ctx context.Context, bookingID int64, charges []folio.Charge) error
+	GetInvoiceByBookingID(ctx context.Context, bookingID int64) (*folio.Invoice, error)
+	CreateInvoice(ctx context.Context, inv *folio.Invoice) error
+}
+
+// ReportRepository aggregates bookings per stay date over [from, to).
+type ReportRepository interface {
+	GetDailyMetrics(ctx context.Context, from, to booking.Date) ([]report.DailyMetrics, error)
+	GetRoomTypeMetrics(ctx context.Context, from, to booking.Date) ([]report.RoomTypeMetrics, error)
+	GetPickup(ctx context.Context, from, to booking.Date, windowDays int) ([]report.Pickup, error)
+}
+
+type CalendarRepository interface {
+	GetExportToken(ctx context.Context, roomID int64) (string, error)
+	SetExportToken(ctx context.Context, roomID int64, token string) error
+	GetRoomIDByToken(ctx context.Context, token string) (int64, error)
+	GetFeeds(ctx context.Context, roomID int64) ([]calendar.Feed, error)
+	GetFeedByID(ctx context.Context, id int64) (*calendar.Feed, error)
+	CreateFeed(ctx context.Context, f *calendar.Feed) error
+	DeleteFeed(ctx context.Context, id int64) error
+	ReplaceFeedBlocks(ctx context.Context, feedID int64, windows []booking.MaintenanceWindow, syncedAt time.Time) error
+	SetFeedError(ctx context.Context, feedID int64, message string) error
+}
+
+type ChannelRepository interface {
+	GetMappings(ctx context.Context, channelName string) ([]channel.Mapping, error)
+	GetMappingByID(ctx context.Context, id int64) (*channel.Mapping, error)
+	CreateMapping(ctx context.Context, m *channel.Mapping) error
+	DeleteMapping(ctx context.Context, id int64) error
+	GetInventory(ctx context.Context, channelName string, from booking.Date) ([]channel.Inventory, error)
+	SaveInventory(ctx context.Context, channelName string, inventory []channel.Inventory, today booking.Date) error
+	GetReservation(ctx context.Context, channelName, ref string) (*channel.ImportedReservation, error)
+	GetReservationByID(ctx context.Context, id int64) (*channel.ImportedReservation, error)
+	GetReservations(ctx context.Context, state channel.ImportState) ([]channel.ImportedReservation, error)
+	SaveReservation(ctx context.Context, res *channel.ImportedReservation) error
+}
+
+type WebhookRepository interface {
+	GetSubscriptions(ctx context.Context) ([]webhook.Subscription, error)
+	GetSubscriptionByID(ctx context.Context, id int64) (*webhook.Subscription, error)
+	CreateSubscription(ctx context.Context, s *webhook.Subscription) error
+	SetSubscriptionActive(ctx context.Context, id int64, active bool) error
+	DeleteSubscription(ctx context.Context, id int64) error
+	CreateDelivery(ctx context.Context, d *webhook.Delivery) error
+	GetDueDeliveries(ctx context.Context, now time.Time, limit int) ([]webhook.Delivery, error)
+	GetDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]webhook.Delivery, error)
+	UpdateDelivery(ctx context.Context, d *webhook.Delivery) error
+}
+
+type WaitlistRepository interface {
+	Create(ctx context.Context, e *waitlist.Entry) error
+	GetByID(ctx context.Context, id int64) (*waitlist.Entry, error)
+	GetByHoldToken(ctx context.Context, token string) (*waitlist.Entry, error)
+	List(ctx context.Context, status waitlist.Status) ([]waitlist.Entry, error)
+	Update(ctx context.Context, e *waitlist.Entry) error
 }
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+)
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+const (
+	roomColumns        = `id, room_number, room_type, base_price, capacity, status, housekeeping_status, description, archived_at, version, created_at, updated_at`
+	specialDateColumns = `id, date, name, coefficient, version, created_at`
+	bookingColumns     = `id, start_date, end_date, room_id, guest_info, price, status, early_check_in, late_check_out, check_in_time, check_out_time, checked_in_at, checked_out_at, check_in_details, penalty, points_redeemed, points_amount, guest_id, guests, version, created_at, updated_at`
+)
+
+var bookingWithRoomColumns = qualify("b", bookingColumns) + ", " + qualify("r", roomColumns)
+
+func qualify(alias, columns string) string {
+	parts := strings.Split(columns, ",")
+	for i, p := range parts {
+		parts[i] = alias + "." + strings.TrimSpace(p)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func roomFields(room *booking.Room) []any {
+	return []any{&room.ID, &room.RoomNumber, &room.RoomType, &room.BasePrice, &room.Capacity, &room.Status, &room.HousekeepingStatus, &room.Description, &room.ArchivedAt, &room.Version, &room.CreatedAt, &room.UpdatedAt}
+}
+
+// bookingJSON holds the raw JSONB columns of a booking row until they are decoded.
+type bookingJSON struct {
+	guestInfo      []byte
+	checkInDetails []byte
+}
+
+func (j *bookingJSON) decode(b *booking.Booking) error {
+	if err := json.Unmarshal(j.guestInfo, &b.GuestInfo); err != nil {
+		return wrapError(err)
+	}
+	if len(j.checkInDetails) > 0 {
+		b.CheckInDetails = &booking.CheckInDetails{}
+		return json.Unmarshal(j.checkInDetails, b.CheckInDetails)
+	}
+	return nil
+}
+
+func bookingFields(b *booking.Booking, raw *bookingJSON) []any {
+	return []any{&b.ID, &b.StartDate, &b.EndDate, &b.RoomID, &raw.guestInfo, &b.Price, &b.Status, &b.EarlyCheckIn, &b.LateCheckOut, &b.CheckInTime, &b.CheckOutTime,
+		&b.CheckedInAt, &b.CheckedOutAt, &raw.checkInDetails, &b.Penalty, &b.PointsRedeemed, &b.PointsAmount, &b.GuestID, &b.Guests, &b.Version, &b.CreatedAt, &b.UpdatedAt}
+}
+
+func specialDateFields(sd *booking.SpecialDate) []any {
+	return []any{&sd.ID, &sd.Date, &sd.Name, &sd.Coefficient, &sd.Version, &sd.CreatedAt}
+}
+
+func scanRoom(row rowScanner, room *booking.Room) error {
+	return row.Scan(roomFields(room)...)
+}
+
+func scanBooking(row rowScanner, b *booking.Booking) error {
+	var raw bookingJSON
+	if err := row.Scan(bookingFields(b, &raw)...); err != nil {
+		return wrapError(err)
+	}
+	return raw.decode(b)
+}
+
+func scanBookingWithRoom(row rowScanner, b *booking.BookingWithRoom) error {
+	var raw bookingJSON
+	dest := append(bookingFields(&b.Booking, &raw), roomFields(&b.Room)...)
+	if err := row.Scan(dest...); err != nil {
+		return wrapError(err)
+	}
+	return raw.decode(&b.Booking)
+}
+
+func queryRooms(ctx context.Context, db dbtx, query string, args ...any) ([]booking.Room, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var rooms []booking.Room
+	for rows.Next() {
+		var room booking.Room
+		if err := scanRoom(rows, &room); err != nil {
+			return nil, wrapError(err)
+		}
+		rooms = append(rooms, room)
+	}
+	return rooms, wrapError(rows.Err())
+}
+
+func queryBookings(ctx context.Context, db dbtx, query string, args ...any) ([]booking.Booking, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var bookings []booking.Booking
+	for rows.Next() {
+		var b booking.Booking
+		if err := scanBooking(rows, &b); err != nil {
+			return nil, wrapError(err)
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, wrapError(rows.Err())
+}
+
+func queryBookingsWithRooms(ctx context.Context, db dbtx, query string, args ...any) ([]booking.BookingWithRoom, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	var bookings []booking.BookingWithRoom
+	for rows.Next() {
+		var b booking.BookingWithRoom
+		if err := scanBookingWithRoom(rows, &b); err != nil {
+			return nil, wrapError(err)
+		}
+		bookings = append(bookings, b)
+	}
+	return bookings, wrapError(rows.Err())
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// dbtx is what the repositories run their statements on: the connection
+// pool, or the transaction of a Repository.Transaction call.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txn is a transaction started by begin. On a db that already is a
+// transaction it joins it: Commit and Rollback are left to the outer one.
+type txn struct {
+	dbtx
+	tx *sql.Tx
+}
+
+func begin(ctx context.Context, db dbtx) (*txn, error) {
+	pool, ok := db.(*sql.DB)
+	if !ok {
+		return &txn{dbtx: db}, nil
+	}
+	tx, err := pool.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &txn{dbtx: tx, tx: tx}, nil
+}
+
+func (t *txn) Commit() error {
+	if t.tx == nil {
+		return nil
+	}
+	return t.tx.Commit()
+}
+
+func (t *txn) Rollback() error {
+	if t.tx == nil {
+		return nil
+	}
+	return t.tx.Rollback()
+}
+
+// Transaction runs fn with a repository whose statements all belong to one
+// transaction, committed when fn returns nil and rolled back otherwise.
+func (r *postgresRepository) Transaction(ctx context.Context, fn func(repo Repository) error) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return wrapError(err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&postgresRepository{db: tx}); err != nil {
+		return err
+	}
+	return wrapError(tx.Commit())
+}
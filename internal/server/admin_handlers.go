@@ -3,31 +3,33 @@ package server
 import (
 	"net/http"
 	"strconv"
-	"time"
 
 	bookingModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
 	"github.com/gofiber/fiber/v2"
 )
 
 func (s *Server) handleAdminGetRooms(ctx *fiber.Ctx) error {
-	rooms, err := s.admin.GetAllRooms(ctx.Context())
+	filter, err := parseRoomFilter(ctx)
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return err
 	}
-	if rooms == nil {
-		rooms = []bookingModel.Room{}
+
+	rooms, err := s.admin.ListRooms(ctx.Context(), filter)
+	if err != nil {
+		return err
 	}
 	return ctx.Status(http.StatusOK).JSON(rooms)
 }
 
 func (s *Server) handleAdminCreateRoom(ctx *fiber.Ctx) error {
 	var room bookingModel.Room
-	if err := ctx.BodyParser(&room); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+	if err := parseBody(ctx, &room); err != nil {
+		return err
 	}
 
 	if err := s.admin.CreateRoom(ctx.Context(), &room); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	return ctx.Status(http.StatusCreated).JSON(room)
@@ -39,81 +41,190 @@ func (s *Server) handleAdminUpdateRoom(ctx *fiber.Ctx) error {
 		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid room ID")
 	}
 
-	var room bookingModel.Room
-	if err := ctx.BodyParser(&room); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req bookingModel.Room
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	room, err := s.admin.UpdateRoom(ctx.Context(), id, req.Patch(), version)
+	if err != nil {
+		return err
+	}
+	s.notification.NotifyRoomStatusChanged(ctx.Context(), room)
+
+	setETag(ctx, room.Version)
+	return ctx.Status(http.StatusOK).JSON(room)
+}
+
+func (s *Server) handleAdminPatchRoom(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid room ID")
+	}
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var patch bookingModel.RoomPatch
+	if err := decodeBody(ctx, &patch); err != nil {
+		return err
 	}
-	room.ID = id
 
-	if err := s.admin.UpdateRoom(ctx.Context(), &room); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	room, err := s.admin.UpdateRoom(ctx.Context(), id, patch, version)
+	if err != nil {
+		return err
 	}
+	s.notification.NotifyRoomStatusChanged(ctx.Context(), room)
 
+	setETag(ctx, room.Version)
 	return ctx.Status(http.StatusOK).JSON(room)
 }
 
+// handleAdminDeleteRoom archives the room; it is refused while the room has
+// bookings to serve, which have to be relocated through the archive endpoint.
 func (s *Server) handleAdminDeleteRoom(ctx *fiber.Ctx) error {
 	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
 	if err != nil {
 		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid room ID")
 	}
 
-	if err := s.admin.DeleteRoom(ctx.Context(), id); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	if err := s.admin.ArchiveRoom(ctx.Context(), id, bookingModel.ArchiveRoomRequest{}); err != nil {
+		return err
 	}
 
-	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Room deleted successfully"})
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Room archived successfully"})
 }
 
-func (s *Server) handleAdminGetBookings(ctx *fiber.Ctx) error {
-	status := ctx.Query("status")
+func (s *Server) handleAdminGetArchivePlan(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid room ID")
+	}
+
+	plan, err := s.admin.GetArchivePlan(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
 
-	bookings := make([]bookingModel.BookingWithRoom, 0)
-	var err error
+	return ctx.Status(http.StatusOK).JSON(plan)
+}
+
+func (s *Server) handleAdminArchiveRoom(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid room ID")
+	}
 
-	if status != "" {
-		bookings, err = s.admin.GetBookingsByStatus(ctx.Context(), bookingModel.BookingStatus(status))
-	} else {
-		bookings, err = s.admin.GetAllBookings(ctx.Context())
+	var req bookingModel.ArchiveRoomRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
 	}
 
+	if err := s.admin.ArchiveRoom(ctx.Context(), id, req); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Room archived successfully"})
+}
+
+func (s *Server) handleAdminRestoreRoom(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid room ID")
 	}
 
-	if bookings == nil {
-		bookings = []bookingModel.BookingWithRoom{}
+	if err := s.admin.RestoreRoom(ctx.Context(), id); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Room restored successfully"})
+}
+
+func (s *Server) handleAdminGetBookings(ctx *fiber.Ctx) error {
+	filter, err := parseBookingFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	bookings, err := s.admin.ListBookings(ctx.Context(), filter)
+	if err != nil {
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(bookings)
 }
 
+func (s *Server) handleAdminSearchBookings(ctx *fiber.Ctx) error {
+	limit, err := queryInt64(ctx, "limit")
+	if err != nil {
+		return err
+	}
+
+	results, err := s.admin.SearchBookings(ctx.Context(), ctx.Query("q"), int(limit))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(results)
+}
+
 type updateStatusRequest struct {
 	Status string `json:"status"`
 }
 
+func (r updateStatusRequest) Validate() error {
+	var v validation.Validator
+	v.Required("status", r.Status)
+	v.Check(r.Status == "" || bookingModel.BookingStatus(r.Status).IsValid(), "status", validation.CodeUnknownValue, "has an unknown value")
+	return v.Err()
+}
+
 func (s *Server) handleAdminUpdateBookingStatus(ctx *fiber.Ctx) error {
 	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
 	if err != nil {
 		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
 	}
 
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
 	var req updateStatusRequest
-	if err := ctx.BodyParser(&req); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+	if err := parseBody(ctx, &req); err != nil {
+		return err
 	}
 
-	if err := s.admin.UpdateBookingStatus(ctx.Context(), id, bookingModel.BookingStatus(req.Status)); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	booking, err := s.booking.UpdateBookingStatus(ctx.Context(), id, bookingModel.BookingStatus(req.Status), version)
+	if err != nil {
+		return err
 	}
 
+	if room, _ := s.booking.GetRoomByID(ctx.Context(), booking.RoomID); room != nil {
+		switch booking.Status {
+		case bookingModel.BookingStatusConfirmed:
+			s.notifyBookingConfirmed(ctx, booking, room)
+		case bookingModel.BookingStatusCancelled:
+			s.notification.NotifyBookingCancelled(ctx.Context(), booking, room)
+		default:
+			s.notification.NotifyBookingStatusChanged(ctx.Context(), booking, room)
+		}
+	}
+
+	setETag(ctx, booking.Version)
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Booking status updated successfully"})
 }
 
 func (s *Server) handleAdminGetStats(ctx *fiber.Ctx) error {
 	stats, err := s.admin.GetStatistics(ctx.Context())
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(stats)
@@ -122,44 +233,239 @@ func (s *Server) handleAdminGetStats(ctx *fiber.Ctx) error {
 func (s *Server) handleAdminGetStatus(ctx *fiber.Ctx) error {
 	status, err := s.admin.GetHotelStatus(ctx.Context())
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{"status": status})
 }
 
+func (s *Server) handleAdminGetFrontDesk(ctx *fiber.Ctx) error {
+	date, err := queryDate(ctx, "date")
+	if err != nil {
+		return err
+	}
+
+	day, err := s.admin.GetFrontDeskDay(ctx.Context(), date)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(day)
+}
+
+func (s *Server) handleAdminGetTapeChart(ctx *fiber.Ctx) error {
+	from, err := queryDate(ctx, "from")
+	if err != nil {
+		return err
+	}
+	to, err := queryDate(ctx, "to")
+	if err != nil {
+		return err
+	}
+
+	chart, err := s.admin.GetTapeChart(ctx.Context(), from, to)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(chart)
+}
+
+func (s *Server) handleAdminMoveBooking(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
+	}
+
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req bookingModel.MoveBookingRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	moved, err := s.booking.MoveBooking(ctx.Context(), id, req, version)
+	if err != nil {
+		return err
+	}
+
+	s.notification.NotifyBookingModified(ctx.Context(), &moved.Booking, &moved.Room)
+
+	setETag(ctx, moved.Version)
+	return ctx.Status(http.StatusOK).JSON(moved)
+}
+
+func (s *Server) handleAdminPatchBooking(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
+	}
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var patch bookingModel.BookingPatch
+	if err := decodeBody(ctx, &patch); err != nil {
+		return err
+	}
+
+	booking, err := s.booking.UpdateBooking(ctx.Context(), id, patch, version)
+	if err != nil {
+		return err
+	}
+
+	if room, _ := s.booking.GetRoomByID(ctx.Context(), booking.RoomID); room != nil {
+		s.notification.NotifyBookingModified(ctx.Context(), booking, room)
+	}
+
+	setETag(ctx, booking.Version)
+	return ctx.Status(http.StatusOK).JSON(booking)
+}
+
+func (s *Server) handleAdminGetMaintenanceWindows(ctx *fiber.Ctx) error {
+	roomID, err := queryInt64(ctx, "room_id")
+	if err != nil {
+		return err
+	}
+
+	windows, err := s.admin.GetMaintenanceWindows(ctx.Context(), roomID)
+	if err != nil {
+		return err
+	}
+	if windows == nil {
+		windows = []bookingModel.MaintenanceWindow{}
+	}
+
+	return ctx.Status(http.StatusOK).JSON(windows)
+}
+
+func (s *Server) handleAdminCreateMaintenanceWindow(ctx *fiber.Ctx) error {
+	var req bookingModel.CreateMaintenanceWindowRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	result, err := s.admin.CreateMaintenanceWindow(ctx.Context(), req)
+	if err != nil {
+		return err
+	}
+	if result.Created {
+		s.notifyRoomStatusChanged(ctx, result.Window.RoomID)
+	}
+
+	if !result.Created {
+		return ctx.Status(http.StatusOK).JSON(result)
+	}
+	return ctx.Status(http.StatusCreated).JSON(result)
+}
+
+func (s *Server) handleAdminDeleteMaintenanceWindow(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+	}
+
+	if err := s.admin.DeleteMaintenanceWindow(ctx.Context(), id); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Maintenance window deleted"})
+}
+
 func (s *Server) handleAdminGetSpecialDates(ctx *fiber.Ctx) error {
 	dates, err := s.booking.GetSpecialDates(ctx.Context())
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return ctx.Status(http.StatusOK).JSON(dates)
 }
 
 func (s *Server) handleAdminCreateSpecialDate(ctx *fiber.Ctx) error {
 	var req bookingModel.CreateSpecialDateRequest
-	if err := ctx.BodyParser(&req); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
-	}
-
-	date, err := time.Parse("2006-01-02", req.Date)
-	if err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid date format")
+	if err := parseBody(ctx, &req); err != nil {
+		return err
 	}
 
 	sd := &bookingModel.SpecialDate{
-		Date:        date,
+		Date:        req.Date,
 		Name:        req.Name,
 		Coefficient: req.Coefficient,
 	}
 
 	if err := s.booking.CreateSpecialDate(ctx.Context(), sd); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	return ctx.Status(http.StatusCreated).JSON(sd)
 }
 
+func (s *Server) handleAdminGetSpecialDate(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+	}
+
+	sd, err := s.booking.GetSpecialDate(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	setETag(ctx, sd.Version)
+	return ctx.Status(http.StatusOK).JSON(sd)
+}
+
+func (s *Server) handleAdminUpdateSpecialDate(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+	}
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req bookingModel.CreateSpecialDateRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	sd, err := s.booking.UpdateSpecialDate(ctx.Context(), id, req.Patch(), version)
+	if err != nil {
+		return err
+	}
+
+	setETag(ctx, sd.Version)
+	return ctx.Status(http.StatusOK).JSON(sd)
+}
+
+func (s *Server) handleAdminPatchSpecialDate(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid ID")
+	}
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var patch bookingModel.SpecialDatePatch
+	if err := decodeBody(ctx, &patch); err != nil {
+		return err
+	}
+
+	sd, err := s.booking.UpdateSpecialDate(ctx.Context(), id, patch, version)
+	if err != nil {
+		return err
+	}
+
+	setETag(ctx, sd.Version)
+	return ctx.Status(http.StatusOK).JSON(sd)
+}
+
 func (s *Server) handleAdminDeleteSpecialDate(ctx *fiber.Ctx) error {
 	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
 	if err != nil {
@@ -167,7 +473,7 @@ func (s *Server) handleAdminDeleteSpecialDate(ctx *fiber.Ctx) error {
 	}
 
 	if err := s.booking.DeleteSpecialDate(ctx.Context(), id); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Special date deleted"})
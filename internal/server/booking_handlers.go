@@ -3,65 +3,85 @@ package server
 import (
 	"net/http"
 	"strconv"
-	"time"
 
 	bookingModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
 	"github.com/gofiber/fiber/v2"
 )
 
 func (s *Server) handleGetRooms(ctx *fiber.Ctx) error {
 	rooms, err := s.booking.GetAllRooms(ctx.Context())
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return ctx.Status(http.StatusOK).JSON(rooms)
 }
 
 func (s *Server) handleSearchRooms(ctx *fiber.Ctx) error {
-	checkInStr := ctx.Query("check_in")
-	checkOutStr := ctx.Query("check_out")
-	roomType := ctx.Query("room_type")
-	capacityStr := ctx.Query("capacity")
+	req := bookingModel.RoomSearchRequest{
+		RoomType: bookingModel.RoomType(ctx.Query("room_type")),
+	}
 
-	var checkIn, checkOut time.Time
 	var err error
+	if req.CheckIn, err = queryDate(ctx, "check_in"); err != nil {
+		return err
+	}
+	if req.CheckIn.IsZero() {
+		req.CheckIn = s.booking.Today()
+	}
+	if req.CheckOut, err = queryDate(ctx, "check_out"); err != nil {
+		return err
+	}
+	if req.CheckOut.IsZero() {
+		req.CheckOut = req.CheckIn.AddDays(1)
+	}
+	capacity, err := queryInt64(ctx, "capacity")
+	if err != nil {
+		return err
+	}
+	req.Capacity = int(capacity)
 
-	if checkInStr != "" {
-		checkIn, err = time.Parse("2006-01-02", checkInStr)
-		if err != nil {
-			return ErrorResponse(ctx, http.StatusBadRequest, "Invalid check_in date format (use YYYY-MM-DD)")
-		}
-	} else {
-		checkIn = time.Now()
+	if err := req.Validate(); err != nil {
+		return err
 	}
 
-	if checkOutStr != "" {
-		checkOut, err = time.Parse("2006-01-02", checkOutStr)
-		if err != nil {
-			return ErrorResponse(ctx, http.StatusBadRequest, "Invalid check_out date format (use YYYY-MM-DD)")
-		}
-	} else {
-		checkOut = checkIn.AddDate(0, 0, 1)
+	rooms, err := s.booking.FindAvailableRooms(ctx.Context(), req)
+	if err != nil {
+		return err
 	}
 
-	var capacity int
-	if capacityStr != "" {
-		capacity, _ = strconv.Atoi(capacityStr)
+	return ctx.Status(http.StatusOK).JSON(rooms)
+}
+
+func (s *Server) handleGetAvailability(ctx *fiber.Ctx) error {
+	req := bookingModel.AvailabilityRequest{
+		RoomType: bookingModel.RoomType(ctx.Query("room_type")),
 	}
 
-	req := bookingModel.RoomSearchRequest{
-		CheckIn:  checkIn,
-		CheckOut: checkOut,
-		RoomType: bookingModel.RoomType(roomType),
-		Capacity: capacity,
+	var err error
+	if req.From, err = queryDate(ctx, "from"); err != nil {
+		return err
+	}
+	if req.From.IsZero() {
+		req.From = s.booking.Today()
+	}
+	if req.To, err = queryDate(ctx, "to"); err != nil {
+		return err
+	}
+	if req.To.IsZero() {
+		req.To = req.From.AddDays(30)
 	}
 
-	rooms, err := s.booking.FindAvailableRooms(ctx.Context(), req)
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	calendar, err := s.booking.GetAvailability(ctx.Context(), req)
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return err
 	}
 
-	return ctx.Status(http.StatusOK).JSON(rooms)
+	return ctx.Status(http.StatusOK).JSON(calendar)
 }
 
 func (s *Server) handleGetRoomByID(ctx *fiber.Ctx) error {
@@ -72,21 +92,40 @@ func (s *Server) handleGetRoomByID(ctx *fiber.Ctx) error {
 
 	room, err := s.booking.GetRoomByID(ctx.Context(), id)
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return err
 	}
 
+	setETag(ctx, room.Version)
 	return ctx.Status(http.StatusOK).JSON(room)
 }
 
 func (s *Server) handleCreateBooking(ctx *fiber.Ctx) error {
 	var req bookingModel.CreateBookingRequest
-	if err := ctx.BodyParser(&req); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+	if err := parseBody(ctx, &req); err != nil {
+		return err
 	}
 
 	booking, err := s.booking.CreateBooking(ctx.Context(), req)
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return err
+	}
+
+	s.notification.NotifyBookingCreated(ctx.Context(), &booking.Booking, &booking.Room)
+
+	return ctx.Status(http.StatusCreated).JSON(booking)
+}
+
+// handleAdminCreateBooking books a stay on behalf of a guest at the front
+// desk, where the guest may also pay with loyalty points.
+func (s *Server) handleAdminCreateBooking(ctx *fiber.Ctx) error {
+	var req bookingModel.CreateBookingRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	booking, err := s.booking.CreateStaffBooking(ctx.Context(), req)
+	if err != nil {
+		return err
 	}
 
 	s.notification.NotifyBookingCreated(ctx.Context(), &booking.Booking, &booking.Room)
@@ -102,42 +141,54 @@ func (s *Server) handleGetBooking(ctx *fiber.Ctx) error {
 
 	booking, err := s.booking.GetBookingByID(ctx.Context(), id)
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusNotFound, err.Error())
+		return err
 	}
 
+	setETag(ctx, booking.Version)
 	return ctx.Status(http.StatusOK).JSON(booking)
 }
 
 func (s *Server) handleGetMyBookings(ctx *fiber.Ctx) error {
-	email := ctx.Query("email")
-	if email == "" {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Email is required")
+	filter, err := parseBookingFilter(ctx)
+	if err != nil {
+		return err
+	}
+	if filter.GuestEmail == "" {
+		return validation.Field("email", validation.CodeRequired, "is required")
 	}
 
-	bookings, err := s.booking.GetBookingsByEmail(ctx.Context(), email)
+	bookings, err := s.booking.ListMyBookings(ctx.Context(), filter)
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(bookings)
 }
 
-func (s *Server) handleConfirmBooking(ctx *fiber.Ctx) error {
+// handleAdminConfirmBooking lets staff confirm a booking that is not paid
+// online, e.g. one paid at the desk; guests' bookings are confirmed when the
+// deposit is captured.
+func (s *Server) handleAdminConfirmBooking(ctx *fiber.Ctx) error {
 	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
 	if err != nil {
 		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
 	}
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
 
-	booking, err := s.booking.ConfirmBooking(ctx.Context(), id)
+	booking, err := s.booking.ConfirmBooking(ctx.Context(), id, version)
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	bookingWithRoom, _ := s.booking.GetBookingByID(ctx.Context(), id)
 	if bookingWithRoom != nil {
-		s.notification.NotifyBookingConfirmed(ctx.Context(), booking, &bookingWithRoom.Room)
+		s.notifyBookingConfirmed(ctx, booking, &bookingWithRoom.Room)
 	}
 
+	setETag(ctx, booking.Version)
 	return ctx.Status(http.StatusOK).JSON(booking)
 }
 
@@ -146,30 +197,39 @@ func (s *Server) handleCancelBooking(ctx *fiber.Ctx) error {
 	if err != nil {
 		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
 	}
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
 
 	bookingWithRoom, _ := s.booking.GetBookingByID(ctx.Context(), id)
 
-	booking, err := s.booking.CancelBooking(ctx.Context(), id)
+	booking, err := s.booking.CancelBooking(ctx.Context(), id, version)
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	if bookingWithRoom != nil {
 		s.notification.NotifyBookingCancelled(ctx.Context(), booking, &bookingWithRoom.Room)
 	}
 
+	setETag(ctx, booking.Version)
 	return ctx.Status(http.StatusOK).JSON(booking)
 }
 
+func (s *Server) handleGetStayPolicy(ctx *fiber.Ctx) error {
+	return ctx.Status(http.StatusOK).JSON(s.booking.GetStayPolicy())
+}
+
 func (s *Server) handleCalculatePrice(ctx *fiber.Ctx) error {
 	var req bookingModel.PriceCalculationRequest
-	if err := ctx.BodyParser(&req); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+	if err := parseBody(ctx, &req); err != nil {
+		return err
 	}
 
 	price, err := s.booking.CalculatePrice(ctx.Context(), req)
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(price)
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/ical"
+	bookingModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	calendarModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/calendar"
+	notificationModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/notification"
+	"github.com/gofiber/fiber/v2"
+)
+
+// notifyBookingConfirmed sends the confirmation with the stay attached as a
+// calendar event.
+func (s *Server) notifyBookingConfirmed(ctx *fiber.Ctx, booking *bookingModel.Booking, room *bookingModel.Room) {
+	s.notification.NotifyBookingConfirmed(ctx.Context(), booking, room, &notificationModel.Attachment{
+		Filename:    fmt.Sprintf("booking-%d.ics", booking.ID),
+		ContentType: ical.ContentType,
+		Content:     s.calendar.BookingEvent(booking, room),
+	})
+}
+
+func (s *Server) handleGetBookingCalendar(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
+	}
+
+	event, err := s.calendar.GetBookingEvent(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, ical.ContentType)
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="booking-%d.ics"`, id))
+	return ctx.Status(http.StatusOK).Send(event)
+}
+
+// handleGetRoomFeed serves the export feed of a room. The token in the URL
+// is the only credential, so channel partners can subscribe to it.
+func (s *Server) handleGetRoomFeed(ctx *fiber.Ctx) error {
+	token := strings.TrimSuffix(ctx.Params("token"), ".ics")
+
+	feed, err := s.calendar.GetRoomFeed(ctx.Context(), token)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, ical.ContentType)
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	return ctx.Status(http.StatusOK).Send(feed)
+}
+
+func (s *Server) handleAdminGetRoomCalendar(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid room ID")
+	}
+
+	cal, err := s.calendar.GetRoomCalendar(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(cal)
+}
+
+func (s *Server) handleAdminRotateRoomCalendar(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid room ID")
+	}
+
+	cal, err := s.calendar.RotateExportToken(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(cal)
+}
+
+func (s *Server) handleAdminGetCalendarFeeds(ctx *fiber.Ctx) error {
+	roomID, err := queryInt64(ctx, "room_id")
+	if err != nil {
+		return err
+	}
+
+	feeds, err := s.calendar.GetFeeds(ctx.Context(), roomID)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(feeds)
+}
+
+func (s *Server) handleAdminCreateCalendarFeed(ctx *fiber.Ctx) error {
+	var req calendarModel.CreateFeedRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	feed, err := s.calendar.CreateFeed(ctx.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(feed)
+}
+
+func (s *Server) handleAdminDeleteCalendarFeed(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid feed ID")
+	}
+
+	if err := s.calendar.DeleteFeed(ctx.Context(), id); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Calendar feed deleted"})
+}
+
+func (s *Server) handleAdminSyncCalendarFeed(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid feed ID")
+	}
+
+	feed, err := s.calendar.SyncFeed(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(feed)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	channelModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/channel"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *Server) handleAdminGetChannels(ctx *fiber.Ctx) error {
+	statuses, err := s.channel.GetStatuses(ctx.Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(statuses)
+}
+
+func (s *Server) handleAdminSyncChannel(ctx *fiber.Ctx) error {
+	result, err := s.channel.Sync(ctx.Context(), ctx.Params("name"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(result)
+}
+
+func (s *Server) handleAdminGetChannelMappings(ctx *fiber.Ctx) error {
+	mappings, err := s.channel.GetMappings(ctx.Context(), ctx.Query("channel"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(mappings)
+}
+
+func (s *Server) handleAdminCreateChannelMapping(ctx *fiber.Ctx) error {
+	var req channelModel.CreateMappingRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	mapping, err := s.channel.CreateMapping(ctx.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(mapping)
+}
+
+func (s *Server) handleAdminDeleteChannelMapping(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid mapping ID")
+	}
+
+	if err := s.channel.DeleteMapping(ctx.Context(), id); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Channel mapping deleted"})
+}
+
+func (s *Server) handleAdminGetChannelReservations(ctx *fiber.Ctx) error {
+	state := channelModel.ImportState(ctx.Query("state"))
+	if state != "" && !state.IsValid() {
+		return validation.Field("state", validation.CodeUnknownValue, "has an unknown value")
+	}
+
+	reservations, err := s.channel.GetReservations(ctx.Context(), state)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(reservations)
+}
+
+func (s *Server) handleAdminResolveChannelReservation(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid reservation ID")
+	}
+
+	var req channelModel.ResolveRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	reservation, err := s.channel.ResolveReservation(ctx.Context(), id, req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(reservation)
+}
+
+func (s *Server) handleAdminRejectChannelReservation(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid reservation ID")
+	}
+
+	reservation, err := s.channel.RejectReservation(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(reservation)
+}
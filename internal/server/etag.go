@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Rooms, bookings and special dates carry a row version that is exposed as a
+// strong ETag. Clients send it back in If-Match to make a change conditional.
+
+var errBadIfMatch = fiber.NewError(http.StatusPreconditionFailed, "If-Match does not name a version of this resource")
+
+func setETag(ctx *fiber.Ctx, version int64) {
+	ctx.Set(fiber.HeaderETag, strconv.Quote(strconv.FormatInt(version, 10)))
+}
+
+// ifMatch returns the version required by the If-Match header, or 0 when the
+// request is unconditional. Weak tags never match, as RFC 9110 requires.
+func ifMatch(ctx *fiber.Ctx) (int64, error) {
+	header := strings.TrimSpace(ctx.Get(fiber.HeaderIfMatch))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	tag, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, errBadIfMatch
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, errBadIfMatch
+	}
+	return version, nil
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	exchangeModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/exchange"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/exchange"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/spreadsheet"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+func parseFormat(value string) (spreadsheet.Format, error) {
+	format := spreadsheet.Format(strings.ToLower(strings.TrimPrefix(value, ".")))
+	if !format.IsValid() {
+		return "", validation.Field("format", validation.CodeUnknownValue, "has an unknown value")
+	}
+	return format, nil
+}
+
+// sendExport streams the export as a file download. Errors after the
+// headers have been sent can only be logged.
+func sendExport(ctx *fiber.Ctx, export *exchange.Export) error {
+	format, err := parseFormat(ctx.Query("format", string(spreadsheet.FormatCSV)))
+	if err != nil {
+		return err
+	}
+
+	ctx.Attachment(format.Filename(export.Name))
+	ctx.Set(fiber.HeaderContentType, format.ContentType())
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := export.WriteTo(context.Background(), format, w); err != nil {
+			log.Printf("export %s: %v", export.Name, err)
+		}
+	})
+	return nil
+}
+
+func (s *Server) handleAdminExportBookings(ctx *fiber.Ctx) error {
+	filter, err := parseBookingFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	export, err := s.exchange.ExportBookings(ctx.Context(), filter)
+	if err != nil {
+		return err
+	}
+	return sendExport(ctx, export)
+}
+
+func (s *Server) handleAdminExportRooms(ctx *fiber.Ctx) error {
+	filter, err := parseRoomFilter(ctx)
+	if err != nil {
+		return err
+	}
+
+	export, err := s.exchange.ExportRooms(ctx.Context(), filter)
+	if err != nil {
+		return err
+	}
+	return sendExport(ctx, export)
+}
+
+func (s *Server) handleAdminExportSpecialDates(ctx *fiber.Ctx) error {
+	export, err := s.exchange.ExportSpecialDates(ctx.Context())
+	if err != nil {
+		return err
+	}
+	return sendExport(ctx, export)
+}
+
+// importFile returns the uploaded spreadsheet: the "file" field of a
+// multipart form or the raw request body. The format is taken from the
+// format query parameter, the file name or the content type.
+func importFile(ctx *fiber.Ctx) (spreadsheet.Format, io.ReadCloser, error) {
+	format := ctx.Query("format")
+
+	if header, err := ctx.FormFile("file"); err == nil {
+		if format == "" {
+			format = filepath.Ext(header.Filename)
+		}
+		f, err := parseFormat(format)
+		if err != nil {
+			return "", nil, err
+		}
+		file, err := header.Open()
+		if err != nil {
+			return "", nil, err
+		}
+		return f, file, nil
+	}
+
+	if format == "" {
+		format = string(spreadsheet.FormatCSV)
+		if strings.HasPrefix(ctx.Get(fiber.HeaderContentType), spreadsheet.FormatXLSX.ContentType()) {
+			format = string(spreadsheet.FormatXLSX)
+		}
+	}
+	f, err := parseFormat(format)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(ctx.Body()) == 0 {
+		return "", nil, validation.Field("file", validation.CodeRequired, "is required")
+	}
+	return f, io.NopCloser(bytes.NewReader(ctx.Body())), nil
+}
+
+// sendImportResult answers 200 for a dry run or an applied import and 422
+// when row errors prevented the import.
+func sendImportResult(ctx *fiber.Ctx, result *exchangeModel.ImportResult) error {
+	status := http.StatusOK
+	if !result.DryRun && !result.Applied() {
+		status = http.StatusUnprocessableEntity
+	}
+	return ctx.Status(status).JSON(result)
+}
+
+func (s *Server) handleAdminImportRooms(ctx *fiber.Ctx) error {
+	format, file, err := importFile(ctx)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := s.exchange.ImportRooms(ctx.Context(), format, file, ctx.QueryBool("dry_run"))
+	if err != nil {
+		return err
+	}
+	return sendImportResult(ctx, result)
+}
+
+func (s *Server) handleAdminImportSpecialDates(ctx *fiber.Ctx) error {
+	format, file, err := importFile(ctx)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	result, err := s.exchange.ImportSpecialDates(ctx.Context(), format, file, ctx.QueryBool("dry_run"))
+	if err != nil {
+		return err
+	}
+	return sendImportResult(ctx, result)
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	notificationModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/notification"
+	"github.com/gofiber/fiber/v2"
+)
+
+const contentTypePDF = "application/pdf"
+
+func (s *Server) handleGetFolio(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
+	}
+
+	folio, err := s.folio.GetFolio(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(folio)
+}
+
+func (s *Server) handleAdminGetInvoice(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
+	}
+
+	invoice, err := s.folio.GetInvoice(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(invoice)
+}
+
+func (s *Server) handleGetInvoicePDF(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
+	}
+
+	invoice, pdf, err := s.folio.RenderInvoicePDF(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, contentTypePDF)
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`inline; filename="invoice-%s.pdf"`, invoice.Number))
+	return ctx.Status(http.StatusOK).Send(pdf)
+}
+
+// sendInvoice emails the invoice issued at checkout to the guest.
+func (s *Server) sendInvoice(ctx *fiber.Ctx, bookingID int64) {
+	invoice, pdf, err := s.folio.RenderInvoicePDF(ctx.Context(), bookingID)
+	if err != nil {
+		log.Printf("booking %d: rendering invoice: %v", bookingID, err)
+		return
+	}
+
+	bookingWithRoom, _ := s.booking.GetBookingByID(ctx.Context(), bookingID)
+	if bookingWithRoom == nil {
+		return
+	}
+	s.notification.NotifyCheckedOut(ctx.Context(), &bookingWithRoom.Booking, &bookingWithRoom.Room, &notificationModel.Attachment{
+		Filename:    "invoice-" + invoice.Number + ".pdf",
+		ContentType: contentTypePDF,
+		Content:     pdf,
+	})
+}
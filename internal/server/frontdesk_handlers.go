@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	bookingModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *Server) handleAdminCheckIn(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
+	}
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req bookingModel.CheckInDetails
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	booking, err := s.frontdesk.CheckIn(ctx.Context(), id, req, version)
+	if err != nil {
+		return err
+	}
+	s.notifyBookingStatusChanged(ctx, booking)
+	s.notifyRoomStatusChanged(ctx, booking.RoomID)
+
+	setETag(ctx, booking.Version)
+	return ctx.Status(http.StatusOK).JSON(booking)
+}
+
+func (s *Server) handleAdminCheckOut(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
+	}
+	version, err := ifMatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	booking, err := s.frontdesk.CheckOut(ctx.Context(), id, version)
+	if err != nil {
+		return err
+	}
+
+	// The checkout and its invoice are already recorded, so a failed email is
+	// only logged; the invoice can still be downloaded.
+	s.sendInvoice(ctx, id)
+	s.notifyRoomStatusChanged(ctx, booking.RoomID)
+
+	setETag(ctx, booking.Version)
+	return ctx.Status(http.StatusOK).JSON(booking)
+}
+
+func (s *Server) handleAdminProcessNoShows(ctx *fiber.Ctx) error {
+	date, err := queryDate(ctx, "date")
+	if err != nil {
+		return err
+	}
+
+	result, err := s.frontdesk.ProcessNoShows(ctx.Context(), date)
+	if err != nil {
+		return err
+	}
+	for _, id := range result.Processed {
+		if b, _ := s.booking.GetBookingByID(ctx.Context(), id); b != nil {
+			s.notification.NotifyBookingStatusChanged(ctx.Context(), &b.Booking, &b.Room)
+		}
+	}
+
+	return ctx.Status(http.StatusOK).JSON(result)
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	guestModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/guest"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *Server) handleAdminGetGuests(ctx *fiber.Ctx) error {
+	page, err := parsePageRequest(ctx)
+	if err != nil {
+		return err
+	}
+
+	guests, err := s.guest.ListGuests(ctx.Context(), guestModel.Filter{Query: ctx.Query("q"), PageRequest: page})
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(guests)
+}
+
+func (s *Server) handleAdminGetGuestProfile(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid guest ID")
+	}
+
+	profile, err := s.guest.GetProfile(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(profile)
+}
+
+func (s *Server) handleAdminUpdateGuest(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid guest ID")
+	}
+
+	var req guestModel.UpdateGuestRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	g, err := s.guest.UpdateGuest(ctx.Context(), id, req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(g)
+}
+
+func (s *Server) handleAdminGetDuplicateGuests(ctx *fiber.Ctx) error {
+	candidates, err := s.guest.GetDuplicates(ctx.Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(candidates)
+}
+
+func (s *Server) handleAdminMergeGuests(ctx *fiber.Ctx) error {
+	var req guestModel.MergeRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	profile, err := s.guest.MergeGuests(ctx.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(profile)
+}
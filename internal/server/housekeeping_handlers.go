@@ -0,0 +1,88 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/housekeeping"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *Server) handleGetHousekeepingTasks(ctx *fiber.Ctx) error {
+	filter := housekeeping.TaskFilter{
+		Assignee: ctx.Query("assignee"),
+		Status:   housekeeping.TaskStatus(ctx.Query("status")),
+	}
+
+	var err error
+	if filter.DueDate, err = queryDate(ctx, "date"); err != nil {
+		return err
+	}
+
+	tasks, err := s.housekeeping.GetTasks(ctx.Context(), filter)
+	if err != nil {
+		return err
+	}
+	if tasks == nil {
+		tasks = []housekeeping.TaskWithRoom{}
+	}
+
+	return ctx.Status(http.StatusOK).JSON(tasks)
+}
+
+func (s *Server) handleCreateHousekeepingTask(ctx *fiber.Ctx) error {
+	var req housekeeping.CreateTaskRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	task, err := s.housekeeping.CreateTask(ctx.Context(), req)
+	if err != nil {
+		return err
+	}
+	if req.Type == housekeeping.TaskTypeRepair {
+		s.notifyRoomStatusChanged(ctx, task.RoomID)
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(task)
+}
+
+func (s *Server) handleUpdateHousekeepingTask(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid task ID")
+	}
+
+	var req housekeeping.UpdateTaskRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	task, err := s.housekeeping.UpdateTask(ctx.Context(), id, req)
+	if err != nil {
+		return err
+	}
+	s.notifyRoomStatusChanged(ctx, task.RoomID)
+
+	return ctx.Status(http.StatusOK).JSON(task)
+}
+
+func (s *Server) handleUpdateHousekeepingRoomStatus(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid room ID")
+	}
+
+	var req housekeeping.UpdateRoomStatusRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	room, err := s.housekeeping.UpdateRoomStatus(ctx.Context(), id, req.Status)
+	if err != nil {
+		return err
+	}
+	s.notification.NotifyRoomStatusChanged(ctx.Context(), room)
+
+	return ctx.Status(http.StatusOK).JSON(room)
+}
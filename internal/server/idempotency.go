@@ -0,0 +1,79 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net/http"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/idempotency"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	headerIdempotencyKey      = "Idempotency-Key"
+	headerIdempotencyReplayed = "Idempotent-Replayed"
+)
+
+// idempotent makes a mutation safe to retry. The first request sent with an
+// Idempotency-Key is handled and its response stored; retries of the same
+// request get the stored response back, while the same key on a different
+// request is a conflict. Server errors are not stored, so such a request
+// can be retried with its key. Reads and requests without a key pass through.
+func (s *Server) idempotent(ctx *fiber.Ctx) error {
+	key := ctx.Get(headerIdempotencyKey)
+	if key == "" || ctx.Method() == fiber.MethodGet || ctx.Method() == fiber.MethodHead {
+		return ctx.Next()
+	}
+	if len(key) > idempotency.MaxKeyLength {
+		return validation.Field(headerIdempotencyKey, validation.CodeTooLong, "is too long")
+	}
+
+	stored, err := s.idempotency.Begin(ctx.Context(), key, requestFingerprint(ctx))
+	if err != nil {
+		return err
+	}
+	if stored != nil {
+		ctx.Set(headerIdempotencyReplayed, "true")
+		ctx.Set(fiber.HeaderContentType, stored.ContentType)
+		return ctx.Status(stored.StatusCode).Send(stored.Body)
+	}
+
+	// Errors are rendered here rather than by the app so that the problem
+	// response is what gets stored.
+	if err := ctx.Next(); err != nil {
+		if err := handleError(ctx, err); err != nil {
+			s.releaseIdempotencyKey(ctx, key)
+			return err
+		}
+	}
+
+	res := ctx.Response()
+	if res.StatusCode() >= http.StatusInternalServerError {
+		s.releaseIdempotencyKey(ctx, key)
+		return nil
+	}
+	if err := s.idempotency.Complete(ctx.Context(), key, res.StatusCode(), string(res.Header.ContentType()), res.Body()); err != nil {
+		log.Printf("idempotency key %q: storing response: %v", key, err)
+		s.releaseIdempotencyKey(ctx, key)
+	}
+	return nil
+}
+
+func (s *Server) releaseIdempotencyKey(ctx *fiber.Ctx, key string) {
+	if err := s.idempotency.Release(ctx.Context(), key); err != nil {
+		log.Printf("idempotency key %q: release: %v", key, err)
+	}
+}
+
+// requestFingerprint identifies a request by method, URL and body.
+func requestFingerprint(ctx *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(ctx.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(ctx.OriginalURL()))
+	h.Write([]byte{0})
+	h.Write(ctx.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
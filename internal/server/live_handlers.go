@@ -0,0 +1,129 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	bookingModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	liveModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/live"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// streamLifetime ends each event stream before the server's write
+	// timeout cuts it; the browser reconnects right away and catches up
+	// through Last-Event-ID.
+	streamLifetime    = _defaultWriteTimeout - 10*time.Second
+	keepAliveInterval = 15 * time.Second
+	reconnectDelay    = time.Second
+)
+
+// notifyBookingStatusChanged announces a status change that has no event of
+// its own, such as a check-in.
+func (s *Server) notifyBookingStatusChanged(ctx *fiber.Ctx, booking *bookingModel.Booking) {
+	if room, _ := s.booking.GetRoomByID(ctx.Context(), booking.RoomID); room != nil {
+		s.notification.NotifyBookingStatusChanged(ctx.Context(), booking, room)
+	}
+}
+
+// notifyRoomStatusChanged announces the current state of a room whose status
+// or housekeeping status may have changed.
+func (s *Server) notifyRoomStatusChanged(ctx *fiber.Ctx, roomID int64) {
+	if room, _ := s.booking.GetRoomByID(ctx.Context(), roomID); room != nil {
+		s.notification.NotifyRoomStatusChanged(ctx.Context(), room)
+	}
+}
+
+// handleEvents streams to a guest (email=...) the events of their own
+// bookings. The full stream is served by handleAdminEvents only.
+func (s *Server) handleEvents(ctx *fiber.Ctx) error {
+	if role := ctx.Query("role"); role != "" && role != string(liveModel.RoleGuest) {
+		return validation.Field("role", validation.CodeInvalid, "must be guest; staff use /admin/events")
+	}
+	return s.streamEvents(ctx, liveModel.Filter{Role: liveModel.RoleGuest, Email: ctx.Query("email")})
+}
+
+// handleAdminEvents streams every booking and room event.
+func (s *Server) handleAdminEvents(ctx *fiber.Ctx) error {
+	return s.streamEvents(ctx, liveModel.Filter{Role: liveModel.RoleAdmin})
+}
+
+// streamEvents sends the events the filter allows as Server-Sent Events.
+func (s *Server) streamEvents(ctx *fiber.Ctx, filter liveModel.Filter) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	// EventSource sends the header on reconnect; the query parameter lets
+	// a page that was reloaded resume too.
+	lastEventID := int64(-1)
+	raw := ctx.Get("Last-Event-ID", ctx.Query("last_event_id"))
+	if raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id < 0 {
+			return validation.Field("last_event_id", validation.CodeInvalid, "must be an event ID")
+		}
+		lastEventID = id
+	}
+
+	sub := s.live.Subscribe(filter, lastEventID)
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	ctx.Set("X-Accel-Buffering", "no")
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer s.live.Unsubscribe(sub)
+
+		fmt.Fprintf(w, "retry: %d\n", reconnectDelay.Milliseconds())
+		// An id without data moves the browser's resume point without
+		// dispatching anything, so a client that sees no event before it
+		// reconnects still gets the ones published in between.
+		fmt.Fprintf(w, "id: %d\n\n", sub.LastID)
+		if sub.Stale {
+			writeLiveEvent(w, liveModel.Event{ID: sub.LastID, Type: liveModel.EventTypeReset, CreatedAt: time.Now()})
+		}
+		for _, e := range sub.Missed {
+			writeLiveEvent(w, e)
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		lifetime := time.NewTimer(streamLifetime)
+		defer lifetime.Stop()
+		keepAlive := time.NewTicker(keepAliveInterval)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case e, ok := <-sub.Events:
+				if !ok {
+					return
+				}
+				writeLiveEvent(w, e)
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			case <-lifetime.C:
+				return
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func writeLiveEvent(w *bufio.Writer, e liveModel.Event) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+}
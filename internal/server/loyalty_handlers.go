@@ -0,0 +1,60 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	loyaltyModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/loyalty"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *Server) handleAdminGetLoyaltyByEmail(ctx *fiber.Ctx) error {
+	email := ctx.Query("email")
+	var v validation.Validator
+	v.Required("email", email)
+	v.Email("email", email)
+	if err := v.Err(); err != nil {
+		return err
+	}
+
+	account, err := s.loyalty.GetAccountByEmail(ctx.Context(), email)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(account)
+}
+
+func (s *Server) handleAdminGetLoyalty(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid guest ID")
+	}
+
+	account, err := s.loyalty.GetAccount(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(account)
+}
+
+func (s *Server) handleAdminAdjustLoyalty(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid guest ID")
+	}
+
+	var req loyaltyModel.AdjustRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	account, err := s.loyalty.Adjust(ctx.Context(), id, req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(account)
+}
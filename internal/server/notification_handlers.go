@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/YurcheuskiRadzivon/booking-system/internal/models/notification"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -21,14 +22,50 @@ type broadcastRequest struct {
 	Message   string   `json:"message"`
 }
 
-func (s *Server) handleSendNotification(ctx *fiber.Ctx) error {
-	var req sendNotificationRequest
-	if err := ctx.BodyParser(&req); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
+const maxNotificationLength = 4000
+
+// validateRecipient checks the recipient against the channel: an address for
+// email, a phone number for SMS and Viber.
+func validateRecipient(v *validation.Validator, channel notification.NotificationChannel, recipient string) {
+	switch channel {
+	case notification.NotificationChannelEmail:
+		v.Email("recipient", recipient)
+	case notification.NotificationChannelSMS, notification.NotificationChannelViber:
+		v.Phone("recipient", recipient)
+	default:
+		v.Add("channel", validation.CodeUnknownValue, "has an unknown value")
+	}
+}
+
+func (r sendNotificationRequest) Validate() error {
+	var v validation.Validator
+	v.Required("channel", r.Channel)
+	v.Required("recipient", r.Recipient)
+	v.Required("message", r.Message)
+	v.MaxLength("message", r.Message, maxNotificationLength)
+	if r.Channel != "" {
+		validateRecipient(&v, notification.NotificationChannel(r.Channel), r.Recipient)
 	}
+	return v.Err()
+}
+
+func (r broadcastRequest) Validate() error {
+	var v validation.Validator
+	v.Check(len(r.Channels) > 0, "channels", validation.CodeRequired, "is required")
+	v.Required("recipient", r.Recipient)
+	v.Required("message", r.Message)
+	v.MaxLength("message", r.Message, maxNotificationLength)
+	for _, ch := range r.Channels {
+		validation.OneOf(&v, "channels", notification.NotificationChannel(ch),
+			notification.NotificationChannelEmail, notification.NotificationChannelSMS, notification.NotificationChannelViber)
+	}
+	return v.Err()
+}
 
-	if req.Recipient == "" || req.Message == "" {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Recipient and message are required")
+func (s *Server) handleSendNotification(ctx *fiber.Ctx) error {
+	var req sendNotificationRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
 	}
 
 	var resp *notification.NotificationResponse
@@ -46,7 +83,7 @@ func (s *Server) handleSendNotification(ctx *fiber.Ctx) error {
 	}
 
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(resp)
@@ -54,12 +91,8 @@ func (s *Server) handleSendNotification(ctx *fiber.Ctx) error {
 
 func (s *Server) handleBroadcastNotification(ctx *fiber.Ctx) error {
 	var req broadcastRequest
-	if err := ctx.BodyParser(&req); err != nil {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid request body")
-	}
-
-	if req.Recipient == "" || req.Message == "" || len(req.Channels) == 0 {
-		return ErrorResponse(ctx, http.StatusBadRequest, "Recipient, message, and channels are required")
+	if err := parseBody(ctx, &req); err != nil {
+		return err
 	}
 
 	channels := make([]notification.NotificationChannel, len(req.Channels))
@@ -69,7 +102,7 @@ func (s *Server) handleBroadcastNotification(ctx *fiber.Ctx) error {
 
 	responses, err := s.notification.Broadcast(ctx.Context(), channels, req.Recipient, req.Subject, req.Message)
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(responses)
@@ -78,7 +111,7 @@ func (s *Server) handleBroadcastNotification(ctx *fiber.Ctx) error {
 func (s *Server) handleGetNotificationTypes(ctx *fiber.Ctx) error {
 	types, err := s.notification.GetNotificationTypes(ctx.Context())
 	if err != nil {
-		return ErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+		return err
 	}
 
 	return ctx.Status(http.StatusOK).JSON(types)
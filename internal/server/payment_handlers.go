@@ -0,0 +1,120 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	paymentModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/payment"
+	"github.com/gofiber/fiber/v2"
+)
+
+const headerPaymentSignature = "X-Payment-Signature"
+
+func (s *Server) handleGetBookingPayments(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
+	}
+
+	summary, err := s.payment.GetSummary(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(summary)
+}
+
+func (s *Server) handleCreatePayment(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid booking ID")
+	}
+
+	var req paymentModel.CreatePaymentRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	result, err := s.payment.Pay(ctx.Context(), id, req)
+	if err != nil {
+		return err
+	}
+	s.notifyPaymentConfirmed(ctx, result)
+
+	return ctx.Status(http.StatusCreated).JSON(result)
+}
+
+func (s *Server) handleAdminCapturePayment(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid payment ID")
+	}
+
+	var req paymentModel.CaptureRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	result, err := s.payment.Capture(ctx.Context(), id, req)
+	if err != nil {
+		return err
+	}
+	s.notifyPaymentConfirmed(ctx, result)
+
+	return ctx.Status(http.StatusOK).JSON(result)
+}
+
+func (s *Server) handleAdminRefundPayment(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid payment ID")
+	}
+
+	var req paymentModel.RefundRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	payment, err := s.payment.Refund(ctx.Context(), id, req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(payment)
+}
+
+func (s *Server) handleAdminVoidPayment(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid payment ID")
+	}
+
+	payment, err := s.payment.Void(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(payment)
+}
+
+// handlePaymentWebhook receives gateway events. Redelivered and unknown
+// events are acknowledged too, so the gateway stops retrying them.
+func (s *Server) handlePaymentWebhook(ctx *fiber.Ctx) error {
+	result, err := s.payment.HandleWebhook(ctx.Context(), ctx.Body(), ctx.Get(headerPaymentSignature))
+	if err != nil {
+		return err
+	}
+	s.notifyPaymentConfirmed(ctx, result)
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"status": "ok"})
+}
+
+func (s *Server) notifyPaymentConfirmed(ctx *fiber.Ctx, result *paymentModel.Result) {
+	if result == nil || !result.Confirmed {
+		return
+	}
+	bookingWithRoom, _ := s.booking.GetBookingByID(ctx.Context(), result.Payment.BookingID)
+	if bookingWithRoom != nil {
+		s.notifyBookingConfirmed(ctx, &bookingWithRoom.Booking, &bookingWithRoom.Room)
+	}
+}
@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem codes are stable identifiers of the kind of failure that clients
+// can switch on; Detail is for humans only.
+const (
+	ProblemValidation    = "validation_failed"
+	ProblemMalformedBody = "malformed_body"
+	ProblemBadRequest    = "bad_request"
+	ProblemNotFound      = "not_found"
+	ProblemConflict      = "conflict"
+	ProblemForbidden     = "forbidden"
+	ProblemPrecondition  = "precondition_failed"
+	ProblemUnavailable   = "unavailable"
+	ProblemInternal      = "internal_error"
+)
+
+// Problem is an RFC 7807 problem details object. Code and Errors are
+// extension members.
+type Problem struct {
+	Type     string                  `json:"type"`
+	Title    string                  `json:"title"`
+	Status   int                     `json:"status"`
+	Detail   string                  `json:"detail,omitempty"`
+	Instance string                  `json:"instance,omitempty"`
+	Code     string                  `json:"code"`
+	Errors   []validation.FieldError `json:"errors,omitempty"`
+}
+
+func problemCode(status int) string {
+	switch status {
+	case http.StatusNotFound:
+		return ProblemNotFound
+	case http.StatusConflict:
+		return ProblemConflict
+	case http.StatusForbidden:
+		return ProblemForbidden
+	case http.StatusPreconditionFailed:
+		return ProblemPrecondition
+	case http.StatusServiceUnavailable:
+		return ProblemUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return ProblemInternal
+	}
+	return ProblemBadRequest
+}
+
+func writeProblem(ctx *fiber.Ctx, p Problem) error {
+	if p.Type == "" {
+		p.Type = "/problems/" + strings.ReplaceAll(p.Code, "_", "-")
+	}
+	p.Title = http.StatusText(p.Status)
+	p.Instance = ctx.OriginalURL()
+	return ctx.Status(p.Status).JSON(p, problemContentType)
+}
+
+func ErrorResponse(ctx *fiber.Ctx, code int, msg string) error {
+	return writeProblem(ctx, Problem{Status: code, Detail: msg, Code: problemCode(code)})
+}
+
+// handleError is the central Fiber error handler: handlers return domain
+// errors and this maps them to problem responses. Internal causes are logged
+// and never sent to the client.
+func handleError(ctx *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return ErrorResponse(ctx, fiberErr.Code, fiberErr.Message)
+	}
+
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return writeProblem(ctx, Problem{
+			Status: http.StatusBadRequest,
+			Detail: "The request has invalid fields",
+			Code:   ProblemValidation,
+			Errors: fields,
+		})
+	}
+	if errors.Is(err, errMalformedBody) {
+		return writeProblem(ctx, Problem{Status: http.StatusBadRequest, Detail: err.Error(), Code: ProblemMalformedBody})
+	}
+
+	var appErr *apperr.Error
+	if !errors.As(err, &appErr) || appErr.Kind == apperr.KindInternal {
+		log.Printf("%s %s: %v", ctx.Method(), ctx.OriginalURL(), err)
+		return writeProblem(ctx, Problem{Status: http.StatusInternalServerError, Detail: "Internal server error", Code: ProblemInternal})
+	}
+
+	status := statusOf(appErr.Kind)
+	if status >= http.StatusInternalServerError {
+		log.Printf("%s %s: %v", ctx.Method(), ctx.OriginalURL(), err)
+	}
+	return writeProblem(ctx, Problem{Status: status, Detail: appErr.Message, Code: appErr.Code})
+}
+
+func statusOf(kind apperr.Kind) int {
+	switch kind {
+	case apperr.KindNotFound:
+		return http.StatusNotFound
+	case apperr.KindConflict:
+		return http.StatusConflict
+	case apperr.KindValidation:
+		return http.StatusBadRequest
+	case apperr.KindForbidden:
+		return http.StatusForbidden
+	case apperr.KindPrecondition:
+		return http.StatusPreconditionFailed
+	case apperr.KindUnavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+var errMalformedBody = errors.New("Invalid request body")
+
+type validatable interface {
+	Validate() error
+}
+
+// parseBody decodes the JSON body into v and validates it.
+func parseBody(ctx *fiber.Ctx, v validatable) error {
+	if err := decodeBody(ctx, v); err != nil {
+		return err
+	}
+	return v.Validate()
+}
+
+// decodeBody decodes the JSON body into v. Type mismatches are reported
+// against the offending field. Patches are decoded with it directly, since
+// they can only be validated once applied to the stored record.
+func decodeBody(ctx *fiber.Ctx, v any) error {
+	if err := ctx.BodyParser(v); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return validation.Field(typeErr.Field, validation.CodeInvalid, "must be a "+typeErr.Type.String())
+		}
+		return errMalformedBody
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"strconv"
+
+	bookingModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+func queryInt64(ctx *fiber.Ctx, key string) (int64, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, validation.Field(key, validation.CodeInvalid, "must be an integer")
+	}
+	return n, nil
+}
+
+func queryFloat(ctx *fiber.Ctx, key string) (float64, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, validation.Field(key, validation.CodeInvalid, "must be a number")
+	}
+	return f, nil
+}
+
+func queryDate(ctx *fiber.Ctx, key string) (bookingModel.Date, error) {
+	value := ctx.Query(key)
+	if value == "" {
+		return bookingModel.Date{}, nil
+	}
+	d, err := bookingModel.ParseDate(value)
+	if err != nil {
+		return d, validation.Field(key, validation.CodeInvalid, "must be a date in YYYY-MM-DD format")
+	}
+	return d, nil
+}
+
+func parsePageRequest(ctx *fiber.Ctx) (bookingModel.PageRequest, error) {
+	limit, err := queryInt64(ctx, "limit")
+	if err != nil {
+		return bookingModel.PageRequest{}, err
+	}
+	page := bookingModel.PageRequest{
+		Limit:  int(limit),
+		Cursor: ctx.Query("cursor"),
+		Sort:   ctx.Query("sort"),
+		Order:  bookingModel.SortOrder(ctx.Query("order")),
+	}
+	return page, page.Validate()
+}
+
+func parseBookingFilter(ctx *fiber.Ctx) (bookingModel.BookingFilter, error) {
+	filter := bookingModel.BookingFilter{
+		Status:     bookingModel.BookingStatus(ctx.Query("status")),
+		GuestEmail: ctx.Query("email"),
+	}
+
+	var err error
+	if filter.PageRequest, err = parsePageRequest(ctx); err != nil {
+		return filter, err
+	}
+	if filter.RoomID, err = queryInt64(ctx, "room_id"); err != nil {
+		return filter, err
+	}
+	if filter.From, err = queryDate(ctx, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = queryDate(ctx, "to"); err != nil {
+		return filter, err
+	}
+	if filter.MinPrice, err = queryFloat(ctx, "min_price"); err != nil {
+		return filter, err
+	}
+	if filter.MaxPrice, err = queryFloat(ctx, "max_price"); err != nil {
+		return filter, err
+	}
+	return filter, filter.Validate()
+}
+
+func parseRoomFilter(ctx *fiber.Ctx) (bookingModel.RoomFilter, error) {
+	filter := bookingModel.RoomFilter{
+		RoomType: bookingModel.RoomType(ctx.Query("room_type")),
+		Status:   bookingModel.RoomStatus(ctx.Query("status")),
+		Archived: ctx.QueryBool("archived"),
+	}
+
+	var err error
+	if filter.PageRequest, err = parsePageRequest(ctx); err != nil {
+		return filter, err
+	}
+	capacity, err := queryInt64(ctx, "capacity")
+	if err != nil {
+		return filter, err
+	}
+	filter.MinCapacity = int(capacity)
+	if filter.MinPrice, err = queryFloat(ctx, "min_price"); err != nil {
+		return filter, err
+	}
+	if filter.MaxPrice, err = queryFloat(ctx, "max_price"); err != nil {
+		return filter, err
+	}
+	return filter, filter.Validate()
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"net/http"
+
+	bookingModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/gofiber/fiber/v2"
+)
+
+func parseReportPeriod(ctx *fiber.Ctx) (bookingModel.Date, bookingModel.Date, error) {
+	from, err := queryDate(ctx, "from")
+	if err != nil {
+		return from, bookingModel.Date{}, err
+	}
+	to, err := queryDate(ctx, "to")
+	return from, to, err
+}
+
+func (s *Server) handleAdminGetDailyReport(ctx *fiber.Ctx) error {
+	from, to, err := parseReportPeriod(ctx)
+	if err != nil {
+		return err
+	}
+
+	report, err := s.report.GetDailyReport(ctx.Context(), from, to)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(report)
+}
+
+func (s *Server) handleAdminGetRoomTypeReport(ctx *fiber.Ctx) error {
+	from, to, err := parseReportPeriod(ctx)
+	if err != nil {
+		return err
+	}
+
+	report, err := s.report.GetRoomTypeReport(ctx.Context(), from, to)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(report)
+}
+
+func (s *Server) handleAdminGetPickupReport(ctx *fiber.Ctx) error {
+	from, to, err := parseReportPeriod(ctx)
+	if err != nil {
+		return err
+	}
+	window, err := queryInt64(ctx, "window")
+	if err != nil {
+		return err
+	}
+
+	report, err := s.report.GetPickupReport(ctx.Context(), from, to, int(window))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(report)
+}
@@ -7,7 +7,21 @@ import (
 
 	"github.com/YurcheuskiRadzivon/booking-system/internal/service/admin"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/service/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/calendar"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/channel"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/exchange"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/folio"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/frontdesk"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/guest"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/housekeeping"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/idempotency"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/live"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/loyalty"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/service/notification"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/payment"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/report"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/waitlist"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/webhook"
 	"github.com/YurcheuskiRadzivon/booking-system/web"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -28,13 +42,23 @@ type Server struct {
 	booking      booking.Service
 	notification notification.Service
 	admin        admin.Service
+	frontdesk    frontdesk.Service
+	housekeeping housekeeping.Service
+	guest        guest.Service
+	loyalty      loyalty.Service
+	idempotency  idempotency.Service
+	payment      payment.Service
+	folio        folio.Service
+	report       report.Service
+	exchange     exchange.Service
+	calendar     calendar.Service
+	channel      channel.Service
+	webhook      webhook.Service
+	live         live.Service
+	waitlist     waitlist.Service
 }
 
-type Error struct {
-	Message string `json:"message" example:"message"`
-}
-
-func New(port string, bookingSvc booking.Service, notificationSvc notification.Service, adminSvc admin.Service) *Server {
+func New(port string, bookingSvc booking.Service, notificationSvc notification.Service, adminSvc admin.Service, frontdeskSvc frontdesk.Service, housekeepingSvc housekeeping.Service, guestSvc guest.Service, loyaltySvc loyalty.Service, idempotencySvc idempotency.Service, paymentSvc payment.Service, folioSvc folio.Service, reportSvc report.Service, exchangeSvc exchange.Service, calendarSvc calendar.Service, channelSvc channel.Service, webhookSvc webhook.Service, liveSvc live.Service, waitlistSvc waitlist.Service) *Server {
 	s := &Server{
 		app:          nil,
 		notify:       make(chan error, 1),
@@ -42,6 +66,20 @@ func New(port string, bookingSvc booking.Service, notificationSvc notification.S
 		booking:      bookingSvc,
 		notification: notificationSvc,
 		admin:        adminSvc,
+		frontdesk:    frontdeskSvc,
+		housekeeping: housekeepingSvc,
+		guest:        guestSvc,
+		loyalty:      loyaltySvc,
+		idempotency:  idempotencySvc,
+		payment:      paymentSvc,
+		folio:        folioSvc,
+		report:       reportSvc,
+		exchange:     exchangeSvc,
+		calendar:     calendarSvc,
+		channel:      channelSvc,
+		webhook:      webhookSvc,
+		live:         liveSvc,
+		waitlist:     waitlistSvc,
 	}
 
 	app := fiber.New(fiber.Config{
@@ -50,12 +88,14 @@ func New(port string, bookingSvc booking.Service, notificationSvc notification.S
 		WriteTimeout: _defaultWriteTimeout,
 		JSONEncoder:  json.Marshal,
 		JSONDecoder:  json.Unmarshal,
+		ErrorHandler: handleError,
 	})
 
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin, Content-Type, Accept",
+		AllowOrigins:  "*",
+		AllowMethods:  "GET,POST,PUT,PATCH,DELETE,OPTIONS",
+		AllowHeaders:  "Origin, Content-Type, Accept, If-Match, Idempotency-Key, Last-Event-ID",
+		ExposeHeaders: "ETag, Idempotent-Replayed",
 	}))
 
 	s.app = app
@@ -85,35 +125,132 @@ func (s *Server) RegisterRoutes() {
 		bookingGroup.Get("/rooms", s.handleGetRooms)
 		bookingGroup.Get("/rooms/search", s.handleSearchRooms)
 		bookingGroup.Get("/rooms/:id", s.handleGetRoomByID)
-		bookingGroup.Post("/", s.handleCreateBooking)
+		bookingGroup.Get("/availability", s.handleGetAvailability)
+		bookingGroup.Post("/", s.idempotent, s.handleCreateBooking)
+		bookingGroup.Get("/policy", s.handleGetStayPolicy)
+		bookingGroup.Get("/my", s.handleGetMyBookings)
 		bookingGroup.Get("/:id", s.handleGetBooking)
-		bookingGroup.Put("/:id/confirm", s.handleConfirmBooking)
+		bookingGroup.Get("/:id/payments", s.handleGetBookingPayments)
+		bookingGroup.Post("/:id/payments", s.idempotent, s.handleCreatePayment)
+		bookingGroup.Get("/:id/folio", s.handleGetFolio)
+		bookingGroup.Get("/:id/invoice.pdf", s.handleGetInvoicePDF)
+		bookingGroup.Get("/:id/calendar.ics", s.handleGetBookingCalendar)
 		bookingGroup.Put("/:id/cancel", s.handleCancelBooking)
 		bookingGroup.Post("/price", s.handleCalculatePrice)
-		bookingGroup.Get("/my", s.handleGetMyBookings)
 	}
 
 	notificationGroup := s.app.Group("/notification")
 	{
-		notificationGroup.Post("/send", s.handleSendNotification)
-		notificationGroup.Post("/broadcast", s.handleBroadcastNotification)
+		notificationGroup.Post("/send", s.idempotent, s.handleSendNotification)
+		notificationGroup.Post("/broadcast", s.idempotent, s.handleBroadcastNotification)
 		notificationGroup.Get("/types", s.handleGetNotificationTypes)
 	}
 
-	adminGroup := s.app.Group("/admin")
+	adminGroup := s.app.Group("/admin", s.idempotent)
 	{
 		adminGroup.Get("/rooms", s.handleAdminGetRooms)
 		adminGroup.Post("/rooms", s.handleAdminCreateRoom)
+		adminGroup.Get("/rooms/export", s.handleAdminExportRooms)
+		adminGroup.Post("/rooms/import", s.handleAdminImportRooms)
 		adminGroup.Put("/rooms/:id", s.handleAdminUpdateRoom)
+		adminGroup.Patch("/rooms/:id", s.handleAdminPatchRoom)
 		adminGroup.Delete("/rooms/:id", s.handleAdminDeleteRoom)
+		adminGroup.Get("/rooms/:id/archive-plan", s.handleAdminGetArchivePlan)
+		adminGroup.Post("/rooms/:id/archive", s.handleAdminArchiveRoom)
+		adminGroup.Post("/rooms/:id/restore", s.handleAdminRestoreRoom)
+		adminGroup.Get("/rooms/:id/calendar", s.handleAdminGetRoomCalendar)
+		adminGroup.Post("/rooms/:id/calendar/rotate", s.handleAdminRotateRoomCalendar)
 		adminGroup.Get("/bookings", s.handleAdminGetBookings)
+		adminGroup.Post("/bookings", s.handleAdminCreateBooking)
+		adminGroup.Get("/bookings/search", s.handleAdminSearchBookings)
+		adminGroup.Get("/bookings/export", s.handleAdminExportBookings)
+		adminGroup.Patch("/bookings/:id", s.handleAdminPatchBooking)
 		adminGroup.Put("/bookings/:id/status", s.handleAdminUpdateBookingStatus)
+		adminGroup.Put("/bookings/:id/confirm", s.handleAdminConfirmBooking)
+		adminGroup.Put("/bookings/:id/move", s.handleAdminMoveBooking)
+		adminGroup.Put("/bookings/:id/check-in", s.handleAdminCheckIn)
+		adminGroup.Put("/bookings/:id/check-out", s.handleAdminCheckOut)
+		adminGroup.Get("/bookings/:id/invoice", s.handleAdminGetInvoice)
+		adminGroup.Post("/payments/:id/capture", s.handleAdminCapturePayment)
+		adminGroup.Post("/payments/:id/refund", s.handleAdminRefundPayment)
+		adminGroup.Post("/payments/:id/void", s.handleAdminVoidPayment)
+		adminGroup.Post("/no-shows/process", s.handleAdminProcessNoShows)
 		adminGroup.Get("/stats", s.handleAdminGetStats)
 		adminGroup.Get("/status", s.handleAdminGetStatus)
+		adminGroup.Get("/frontdesk", s.handleAdminGetFrontDesk)
+		adminGroup.Get("/tape-chart", s.handleAdminGetTapeChart)
+
+		adminGroup.Get("/reports/daily", s.handleAdminGetDailyReport)
+		adminGroup.Get("/reports/room-types", s.handleAdminGetRoomTypeReport)
+		adminGroup.Get("/reports/pickup", s.handleAdminGetPickupReport)
 
 		adminGroup.Get("/dates", s.handleAdminGetSpecialDates)
 		adminGroup.Post("/dates", s.handleAdminCreateSpecialDate)
+		adminGroup.Get("/dates/export", s.handleAdminExportSpecialDates)
+		adminGroup.Post("/dates/import", s.handleAdminImportSpecialDates)
+		adminGroup.Get("/dates/:id", s.handleAdminGetSpecialDate)
+		adminGroup.Put("/dates/:id", s.handleAdminUpdateSpecialDate)
+		adminGroup.Patch("/dates/:id", s.handleAdminPatchSpecialDate)
 		adminGroup.Delete("/dates/:id", s.handleAdminDeleteSpecialDate)
+
+		adminGroup.Get("/maintenance", s.handleAdminGetMaintenanceWindows)
+		adminGroup.Post("/maintenance", s.handleAdminCreateMaintenanceWindow)
+		adminGroup.Delete("/maintenance/:id", s.handleAdminDeleteMaintenanceWindow)
+
+		adminGroup.Get("/calendar/feeds", s.handleAdminGetCalendarFeeds)
+		adminGroup.Post("/calendar/feeds", s.handleAdminCreateCalendarFeed)
+		adminGroup.Delete("/calendar/feeds/:id", s.handleAdminDeleteCalendarFeed)
+		adminGroup.Post("/calendar/feeds/:id/sync", s.handleAdminSyncCalendarFeed)
+
+		adminGroup.Get("/channels", s.handleAdminGetChannels)
+		adminGroup.Post("/channels/:name/sync", s.handleAdminSyncChannel)
+		adminGroup.Get("/channels/mappings", s.handleAdminGetChannelMappings)
+		adminGroup.Post("/channels/mappings", s.handleAdminCreateChannelMapping)
+		adminGroup.Delete("/channels/mappings/:id", s.handleAdminDeleteChannelMapping)
+		adminGroup.Get("/channels/reservations", s.handleAdminGetChannelReservations)
+		adminGroup.Post("/channels/reservations/:id/resolve", s.handleAdminResolveChannelReservation)
+		adminGroup.Post("/channels/reservations/:id/reject", s.handleAdminRejectChannelReservation)
+		adminGroup.Get("/webhooks", s.handleAdminGetWebhooks)
+		adminGroup.Post("/webhooks", s.handleAdminCreateWebhook)
+		adminGroup.Patch("/webhooks/:id", s.handleAdminUpdateWebhook)
+		adminGroup.Delete("/webhooks/:id", s.handleAdminDeleteWebhook)
+		adminGroup.Post("/webhooks/:id/test", s.handleAdminTestWebhook)
+		adminGroup.Get("/webhooks/:id/deliveries", s.handleAdminGetWebhookDeliveries)
+		adminGroup.Get("/waitlist", s.handleAdminGetWaitlist)
+		adminGroup.Post("/waitlist/offer", s.handleAdminOfferWaitlist)
+		adminGroup.Delete("/waitlist/:id", s.handleAdminRemoveWaitlistEntry)
+
+		adminGroup.Get("/guests", s.handleAdminGetGuests)
+		adminGroup.Get("/guests/duplicates", s.handleAdminGetDuplicateGuests)
+		adminGroup.Post("/guests/merge", s.handleAdminMergeGuests)
+		adminGroup.Get("/guests/:id", s.handleAdminGetGuestProfile)
+		adminGroup.Put("/guests/:id", s.handleAdminUpdateGuest)
+		adminGroup.Get("/guests/:id/loyalty", s.handleAdminGetLoyalty)
+		adminGroup.Get("/loyalty", s.handleAdminGetLoyaltyByEmail)
+		adminGroup.Post("/guests/:id/loyalty/adjust", s.handleAdminAdjustLoyalty)
+
+		adminGroup.Get("/events", s.handleAdminEvents)
+	}
+
+	s.app.Post("/payments/webhook", s.handlePaymentWebhook)
+	s.app.Get("/ical/rooms/:token", s.handleGetRoomFeed)
+	s.app.Get("/events", s.handleEvents)
+
+	waitlistGroup := s.app.Group("/waitlist")
+	{
+		waitlistGroup.Post("/", s.idempotent, s.handleJoinWaitlist)
+		waitlistGroup.Delete("/:id", s.handleLeaveWaitlist)
+		waitlistGroup.Get("/holds/:token", s.handleGetWaitlistHold)
+		waitlistGroup.Post("/holds/:token/claim", s.handleClaimWaitlistHold)
+		waitlistGroup.Post("/holds/:token/decline", s.handleDeclineWaitlistHold)
+	}
+
+	housekeepingGroup := s.app.Group("/housekeeping")
+	{
+		housekeepingGroup.Get("/tasks", s.handleGetHousekeepingTasks)
+		housekeepingGroup.Post("/tasks", s.handleCreateHousekeepingTask)
+		housekeepingGroup.Put("/tasks/:id", s.handleUpdateHousekeepingTask)
+		housekeepingGroup.Put("/rooms/:id/status", s.handleUpdateHousekeepingRoomStatus)
 	}
 }
 
@@ -124,7 +261,3 @@ func (s *Server) Notify() <-chan error {
 func (s *Server) Shutdown() error {
 	return s.app.ShutdownWithTimeout(_defaultShutdownTimeout)
 }
-
-func ErrorResponse(ctx *fiber.Ctx, code int, msg string) error {
-	return ctx.Status(code).JSON(Error{Message: msg})
-}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	waitlistModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/waitlist"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *Server) handleJoinWaitlist(ctx *fiber.Ctx) error {
+	var req waitlistModel.JoinRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	entry, err := s.waitlist.Join(ctx.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(entry)
+}
+
+func (s *Server) handleLeaveWaitlist(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid waitlist entry ID")
+	}
+	email := ctx.Query("email")
+	if email == "" {
+		return validation.Field("email", validation.CodeRequired, "is required")
+	}
+
+	if err := s.waitlist.Leave(ctx.Context(), id, email); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Left the waitlist"})
+}
+
+func (s *Server) handleGetWaitlistHold(ctx *fiber.Ctx) error {
+	hold, err := s.waitlist.GetHold(ctx.Context(), ctx.Params("token"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(hold)
+}
+
+func (s *Server) handleClaimWaitlistHold(ctx *fiber.Ctx) error {
+	hold, err := s.waitlist.ClaimHold(ctx.Context(), ctx.Params("token"))
+	if err != nil {
+		return err
+	}
+	s.notifyBookingConfirmed(ctx, &hold.Booking.Booking, &hold.Booking.Room)
+
+	return ctx.Status(http.StatusOK).JSON(hold)
+}
+
+func (s *Server) handleDeclineWaitlistHold(ctx *fiber.Ctx) error {
+	if err := s.waitlist.DeclineHold(ctx.Context(), ctx.Params("token")); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Hold declined"})
+}
+
+func (s *Server) handleAdminGetWaitlist(ctx *fiber.Ctx) error {
+	status := waitlistModel.Status(ctx.Query("status"))
+	if status != "" && !status.IsValid() {
+		return validation.Field("status", validation.CodeUnknownValue, "has an unknown value")
+	}
+
+	entries, err := s.waitlist.List(ctx.Context(), status)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(entries)
+}
+
+// handleAdminOfferWaitlist matches the waitlist against free rooms right
+// away instead of waiting for the next cancellation or scan.
+func (s *Server) handleAdminOfferWaitlist(ctx *fiber.Ctx) error {
+	offered, err := s.waitlist.Offer(ctx.Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"offered": offered})
+}
+
+func (s *Server) handleAdminRemoveWaitlistEntry(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid waitlist entry ID")
+	}
+
+	if err := s.waitlist.Remove(ctx.Context(), id); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Waitlist entry removed"})
+}
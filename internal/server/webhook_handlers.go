@@ -0,0 +1,92 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	webhookModel "github.com/YurcheuskiRadzivon/booking-system/internal/models/webhook"
+	"github.com/gofiber/fiber/v2"
+)
+
+func (s *Server) handleAdminGetWebhooks(ctx *fiber.Ctx) error {
+	subs, err := s.webhook.GetSubscriptions(ctx.Context())
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(subs)
+}
+
+func (s *Server) handleAdminCreateWebhook(ctx *fiber.Ctx) error {
+	var req webhookModel.CreateSubscriptionRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	sub, err := s.webhook.CreateSubscription(ctx.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusCreated).JSON(sub)
+}
+
+func (s *Server) handleAdminUpdateWebhook(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid webhook ID")
+	}
+
+	var req webhookModel.UpdateSubscriptionRequest
+	if err := parseBody(ctx, &req); err != nil {
+		return err
+	}
+
+	sub, err := s.webhook.UpdateSubscription(ctx.Context(), id, req)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(sub)
+}
+
+func (s *Server) handleAdminDeleteWebhook(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid webhook ID")
+	}
+
+	if err := s.webhook.DeleteSubscription(ctx.Context(), id); err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(fiber.Map{"message": "Webhook deleted"})
+}
+
+func (s *Server) handleAdminTestWebhook(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid webhook ID")
+	}
+
+	delivery, err := s.webhook.SendTest(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(delivery)
+}
+
+func (s *Server) handleAdminGetWebhookDeliveries(ctx *fiber.Ctx) error {
+	id, err := strconv.ParseInt(ctx.Params("id"), 10, 64)
+	if err != nil {
+		return ErrorResponse(ctx, http.StatusBadRequest, "Invalid webhook ID")
+	}
+
+	deliveries, err := s.webhook.GetDeliveries(ctx.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(http.StatusOK).JSON(deliveries)
+}
@@ -2,10 +2,30 @@ package admin
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+var (
+	ErrRoomNotFound              = apperr.NotFound("room_not_found", "room not found")
+	ErrInvalidDates              = apperr.Validation("invalid_dates", "invalid dates: end date must be after start date")
+	ErrInvalidMaintenanceKind    = apperr.Validation("invalid_maintenance_kind", "invalid maintenance kind")
+	ErrMaintenanceWindowNotFound = apperr.NotFound("maintenance_window_not_found", "maintenance window not found")
+	ErrRoomHasBookings           = apperr.Conflict("room_has_bookings", "room has current or upcoming bookings that must be relocated")
+	ErrRoomArchived              = apperr.Conflict("room_archived", "room is archived")
+	ErrRoomNotArchived           = apperr.Conflict("room_not_archived", "room is not archived")
+	ErrBookingNotFound           = apperr.NotFound("booking_not_found", "booking not found")
+	ErrSearchQueryTooShort       = apperr.Validation("search_query_too_short", "search query must be at least 2 characters")
 )
 
 type Statistics struct {
@@ -16,33 +36,69 @@ type Statistics struct {
 	PendingBookings   int                      `json:"pending_bookings"`
 	ConfirmedBookings int                      `json:"confirmed_bookings"`
 	CancelledBookings int                      `json:"cancelled_bookings"`
+	CheckedInBookings int                      `json:"checked_in_bookings"`
+	CompletedBookings int                      `json:"completed_bookings"`
+	NoShowBookings    int                      `json:"no_show_bookings"`
 	RoomsByType       map[booking.RoomType]int `json:"rooms_by_type"`
 	TotalRevenue      float64                  `json:"total_revenue"`
 }
 
+type FrontDeskDay struct {
+	Date       booking.Date              `json:"date"`
+	Arrivals   []booking.BookingWithRoom `json:"arrivals"`
+	Departures []booking.BookingWithRoom `json:"departures"`
+}
+
+// TapeChart is the room-by-date view of the front desk: every room, the
+// bookings and maintenance windows overlapping [From, To), and the IDs of
+// bookings that overlap another booking or a maintenance window in their room.
+type TapeChart struct {
+	From        booking.Date                `json:"from"`
+	To          booking.Date                `json:"to"`
+	Rooms       []booking.Room              `json:"rooms"`
+	Bookings    []booking.BookingWithRoom   `json:"bookings"`
+	Maintenance []booking.MaintenanceWindow `json:"maintenance"`
+	Conflicts   []int64                     `json:"conflicts"`
+}
+
 type Service interface {
 	GetAllRooms(ctx context.Context) ([]booking.Room, error)
+	ListRooms(ctx context.Context, filter booking.RoomFilter) (*booking.Page[booking.Room], error)
 	CreateRoom(ctx context.Context, room *booking.Room) error
-	UpdateRoom(ctx context.Context, room *booking.Room) error
-	DeleteRoom(ctx context.Context, id int64) error
+	// UpdateRoom applies patch to the room if it is still at version (0 for
+	// an unconditional update) and returns the stored room.
+	UpdateRoom(ctx context.Context, id int64, patch booking.RoomPatch, version int64) (*booking.Room, error)
+	GetArchivePlan(ctx context.Context, id int64) ([]booking.RelocationProposal, error)
+	ArchiveRoom(ctx context.Context, id int64, req booking.ArchiveRoomRequest) error
+	RestoreRoom(ctx context.Context, id int64) error
 
 	GetAllBookings(ctx context.Context) ([]booking.BookingWithRoom, error)
 	GetBookingsByStatus(ctx context.Context, status booking.BookingStatus) ([]booking.BookingWithRoom, error)
-	UpdateBookingStatus(ctx context.Context, id int64, status booking.BookingStatus) error
+	ListBookings(ctx context.Context, filter booking.BookingFilter) (*booking.Page[booking.BookingWithRoom], error)
+	SearchBookings(ctx context.Context, query string, limit int) ([]booking.BookingSearchResult, error)
+
+	GetFrontDeskDay(ctx context.Context, date booking.Date) (*FrontDeskDay, error)
+	GetTapeChart(ctx context.Context, from, to booking.Date) (*TapeChart, error)
+
+	GetMaintenanceWindows(ctx context.Context, roomID int64) ([]booking.MaintenanceWindow, error)
+	CreateMaintenanceWindow(ctx context.Context, req booking.CreateMaintenanceWindowRequest) (*booking.MaintenanceWindowResult, error)
+	DeleteMaintenanceWindow(ctx context.Context, id int64) error
 
 	GetStatistics(ctx context.Context) (*Statistics, error)
 	GetHotelStatus(ctx context.Context) (string, error)
 }
 
 type service struct {
-	ctx  context.Context
-	repo repository.Repository
+	ctx      context.Context
+	repo     repository.Repository
+	location *time.Location
 }
 
-func NewService(ctx context.Context, repo repository.Repository) (Service, error) {
+func NewService(ctx context.Context, repo repository.Repository, location *time.Location) (Service, error) {
 	return &service{
-		ctx:  ctx,
-		repo: repo,
+		ctx:      ctx,
+		repo:     repo,
+		location: location,
 	}, nil
 }
 
@@ -50,6 +106,11 @@ func (s *service) GetAllRooms(ctx context.Context) ([]booking.Room, error) {
 	return s.repo.Room().GetAll(ctx)
 }
 
+func (s *service) ListRooms(ctx context.Context, filter booking.RoomFilter) (*booking.Page[booking.Room], error) {
+	filter.Normalize("room_number", booking.SortAsc)
+	return s.repo.Room().List(ctx, filter)
+}
+
 func (s *service) CreateRoom(ctx context.Context, room *booking.Room) error {
 	if room.Status == "" {
 		room.Status = booking.RoomStatusAvailable
@@ -57,12 +118,180 @@ func (s *service) CreateRoom(ctx context.Context, room *booking.Room) error {
 	return s.repo.Room().Create(ctx, room)
 }
 
-func (s *service) UpdateRoom(ctx context.Context, room *booking.Room) error {
-	return s.repo.Room().Update(ctx, room)
+func (s *service) UpdateRoom(ctx context.Context, id int64, patch booking.RoomPatch, version int64) (*booking.Room, error) {
+	room, err := s.repo.Room().GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	if err := repository.CheckVersion(room.Version, version); err != nil {
+		return nil, err
+	}
+	if err := patch.Apply(room); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.Room().Update(ctx, room)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrRoomNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return room, nil
+}
+
+// pendingBookings returns the bookings of the room that still have nights
+// to be served, plus any guest who has not checked out yet.
+func (s *service) pendingBookings(ctx context.Context, roomID int64) ([]booking.Booking, error) {
+	bookings, err := s.repo.Booking().GetByRoomID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	today := booking.Today(s.location)
+	var pending []booking.Booking
+	for _, b := range bookings {
+		if b.Status == booking.BookingStatusCheckedIn || isActive(b.Status) && b.EndDate.After(today) {
+			pending = append(pending, b)
+		}
+	}
+	return pending, nil
+}
+
+func (s *service) getActiveRoom(ctx context.Context, id int64) (*booking.Room, error) {
+	room, err := s.repo.Room().GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	if room.IsArchived() {
+		return nil, ErrRoomArchived
+	}
+	return room, nil
+}
+
+// GetArchivePlan lists the bookings that block archiving the room together
+// with the rooms each of them could be moved to.
+func (s *service) GetArchivePlan(ctx context.Context, id int64) ([]booking.RelocationProposal, error) {
+	room, err := s.getActiveRoom(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.pendingBookings(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := make([]booking.RelocationProposal, 0, len(pending))
+	for _, b := range pending {
+		candidates, err := s.relocationCandidates(ctx, room, b)
+		if err != nil {
+			return nil, err
+		}
+		plan = append(plan, booking.RelocationProposal{Booking: b, Candidates: candidates})
+	}
+	return plan, nil
+}
+
+// ArchiveRoom takes the room out of service. Every booking that still has
+// nights to be served must be relocated by req; past bookings stay attached
+// to the archived room so that history and reports are kept.
+func (s *service) ArchiveRoom(ctx context.Context, id int64, req booking.ArchiveRoomRequest) error {
+	if _, err := s.getActiveRoom(ctx, id); err != nil {
+		return err
+	}
+	pending, err := s.pendingBookings(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[int64]booking.Booking, len(pending))
+	for _, b := range pending {
+		byID[b.ID] = b
+	}
+
+	var v validation.Validator
+	planned := make(map[int64]bool, len(req.Relocations))
+	for i, rel := range req.Relocations {
+		field := fmt.Sprintf("relocations[%d]", i)
+		b, ok := byID[rel.BookingID]
+		if !ok || planned[rel.BookingID] {
+			v.Add(field+".booking_id", validation.CodeInvalid, "is not a current booking of this room")
+			continue
+		}
+		planned[rel.BookingID] = true
+
+		problem, err := s.relocationProblem(ctx, id, b, rel, req.Relocations[:i])
+		if err != nil {
+			return err
+		}
+		if problem != "" {
+			v.Add(field+".room_id", validation.CodeInvalid, problem)
+		}
+	}
+	if err := v.Err(); err != nil {
+		return err
+	}
+	if len(planned) < len(pending) {
+		return ErrRoomHasBookings
+	}
+
+	return s.repo.Room().Archive(ctx, id, req.Relocations)
+}
+
+// relocationProblem explains why b cannot move to rel.RoomID for its dates,
+// taking the relocations planned before it into account, or returns "".
+func (s *service) relocationProblem(ctx context.Context, fromID int64, b booking.Booking, rel booking.Relocation, earlier []booking.Relocation) (string, error) {
+	if rel.RoomID == fromID {
+		return "must be another room", nil
+	}
+	target, err := s.repo.Room().GetByID(ctx, rel.RoomID)
+	if err != nil {
+		return "", err
+	}
+	if target == nil || target.IsArchived() {
+		return "room does not exist or is archived", nil
+	}
+
+	available, err := s.repo.Booking().IsRoomAvailableExcept(ctx, rel.RoomID, b.StartDate, b.EndDate, b.ID)
+	if err != nil {
+		return "", err
+	}
+	if !available {
+		return "room is not available for the booking dates", nil
+	}
+
+	for _, other := range earlier {
+		if other.RoomID != rel.RoomID {
+			continue
+		}
+		ob, err := s.repo.Booking().GetByID(ctx, other.BookingID)
+		if err != nil {
+			return "", err
+		}
+		if ob != nil && overlaps(b.StartDate, b.EndDate, ob.StartDate, ob.EndDate) {
+			return "room is already taken by another relocation for these dates", nil
+		}
+	}
+	return "", nil
 }
 
-func (s *service) DeleteRoom(ctx context.Context, id int64) error {
-	return s.repo.Room().Delete(ctx, id)
+func (s *service) RestoreRoom(ctx context.Context, id int64) error {
+	room, err := s.repo.Room().GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		return ErrRoomNotFound
+	}
+	if !room.IsArchived() {
+		return ErrRoomNotArchived
+	}
+	return s.repo.Room().Restore(ctx, id)
 }
 
 func (s *service) GetAllBookings(ctx context.Context) ([]booking.BookingWithRoom, error) {
@@ -73,8 +302,277 @@ func (s *service) GetBookingsByStatus(ctx context.Context, status booking.Bookin
 	return s.repo.Booking().GetByStatusWithRooms(ctx, status)
 }
 
-func (s *service) UpdateBookingStatus(ctx context.Context, id int64, status booking.BookingStatus) error {
-	return s.repo.Booking().UpdateStatus(ctx, id, status)
+func (s *service) ListBookings(ctx context.Context, filter booking.BookingFilter) (*booking.Page[booking.BookingWithRoom], error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, ErrInvalidDates
+	}
+	filter.Normalize("created_at", booking.SortDesc)
+	return s.repo.Booking().List(ctx, filter)
+}
+
+// SearchBookings finds bookings by a fragment of the guest name, email or
+// phone number, or by booking ID ("123" or "#123").
+func (s *service) SearchBookings(ctx context.Context, query string, limit int) ([]booking.BookingSearchResult, error) {
+	query = strings.TrimSpace(query)
+	q := booking.BookingSearchQuery{Text: query, Limit: limit}
+	if q.Limit <= 0 || q.Limit > booking.DefaultPageLimit {
+		q.Limit = booking.DefaultPageLimit
+	}
+
+	if id, err := strconv.ParseInt(strings.TrimPrefix(query, "#"), 10, 64); err == nil {
+		q.ID = id
+	}
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, query)
+	if len(digits) >= 3 {
+		q.Phone = digits
+	}
+	if len([]rune(query)) < 2 {
+		q.Text = ""
+	}
+	if q.Text == "" && q.ID == 0 {
+		return nil, ErrSearchQueryTooShort
+	}
+
+	results, err := s.repo.Booking().Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if results == nil {
+		results = []booking.BookingSearchResult{}
+	}
+	return results, nil
+}
+
+func (s *service) GetFrontDeskDay(ctx context.Context, date booking.Date) (*FrontDeskDay, error) {
+	if date.IsZero() {
+		date = booking.Today(s.location)
+	}
+
+	arrivals, err := s.repo.Booking().GetArrivalsWithRooms(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	departures, err := s.repo.Booking().GetDeparturesWithRooms(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	if arrivals == nil {
+		arrivals = []booking.BookingWithRoom{}
+	}
+	if departures == nil {
+		departures = []booking.BookingWithRoom{}
+	}
+
+	return &FrontDeskDay{
+		Date:       date,
+		Arrivals:   arrivals,
+		Departures: departures,
+	}, nil
+}
+
+func (s *service) GetTapeChart(ctx context.Context, from, to booking.Date) (*TapeChart, error) {
+	if from.IsZero() {
+		from = booking.Today(s.location)
+	}
+	if to.IsZero() {
+		to = from.AddDays(14)
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidDates
+	}
+
+	rooms, err := s.repo.Room().GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.repo.Booking().GetInRangeWithRooms(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	windows, err := s.repo.Maintenance().GetOverlapping(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Archived rooms only appear when they still carry bookings in the range.
+	shown := make(map[int64]bool, len(rooms))
+	for _, r := range rooms {
+		shown[r.ID] = true
+	}
+	for _, b := range bookings {
+		if !shown[b.Room.ID] {
+			shown[b.Room.ID] = true
+			rooms = append(rooms, b.Room)
+		}
+	}
+
+	if rooms == nil {
+		rooms = []booking.Room{}
+	}
+	if bookings == nil {
+		bookings = []booking.BookingWithRoom{}
+	}
+	if windows == nil {
+		windows = []booking.MaintenanceWindow{}
+	}
+
+	return &TapeChart{
+		From:        from,
+		To:          to,
+		Rooms:       rooms,
+		Bookings:    bookings,
+		Maintenance: windows,
+		Conflicts:   findConflicts(bookings, windows),
+	}, nil
+}
+
+func findConflicts(bookings []booking.BookingWithRoom, windows []booking.MaintenanceWindow) []int64 {
+	conflicts := []int64{}
+	for i, b := range bookings {
+		conflict := false
+		for j, other := range bookings {
+			if i != j && other.RoomID == b.RoomID && overlaps(b.StartDate, b.EndDate, other.StartDate, other.EndDate) {
+				conflict = true
+				break
+			}
+		}
+		for _, w := range windows {
+			if !conflict && w.RoomID == b.RoomID && overlaps(b.StartDate, b.EndDate, w.StartDate, w.EndDate) {
+				conflict = true
+			}
+		}
+		if conflict {
+			conflicts = append(conflicts, b.ID)
+		}
+	}
+	return conflicts
+}
+
+// isActive reports whether a booking still holds its room.
+func isActive(status booking.BookingStatus) bool {
+	switch status {
+	case booking.BookingStatusPending, booking.BookingStatusConfirmed, booking.BookingStatusCheckedIn:
+		return true
+	}
+	return false
+}
+
+func overlaps(start1, end1, start2, end2 booking.Date) bool {
+	return start1.Before(end2) && start2.Before(end1)
+}
+
+func (s *service) GetMaintenanceWindows(ctx context.Context, roomID int64) ([]booking.MaintenanceWindow, error) {
+	if roomID > 0 {
+		return s.repo.Maintenance().GetByRoomID(ctx, roomID)
+	}
+	return s.repo.Maintenance().GetAll(ctx)
+}
+
+// CreateMaintenanceWindow blocks a room for a date range. Bookings that already
+// hold the room on those dates are not touched; they are returned together with
+// the rooms they could be moved to so the front desk can relocate the guests.
+func (s *service) CreateMaintenanceWindow(ctx context.Context, req booking.CreateMaintenanceWindowRequest) (*booking.MaintenanceWindowResult, error) {
+	if req.StartDate.IsZero() || req.EndDate.IsZero() || !req.EndDate.After(req.StartDate) {
+		return nil, ErrInvalidDates
+	}
+	if req.Kind == "" {
+		req.Kind = booking.MaintenanceKindOutOfOrder
+	}
+	if req.Kind != booking.MaintenanceKindOutOfOrder && req.Kind != booking.MaintenanceKindOutOfService {
+		return nil, ErrInvalidMaintenanceKind
+	}
+
+	room, err := s.repo.Room().GetByID(ctx, req.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	conflicts, err := s.repo.Booking().GetActiveForRoom(ctx, req.RoomID, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &booking.MaintenanceWindowResult{
+		Window: booking.MaintenanceWindow{
+			RoomID:    req.RoomID,
+			StartDate: req.StartDate,
+			EndDate:   req.EndDate,
+			Kind:      req.Kind,
+			Reason:    req.Reason,
+		},
+		Conflicts: make([]booking.RelocationProposal, 0, len(conflicts)),
+	}
+
+	for _, b := range conflicts {
+		candidates, err := s.relocationCandidates(ctx, room, b)
+		if err != nil {
+			return nil, err
+		}
+		result.Conflicts = append(result.Conflicts, booking.RelocationProposal{
+			Booking:    b,
+			Candidates: candidates,
+		})
+	}
+
+	if len(conflicts) > 0 {
+		result.Warning = fmt.Sprintf("%d booking(s) hold room %s in this period and should be relocated", len(conflicts), room.RoomNumber)
+	}
+
+	if req.DryRun {
+		return result, nil
+	}
+
+	if err := s.repo.Maintenance().Create(ctx, &result.Window); err != nil {
+		return nil, err
+	}
+	result.Created = true
+
+	return result, nil
+}
+
+// relocationCandidates lists free rooms that fit the guests of b, rooms of the
+// same type first.
+func (s *service) relocationCandidates(ctx context.Context, from *booking.Room, b booking.Booking) ([]booking.Room, error) {
+	rooms, err := s.repo.Room().GetAvailableByCapacity(ctx, from.Capacity, b.StartDate, b.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]booking.Room, 0, len(rooms))
+	for _, r := range rooms {
+		if r.ID != from.ID {
+			candidates = append(candidates, r)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].RoomType == from.RoomType && candidates[j].RoomType != from.RoomType
+	})
+
+	return candidates, nil
+}
+
+func (s *service) DeleteMaintenanceWindow(ctx context.Context, id int64) error {
+	w, err := s.repo.Maintenance().GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if w == nil {
+		return ErrMaintenanceWindowNotFound
+	}
+	return s.repo.Maintenance().Delete(ctx, id)
 }
 
 func (s *service) GetStatistics(ctx context.Context) (*Statistics, error) {
@@ -93,12 +591,12 @@ func (s *service) GetStatistics(ctx context.Context) (*Statistics, error) {
 		RoomsByType: make(map[booking.RoomType]int),
 	}
 
-	now := time.Now()
 	for _, room := range rooms {
 		stats.RoomsByType[room.RoomType]++
-		if room.Status == booking.RoomStatusAvailable {
+		switch room.Status {
+		case booking.RoomStatusAvailable:
 			stats.AvailableRooms++
-		} else {
+		case booking.RoomStatusOccupied:
 			stats.OccupiedRooms++
 		}
 	}
@@ -110,11 +608,16 @@ func (s *service) GetStatistics(ctx context.Context) (*Statistics, error) {
 			stats.PendingBookings++
 		case booking.BookingStatusConfirmed:
 			stats.ConfirmedBookings++
-			if b.StartDate.Before(now) && b.EndDate.After(now) {
-				stats.OccupiedRooms++
-				stats.AvailableRooms--
-			}
 			stats.TotalRevenue += b.Price
+		case booking.BookingStatusCheckedIn:
+			stats.CheckedInBookings++
+			stats.TotalRevenue += b.Price
+		case booking.BookingStatusCheckedOut:
+			stats.CompletedBookings++
+			stats.TotalRevenue += b.Price
+		case booking.BookingStatusNoShow:
+			stats.NoShowBookings++
+			stats.TotalRevenue += b.Penalty
 		case booking.BookingStatusCancelled:
 			stats.CancelledBookings++
 		}
@@ -136,6 +639,9 @@ func (s *service) GetHotelStatus(ctx context.Context) (string, error) {
 	status += "\nБронирования:\n"
 	status += "  Ожидают: " + itoa(stats.PendingBookings) + "\n"
 	status += "  Подтверждено: " + itoa(stats.ConfirmedBookings) + "\n"
+	status += "  Проживают: " + itoa(stats.CheckedInBookings) + "\n"
+	status += "  Выехали: " + itoa(stats.CompletedBookings) + "\n"
+	status += "  Не заехали: " + itoa(stats.NoShowBookings) + "\n"
 	status += "  Отменено: " + itoa(stats.CancelledBookings) + "\n"
 	status += "\nНомера по типам:\n"
 	for t, count := range stats.RoomsByType {
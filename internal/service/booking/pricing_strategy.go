@@ -2,6 +2,7 @@ package booking
 
 import (
 	"context"
+	"math"
 	"time"
 
 	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
@@ -15,19 +16,98 @@ const (
 
 type PriceCalculator struct {
 	specialDates map[string]booking.SpecialDate
+	policy       booking.StayPolicy
+	discount     *booking.Discount
 }
 
-func NewPriceCalculator(specialDates []booking.SpecialDate) *PriceCalculator {
+func NewPriceCalculator(specialDates []booking.SpecialDate, policy booking.StayPolicy) *PriceCalculator {
 	dateMap := make(map[string]booking.SpecialDate)
 	for _, sd := range specialDates {
-		key := sd.Date.Format("2006-01-02")
-		dateMap[key] = sd
+		dateMap[sd.Date.String()] = sd
 	}
-	return &PriceCalculator{specialDates: dateMap}
+	return &PriceCalculator{specialDates: dateMap, policy: policy}
 }
 
-func (pc *PriceCalculator) CalculateTotalPrice(basePrice float64, checkIn, checkOut time.Time) booking.PriceCalculationResponse {
-	nights := int(checkOut.Sub(checkIn).Hours() / 24)
+// WithDiscount takes the given percentage off the room nights of every
+// booking price the calculator produces.
+func (pc *PriceCalculator) WithDiscount(discount booking.Discount) *PriceCalculator {
+	pc.discount = &discount
+	return pc
+}
+
+func (pc *PriceCalculator) CalculateBookingPrice(basePrice float64, checkIn, checkOut booking.Date, options booking.StayOptions) booking.PriceCalculationResponse {
+	result := pc.CalculateTotalPrice(basePrice, checkIn, checkOut)
+
+	if pc.discount != nil && pc.discount.Rate > 0 {
+		amount := math.Round(result.TotalPrice*pc.discount.Rate*100) / 100
+		result.Extras = append(result.Extras, booking.ExtraCharge{
+			Code:  pc.discount.Code,
+			Name:  pc.discount.Name,
+			Price: -amount,
+		})
+		result.TotalPrice -= amount
+	}
+
+	for _, extra := range pc.calculateExtras(options) {
+		result.Extras = append(result.Extras, extra)
+		result.TotalPrice += extra.Price
+	}
+
+	return result
+}
+
+// withTotal replaces a calculated price with total, e.g. what a channel
+// charged the guest. The total is spread over the nights in proportion to
+// their calculated prices; extras and discounts are part of it.
+func withTotal(result booking.PriceCalculationResponse, total float64) booking.PriceCalculationResponse {
+	var nightsTotal float64
+	for _, day := range result.DailyBreakdown {
+		nightsTotal += day.DayPrice
+	}
+
+	days := make([]booking.DayPriceInfo, len(result.DailyBreakdown))
+	rest := total
+	for i, day := range result.DailyBreakdown {
+		share := total / float64(len(days))
+		if nightsTotal > 0 {
+			share = total * day.DayPrice / nightsTotal
+		}
+		day.DayPrice = math.Round(share*100) / 100
+		if i == len(days)-1 {
+			// The last night takes what rounding left over.
+			day.DayPrice = math.Round(rest*100) / 100
+		}
+		rest -= day.DayPrice
+		days[i] = day
+	}
+
+	result.DailyBreakdown = days
+	result.Extras = nil
+	result.TotalPrice = total
+	return result
+}
+
+func (pc *PriceCalculator) calculateExtras(options booking.StayOptions) []booking.ExtraCharge {
+	var extras []booking.ExtraCharge
+	if options.EarlyCheckIn {
+		extras = append(extras, booking.ExtraCharge{
+			Code:  booking.ExtraEarlyCheckIn,
+			Name:  "Ranniy zaezd s " + pc.policy.EarlyCheckInTime,
+			Price: pc.policy.EarlyCheckInFee,
+		})
+	}
+	if options.LateCheckOut {
+		extras = append(extras, booking.ExtraCharge{
+			Code:  booking.ExtraLateCheckOut,
+			Name:  "Pozdniy vyezd do " + pc.policy.LateCheckOutTime,
+			Price: pc.policy.LateCheckOutFee,
+		})
+	}
+	return extras
+}
+
+func (pc *PriceCalculator) CalculateTotalPrice(basePrice float64, checkIn, checkOut booking.Date) booking.PriceCalculationResponse {
+	nights := checkIn.DaysUntil(checkOut)
 	if nights <= 0 {
 		nights = 1
 	}
@@ -40,7 +120,7 @@ func (pc *PriceCalculator) CalculateTotalPrice(basePrice float64, checkIn, check
 		dayInfo := pc.calculateDayPrice(basePrice, currentDate)
 		breakdown = append(breakdown, dayInfo)
 		totalPrice += dayInfo.DayPrice
-		currentDate = currentDate.AddDate(0, 0, 1)
+		currentDate = currentDate.AddDays(1)
 	}
 
 	return booking.PriceCalculationResponse{
@@ -51,14 +131,17 @@ func (pc *PriceCalculator) CalculateTotalPrice(basePrice float64, checkIn, check
 	}
 }
 
-func (pc *PriceCalculator) calculateDayPrice(basePrice float64, date time.Time) booking.DayPriceInfo {
+func (pc *PriceCalculator) CalculateDayPrice(basePrice float64, date booking.Date) booking.DayPriceInfo {
+	return pc.calculateDayPrice(basePrice, date)
+}
+
+func (pc *PriceCalculator) calculateDayPrice(basePrice float64, date booking.Date) booking.DayPriceInfo {
 	coefficient := 1.0
 	reasons := []string{}
 
-	dateKey := date.Format("2006-01-02")
-	if special, ok := pc.specialDates[dateKey]; ok {
+	if special, ok := pc.specialDates[date.String()]; ok {
 		return booking.DayPriceInfo{
-			Date:        dateKey,
+			Date:        date,
 			BasePrice:   basePrice,
 			Coefficient: special.Coefficient,
 			Reason:      special.Name,
@@ -92,7 +175,7 @@ func (pc *PriceCalculator) calculateDayPrice(basePrice float64, date time.Time)
 	}
 
 	return booking.DayPriceInfo{
-		Date:        dateKey,
+		Date:        date,
 		BasePrice:   basePrice,
 		Coefficient: coefficient,
 		Reason:      reason,
@@ -101,5 +184,5 @@ func (pc *PriceCalculator) calculateDayPrice(basePrice float64, date time.Time)
 }
 
 type PriceService interface {
-	CalculatePrice(ctx context.Context, roomID int64, checkIn, checkOut time.Time) (*booking.PriceCalculationResponse, error)
+	CalculatePrice(ctx context.Context, roomID int64, checkIn, checkOut booking.Date) (*booking.PriceCalculationResponse, error)
 }
@@ -0,0 +1,109 @@
+package booking
+
+import (
+	"math"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+)
+
+func TestCalculateTotalPriceAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	// Stay dates come from local midnights, which sit at different UTC
+	// offsets on either side of the transition.
+	local := func(month time.Month, day int) booking.Date {
+		return booking.DateOf(time.Date(2026, month, day, 0, 0, 0, 0, ny))
+	}
+	pc := NewPriceCalculator(nil, booking.StayPolicy{})
+
+	// Low season: 90 on weekdays, 112.5 on weekends. The stays below contain
+	// the 23-hour March 8 and the 25-hour November 1.
+	tests := []struct {
+		name      string
+		checkIn   booking.Date
+		checkOut  booking.Date
+		wantTotal float64
+		wantDates []string
+	}{
+		{
+			name:      "spring forward",
+			checkIn:   local(time.March, 6),
+			checkOut:  local(time.March, 10),
+			wantTotal: 405,
+			wantDates: []string{"2026-03-06", "2026-03-07", "2026-03-08", "2026-03-09"},
+		},
+		{
+			name:      "fall back",
+			checkIn:   local(time.October, 30),
+			checkOut:  local(time.November, 3),
+			wantTotal: 405,
+			wantDates: []string{"2026-10-30", "2026-10-31", "2026-11-01", "2026-11-02"},
+		},
+		{
+			name:      "single short night",
+			checkIn:   local(time.March, 8),
+			checkOut:  local(time.March, 9),
+			wantTotal: 112.5,
+			wantDates: []string{"2026-03-08"},
+		},
+		{
+			name:      "single long night",
+			checkIn:   local(time.November, 1),
+			checkOut:  local(time.November, 2),
+			wantTotal: 112.5,
+			wantDates: []string{"2026-11-01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pc.CalculateTotalPrice(100, tt.checkIn, tt.checkOut)
+
+			if got.Nights != len(tt.wantDates) {
+				t.Errorf("Nights = %d, want %d", got.Nights, len(tt.wantDates))
+			}
+			if math.Abs(got.TotalPrice-tt.wantTotal) > 1e-9 {
+				t.Errorf("TotalPrice = %v, want %v", got.TotalPrice, tt.wantTotal)
+			}
+			if len(got.DailyBreakdown) != len(tt.wantDates) {
+				t.Fatalf("breakdown has %d nights, want %d", len(got.DailyBreakdown), len(tt.wantDates))
+			}
+			for i, day := range got.DailyBreakdown {
+				if day.Date.String() != tt.wantDates[i] {
+					t.Errorf("night %d = %v, want %s", i, day.Date, tt.wantDates[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWithTotal(t *testing.T) {
+	pc := NewPriceCalculator(nil, booking.StayPolicy{EarlyCheckInFee: 20})
+	// Low season: 90, 90, 112.5 (Saturday) and an early check-in fee.
+	price := pc.CalculateBookingPrice(100, booking.NewDate(2026, time.March, 5), booking.NewDate(2026, time.March, 8), booking.StayOptions{EarlyCheckIn: true})
+
+	got := withTotal(price, 250)
+
+	if got.TotalPrice != 250 {
+		t.Errorf("TotalPrice = %v, want 250", got.TotalPrice)
+	}
+	if len(got.Extras) != 0 {
+		t.Errorf("Extras = %v, want none", got.Extras)
+	}
+	want := []float64{76.92, 76.92, 96.16}
+	var sum float64
+	for i, day := range got.DailyBreakdown {
+		if math.Abs(day.DayPrice-want[i]) > 1e-9 {
+			t.Errorf("night %d = %v, want %v", i, day.DayPrice, want[i])
+		}
+		sum += day.DayPrice
+	}
+	if math.Abs(sum-250) > 1e-9 {
+		t.Errorf("nights add up to %v, want 250", sum)
+	}
+}
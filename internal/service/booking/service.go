@@ -2,40 +2,76 @@ package booking
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"time"
 
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/folio"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/guest"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/loyalty"
 	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
 )
 
 var (
-	ErrRoomNotFound     = errors.New("room not found")
-	ErrRoomNotAvailable = errors.New("room is not available for selected dates")
-	ErrBookingNotFound  = errors.New("booking not found")
-	ErrInvalidDates     = errors.New("invalid booking dates")
-	ErrInvalidGuestInfo = errors.New("invalid guest information")
+	ErrRoomNotFound        = apperr.NotFound("room_not_found", "room not found")
+	ErrRoomArchived        = apperr.Conflict("room_archived", "room is no longer in service")
+	ErrRoomNotAvailable    = apperr.Conflict("room_not_available", "room is not available for selected dates")
+	ErrBookingNotFound     = apperr.NotFound("booking_not_found", "booking not found")
+	ErrInvalidDates        = apperr.Validation("invalid_dates", "invalid booking dates")
+	ErrInvalidGuestInfo    = apperr.Validation("invalid_guest_info", "invalid guest information")
+	ErrBookingClosed       = apperr.Conflict("booking_closed", "booking can no longer be changed")
+	ErrRangeTooLong        = apperr.Validation("range_too_long", "date range is too long")
+	ErrNotMovable          = apperr.Conflict("booking_not_movable", "only pending or confirmed bookings can be moved")
+	ErrNotEnoughPoints     = apperr.Conflict("not_enough_points", "not enough loyalty points")
+	ErrTooManyPoints       = apperr.Validation("too_many_points", "redeemed points exceed the booking price")
+	ErrRedemptionStaffOnly = apperr.Forbidden("redemption_staff_only", "loyalty points can only be redeemed by staff")
+	ErrPointsRefunded      = apperr.Conflict("points_refunded", "the booking was cancelled and its loyalty points refunded; make a new booking instead")
+	ErrInvalidStatusChange = apperr.Conflict("invalid_status_change", "the booking can't be moved to this status")
+	ErrSpecialDateNotFound = apperr.NotFound("special_date_not_found", "special date not found")
+
+	errTooManyGuests = validation.Field("guests", validation.CodeOutOfRange, "exceeds the room capacity")
+
+	ErrEarlyCheckInUnavailable = apperr.Conflict("early_check_in_unavailable", "early check-in is not available: the room is turned over that day")
+	ErrLateCheckOutUnavailable = apperr.Conflict("late_check_out_unavailable", "late checkout is not available: the room is turned over that day")
 )
 
 type Service interface {
+	Location() *time.Location
+	Today() booking.Date
+	GetStayPolicy() booking.StayPolicy
+
 	GetAllRooms(ctx context.Context) ([]booking.Room, error)
 	GetRoomByID(ctx context.Context, id int64) (*booking.Room, error)
 	FindAvailableRooms(ctx context.Context, req booking.RoomSearchRequest) ([]booking.RoomWithAvailability, error)
+	GetAvailability(ctx context.Context, req booking.AvailabilityRequest) (*booking.AvailabilityCalendar, error)
 
 	CreateBooking(ctx context.Context, req booking.CreateBookingRequest) (*booking.BookingResponse, error)
+	CreateStaffBooking(ctx context.Context, req booking.CreateBookingRequest) (*booking.BookingResponse, error)
 	GetBookingByID(ctx context.Context, id int64) (*booking.BookingWithRoom, error)
 	GetAllBookings(ctx context.Context) ([]booking.Booking, error)
 	GetBookingsByEmail(ctx context.Context, email string) ([]booking.Booking, error)
-	ConfirmBooking(ctx context.Context, id int64) (*booking.Booking, error)
-	CancelBooking(ctx context.Context, id int64) (*booking.Booking, error)
+	ListMyBookings(ctx context.Context, filter booking.BookingFilter) (*booking.Page[booking.BookingWithRoom], error)
+	// The booking changes below take the version the client last saw (0 for
+	// an unconditional change) and fail with repository.ErrStaleVersion when
+	// the booking has changed since.
+	ConfirmBooking(ctx context.Context, id, version int64) (*booking.Booking, error)
+	CancelBooking(ctx context.Context, id, version int64) (*booking.Booking, error)
+	// UpdateBookingStatus is the staff override of a booking's status, limited
+	// to the changes booking.BookingStatus.CanBecome allows.
+	UpdateBookingStatus(ctx context.Context, id int64, status booking.BookingStatus, version int64) (*booking.Booking, error)
+	MoveBooking(ctx context.Context, id int64, req booking.MoveBookingRequest, version int64) (*booking.BookingResponse, error)
+	UpdateBooking(ctx context.Context, id int64, patch booking.BookingPatch, version int64) (*booking.Booking, error)
 
 	CalculatePrice(ctx context.Context, req booking.PriceCalculationRequest) (*booking.PriceCalculationResponse, error)
 
 	CreateRoom(ctx context.Context, room *booking.Room) error
-	UpdateRoom(ctx context.Context, room *booking.Room) error
-	DeleteRoom(ctx context.Context, id int64) error
 
 	GetSpecialDates(ctx context.Context) ([]booking.SpecialDate, error)
+	GetSpecialDate(ctx context.Context, id int64) (*booking.SpecialDate, error)
 	CreateSpecialDate(ctx context.Context, sd *booking.SpecialDate) error
+	UpdateSpecialDate(ctx context.Context, id int64, patch booking.SpecialDatePatch, version int64) (*booking.SpecialDate, error)
 	DeleteSpecialDate(ctx context.Context, id int64) error
 }
 
@@ -43,18 +79,63 @@ type service struct {
 	ctx         context.Context
 	repo        repository.Repository
 	roomFactory *RoomFactory
+	location    *time.Location
+	policy      booking.StayPolicy
+	loyalty     loyalty.Policy
 }
 
-func NewService(ctx context.Context, repo repository.Repository) (Service, error) {
+func NewService(ctx context.Context, repo repository.Repository, location *time.Location, policy booking.StayPolicy, loyaltyPolicy loyalty.Policy) (Service, error) {
 	srv := &service{
 		ctx:         ctx,
 		repo:        repo,
 		roomFactory: NewRoomFactory(),
+		location:    location,
+		policy:      policy,
+		loyalty:     loyaltyPolicy,
 	}
 
 	return srv, nil
 }
 
+func (s *service) Location() *time.Location {
+	return s.location
+}
+
+func (s *service) Today() booking.Date {
+	return booking.Today(s.location)
+}
+
+func (s *service) GetStayPolicy() booking.StayPolicy {
+	return s.policy
+}
+
+func (s *service) newPriceCalculator(ctx context.Context, checkIn, checkOut booking.Date) *PriceCalculator {
+	specialDates, _ := s.repo.SpecialDate().GetByDateRange(ctx, checkIn, checkOut)
+	return NewPriceCalculator(specialDates, s.policy)
+}
+
+// guestPriceCalculator is newPriceCalculator with the loyalty tier discount
+// of the given guest applied.
+func (s *service) guestPriceCalculator(ctx context.Context, checkIn, checkOut booking.Date, guestID int64) (*PriceCalculator, error) {
+	calculator := s.newPriceCalculator(ctx, checkIn, checkOut)
+
+	since := s.Today().AddDays(-s.loyalty.QualifyingDays)
+	earned, err := s.repo.Loyalty().GetEarnedSince(ctx, guestID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	tier := s.loyalty.Tier(earned)
+	if rate := s.loyalty.Discount(tier); rate > 0 {
+		calculator.WithDiscount(booking.Discount{
+			Code: "loyalty_" + string(tier),
+			Name: fmt.Sprintf("Skidka %s %.0f%%", tier, rate*100),
+			Rate: rate,
+		})
+	}
+	return calculator, nil
+}
+
 func (s *service) GetAllRooms(ctx context.Context) ([]booking.Room, error) {
 	return s.repo.Room().GetAll(ctx)
 }
@@ -90,8 +171,7 @@ func (s *service) FindAvailableRooms(ctx context.Context, req booking.RoomSearch
 		return nil, err
 	}
 
-	specialDates, _ := s.repo.SpecialDate().GetByDateRange(ctx, req.CheckIn, req.CheckOut)
-	calculator := NewPriceCalculator(specialDates)
+	calculator := s.newPriceCalculator(ctx, req.CheckIn, req.CheckOut)
 
 	result := make([]booking.RoomWithAvailability, len(rooms))
 	for i, room := range rooms {
@@ -106,7 +186,116 @@ func (s *service) FindAvailableRooms(ctx context.Context, req booking.RoomSearch
 	return result, nil
 }
 
+// GetAvailability builds a per-night availability grid for every room in the
+// range [From, To) and aggregates it per room type.
+func (s *service) GetAvailability(ctx context.Context, req booking.AvailabilityRequest) (*booking.AvailabilityCalendar, error) {
+	if req.From.IsZero() || req.To.IsZero() || !req.From.Before(req.To) {
+		return nil, ErrInvalidDates
+	}
+	if req.From.DaysUntil(req.To) > booking.MaxAvailabilityNights {
+		return nil, ErrRangeTooLong
+	}
+
+	nights, err := s.repo.Room().GetNightlyAvailability(ctx, req.RoomType, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	calculator := s.newPriceCalculator(ctx, req.From, req.To)
+	today := s.Today()
+
+	calendar := &booking.AvailabilityCalendar{
+		From:      req.From,
+		To:        req.To,
+		Rooms:     []booking.RoomAvailability{},
+		RoomTypes: []booking.RoomTypeAvailability{},
+	}
+	roomIndex := make(map[int64]int)
+	typeIndex := make(map[booking.RoomType]int)
+
+	for _, n := range nights {
+		price := calculator.CalculateDayPrice(n.Room.BasePrice, n.Date)
+		night := booking.NightAvailability{
+			Date:        n.Date,
+			Available:   true,
+			Price:       price.DayPrice,
+			PriceReason: price.Reason,
+		}
+		switch {
+		case n.MaintenanceKind != "":
+			night.Available = false
+			night.Restriction = booking.Restriction(n.MaintenanceKind)
+			night.Note = n.MaintenanceReason
+		case n.Booked:
+			night.Available = false
+			night.Restriction = booking.RestrictionBooked
+		case n.Date.Before(today):
+			night.Available = false
+			night.Restriction = booking.RestrictionPast
+		}
+
+		i, ok := roomIndex[n.Room.ID]
+		if !ok {
+			i = len(calendar.Rooms)
+			roomIndex[n.Room.ID] = i
+			calendar.Rooms = append(calendar.Rooms, booking.RoomAvailability{Room: n.Room})
+		}
+		calendar.Rooms[i].Nights = append(calendar.Rooms[i].Nights, night)
+
+		t, ok := typeIndex[n.Room.RoomType]
+		if !ok {
+			t = len(calendar.RoomTypes)
+			typeIndex[n.Room.RoomType] = t
+			calendar.RoomTypes = append(calendar.RoomTypes, booking.RoomTypeAvailability{RoomType: n.Room.RoomType})
+		}
+		addRoomTypeNight(&calendar.RoomTypes[t], night)
+	}
+
+	return calendar, nil
+}
+
+// addRoomTypeNight folds one room night into the aggregate for its room type.
+// Rows arrive ordered by date within each room, so the aggregate is indexed by
+// the night's offset from the first date.
+func addRoomTypeNight(rt *booking.RoomTypeAvailability, night booking.NightAvailability) {
+	var agg *booking.RoomTypeNight
+	if len(rt.Nights) > 0 {
+		if offset := rt.Nights[0].Date.DaysUntil(night.Date); offset >= 0 && offset < len(rt.Nights) {
+			agg = &rt.Nights[offset]
+		}
+	}
+	if agg == nil {
+		rt.Nights = append(rt.Nights, booking.RoomTypeNight{Date: night.Date})
+		agg = &rt.Nights[len(rt.Nights)-1]
+	}
+
+	agg.Total++
+	switch {
+	case night.Available:
+		agg.Available++
+		if agg.MinPrice == 0 || night.Price < agg.MinPrice {
+			agg.MinPrice = night.Price
+		}
+	case night.Restriction != booking.RestrictionBooked && night.Restriction != booking.RestrictionPast:
+		agg.Restricted++
+	}
+}
+
+// CreateBooking books a stay for anyone. Points are only spent through
+// CreateStaffBooking: the guest is found by the email in the request, so
+// spending them needs staff who have checked who the guest is.
 func (s *service) CreateBooking(ctx context.Context, req booking.CreateBookingRequest) (*booking.BookingResponse, error) {
+	if req.RedeemPoints > 0 {
+		return nil, ErrRedemptionStaffOnly
+	}
+	return s.createBooking(ctx, req)
+}
+
+func (s *service) CreateStaffBooking(ctx context.Context, req booking.CreateBookingRequest) (*booking.BookingResponse, error) {
+	return s.createBooking(ctx, req)
+}
+
+func (s *service) createBooking(ctx context.Context, req booking.CreateBookingRequest) (*booking.BookingResponse, error) {
 	if req.StartDate.IsZero() || req.EndDate.IsZero() || req.EndDate.Before(req.StartDate) {
 		return nil, ErrInvalidDates
 	}
@@ -114,6 +303,9 @@ func (s *service) CreateBooking(ctx context.Context, req booking.CreateBookingRe
 	if req.GuestInfo.Name == "" || req.GuestInfo.Email == "" {
 		return nil, ErrInvalidGuestInfo
 	}
+	if req.StartDate.Before(s.Today()) {
+		return nil, validation.Field("start_date", validation.CodeInPast, "must not be in the past")
+	}
 
 	room, err := s.repo.Room().GetByID(ctx, req.RoomID)
 	if err != nil {
@@ -122,6 +314,13 @@ func (s *service) CreateBooking(ctx context.Context, req booking.CreateBookingRe
 	if room == nil {
 		return nil, ErrRoomNotFound
 	}
+	if room.IsArchived() {
+		return nil, ErrRoomArchived
+	}
+	guests := max(req.Guests, 1)
+	if guests > room.Capacity {
+		return nil, errTooManyGuests
+	}
 
 	available, err := s.repo.Booking().IsRoomAvailable(ctx, req.RoomID, req.StartDate, req.EndDate)
 	if err != nil {
@@ -131,20 +330,81 @@ func (s *service) CreateBooking(ctx context.Context, req booking.CreateBookingRe
 		return nil, ErrRoomNotAvailable
 	}
 
-	specialDates, _ := s.repo.SpecialDate().GetByDateRange(ctx, req.StartDate, req.EndDate)
-	calculator := NewPriceCalculator(specialDates)
-	priceInfo := calculator.CalculateTotalPrice(room.BasePrice, req.StartDate, req.EndDate)
+	if err := s.checkTurnover(ctx, req.RoomID, req.StartDate, req.EndDate, req.StayOptions, 0); err != nil {
+		return nil, err
+	}
+
+	g, err := s.resolveGuest(ctx, req.GuestInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	calculator, err := s.guestPriceCalculator(ctx, req.StartDate, req.EndDate, g.ID)
+	if err != nil {
+		return nil, err
+	}
+	priceInfo := calculator.CalculateBookingPrice(room.BasePrice, req.StartDate, req.EndDate, req.StayOptions)
+	if req.Total > 0 {
+		priceInfo = withTotal(priceInfo, req.Total)
+	}
 
 	newBooking := &booking.Booking{
-		StartDate: req.StartDate,
-		EndDate:   req.EndDate,
-		RoomID:    req.RoomID,
-		GuestInfo: req.GuestInfo,
-		Price:     priceInfo.TotalPrice,
-		Status:    booking.BookingStatusPending,
+		StartDate:    req.StartDate,
+		EndDate:      req.EndDate,
+		RoomID:       req.RoomID,
+		GuestInfo:    req.GuestInfo,
+		GuestID:      &g.ID,
+		Guests:       guests,
+		Price:        priceInfo.TotalPrice,
+		Status:       booking.BookingStatusPending,
+		EarlyCheckIn: req.EarlyCheckIn,
+		LateCheckOut: req.LateCheckOut,
+		CheckInTime:  s.policy.ArrivalTime(req.EarlyCheckIn),
+		CheckOutTime: s.policy.DepartureTime(req.LateCheckOut),
+	}
+
+	if req.Confirmed {
+		newBooking.Status = booking.BookingStatusConfirmed
+	}
+
+	if req.RedeemPoints > 0 {
+		balance, err := s.repo.Loyalty().GetBalance(ctx, g.ID)
+		if err != nil {
+			return nil, err
+		}
+		if req.RedeemPoints > balance {
+			return nil, ErrNotEnoughPoints
+		}
+		amount := s.loyalty.Value(req.RedeemPoints)
+		if amount > newBooking.Price {
+			return nil, ErrTooManyPoints
+		}
+		newBooking.PointsRedeemed = req.RedeemPoints
+		newBooking.PointsAmount = amount
 	}
 
-	if err := s.repo.Booking().Create(ctx, newBooking); err != nil {
+	// The booking, its charges and the points it spends are written together.
+	err = s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		if err := repo.Booking().Create(ctx, newBooking); err != nil {
+			return err
+		}
+		if err := repo.Folio().ReplaceCharges(ctx, newBooking.ID, folio.StayCharges(newBooking.ID, priceInfo)); err != nil {
+			return err
+		}
+		if newBooking.PointsRedeemed == 0 {
+			return nil
+		}
+
+		bookingID := newBooking.ID
+		return repo.Loyalty().Redeem(ctx, &loyalty.LedgerEntry{
+			GuestID:   g.ID,
+			BookingID: &bookingID,
+			Type:      loyalty.EntryTypeRedeem,
+			Points:    -newBooking.PointsRedeemed,
+			Reason:    fmt.Sprintf("Oplata bronirovaniya #%d", newBooking.ID),
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -155,6 +415,77 @@ func (s *service) CreateBooking(ctx context.Context, req booking.CreateBookingRe
 	}, nil
 }
 
+// resolveGuest finds the guest profile matching the booking's contact details
+// by normalized email, then by phone, and creates one if there is none.
+func (s *service) resolveGuest(ctx context.Context, info booking.GuestInfo) (*guest.Guest, error) {
+	email := guest.NormalizeEmail(info.Email)
+	phone := guest.NormalizePhone(info.Phone)
+
+	g, err := s.repo.Guest().GetByNormalizedEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil && phone != "" {
+		if g, err = s.repo.Guest().GetByNormalizedPhone(ctx, phone); err != nil {
+			return nil, err
+		}
+	}
+
+	if g == nil {
+		g = &guest.Guest{
+			Name:            info.Name,
+			Email:           info.Email,
+			Phone:           info.Phone,
+			NormalizedEmail: email,
+			NormalizedPhone: phone,
+		}
+		return g, s.repo.Guest().Create(ctx, g)
+	}
+
+	if g.NormalizedPhone == "" && phone != "" {
+		g.Phone = info.Phone
+		g.NormalizedPhone = phone
+		if err := s.repo.Guest().Update(ctx, g); err != nil {
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// checkTurnover rejects stays whose arrival or departure collides with another
+// guest leaving or arriving the same day when either side has asked for an
+// early check-in or a late checkout. The booking with exceptID, the one being
+// moved, is not counted as another guest.
+func (s *service) checkTurnover(ctx context.Context, roomID int64, startDate, endDate booking.Date, options booking.StayOptions, exceptID int64) error {
+	departure, err := s.repo.Booking().GetDepartureForRoom(ctx, roomID, startDate, exceptID)
+	if err != nil {
+		return err
+	}
+	if departure != nil {
+		if options.EarlyCheckIn {
+			return ErrEarlyCheckInUnavailable
+		}
+		if departure.LateCheckOut {
+			return ErrRoomNotAvailable
+		}
+	}
+
+	arrival, err := s.repo.Booking().GetArrivalForRoom(ctx, roomID, endDate, exceptID)
+	if err != nil {
+		return err
+	}
+	if arrival != nil {
+		if options.LateCheckOut {
+			return ErrLateCheckOutUnavailable
+		}
+		if arrival.EarlyCheckIn {
+			return ErrRoomNotAvailable
+		}
+	}
+
+	return nil
+}
+
 func (s *service) GetBookingByID(ctx context.Context, id int64) (*booking.BookingWithRoom, error) {
 	b, err := s.repo.Booking().GetByID(ctx, id)
 	if err != nil {
@@ -183,7 +514,21 @@ func (s *service) GetBookingsByEmail(ctx context.Context, email string) ([]booki
 	return s.repo.Booking().GetByEmail(ctx, email)
 }
 
-func (s *service) ConfirmBooking(ctx context.Context, id int64) (*booking.Booking, error) {
+// ListMyBookings pages through the bookings of the guest with filter.GuestEmail.
+func (s *service) ListMyBookings(ctx context.Context, filter booking.BookingFilter) (*booking.Page[booking.BookingWithRoom], error) {
+	if filter.GuestEmail == "" {
+		return nil, ErrInvalidGuestInfo
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, ErrInvalidDates
+	}
+	filter.Normalize("start_date", booking.SortDesc)
+	return s.repo.Booking().List(ctx, filter)
+}
+
+// getBooking loads a booking that the client wants to change at the given
+// version.
+func (s *service) getBooking(ctx context.Context, id, version int64) (*booking.Booking, error) {
 	b, err := s.repo.Booking().GetByID(ctx, id)
 	if err != nil {
 		return nil, err
@@ -191,25 +536,130 @@ func (s *service) ConfirmBooking(ctx context.Context, id int64) (*booking.Bookin
 	if b == nil {
 		return nil, ErrBookingNotFound
 	}
+	if err := repository.CheckVersion(b.Version, version); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
 
-	if err := s.repo.Booking().UpdateStatus(ctx, id, booking.BookingStatusConfirmed); err != nil {
+func (s *service) ConfirmBooking(ctx context.Context, id, version int64) (*booking.Booking, error) {
+	b, err := s.getBooking(ctx, id, version)
+	if err != nil {
 		return nil, err
 	}
+	if isClosed(b.Status) {
+		return nil, ErrBookingClosed
+	}
+	return s.changeStatus(ctx, b, booking.BookingStatusConfirmed)
+}
 
-	b.Status = booking.BookingStatusConfirmed
-	return b, nil
+func (s *service) CancelBooking(ctx context.Context, id, version int64) (*booking.Booking, error) {
+	b, err := s.getBooking(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if isClosed(b.Status) {
+		return nil, ErrBookingClosed
+	}
+	return s.changeStatus(ctx, b, booking.BookingStatusCancelled)
 }
 
-func (s *service) CancelBooking(ctx context.Context, id int64) (*booking.Booking, error) {
-	b, err := s.repo.Booking().GetByID(ctx, id)
+func (s *service) UpdateBookingStatus(ctx context.Context, id int64, status booking.BookingStatus, version int64) (*booking.Booking, error) {
+	b, err := s.getBooking(ctx, id, version)
 	if err != nil {
 		return nil, err
 	}
-	if b == nil {
-		return nil, ErrBookingNotFound
+	return s.changeStatus(ctx, b, status)
+}
+
+// changeStatus moves a booking to status if booking.BookingStatus.CanBecome
+// allows it. A booking that takes its room again is checked like a new one,
+// since the nights may have been sold meanwhile.
+func (s *service) changeStatus(ctx context.Context, b *booking.Booking, status booking.BookingStatus) (*booking.Booking, error) {
+	if b.Status == status {
+		return b, nil
+	}
+	if !b.Status.CanBecome(status) {
+		return nil, ErrInvalidStatusChange
+	}
+	if status == booking.BookingStatusCancelled {
+		return s.cancel(ctx, b)
+	}
+
+	if !b.Status.HoldsRoom() {
+		if err := checkPointsNotRefunded(b); err != nil {
+			return nil, err
+		}
+		if err := s.checkRoomFree(ctx, b); err != nil {
+			return nil, err
+		}
 	}
 
-	if err := s.repo.Booking().UpdateStatus(ctx, id, booking.BookingStatusCancelled); err != nil {
+	if err := s.repo.Booking().UpdateStatus(ctx, b, status); err != nil {
+		return nil, err
+	}
+
+	b.Status = status
+	return b, nil
+}
+
+// checkRoomFree checks that the room of a booking that does not hold it can
+// take the booking's nights again.
+func (s *service) checkRoomFree(ctx context.Context, b *booking.Booking) error {
+	room, err := s.repo.Room().GetByID(ctx, b.RoomID)
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		return ErrRoomNotFound
+	}
+	if room.IsArchived() {
+		return ErrRoomArchived
+	}
+
+	available, err := s.repo.Booking().IsRoomAvailableExcept(ctx, b.RoomID, b.StartDate, b.EndDate, b.ID)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return ErrRoomNotAvailable
+	}
+	options := booking.StayOptions{EarlyCheckIn: b.EarlyCheckIn, LateCheckOut: b.LateCheckOut}
+	return s.checkTurnover(ctx, b.RoomID, b.StartDate, b.EndDate, options, b.ID)
+}
+
+// checkPointsNotRefunded refuses to bring back a cancelled booking whose
+// redeemed points were refunded: it would keep the discount for free.
+func checkPointsNotRefunded(b *booking.Booking) error {
+	if b.Status == booking.BookingStatusCancelled && b.PointsRedeemed > 0 {
+		return ErrPointsRefunded
+	}
+	return nil
+}
+
+func (s *service) cancel(ctx context.Context, b *booking.Booking) (*booking.Booking, error) {
+	// The cancellation and the refund of the redeemed points are written
+	// together, so the guest can't lose the points.
+	err := s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		if err := repo.Booking().UpdateStatus(ctx, b, booking.BookingStatusCancelled); err != nil {
+			return err
+		}
+		if b.PointsRedeemed == 0 || b.GuestID == nil {
+			return nil
+		}
+
+		expires := s.loyalty.ExpiresOn(s.Today())
+		return repo.Loyalty().AddEntry(ctx, &loyalty.LedgerEntry{
+			GuestID:   *b.GuestID,
+			BookingID: &b.ID,
+			Type:      loyalty.EntryTypeRefund,
+			Points:    b.PointsRedeemed,
+			Remaining: b.PointsRedeemed,
+			ExpiresAt: &expires,
+			Reason:    fmt.Sprintf("Otmena bronirovaniya #%d", b.ID),
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -217,6 +667,123 @@ func (s *service) CancelBooking(ctx context.Context, id int64) (*booking.Booking
 	return b, nil
 }
 
+// MoveBooking reassigns a booking to another room and/or dates after
+// re-checking availability, and reprices the stay for its new room and nights.
+func (s *service) MoveBooking(ctx context.Context, id int64, req booking.MoveBookingRequest, version int64) (*booking.BookingResponse, error) {
+	b, err := s.getBooking(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if b.Status != booking.BookingStatusPending && b.Status != booking.BookingStatusConfirmed {
+		return nil, ErrNotMovable
+	}
+
+	roomID := b.RoomID
+	if req.RoomID > 0 {
+		roomID = req.RoomID
+	}
+	startDate, endDate := b.StartDate, b.EndDate
+	if !req.StartDate.IsZero() {
+		startDate = req.StartDate
+		endDate = startDate.AddDays(b.StartDate.DaysUntil(b.EndDate))
+	}
+	if !req.EndDate.IsZero() {
+		endDate = req.EndDate
+	}
+	if !startDate.Before(endDate) {
+		return nil, ErrInvalidDates
+	}
+	if !req.StartDate.IsZero() && req.StartDate.Before(s.Today()) {
+		return nil, validation.Field("start_date", validation.CodeInPast, "must not be in the past")
+	}
+
+	room, err := s.repo.Room().GetByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	if room.IsArchived() {
+		return nil, ErrRoomArchived
+	}
+	if b.Guests > room.Capacity {
+		return nil, errTooManyGuests
+	}
+
+	available, err := s.repo.Booking().IsRoomAvailableExcept(ctx, roomID, startDate, endDate, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, ErrRoomNotAvailable
+	}
+
+	options := booking.StayOptions{EarlyCheckIn: b.EarlyCheckIn, LateCheckOut: b.LateCheckOut}
+	if err := s.checkTurnover(ctx, roomID, startDate, endDate, options, b.ID); err != nil {
+		return nil, err
+	}
+
+	calculator := s.newPriceCalculator(ctx, startDate, endDate)
+	if b.GuestID != nil {
+		if calculator, err = s.guestPriceCalculator(ctx, startDate, endDate, *b.GuestID); err != nil {
+			return nil, err
+		}
+	}
+	priceInfo := calculator.CalculateBookingPrice(room.BasePrice, startDate, endDate, options)
+	if req.Total > 0 {
+		priceInfo = withTotal(priceInfo, req.Total)
+	}
+
+	// The points already spent on the booking must not exceed its new price.
+	if priceInfo.TotalPrice < b.PointsAmount {
+		return nil, ErrTooManyPoints
+	}
+
+	b.RoomID = roomID
+	b.StartDate = startDate
+	b.EndDate = endDate
+	b.Price = priceInfo.TotalPrice
+	err = s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		if err := repo.Booking().Update(ctx, b); err != nil {
+			return err
+		}
+		return repo.Folio().ReplaceCharges(ctx, b.ID, folio.StayCharges(b.ID, priceInfo))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &booking.BookingResponse{
+		Booking: *b,
+		Room:    *room,
+		Nights:  priceInfo.Nights,
+	}, nil
+}
+
+// UpdateBooking applies a partial change of the guest details.
+func (s *service) UpdateBooking(ctx context.Context, id int64, patch booking.BookingPatch, version int64) (*booking.Booking, error) {
+	b, err := s.getBooking(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := patch.Apply(b); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Booking().Update(ctx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func isClosed(status booking.BookingStatus) bool {
+	switch status {
+	case booking.BookingStatusCheckedIn, booking.BookingStatusCheckedOut, booking.BookingStatusNoShow:
+		return true
+	}
+	return false
+}
+
 func (s *service) CalculatePrice(ctx context.Context, req booking.PriceCalculationRequest) (*booking.PriceCalculationResponse, error) {
 	if req.CheckIn.IsZero() || req.CheckOut.IsZero() || req.CheckOut.Before(req.CheckIn) {
 		return nil, ErrInvalidDates
@@ -230,9 +797,19 @@ func (s *service) CalculatePrice(ctx context.Context, req booking.PriceCalculati
 		return nil, ErrRoomNotFound
 	}
 
-	specialDates, _ := s.repo.SpecialDate().GetByDateRange(ctx, req.CheckIn, req.CheckOut)
-	calculator := NewPriceCalculator(specialDates)
-	priceInfo := calculator.CalculateTotalPrice(room.BasePrice, req.CheckIn, req.CheckOut)
+	calculator := s.newPriceCalculator(ctx, req.CheckIn, req.CheckOut)
+	if req.GuestEmail != "" {
+		g, err := s.repo.Guest().GetByNormalizedEmail(ctx, guest.NormalizeEmail(req.GuestEmail))
+		if err != nil {
+			return nil, err
+		}
+		if g != nil {
+			if calculator, err = s.guestPriceCalculator(ctx, req.CheckIn, req.CheckOut, g.ID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	priceInfo := calculator.CalculateBookingPrice(room.BasePrice, req.CheckIn, req.CheckOut, req.StayOptions)
 
 	return &priceInfo, nil
 }
@@ -241,22 +818,42 @@ func (s *service) CreateRoom(ctx context.Context, room *booking.Room) error {
 	return s.repo.Room().Create(ctx, room)
 }
 
-func (s *service) UpdateRoom(ctx context.Context, room *booking.Room) error {
-	return s.repo.Room().Update(ctx, room)
-}
-
-func (s *service) DeleteRoom(ctx context.Context, id int64) error {
-	return s.repo.Room().Delete(ctx, id)
-}
-
 func (s *service) GetSpecialDates(ctx context.Context) ([]booking.SpecialDate, error) {
 	return s.repo.SpecialDate().GetAll(ctx)
 }
 
+func (s *service) GetSpecialDate(ctx context.Context, id int64) (*booking.SpecialDate, error) {
+	sd, err := s.repo.SpecialDate().GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sd == nil {
+		return nil, ErrSpecialDateNotFound
+	}
+	return sd, nil
+}
+
 func (s *service) CreateSpecialDate(ctx context.Context, sd *booking.SpecialDate) error {
 	return s.repo.SpecialDate().Create(ctx, sd)
 }
 
+func (s *service) UpdateSpecialDate(ctx context.Context, id int64, patch booking.SpecialDatePatch, version int64) (*booking.SpecialDate, error) {
+	sd, err := s.GetSpecialDate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := repository.CheckVersion(sd.Version, version); err != nil {
+		return nil, err
+	}
+	if err := patch.Apply(sd); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SpecialDate().Update(ctx, sd); err != nil {
+		return nil, err
+	}
+	return sd, nil
+}
+
 func (s *service) DeleteSpecialDate(ctx context.Context, id int64) error {
 	return s.repo.SpecialDate().Delete(ctx, id)
 }
@@ -0,0 +1,374 @@
+package calendar
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/ical"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/calendar"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+)
+
+const (
+	prodID = "-//booking-system//Hotel Booking System//RU"
+	// uidDomain makes event UIDs globally unique as RFC 5545 requires.
+	uidDomain = "booking-system"
+	// exportPastDays keeps recently ended stays in the export feed so that
+	// partners do not see them disappear mid-checkout.
+	exportPastDays   = 30
+	exportPathPrefix = "/ical/rooms/"
+)
+
+var (
+	ErrRoomNotFound     = apperr.NotFound("room_not_found", "room not found")
+	ErrBookingNotFound  = apperr.NotFound("booking_not_found", "booking not found")
+	ErrCalendarNotFound = apperr.NotFound("calendar_not_found", "calendar not found")
+	ErrFeedNotFound     = apperr.NotFound("calendar_feed_not_found", "calendar feed not found")
+	ErrFeedUnavailable  = apperr.Unavailable("calendar_feed_unavailable", "external calendar could not be loaded")
+)
+
+type Service interface {
+	// BookingEvent renders the stay as an iCalendar event for the guest.
+	BookingEvent(b *booking.Booking, room *booking.Room) []byte
+	GetBookingEvent(ctx context.Context, bookingID int64) ([]byte, error)
+
+	// GetRoomFeed renders the blocked dates of the room holding the export
+	// token: its bookings and maintenance windows, without guest details.
+	GetRoomFeed(ctx context.Context, token string) ([]byte, error)
+	GetRoomCalendar(ctx context.Context, roomID int64) (*calendar.RoomCalendar, error)
+	// RotateExportToken invalidates the room's export URL and issues a new one.
+	RotateExportToken(ctx context.Context, roomID int64) (*calendar.RoomCalendar, error)
+
+	GetFeeds(ctx context.Context, roomID int64) ([]calendar.Feed, error)
+	// CreateFeed subscribes the room to an external calendar and syncs it
+	// right away; a failed first sync is reported on the feed.
+	CreateFeed(ctx context.Context, req calendar.CreateFeedRequest) (*calendar.Feed, error)
+	DeleteFeed(ctx context.Context, id int64) error
+	SyncFeed(ctx context.Context, id int64) (*calendar.Feed, error)
+	SyncAll(ctx context.Context) (int, error)
+	StartSyncWorker(ctx context.Context)
+}
+
+type service struct {
+	ctx          context.Context
+	repo         repository.Repository
+	location     *time.Location
+	hotelName    string
+	syncInterval time.Duration
+	client       *http.Client
+}
+
+func NewService(ctx context.Context, repo repository.Repository, location *time.Location, hotelName string, syncInterval time.Duration) (Service, error) {
+	return &service{
+		ctx:          ctx,
+		repo:         repo,
+		location:     location,
+		hotelName:    hotelName,
+		syncInterval: syncInterval,
+		client:       &http.Client{Timeout: fetchTimeout},
+	}, nil
+}
+
+func (s *service) BookingEvent(b *booking.Booking, room *booking.Room) []byte {
+	event := ical.Event{
+		UID:         fmt.Sprintf("booking-%d@%s", b.ID, uidDomain),
+		Sequence:    b.Version,
+		Stamp:       b.UpdatedAt,
+		Summary:     fmt.Sprintf("%s, номер %s", s.hotelName, room.RoomNumber),
+		Description: s.bookingDescription(b, room),
+		Location:    s.hotelName,
+		Status:      eventStatus(b.Status),
+	}
+
+	checkIn, errIn := time.Parse("15:04", b.CheckInTime)
+	checkOut, errOut := time.Parse("15:04", b.CheckOutTime)
+	if errIn == nil && errOut == nil {
+		event.Start = atTime(b.StartDate, checkIn, s.location)
+		event.End = atTime(b.EndDate, checkOut, s.location)
+	} else {
+		event.AllDay = true
+		event.Start = b.StartDate.In(time.UTC)
+		event.End = b.EndDate.In(time.UTC)
+	}
+
+	return ical.Calendar{ProdID: prodID, Method: "PUBLISH", Events: []ical.Event{event}}.Marshal()
+}
+
+func (s *service) bookingDescription(b *booking.Booking, room *booking.Room) string {
+	description := fmt.Sprintf("Бронирование #%d\nНомер: %s\nЗаезд: %s", b.ID, room.RoomNumber, b.StartDate.Format("02.01.2006"))
+	if b.CheckInTime != "" {
+		description += " с " + b.CheckInTime
+	}
+	description += "\nВыезд: " + b.EndDate.Format("02.01.2006")
+	if b.CheckOutTime != "" {
+		description += " до " + b.CheckOutTime
+	}
+	return description + fmt.Sprintf("\nГостей: %d", b.Guests)
+}
+
+func eventStatus(status booking.BookingStatus) string {
+	switch status {
+	case booking.BookingStatusPending:
+		return ical.StatusTentative
+	case booking.BookingStatusCancelled, booking.BookingStatusNoShow:
+		return ical.StatusCancelled
+	}
+	return ical.StatusConfirmed
+}
+
+// atTime returns the moment of a "15:04" time of day on date in loc.
+func atTime(date booking.Date, clock time.Time, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+}
+
+func (s *service) GetBookingEvent(ctx context.Context, bookingID int64) ([]byte, error) {
+	b, err := s.repo.Booking().GetByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrBookingNotFound
+	}
+	room, err := s.repo.Room().GetByID(ctx, b.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	return s.BookingEvent(b, room), nil
+}
+
+func (s *service) GetRoomFeed(ctx context.Context, token string) ([]byte, error) {
+	roomID, err := s.repo.Calendar().GetRoomIDByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if roomID == 0 {
+		return nil, ErrCalendarNotFound
+	}
+	room, err := s.repo.Room().GetByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrCalendarNotFound
+	}
+
+	bookings, err := s.repo.Booking().GetByRoomID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	windows, err := s.repo.Maintenance().GetByRoomID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := booking.Today(s.location).AddDays(-exportPastDays)
+	now := time.Now()
+	var events []ical.Event
+	for _, b := range bookings {
+		if b.Status == booking.BookingStatusCancelled || b.Status == booking.BookingStatusNoShow || !b.EndDate.After(since) {
+			continue
+		}
+		events = append(events, ical.Event{
+			UID:      fmt.Sprintf("booking-%d@%s", b.ID, uidDomain),
+			Sequence: b.Version,
+			Stamp:    now,
+			Start:    b.StartDate.In(time.UTC),
+			End:      b.EndDate.In(time.UTC),
+			AllDay:   true,
+			Summary:  "Занято",
+			Status:   ical.StatusConfirmed,
+		})
+	}
+	for _, w := range windows {
+		// Dates blocked by other channels are theirs to publish.
+		if w.Kind == booking.MaintenanceKindExternal || !w.EndDate.After(since) {
+			continue
+		}
+		events = append(events, ical.Event{
+			UID:     fmt.Sprintf("block-%d@%s", w.ID, uidDomain),
+			Stamp:   now,
+			Start:   w.StartDate.In(time.UTC),
+			End:     w.EndDate.In(time.UTC),
+			AllDay:  true,
+			Summary: "Недоступен",
+			Status:  ical.StatusConfirmed,
+		})
+	}
+
+	return ical.Calendar{
+		ProdID: prodID,
+		Name:   fmt.Sprintf("%s, номер %s", s.hotelName, room.RoomNumber),
+		Events: events,
+	}.Marshal(), nil
+}
+
+func (s *service) GetRoomCalendar(ctx context.Context, roomID int64) (*calendar.RoomCalendar, error) {
+	if err := s.checkRoom(ctx, roomID); err != nil {
+		return nil, err
+	}
+
+	token, err := s.repo.Calendar().GetExportToken(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		if token, err = s.issueToken(ctx, roomID); err != nil {
+			return nil, err
+		}
+	}
+	return s.roomCalendar(ctx, roomID, token)
+}
+
+func (s *service) RotateExportToken(ctx context.Context, roomID int64) (*calendar.RoomCalendar, error) {
+	if err := s.checkRoom(ctx, roomID); err != nil {
+		return nil, err
+	}
+
+	token, err := s.issueToken(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return s.roomCalendar(ctx, roomID, token)
+}
+
+func (s *service) checkRoom(ctx context.Context, roomID int64) error {
+	room, err := s.repo.Room().GetByID(ctx, roomID)
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		return ErrRoomNotFound
+	}
+	return nil
+}
+
+func (s *service) issueToken(ctx context.Context, roomID int64) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buf)
+	if err := s.repo.Calendar().SetExportToken(ctx, roomID, token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *service) roomCalendar(ctx context.Context, roomID int64, token string) (*calendar.RoomCalendar, error) {
+	feeds, err := s.GetFeeds(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return &calendar.RoomCalendar{
+		RoomID:     roomID,
+		ExportPath: exportPathPrefix + token + ".ics",
+		Feeds:      feeds,
+	}, nil
+}
+
+func (s *service) GetFeeds(ctx context.Context, roomID int64) ([]calendar.Feed, error) {
+	feeds, err := s.repo.Calendar().GetFeeds(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if feeds == nil {
+		feeds = []calendar.Feed{}
+	}
+	return feeds, nil
+}
+
+func (s *service) CreateFeed(ctx context.Context, req calendar.CreateFeedRequest) (*calendar.Feed, error) {
+	if err := s.checkRoom(ctx, req.RoomID); err != nil {
+		return nil, err
+	}
+
+	feed := &calendar.Feed{RoomID: req.RoomID, Name: req.Name, URL: req.URL}
+	if err := s.repo.Calendar().CreateFeed(ctx, feed); err != nil {
+		return nil, err
+	}
+
+	// The subscription stands even if the first download fails; the error
+	// is shown on the feed and the worker retries.
+	if err := s.sync(ctx, feed); err != nil && !errors.Is(err, ErrFeedUnavailable) {
+		return nil, err
+	}
+	return s.getFeed(ctx, feed.ID)
+}
+
+func (s *service) DeleteFeed(ctx context.Context, id int64) error {
+	err := s.repo.Calendar().DeleteFeed(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrFeedNotFound
+	}
+	return err
+}
+
+func (s *service) SyncFeed(ctx context.Context, id int64) (*calendar.Feed, error) {
+	feed, err := s.getFeed(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.sync(ctx, feed); err != nil {
+		return nil, err
+	}
+	return s.getFeed(ctx, id)
+}
+
+func (s *service) getFeed(ctx context.Context, id int64) (*calendar.Feed, error) {
+	feed, err := s.repo.Calendar().GetFeedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if feed == nil {
+		return nil, ErrFeedNotFound
+	}
+	return feed, nil
+}
+
+// SyncAll refreshes every feed and returns how many failed.
+func (s *service) SyncAll(ctx context.Context) (int, error) {
+	feeds, err := s.repo.Calendar().GetFeeds(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	failed := 0
+	for i := range feeds {
+		if err := s.sync(ctx, &feeds[i]); err != nil {
+			fmt.Printf(" Calendar feed %d (%s) sync failed: %v\n", feeds[i].ID, feeds[i].Name, err)
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+func (s *service) StartSyncWorker(ctx context.Context) {
+	go func() {
+		fmt.Println(" Calendar feed sync worker started")
+		ticker := time.NewTicker(s.syncInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.SyncAll(ctx); err != nil {
+				fmt.Printf(" Calendar feed sync failed: %v\n", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				fmt.Println(" Calendar feed sync worker stopping...")
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,104 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/ical"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/calendar"
+)
+
+const (
+	fetchTimeout = 30 * time.Second
+	maxFeedSize  = 5 << 20
+	// importHorizonDays ignores events too far ahead to matter for sales.
+	importHorizonDays = 2 * 365
+)
+
+// sync downloads the feed and replaces its blocks. When the download fails
+// the error is recorded on the feed and the previous blocks stay in place.
+func (s *service) sync(ctx context.Context, feed *calendar.Feed) error {
+	events, err := s.fetch(ctx, feed.URL)
+	if err != nil {
+		if err := s.repo.Calendar().SetFeedError(ctx, feed.ID, err.Error()); err != nil {
+			return err
+		}
+		return ErrFeedUnavailable.Wrap(err)
+	}
+	return s.repo.Calendar().ReplaceFeedBlocks(ctx, feed.ID, s.blocks(feed, events), time.Now())
+}
+
+func (s *service) fetch(ctx context.Context, rawURL string) ([]ical.Event, error) {
+	u, err := calendar.FetchURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxFeedSize {
+		return nil, fmt.Errorf("calendar is larger than %d bytes", maxFeedSize)
+	}
+	return ical.Parse(bytes.NewReader(body), s.location)
+}
+
+// blocks turns the busy events of a feed into the nights they occupy in
+// the hotel's time zone. Past and cancelled events are skipped.
+func (s *service) blocks(feed *calendar.Feed, events []ical.Event) []booking.MaintenanceWindow {
+	today := booking.Today(s.location)
+	horizon := today.AddDays(importHorizonDays)
+
+	var windows []booking.MaintenanceWindow
+	for _, e := range events {
+		if e.Status == ical.StatusCancelled || e.Transparent {
+			continue
+		}
+
+		var start, end booking.Date
+		if e.AllDay {
+			start, end = booking.DateOf(e.Start), booking.DateOf(e.End)
+		} else {
+			start, end = booking.DateOf(e.Start.In(s.location)), booking.DateOf(e.End.In(s.location))
+		}
+		if !end.After(start) {
+			end = start.AddDays(1)
+		}
+		if !end.After(today) || !start.Before(horizon) {
+			continue
+		}
+
+		reason := feed.Name
+		if e.Summary != "" {
+			reason += ": " + e.Summary
+		}
+		windows = append(windows, booking.MaintenanceWindow{
+			RoomID:    feed.RoomID,
+			StartDate: start,
+			EndDate:   end,
+			Kind:      booking.MaintenanceKindExternal,
+			Reason:    reason,
+		})
+	}
+	return windows
+}
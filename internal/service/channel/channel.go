@@ -0,0 +1,20 @@
+package channel
+
+import (
+	"context"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/channel"
+)
+
+// OTAChannel is an online travel agency or channel manager the hotel sells
+// through. Errors mean the channel could not be reached or refused the call
+// as a whole; the sync retries on its next run.
+type OTAChannel interface {
+	Name() string
+	// PushInventory sends availability, rates and restrictions. It only
+	// receives the nights that changed since the last accepted push.
+	PushInventory(ctx context.Context, inventory []channel.Inventory) error
+	// PullReservations returns the current state of the channel's upcoming
+	// reservations, including ones already pulled before.
+	PullReservations(ctx context.Context) ([]channel.Reservation, error)
+}
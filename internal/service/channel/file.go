@@ -0,0 +1,126 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/channel"
+)
+
+const (
+	fileInventory    = "inventory.json"
+	fileReservations = "reservations.json"
+)
+
+// FileChannel is a channel for local testing that lives in a directory.
+// Pushed inventory is merged into inventory.json, which shows what the
+// channel would be selling. Reservations are read from reservations.json, a
+// JSON array of channel.Reservation edited by hand to simulate bookings,
+// changes and cancellations made on the channel.
+type FileChannel struct {
+	name string
+	dir  string
+
+	mu sync.Mutex
+}
+
+func NewFileChannel(name, dir string) *FileChannel {
+	return &FileChannel{name: name, dir: dir}
+}
+
+func (c *FileChannel) Name() string {
+	return c.name
+}
+
+func (c *FileChannel) PushInventory(ctx context.Context, inventory []channel.Inventory) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var current []channel.Inventory
+	if err := c.read(fileInventory, &current); err != nil {
+		return err
+	}
+
+	merged := make(map[string]channel.Inventory, len(current)+len(inventory))
+	for _, i := range current {
+		merged[i.Key()] = i
+	}
+	for _, i := range inventory {
+		merged[i.Key()] = i
+	}
+
+	current = current[:0]
+	for _, i := range merged {
+		current = append(current, i)
+	}
+	sort.Slice(current, func(a, b int) bool {
+		if current[a].RoomCode != current[b].RoomCode {
+			return current[a].RoomCode < current[b].RoomCode
+		}
+		if current[a].RateCode != current[b].RateCode {
+			return current[a].RateCode < current[b].RateCode
+		}
+		return current[a].Date.Before(current[b].Date)
+	})
+
+	return c.write(fileInventory, current)
+}
+
+func (c *FileChannel) PullReservations(ctx context.Context) ([]channel.Reservation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var reservations []channel.Reservation
+	if err := c.read(fileReservations, &reservations); err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// read decodes a file of the channel directory; a missing file is empty.
+func (c *FileChannel) read(name string, v any) error {
+	data, err := os.ReadFile(filepath.Join(c.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// write replaces a file of the channel directory atomically.
+func (c *FileChannel) write(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(c.dir, name+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(c.dir, name))
+}
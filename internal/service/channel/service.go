@@ -0,0 +1,292 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/channel"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+	bookingService "github.com/YurcheuskiRadzivon/booking-system/internal/service/booking"
+	notificationService "github.com/YurcheuskiRadzivon/booking-system/internal/service/notification"
+)
+
+var (
+	ErrChannelNotFound     = apperr.NotFound("channel_not_found", "channel is not configured")
+	ErrMappingNotFound     = apperr.NotFound("channel_mapping_not_found", "channel mapping not found")
+	ErrReservationNotFound = apperr.NotFound("channel_reservation_not_found", "channel reservation not found")
+	ErrNotConflict         = apperr.Conflict("channel_reservation_not_conflict", "only conflicting reservations can be resolved or rejected")
+	ErrChannelUnavailable  = apperr.Unavailable("channel_unavailable", "channel could not be reached")
+)
+
+type Service interface {
+	// GetStatuses describes every configured channel: its mappings, open
+	// conflicts and the outcome of its last sync.
+	GetStatuses(ctx context.Context) ([]channel.Status, error)
+
+	GetMappings(ctx context.Context, channelName string) ([]channel.Mapping, error)
+	CreateMapping(ctx context.Context, req channel.CreateMappingRequest) (*channel.Mapping, error)
+	DeleteMapping(ctx context.Context, id int64) error
+
+	GetReservations(ctx context.Context, state channel.ImportState) ([]channel.ImportedReservation, error)
+	// ResolveReservation books a conflicting reservation into the given room.
+	ResolveReservation(ctx context.Context, id int64, req channel.ResolveRequest) (*channel.ImportedReservation, error)
+	// RejectReservation gives up on a conflicting reservation and cancels
+	// the booking it had before the conflicting change, if any. The guest
+	// has to be relocated on the channel's side.
+	RejectReservation(ctx context.Context, id int64) (*channel.ImportedReservation, error)
+
+	// Sync pulls the channel's reservations into bookings, then pushes the
+	// inventory that changed since the last push.
+	Sync(ctx context.Context, channelName string) (*channel.SyncResult, error)
+	SyncAll(ctx context.Context) (int, error)
+	StartSyncWorker(ctx context.Context)
+}
+
+type syncStatus struct {
+	last      *channel.SyncResult
+	lastError string
+}
+
+type service struct {
+	ctx           context.Context
+	repo          repository.Repository
+	bookings      bookingService.Service
+	notifications notificationService.Service
+	location      *time.Location
+	channels      []OTAChannel
+	syncInterval  time.Duration
+	horizonDays   int
+
+	// mu serializes syncs and conflict resolution so that a reservation is
+	// never placed twice.
+	mu     sync.Mutex
+	status map[string]*syncStatus
+}
+
+func NewService(ctx context.Context, repo repository.Repository, bookings bookingService.Service, notifications notificationService.Service, location *time.Location, channels []OTAChannel, syncInterval time.Duration, horizonDays int) (Service, error) {
+	if horizonDays <= 0 || horizonDays > booking.MaxAvailabilityNights {
+		horizonDays = booking.MaxAvailabilityNights
+	}
+
+	status := make(map[string]*syncStatus, len(channels))
+	for _, ch := range channels {
+		status[ch.Name()] = &syncStatus{}
+	}
+
+	return &service{
+		ctx:           ctx,
+		repo:          repo,
+		bookings:      bookings,
+		notifications: notifications,
+		location:      location,
+		channels:      channels,
+		syncInterval:  syncInterval,
+		horizonDays:   horizonDays,
+		status:        status,
+	}, nil
+}
+
+func (s *service) channel(name string) (OTAChannel, error) {
+	for _, ch := range s.channels {
+		if ch.Name() == name {
+			return ch, nil
+		}
+	}
+	return nil, ErrChannelNotFound
+}
+
+func (s *service) GetStatuses(ctx context.Context) ([]channel.Status, error) {
+	conflicts, err := s.repo.Channel().GetReservations(ctx, channel.ImportStateConflict)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]channel.Status, 0, len(s.channels))
+	for _, ch := range s.channels {
+		mappings, err := s.GetMappings(ctx, ch.Name())
+		if err != nil {
+			return nil, err
+		}
+		st := channel.Status{
+			Channel:   ch.Name(),
+			Mappings:  mappings,
+			LastSync:  s.status[ch.Name()].last,
+			LastError: s.status[ch.Name()].lastError,
+		}
+		for _, c := range conflicts {
+			if c.Channel == ch.Name() {
+				st.Conflicts++
+			}
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+func (s *service) GetMappings(ctx context.Context, channelName string) ([]channel.Mapping, error) {
+	mappings, err := s.repo.Channel().GetMappings(ctx, channelName)
+	if err != nil {
+		return nil, err
+	}
+	if mappings == nil {
+		mappings = []channel.Mapping{}
+	}
+	return mappings, nil
+}
+
+func (s *service) CreateMapping(ctx context.Context, req channel.CreateMappingRequest) (*channel.Mapping, error) {
+	if _, err := s.channel(req.Channel); err != nil {
+		return nil, err
+	}
+
+	m := &channel.Mapping{
+		Channel:  req.Channel,
+		RoomType: req.RoomType,
+		RoomCode: req.RoomCode,
+		RateCode: req.RateCode,
+	}
+	if err := s.repo.Channel().CreateMapping(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *service) DeleteMapping(ctx context.Context, id int64) error {
+	err := s.repo.Channel().DeleteMapping(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrMappingNotFound
+	}
+	return err
+}
+
+func (s *service) GetReservations(ctx context.Context, state channel.ImportState) ([]channel.ImportedReservation, error) {
+	reservations, err := s.repo.Channel().GetReservations(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if reservations == nil {
+		reservations = []channel.ImportedReservation{}
+	}
+	return reservations, nil
+}
+
+func (s *service) getConflict(ctx context.Context, id int64) (*channel.ImportedReservation, error) {
+	res, err := s.repo.Channel().GetReservationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, ErrReservationNotFound
+	}
+	if res.State != channel.ImportStateConflict {
+		return nil, ErrNotConflict
+	}
+	return res, nil
+}
+
+func (s *service) ResolveReservation(ctx context.Context, id int64, req channel.ResolveRequest) (*channel.ImportedReservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.getConflict(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	room, err := s.bookings.GetRoomByID(ctx, req.RoomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.placeInRoom(ctx, res, room.ID); err != nil {
+		return nil, err
+	}
+	res.State = channel.ImportStateBooked
+	res.Conflict = ""
+	if err := s.repo.Channel().SaveReservation(ctx, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *service) RejectReservation(ctx context.Context, id int64) (*channel.ImportedReservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, err := s.getConflict(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cancelBooking(ctx, res); err != nil {
+		return nil, err
+	}
+	res.State = channel.ImportStateRejected
+	if err := s.repo.Channel().SaveReservation(ctx, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *service) Sync(ctx context.Context, channelName string) (*channel.SyncResult, error) {
+	ch, err := s.channel(channelName)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, err := s.sync(ctx, ch)
+	st := s.status[ch.Name()]
+	st.lastError = ""
+	if err != nil {
+		st.lastError = err.Error()
+		return nil, err
+	}
+	st.last = result
+	return result, nil
+}
+
+// SyncAll syncs every channel and returns how many failed.
+func (s *service) SyncAll(ctx context.Context) (int, error) {
+	failed := 0
+	for _, ch := range s.channels {
+		if _, err := s.Sync(ctx, ch.Name()); err != nil {
+			fmt.Printf(" Channel %s sync failed: %v\n", ch.Name(), err)
+			failed++
+		}
+	}
+	return failed, nil
+}
+
+func (s *service) StartSyncWorker(ctx context.Context) {
+	if len(s.channels) == 0 {
+		return
+	}
+
+	go func() {
+		fmt.Println(" Channel sync worker started")
+		ticker := time.NewTicker(s.syncInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := s.SyncAll(ctx); err != nil {
+				fmt.Printf(" Channel sync failed: %v\n", err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				fmt.Println(" Channel sync worker stopping...")
+				return
+			}
+		}
+	}()
+}
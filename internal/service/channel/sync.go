@@ -0,0 +1,410 @@
+package channel
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/channel"
+	bookingService "github.com/YurcheuskiRadzivon/booking-system/internal/service/booking"
+)
+
+// placeholderEmailDomain is used for guests whose e-mail the channel hides;
+// bookings require one.
+const placeholderEmailDomain = "channel.invalid"
+
+func (s *service) sync(ctx context.Context, ch OTAChannel) (*channel.SyncResult, error) {
+	mappings, err := s.repo.Channel().GetMappings(ctx, ch.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	// Reservations go first so that the inventory pushed afterwards
+	// already accounts for them.
+	result := &channel.SyncResult{Channel: ch.Name()}
+	if err := s.pull(ctx, ch, mappings, result); err != nil {
+		return nil, err
+	}
+	if err := s.push(ctx, ch, mappings, result); err != nil {
+		return nil, err
+	}
+	result.FinishedAt = time.Now()
+	return result, nil
+}
+
+func (s *service) pull(ctx context.Context, ch OTAChannel, mappings []channel.Mapping, result *channel.SyncResult) error {
+	reservations, err := ch.PullReservations(ctx)
+	if err != nil {
+		return ErrChannelUnavailable.Wrap(err)
+	}
+
+	roomTypes := make(map[string]booking.RoomType, len(mappings))
+	for _, m := range mappings {
+		roomTypes[m.RoomCode] = m.RoomType
+	}
+
+	result.Pulled = len(reservations)
+	for _, r := range reservations {
+		if r.Ref == "" {
+			continue
+		}
+		if err := s.apply(ctx, ch.Name(), roomTypes, r, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply brings our side in line with the channel's view of a reservation.
+// Reservations the channel reports unchanged since the last pull are
+// skipped, except conflicts: they are retried in case a room has freed up
+// or the room code was mapped meanwhile.
+func (s *service) apply(ctx context.Context, channelName string, roomTypes map[string]booking.RoomType, r channel.Reservation, result *channel.SyncResult) error {
+	res, err := s.repo.Channel().GetReservation(ctx, channelName, r.Ref)
+	if err != nil {
+		return err
+	}
+	sum := fingerprint(r)
+	if res != nil && res.Fingerprint == sum && res.State != channel.ImportStateConflict {
+		return nil
+	}
+	if res == nil {
+		res = &channel.ImportedReservation{Channel: channelName, Ref: r.Ref}
+	}
+	hadBooking := res.BookingID != nil
+
+	res.Status = r.Status
+	res.RoomCode = r.RoomCode
+	res.CheckIn = r.CheckIn
+	res.CheckOut = r.CheckOut
+	res.Guest = r.Guest
+	res.Guests = max(r.Guests, 1)
+	res.Total = r.Total
+	res.Fingerprint = sum
+
+	switch {
+	case r.Status == channel.ReservationStatusCancelled:
+		if err := s.cancelBooking(ctx, res); err != nil {
+			return err
+		}
+		res.State = channel.ImportStateCancelled
+		res.Conflict = ""
+		result.Cancelled++
+
+	case res.State == channel.ImportStateRejected:
+		// An admin gave up on the reservation; later changes do not revive it.
+
+	default:
+		reason, err := s.place(ctx, res, roomTypes)
+		if err != nil {
+			return err
+		}
+		switch {
+		case reason != "":
+			res.State = channel.ImportStateConflict
+			res.Conflict = reason
+			result.Conflicts++
+		case hadBooking:
+			res.State = channel.ImportStateBooked
+			res.Conflict = ""
+			result.Modified++
+		default:
+			res.State = channel.ImportStateBooked
+			res.Conflict = ""
+			result.Booked++
+		}
+	}
+
+	return s.repo.Channel().SaveReservation(ctx, res)
+}
+
+// fingerprint changes whenever any reported detail of the reservation does.
+func fingerprint(r channel.Reservation) string {
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// place books the reservation into a free room of its mapped type, or moves
+// the booking it already has, preferring the booking's current room. When no
+// room takes it, the reason is returned; an error means the attempt itself
+// failed and should be retried.
+func (s *service) place(ctx context.Context, res *channel.ImportedReservation, roomTypes map[string]booking.RoomType) (string, error) {
+	roomType, ok := roomTypes[res.RoomCode]
+	if !ok {
+		return fmt.Sprintf("room code %q is not mapped to a room type", res.RoomCode), nil
+	}
+	if res.CheckIn.IsZero() || res.CheckOut.IsZero() || !res.CheckIn.Before(res.CheckOut) {
+		return "invalid stay dates", nil
+	}
+
+	var candidates []int64
+	current, err := s.currentBooking(ctx, res)
+	if err != nil {
+		return "", err
+	}
+	if current != nil && current.Room.RoomType == roomType {
+		if current.StartDate.Equal(res.CheckIn) && current.EndDate.Equal(res.CheckOut) {
+			return "", nil
+		}
+		candidates = append(candidates, current.RoomID)
+	}
+
+	rooms, err := s.repo.Room().GetAvailableByType(ctx, roomType, res.CheckIn, res.CheckOut)
+	if err != nil {
+		return "", err
+	}
+	for _, room := range rooms {
+		candidates = append(candidates, room.ID)
+	}
+
+	var lastErr error
+	for _, roomID := range candidates {
+		err := s.placeInRoom(ctx, res, roomID)
+		if err == nil {
+			return "", nil
+		}
+		if !isPlacementError(err) {
+			return "", err
+		}
+		lastErr = err
+	}
+
+	if lastErr != nil && !errors.Is(lastErr, bookingService.ErrRoomNotAvailable) {
+		return lastErr.Error(), nil
+	}
+	return s.overlapReason(ctx, res, roomType)
+}
+
+// isPlacementError tells a room that does not take the reservation from a
+// failure to talk to the database.
+func isPlacementError(err error) bool {
+	switch apperr.KindOf(err) {
+	case apperr.KindConflict, apperr.KindValidation, apperr.KindNotFound:
+		return true
+	}
+	return false
+}
+
+// overlapReason explains a reservation no room of the type is free for by
+// listing the bookings of that type in the way.
+func (s *service) overlapReason(ctx context.Context, res *channel.ImportedReservation, roomType booking.RoomType) (string, error) {
+	bookings, err := s.repo.Booking().GetInRangeWithRooms(ctx, res.CheckIn, res.CheckOut)
+	if err != nil {
+		return "", err
+	}
+
+	var ids []string
+	for _, b := range bookings {
+		if b.Room.RoomType != roomType || (res.BookingID != nil && b.ID == *res.BookingID) {
+			continue
+		}
+		ids = append(ids, fmt.Sprintf("#%d", b.ID))
+	}
+
+	reason := fmt.Sprintf("no free %s room from %s to %s", roomType, res.CheckIn, res.CheckOut)
+	if len(ids) > 0 {
+		reason += "; overlapping bookings: " + strings.Join(ids, ", ")
+	}
+	return reason, nil
+}
+
+// currentBooking returns the live booking the reservation became, or nil
+// when it has none or it was cancelled on our side.
+func (s *service) currentBooking(ctx context.Context, res *channel.ImportedReservation) (*booking.BookingWithRoom, error) {
+	if res.BookingID == nil {
+		return nil, nil
+	}
+	b, err := s.bookings.GetBookingByID(ctx, *res.BookingID)
+	if errors.Is(err, bookingService.ErrBookingNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if b.Status == booking.BookingStatusCancelled || b.Status == booking.BookingStatusNoShow {
+		return nil, nil
+	}
+	return b, nil
+}
+
+// placeInRoom moves the reservation's booking to the room and dates, or
+// books the room for it when it has no booking yet. Channel reservations
+// are paid for and confirmed on the channel, so new bookings are created
+// confirmed and priced at what the channel charged.
+func (s *service) placeInRoom(ctx context.Context, res *channel.ImportedReservation, roomID int64) error {
+	current, err := s.currentBooking(ctx, res)
+	if err != nil {
+		return err
+	}
+	if current != nil {
+		moved, err := s.bookings.MoveBooking(ctx, current.ID, booking.MoveBookingRequest{
+			RoomID:    roomID,
+			StartDate: res.CheckIn,
+			EndDate:   res.CheckOut,
+			Total:     res.Total,
+		}, 0)
+		if err != nil {
+			return err
+		}
+		s.notifications.NotifyBookingModified(ctx, &moved.Booking, &moved.Room)
+		return nil
+	}
+
+	created, err := s.bookings.CreateBooking(ctx, booking.CreateBookingRequest{
+		RoomID:    roomID,
+		StartDate: res.CheckIn,
+		EndDate:   res.CheckOut,
+		GuestInfo: guestInfo(res),
+		Guests:    res.Guests,
+		Total:     res.Total,
+		Confirmed: true,
+	})
+	if err != nil {
+		return err
+	}
+	res.BookingID = &created.ID
+	s.notifications.NotifyBookingCreated(ctx, &created.Booking, &created.Room)
+	return nil
+}
+
+func guestInfo(res *channel.ImportedReservation) booking.GuestInfo {
+	info := res.Guest
+	if info.Name == "" {
+		info.Name = res.Channel + " " + res.Ref
+	}
+	if info.Email == "" {
+		info.Email = fmt.Sprintf("%s@%s.%s", res.Ref, res.Channel, placeholderEmailDomain)
+	}
+	return info
+}
+
+// cancelBooking cancels the live booking of the reservation, if any. A guest
+// who has already arrived keeps the booking.
+func (s *service) cancelBooking(ctx context.Context, res *channel.ImportedReservation) error {
+	current, err := s.currentBooking(ctx, res)
+	if err != nil || current == nil {
+		return err
+	}
+	cancelled, err := s.bookings.CancelBooking(ctx, current.ID, 0)
+	if errors.Is(err, bookingService.ErrBookingClosed) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	s.notifications.NotifyBookingCancelled(ctx, cancelled, &current.Room)
+	return nil
+}
+
+func (s *service) push(ctx context.Context, ch OTAChannel, mappings []channel.Mapping, result *channel.SyncResult) error {
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	today := booking.Today(s.location)
+	current, err := s.inventory(ctx, mappings, today)
+	if err != nil {
+		return err
+	}
+	pushed, err := s.repo.Channel().GetInventory(ctx, ch.Name(), today)
+	if err != nil {
+		return err
+	}
+
+	last := make(map[string]channel.Inventory, len(pushed))
+	for _, i := range pushed {
+		last[i.Key()] = i
+	}
+	var changed []channel.Inventory
+	for _, i := range current {
+		p, ok := last[i.Key()]
+		if !ok || p.Available != i.Available || p.Price != i.Price || p.StopSell != i.StopSell {
+			changed = append(changed, i)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := ch.PushInventory(ctx, changed); err != nil {
+		return ErrChannelUnavailable.Wrap(err)
+	}
+	result.Pushed = len(changed)
+	return s.repo.Channel().SaveInventory(ctx, ch.Name(), changed, today)
+}
+
+// typeNight aggregates the rooms of one type on one night.
+type typeNight struct {
+	rooms     int
+	available int
+	closed    int
+	price     float64
+}
+
+// inventory computes what each mapped room type has to offer over the sync
+// horizon. The rate is the lowest nightly price among the rooms of the type,
+// whether or not they are free, so that it does not jump as rooms sell.
+func (s *service) inventory(ctx context.Context, mappings []channel.Mapping, today booking.Date) ([]channel.Inventory, error) {
+	calendar, err := s.bookings.GetAvailability(ctx, booking.AvailabilityRequest{
+		From: today,
+		To:   today.AddDays(s.horizonDays),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	nights := make(map[booking.RoomType][]typeNight)
+	for _, room := range calendar.Rooms {
+		types, ok := nights[room.Room.RoomType]
+		if !ok {
+			types = make([]typeNight, s.horizonDays)
+			nights[room.Room.RoomType] = types
+		}
+		for _, n := range room.Nights {
+			i := today.DaysUntil(n.Date)
+			if i < 0 || i >= s.horizonDays {
+				continue
+			}
+			agg := &types[i]
+			agg.rooms++
+			switch {
+			case n.Available:
+				agg.available++
+			case n.Restriction == booking.RestrictionOutOfOrder || n.Restriction == booking.RestrictionOutOfService:
+				agg.closed++
+			}
+			if agg.price == 0 || n.Price < agg.price {
+				agg.price = n.Price
+			}
+		}
+	}
+
+	inventory := make([]channel.Inventory, 0, len(mappings)*s.horizonDays)
+	for _, m := range mappings {
+		types := nights[m.RoomType]
+		for i := 0; i < s.horizonDays; i++ {
+			var agg typeNight
+			if types != nil {
+				agg = types[i]
+			}
+			inventory = append(inventory, channel.Inventory{
+				RoomCode:  m.RoomCode,
+				RateCode:  m.RateCode,
+				Date:      today.AddDays(i),
+				Available: agg.available,
+				Price:     math.Round(agg.price*100) / 100,
+				StopSell:  agg.closed == agg.rooms,
+			})
+		}
+	}
+	return inventory, nil
+}
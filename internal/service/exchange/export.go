@@ -0,0 +1,136 @@
+package exchange
+
+import (
+	"context"
+	"io"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/spreadsheet"
+)
+
+// Export is a checked export whose rows are written later, typically while
+// the response is streamed, one page of records at a time.
+type Export struct {
+	// Name is used for the file name and the sheet.
+	Name   string
+	header []any
+	rows   func(ctx context.Context, emit func(values ...any) error) error
+}
+
+func (e *Export) WriteTo(ctx context.Context, format spreadsheet.Format, w io.Writer) error {
+	sw, err := spreadsheet.NewWriter(format, w, e.Name)
+	if err != nil {
+		return err
+	}
+	if err := sw.WriteRow(e.header...); err != nil {
+		return err
+	}
+	if err := e.rows(ctx, sw.WriteRow); err != nil {
+		return err
+	}
+	return sw.Close()
+}
+
+// pages walks a keyset-paginated list starting from an already loaded
+// first page.
+func pages[T any](ctx context.Context, first *booking.Page[T], page booking.PageRequest, list func(ctx context.Context, page booking.PageRequest) (*booking.Page[T], error), emit func(T) error) error {
+	current := first
+	for {
+		for _, item := range current.Items {
+			if err := emit(item); err != nil {
+				return err
+			}
+		}
+		if current.NextCursor == "" {
+			return nil
+		}
+		page.Cursor = current.NextCursor
+		next, err := list(ctx, page)
+		if err != nil {
+			return err
+		}
+		current = next
+	}
+}
+
+// exportPage requests the largest pages from the start of the list, keeping
+// the requested sort.
+func exportPage(page booking.PageRequest, defaultSort string, defaultOrder booking.SortOrder) booking.PageRequest {
+	page.Limit = booking.MaxPageLimit
+	page.Cursor = ""
+	page.Normalize(defaultSort, defaultOrder)
+	return page
+}
+
+func (s *service) ExportBookings(ctx context.Context, filter booking.BookingFilter) (*Export, error) {
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, ErrInvalidDates
+	}
+	filter.PageRequest = exportPage(filter.PageRequest, "created_at", booking.SortDesc)
+
+	list := func(ctx context.Context, page booking.PageRequest) (*booking.Page[booking.BookingWithRoom], error) {
+		f := filter
+		f.PageRequest = page
+		return s.repo.Booking().List(ctx, f)
+	}
+	first, err := list(ctx, filter.PageRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Export{
+		Name: "bookings",
+		header: []any{"id", "status", "room_number", "room_type", "start_date", "end_date", "nights", "guests",
+			"guest_name", "guest_email", "guest_phone", "price", "penalty", "created_at"},
+		rows: func(ctx context.Context, emit func(values ...any) error) error {
+			return pages(ctx, first, filter.PageRequest, list, func(b booking.BookingWithRoom) error {
+				return emit(b.ID, string(b.Status), b.Room.RoomNumber, string(b.Room.RoomType), b.StartDate, b.EndDate, b.Nights(), b.Guests,
+					b.GuestInfo.Name, b.GuestInfo.Email, b.GuestInfo.Phone, b.Price, b.Penalty, b.CreatedAt.In(s.location))
+			})
+		},
+	}, nil
+}
+
+func (s *service) ExportRooms(ctx context.Context, filter booking.RoomFilter) (*Export, error) {
+	filter.PageRequest = exportPage(filter.PageRequest, "room_number", booking.SortAsc)
+
+	list := func(ctx context.Context, page booking.PageRequest) (*booking.Page[booking.Room], error) {
+		f := filter
+		f.PageRequest = page
+		return s.repo.Room().List(ctx, f)
+	}
+	first, err := list(ctx, filter.PageRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Export{
+		Name:   "rooms",
+		header: []any{roomNumberColumn, roomTypeColumn, basePriceColumn, capacityColumn, statusColumn, descriptionColumn, "housekeeping_status", "id"},
+		rows: func(ctx context.Context, emit func(values ...any) error) error {
+			return pages(ctx, first, filter.PageRequest, list, func(r booking.Room) error {
+				return emit(r.RoomNumber, string(r.RoomType), r.BasePrice, r.Capacity, string(r.Status), r.Description, string(r.HousekeepingStatus), r.ID)
+			})
+		},
+	}, nil
+}
+
+func (s *service) ExportSpecialDates(ctx context.Context) (*Export, error) {
+	dates, err := s.repo.SpecialDate().GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Export{
+		Name:   "special_dates",
+		header: []any{dateColumn, nameColumn, coefficientColumn, "id"},
+		rows: func(ctx context.Context, emit func(values ...any) error) error {
+			for _, sd := range dates {
+				if err := emit(sd.Date, sd.Name, sd.Coefficient, sd.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}, nil
+}
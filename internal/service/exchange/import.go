@@ -0,0 +1,335 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/exchange"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/spreadsheet"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+const (
+	roomNumberColumn  = "room_number"
+	roomTypeColumn    = "room_type"
+	basePriceColumn   = "base_price"
+	capacityColumn    = "capacity"
+	statusColumn      = "status"
+	descriptionColumn = "description"
+
+	dateColumn        = "date"
+	nameColumn        = "name"
+	coefficientColumn = "coefficient"
+)
+
+// sheetRow is a data row of an import file with access to cells by column
+// name. Columns are matched case-insensitively; unknown ones are ignored.
+type sheetRow struct {
+	number  int
+	cells   []string
+	columns map[string]int
+}
+
+// get returns the trimmed cell of the column; empty cells count as absent.
+func (r sheetRow) get(column string) (string, bool) {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.cells) {
+		return "", false
+	}
+	value := strings.TrimSpace(r.cells[i])
+	return value, value != ""
+}
+
+func (r sheetRow) blank() bool {
+	for _, cell := range r.cells {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (r sheetRow) float(v *validation.Validator, column string) *float64 {
+	value, ok := r.get(column)
+	if !ok {
+		return nil
+	}
+	f, err := spreadsheet.ParseNumber(value)
+	if err != nil {
+		v.Add(column, validation.CodeInvalid, "must be a number")
+		return nil
+	}
+	return &f
+}
+
+func (r sheetRow) int(v *validation.Validator, column string) *int {
+	value, ok := r.get(column)
+	if !ok {
+		return nil
+	}
+	f, err := spreadsheet.ParseNumber(value)
+	if err != nil || f != math.Trunc(f) {
+		v.Add(column, validation.CodeInvalid, "must be an integer")
+		return nil
+	}
+	n := int(f)
+	return &n
+}
+
+func (r sheetRow) date(v *validation.Validator, column string) booking.Date {
+	value, ok := r.get(column)
+	if !ok {
+		return booking.Date{}
+	}
+	t, err := spreadsheet.ParseDate(value)
+	if err != nil {
+		v.Add(column, validation.CodeInvalid, "must be a date in YYYY-MM-DD format")
+		return booking.Date{}
+	}
+	return booking.DateOf(t)
+}
+
+// readSheet reads an import file whose first row names the columns and
+// checks that the required ones are present.
+func readSheet(format spreadsheet.Format, r io.Reader, required ...string) ([]sheetRow, error) {
+	records, err := spreadsheet.ReadAll(format, r)
+	if err != nil {
+		return nil, ErrUnreadable.Wrap(err)
+	}
+	if len(records) == 0 {
+		return nil, ErrEmptyFile
+	}
+	if len(records)-1 > exchange.MaxImportRows {
+		return nil, ErrTooManyRows
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; !ok && name != "" {
+			columns[name] = i
+		}
+	}
+	var v validation.Validator
+	for _, column := range required {
+		_, ok := columns[column]
+		v.Check(ok, column, validation.CodeRequired, "column is missing")
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+
+	rows := make([]sheetRow, 0, len(records)-1)
+	for i, cells := range records[1:] {
+		row := sheetRow{number: i + 2, cells: cells, columns: columns}
+		if !row.blank() {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// addRowError records the field errors of a row.
+func addRowError(result *exchange.ImportResult, row int, err error) error {
+	var errs validation.Errors
+	if !errors.As(err, &errs) {
+		return err
+	}
+	result.Errors = append(result.Errors, exchange.RowError{Row: row, Errors: errs})
+	return nil
+}
+
+// ImportRooms creates rooms with new numbers and updates rooms in service
+// with the same number. Empty cells keep the current value of a room.
+func (s *service) ImportRooms(ctx context.Context, format spreadsheet.Format, r io.Reader, dryRun bool) (*exchange.ImportResult, error) {
+	rows, err := readSheet(format, r, roomNumberColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		existing, err := s.repo.Room().GetAll(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result, _, err := planRooms(rows, existing)
+		if err != nil {
+			return nil, err
+		}
+		result.DryRun = true
+		return result, nil
+	}
+
+	// The rooms are matched on their numbers while locked, so none of them
+	// can be archived or changed before the import is written.
+	var result *exchange.ImportResult
+	err = s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		existing, err := repo.Room().LockAll(ctx)
+		if err != nil {
+			return err
+		}
+		var rooms []booking.Room
+		result, rooms, err = planRooms(rows, existing)
+		if err != nil || len(result.Errors) > 0 || len(rooms) == 0 {
+			return err
+		}
+		return repo.Room().Import(ctx, rooms)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// planRooms matches the rows to the existing rooms and returns the rooms to
+// write along with the counts and row errors of the import.
+func planRooms(rows []sheetRow, existing []booking.Room) (*exchange.ImportResult, []booking.Room, error) {
+	byNumber := make(map[string]booking.Room, len(existing))
+	for _, room := range existing {
+		byNumber[room.RoomNumber] = room
+	}
+
+	result := &exchange.ImportResult{Rows: len(rows), Errors: []exchange.RowError{}}
+	seen := make(map[string]int)
+	var rooms []booking.Room
+	for _, row := range rows {
+		var v validation.Validator
+		number, _ := row.get(roomNumberColumn)
+		v.Required(roomNumberColumn, number)
+		if prev, ok := seen[number]; ok && number != "" {
+			v.Add(roomNumberColumn, validation.CodeDuplicate, fmt.Sprintf("repeats row %d", prev))
+		} else {
+			seen[number] = row.number
+		}
+
+		patch := booking.RoomPatch{
+			RoomNumber: &number,
+			BasePrice:  row.float(&v, basePriceColumn),
+			Capacity:   row.int(&v, capacityColumn),
+		}
+		if value, ok := row.get(roomTypeColumn); ok {
+			roomType := booking.RoomType(value)
+			patch.RoomType = &roomType
+		}
+		if value, ok := row.get(statusColumn); ok {
+			status := booking.RoomStatus(value)
+			patch.Status = &status
+		}
+		if value, ok := row.get(descriptionColumn); ok {
+			patch.Description = &value
+		}
+		if err := v.Err(); err != nil {
+			if err := addRowError(result, row.number, err); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		room, found := byNumber[number]
+		if !found {
+			room = booking.Room{Status: booking.RoomStatusAvailable}
+		}
+		before := room
+		if err := patch.Apply(&room); err != nil {
+			if err := addRowError(result, row.number, err); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		switch {
+		case !found:
+			result.Created++
+		case room == before:
+			result.Unchanged++
+			continue
+		default:
+			result.Updated++
+		}
+		rooms = append(rooms, room)
+	}
+	return result, rooms, nil
+}
+
+// ImportSpecialDates creates or updates the special date of each row's date.
+func (s *service) ImportSpecialDates(ctx context.Context, format spreadsheet.Format, r io.Reader, dryRun bool) (*exchange.ImportResult, error) {
+	rows, err := readSheet(format, r, dateColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.repo.SpecialDate().GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[booking.Date]booking.SpecialDate, len(existing))
+	for _, sd := range existing {
+		if _, ok := byDate[sd.Date]; !ok {
+			byDate[sd.Date] = sd
+		}
+	}
+
+	result := &exchange.ImportResult{DryRun: dryRun, Rows: len(rows), Errors: []exchange.RowError{}}
+	seen := make(map[booking.Date]int)
+	var dates []booking.SpecialDate
+	for _, row := range rows {
+		var v validation.Validator
+		date := row.date(&v, dateColumn)
+		if _, ok := row.get(dateColumn); !ok {
+			v.Add(dateColumn, validation.CodeRequired, "is required")
+		}
+		if prev, ok := seen[date]; ok && !date.IsZero() {
+			v.Add(dateColumn, validation.CodeDuplicate, fmt.Sprintf("repeats row %d", prev))
+		} else {
+			seen[date] = row.number
+		}
+
+		patch := booking.SpecialDatePatch{
+			Date:        &date,
+			Coefficient: row.float(&v, coefficientColumn),
+		}
+		if value, ok := row.get(nameColumn); ok {
+			patch.Name = &value
+		}
+		if err := v.Err(); err != nil {
+			if err := addRowError(result, row.number, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		sd, found := byDate[date]
+		before := sd
+		if err := patch.Apply(&sd); err != nil {
+			if err := addRowError(result, row.number, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		switch {
+		case !found:
+			result.Created++
+		case sd == before:
+			result.Unchanged++
+			continue
+		default:
+			result.Updated++
+		}
+		dates = append(dates, sd)
+	}
+
+	if len(result.Errors) > 0 || dryRun || len(dates) == 0 {
+		return result, nil
+	}
+	if err := s.repo.SpecialDate().Import(ctx, dates); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
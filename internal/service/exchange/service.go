@@ -0,0 +1,48 @@
+package exchange
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/exchange"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/spreadsheet"
+)
+
+var (
+	ErrInvalidDates = apperr.Validation("invalid_dates", "invalid dates: end date must be after start date")
+	ErrEmptyFile    = apperr.Validation("empty_file", "import file has no header row")
+	ErrTooManyRows  = apperr.Validation("too_many_rows", "import file has too many rows")
+	ErrUnreadable   = apperr.Validation("unreadable_file", "import file cannot be read")
+)
+
+// Service exports bookings, rooms and special dates as spreadsheets and
+// imports rooms and special dates from them.
+type Service interface {
+	ExportBookings(ctx context.Context, filter booking.BookingFilter) (*Export, error)
+	ExportRooms(ctx context.Context, filter booking.RoomFilter) (*Export, error)
+	ExportSpecialDates(ctx context.Context) (*Export, error)
+
+	// ImportRooms upserts rooms keyed on room_number, and ImportSpecialDates
+	// special dates keyed on date. Nothing is written when a row is invalid
+	// or the import is a dry run.
+	ImportRooms(ctx context.Context, format spreadsheet.Format, r io.Reader, dryRun bool) (*exchange.ImportResult, error)
+	ImportSpecialDates(ctx context.Context, format spreadsheet.Format, r io.Reader, dryRun bool) (*exchange.ImportResult, error)
+}
+
+type service struct {
+	ctx      context.Context
+	repo     repository.Repository
+	location *time.Location
+}
+
+func NewService(ctx context.Context, repo repository.Repository, location *time.Location) (Service, error) {
+	return &service{
+		ctx:      ctx,
+		repo:     repo,
+		location: location,
+	}, nil
+}
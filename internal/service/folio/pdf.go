@@ -0,0 +1,126 @@
+package folio
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/folio"
+	"github.com/go-pdf/fpdf"
+)
+
+// DejaVu Sans covers Cyrillic, which the core PDF fonts do not.
+//
+//go:embed fonts/DejaVuSans.ttf fonts/DejaVuSans-Bold.ttf
+var fonts embed.FS
+
+const (
+	fontFamily   = "DejaVuSans"
+	pageWidth    = 180.0
+	dateColumn   = 30.0
+	amountColumn = 40.0
+	lineHeight   = 7.0
+)
+
+type invoiceDocument struct {
+	HotelName string
+	Invoice   *folio.Invoice
+	Booking   *booking.Booking
+	Room      *booking.Room
+	Location  *time.Location
+}
+
+func renderInvoice(doc invoiceDocument) ([]byte, error) {
+	regular, err := fonts.ReadFile("fonts/DejaVuSans.ttf")
+	if err != nil {
+		return nil, err
+	}
+	bold, err := fonts.ReadFile("fonts/DejaVuSans-Bold.ttf")
+	if err != nil {
+		return nil, err
+	}
+
+	inv, f := doc.Invoice, doc.Invoice.Folio
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(fontFamily, "", regular)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", bold)
+	pdf.SetTitle("Счет № "+inv.Number, true)
+	pdf.SetCreationDate(inv.IssuedAt)
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+
+	pdf.SetFont(fontFamily, "B", 16)
+	pdf.CellFormat(pageWidth, 10, doc.HotelName, "", 1, "L", false, 0, "")
+	pdf.SetFont(fontFamily, "B", 13)
+	pdf.CellFormat(pageWidth, 9, fmt.Sprintf("Счет № %s от %s", inv.Number, inv.IssuedAt.In(doc.Location).Format("02.01.2006")), "", 1, "L", false, 0, "")
+	pdf.Ln(3)
+
+	pdf.SetFont(fontFamily, "", 10)
+	b := doc.Booking
+	details := []string{
+		"Гость: " + inv.GuestName + " (" + inv.GuestEmail + ")",
+		fmt.Sprintf("Бронирование #%d, номер %s", b.ID, roomNumber(doc.Room)),
+		fmt.Sprintf("Проживание: %s - %s, ночей: %d, гостей: %d", b.StartDate.Format("02.01.2006"), b.EndDate.Format("02.01.2006"), b.Nights(), b.Guests),
+	}
+	for _, line := range details {
+		pdf.CellFormat(pageWidth, 6, line, "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.SetFillColor(235, 235, 235)
+	pdf.CellFormat(dateColumn, lineHeight, "Дата", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(pageWidth-dateColumn-amountColumn, lineHeight, "Услуга", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(amountColumn, lineHeight, "Сумма, "+f.Currency, "1", 1, "R", true, 0, "")
+
+	pdf.SetFont(fontFamily, "", 10)
+	for _, c := range f.Charges {
+		date := ""
+		if c.ServiceDate != nil {
+			date = c.ServiceDate.Format("02.01.2006")
+		}
+		pdf.CellFormat(dateColumn, lineHeight, date, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(pageWidth-dateColumn-amountColumn, lineHeight, c.Description, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(amountColumn, lineHeight, money(c.Amount), "1", 1, "R", false, 0, "")
+	}
+	pdf.Ln(2)
+
+	total := func(label string, amount float64, style string) {
+		pdf.SetFont(fontFamily, style, 10)
+		pdf.CellFormat(pageWidth-amountColumn, lineHeight, label, "", 0, "R", false, 0, "")
+		pdf.CellFormat(amountColumn, lineHeight, money(amount), "", 1, "R", false, 0, "")
+	}
+	total("Итого по услугам:", f.Subtotal, "")
+	if f.VATIncluded {
+		total(fmt.Sprintf("в т.ч. НДС %g%%:", f.VATRate), f.VAT, "")
+	} else {
+		total(fmt.Sprintf("НДС %g%%:", f.VATRate), f.VAT, "")
+	}
+	if f.CityTax > 0 {
+		total("Туристический налог:", f.CityTax, "")
+	}
+	total("Всего:", f.Total, "B")
+	for _, p := range f.Payments {
+		total(fmt.Sprintf("%s (%s):", p.Description, p.Date.In(doc.Location).Format("02.01.2006")), -p.Amount, "")
+	}
+	total("К оплате:", f.Balance, "B")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func roomNumber(room *booking.Room) string {
+	if room == nil {
+		return "-"
+	}
+	return room.RoomNumber
+}
+
+func money(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
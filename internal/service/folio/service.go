@@ -0,0 +1,129 @@
+package folio
+
+import (
+	"context"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/folio"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/payment"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+)
+
+var (
+	ErrBookingNotFound = apperr.NotFound("booking_not_found", "booking not found")
+	ErrInvoiceNotFound = apperr.NotFound("invoice_not_found", "the booking has no invoice yet")
+)
+
+type Service interface {
+	GetFolio(ctx context.Context, bookingID int64) (*folio.Folio, error)
+	// NewInvoice builds the invoice of a booking that is being checked out.
+	// The checkout stores it, so a booking is invoiced once.
+	NewInvoice(b *booking.Booking, charges []folio.Charge, payments []payment.Payment) *folio.Invoice
+	GetInvoice(ctx context.Context, bookingID int64) (*folio.Invoice, error)
+	RenderInvoicePDF(ctx context.Context, bookingID int64) (*folio.Invoice, []byte, error)
+}
+
+type service struct {
+	ctx       context.Context
+	repo      repository.Repository
+	location  *time.Location
+	taxes     folio.TaxPolicy
+	hotelName string
+}
+
+func NewService(ctx context.Context, repo repository.Repository, location *time.Location, taxes folio.TaxPolicy, hotelName string) (Service, error) {
+	return &service{
+		ctx:       ctx,
+		repo:      repo,
+		location:  location,
+		taxes:     taxes,
+		hotelName: hotelName,
+	}, nil
+}
+
+func (s *service) getBooking(ctx context.Context, id int64) (*booking.Booking, error) {
+	b, err := s.repo.Booking().GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrBookingNotFound
+	}
+	return b, nil
+}
+
+func (s *service) GetFolio(ctx context.Context, bookingID int64) (*folio.Folio, error) {
+	b, err := s.getBooking(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	return s.build(ctx, b)
+}
+
+func (s *service) build(ctx context.Context, b *booking.Booking) (*folio.Folio, error) {
+	charges, err := s.repo.Folio().GetCharges(ctx, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := s.repo.Payment().GetByBookingID(ctx, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	f := folio.Build(*b, charges, payments, s.taxes)
+	return &f, nil
+}
+
+func (s *service) NewInvoice(b *booking.Booking, charges []folio.Charge, payments []payment.Payment) *folio.Invoice {
+	f := folio.Build(*b, charges, payments, s.taxes)
+	return &folio.Invoice{
+		Year:       time.Now().In(s.location).Year(),
+		BookingID:  b.ID,
+		GuestName:  b.GuestInfo.Name,
+		GuestEmail: b.GuestInfo.Email,
+		Total:      f.Total,
+		VAT:        f.VAT,
+		CityTax:    f.CityTax,
+		Currency:   f.Currency,
+		Folio:      f,
+	}
+}
+
+func (s *service) GetInvoice(ctx context.Context, bookingID int64) (*folio.Invoice, error) {
+	inv, err := s.repo.Folio().GetInvoiceByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, ErrInvoiceNotFound
+	}
+	return inv, nil
+}
+
+func (s *service) RenderInvoicePDF(ctx context.Context, bookingID int64) (*folio.Invoice, []byte, error) {
+	inv, err := s.GetInvoice(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := s.getBooking(ctx, bookingID)
+	if err != nil {
+		return nil, nil, err
+	}
+	room, err := s.repo.Room().GetByID(ctx, b.RoomID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pdf, err := renderInvoice(invoiceDocument{
+		HotelName: s.hotelName,
+		Invoice:   inv,
+		Booking:   b,
+		Room:      room,
+		Location:  s.location,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return inv, pdf, nil
+}
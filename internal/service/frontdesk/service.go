@@ -0,0 +1,267 @@
+package frontdesk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/housekeeping"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/folio"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/service/loyalty"
+)
+
+var (
+	ErrBookingNotFound   = apperr.NotFound("booking_not_found", "booking not found")
+	ErrNotConfirmed      = apperr.Conflict("booking_not_confirmed", "only confirmed bookings can be checked in")
+	ErrNotCheckedIn      = apperr.Conflict("booking_not_checked_in", "booking is not checked in")
+	ErrArrivalNotDue     = apperr.Conflict("arrival_not_due", "check-in is only possible between the arrival and departure dates")
+	ErrRoomOccupied      = apperr.Conflict("room_occupied", "room is still occupied by another guest")
+	ErrRoomNotInspected  = apperr.Conflict("room_not_inspected", "room has not been inspected by housekeeping yet")
+	ErrMissingDocument   = apperr.Validation("missing_document", "guest ID document type and number are required")
+	ErrInvalidNoShowTime = apperr.Validation("invalid_no_show_time", "invalid no-show run time (use HH:MM)")
+)
+
+type Policy struct {
+	// NoShowPenaltyNights is how many nights of the stay are charged to a
+	// guest who never arrives.
+	NoShowPenaltyNights int
+	// NoShowRunTime is the local "HH:MM" at which the nightly no-show job runs.
+	NoShowRunTime string
+}
+
+type NoShowResult struct {
+	Date      booking.Date `json:"date"`
+	Processed []int64      `json:"processed"`
+	Penalties float64      `json:"penalties"`
+}
+
+type Service interface {
+	// CheckIn and CheckOut take the booking version the front desk last saw,
+	// or 0 to skip the check.
+	CheckIn(ctx context.Context, id int64, details booking.CheckInDetails, version int64) (*booking.Booking, error)
+	CheckOut(ctx context.Context, id, version int64) (*booking.Booking, error)
+	ProcessNoShows(ctx context.Context, date booking.Date) (*NoShowResult, error)
+
+	StartNoShowWorker(ctx context.Context)
+}
+
+type service struct {
+	ctx      context.Context
+	repo     repository.Repository
+	location *time.Location
+	policy   Policy
+	loyalty  loyalty.Service
+	folio    folio.Service
+}
+
+func NewService(ctx context.Context, repo repository.Repository, location *time.Location, policy Policy, loyaltySvc loyalty.Service, folioSvc folio.Service) (Service, error) {
+	if _, err := time.Parse("15:04", policy.NoShowRunTime); err != nil {
+		return nil, ErrInvalidNoShowTime
+	}
+
+	return &service{
+		ctx:      ctx,
+		repo:     repo,
+		location: location,
+		policy:   policy,
+		loyalty:  loyaltySvc,
+		folio:    folioSvc,
+	}, nil
+}
+
+func (s *service) CheckIn(ctx context.Context, id int64, details booking.CheckInDetails, version int64) (*booking.Booking, error) {
+	if details.DocumentType == "" || details.DocumentNumber == "" {
+		return nil, ErrMissingDocument
+	}
+
+	b, err := s.repo.Booking().GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrBookingNotFound
+	}
+	if err := repository.CheckVersion(b.Version, version); err != nil {
+		return nil, err
+	}
+	if b.Status != booking.BookingStatusConfirmed {
+		return nil, ErrNotConfirmed
+	}
+
+	today := booking.Today(s.location)
+	if today.Before(b.StartDate) || !today.Before(b.EndDate) {
+		return nil, ErrArrivalNotDue
+	}
+
+	room, err := s.repo.Room().GetByID(ctx, b.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if room != nil && room.Status == booking.RoomStatusOccupied {
+		return nil, ErrRoomOccupied
+	}
+	if room != nil && room.HousekeepingStatus != booking.HousekeepingStatusInspected {
+		return nil, ErrRoomNotInspected
+	}
+
+	now := time.Now().In(s.location)
+	err = s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		if err := repo.Booking().CheckIn(ctx, b, &details, now); err != nil {
+			return err
+		}
+		return repo.Room().UpdateStatus(ctx, b.RoomID, booking.RoomStatusOccupied)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.Status = booking.BookingStatusCheckedIn
+	b.CheckedInAt = &now
+	b.CheckInDetails = &details
+	return b, nil
+}
+
+func (s *service) CheckOut(ctx context.Context, id, version int64) (*booking.Booking, error) {
+	b, err := s.repo.Booking().GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrBookingNotFound
+	}
+	if err := repository.CheckVersion(b.Version, version); err != nil {
+		return nil, err
+	}
+	if b.Status != booking.BookingStatusCheckedIn {
+		return nil, ErrNotCheckedIn
+	}
+
+	now := time.Now().In(s.location)
+	bookingID := b.ID
+	cleaning := &housekeeping.Task{
+		RoomID:    b.RoomID,
+		BookingID: &bookingID,
+		Type:      housekeeping.TaskTypeCleaning,
+		Status:    housekeeping.TaskStatusOpen,
+		DueDate:   booking.DateOf(now),
+	}
+
+	// The departure, the freed room, its cleaning task, the points the stay
+	// earns and the invoice are written together, so a failure cannot leave a
+	// checked-out guest's room occupied or the guest without points or invoice.
+	err = s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		if err := repo.Booking().CheckOut(ctx, b, now); err != nil {
+			return err
+		}
+		if err := repo.Room().UpdateStatus(ctx, b.RoomID, booking.RoomStatusAvailable); err != nil {
+			return err
+		}
+		if err := repo.Room().UpdateHousekeepingStatus(ctx, b.RoomID, booking.HousekeepingStatusDirty); err != nil {
+			return err
+		}
+		if err := repo.Housekeeping().CreateTask(ctx, cleaning); err != nil {
+			return err
+		}
+		if earning := s.loyalty.Earning(b); earning != nil {
+			if err := repo.Loyalty().AddEntry(ctx, earning); err != nil {
+				return err
+			}
+		}
+
+		charges, err := repo.Folio().GetCharges(ctx, b.ID)
+		if err != nil {
+			return err
+		}
+		payments, err := repo.Payment().GetByBookingID(ctx, b.ID)
+		if err != nil {
+			return err
+		}
+		return repo.Folio().CreateInvoice(ctx, s.folio.NewInvoice(b, charges, payments))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.Status = booking.BookingStatusCheckedOut
+	b.CheckedOutAt = &now
+	return b, nil
+}
+
+// ProcessNoShows marks every confirmed booking whose arrival date is before
+// date and that was never checked in as a no-show and charges the penalty.
+func (s *service) ProcessNoShows(ctx context.Context, date booking.Date) (*NoShowResult, error) {
+	if date.IsZero() {
+		date = booking.Today(s.location)
+	}
+
+	candidates, err := s.repo.Booking().GetNoShowCandidates(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &NoShowResult{Date: date, Processed: []int64{}}
+	for _, b := range candidates {
+		penalty := s.noShowPenalty(b)
+		if err := s.repo.Booking().MarkNoShow(ctx, b.ID, penalty); err != nil {
+			return result, err
+		}
+		result.Processed = append(result.Processed, b.ID)
+		result.Penalties += penalty
+	}
+
+	return result, nil
+}
+
+func (s *service) noShowPenalty(b booking.Booking) float64 {
+	nights := b.StartDate.DaysUntil(b.EndDate)
+	if nights <= 0 {
+		return b.Price
+	}
+
+	charged := s.policy.NoShowPenaltyNights
+	if charged > nights {
+		charged = nights
+	}
+	if charged <= 0 {
+		return 0
+	}
+
+	return math.Round(b.Price/float64(nights)*float64(charged)*100) / 100
+}
+
+func (s *service) StartNoShowWorker(ctx context.Context) {
+	go func() {
+		fmt.Println(" No-show worker started")
+		for {
+			next := s.nextNoShowRun(time.Now().In(s.location))
+			timer := time.NewTimer(time.Until(next))
+
+			select {
+			case <-timer.C:
+				result, err := s.ProcessNoShows(ctx, booking.DateOf(next))
+				if err != nil {
+					fmt.Printf(" No-show processing failed: %v\n", err)
+					continue
+				}
+				fmt.Printf(" No-show processing for %s: %d bookings, penalties %.2f\n", result.Date, len(result.Processed), result.Penalties)
+			case <-ctx.Done():
+				timer.Stop()
+				fmt.Println(" No-show worker stopping...")
+				return
+			}
+		}
+	}()
+}
+
+func (s *service) nextNoShowRun(now time.Time) time.Time {
+	runAt, _ := time.Parse("15:04", s.policy.NoShowRunTime)
+	next := time.Date(now.Year(), now.Month(), now.Day(), runAt.Hour(), runAt.Minute(), 0, 0, s.location)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
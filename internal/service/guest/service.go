@@ -0,0 +1,143 @@
+package guest
+
+import (
+	"context"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/guest"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+)
+
+var (
+	ErrGuestNotFound = apperr.NotFound("guest_not_found", "guest not found")
+	ErrInvalidMerge  = apperr.Validation("invalid_merge", "source and target guests must be different")
+	ErrInvalidName   = apperr.Validation("invalid_guest_name", "guest name cannot be empty")
+)
+
+type Service interface {
+	ListGuests(ctx context.Context, filter guest.Filter) (*booking.Page[guest.Guest], error)
+	GetProfile(ctx context.Context, id int64) (*guest.Profile, error)
+	UpdateGuest(ctx context.Context, id int64, req guest.UpdateGuestRequest) (*guest.Guest, error)
+	GetDuplicates(ctx context.Context) ([]guest.DuplicateCandidate, error)
+	MergeGuests(ctx context.Context, req guest.MergeRequest) (*guest.Profile, error)
+}
+
+type service struct {
+	ctx  context.Context
+	repo repository.Repository
+}
+
+func NewService(ctx context.Context, repo repository.Repository) (Service, error) {
+	return &service{
+		ctx:  ctx,
+		repo: repo,
+	}, nil
+}
+
+func (s *service) ListGuests(ctx context.Context, filter guest.Filter) (*booking.Page[guest.Guest], error) {
+	filter.Normalize("name", booking.SortAsc)
+	return s.repo.Guest().List(ctx, filter)
+}
+
+func (s *service) GetProfile(ctx context.Context, id int64) (*guest.Profile, error) {
+	g, err := s.repo.Guest().GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrGuestNotFound
+	}
+
+	stays, err := s.repo.Booking().GetByGuestWithRooms(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &guest.Profile{Guest: *g, Stays: []booking.BookingWithRoom{}}
+	for _, b := range stays {
+		profile.Stays = append(profile.Stays, b)
+
+		switch b.Status {
+		case booking.BookingStatusCheckedIn, booking.BookingStatusCheckedOut:
+			profile.StayCount++
+			profile.Nights += b.StartDate.DaysUntil(b.EndDate)
+			profile.LifetimeValue += b.Price
+			if profile.LastStay == nil || b.StartDate.After(*profile.LastStay) {
+				start := b.StartDate
+				profile.LastStay = &start
+			}
+		case booking.BookingStatusNoShow:
+			profile.LifetimeValue += b.Penalty
+		case booking.BookingStatusPending, booking.BookingStatusConfirmed:
+			profile.UpcomingValue += b.Price
+		}
+	}
+
+	return profile, nil
+}
+
+func (s *service) UpdateGuest(ctx context.Context, id int64, req guest.UpdateGuestRequest) (*guest.Guest, error) {
+	g, err := s.repo.Guest().GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrGuestNotFound
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return nil, ErrInvalidName
+		}
+		g.Name = *req.Name
+	}
+	if req.Phone != nil {
+		g.Phone = *req.Phone
+		g.NormalizedPhone = guest.NormalizePhone(*req.Phone)
+	}
+	if req.Preferences != nil {
+		g.Preferences = req.Preferences
+	}
+	if req.Notes != nil {
+		g.Notes = *req.Notes
+	}
+
+	if err := s.repo.Guest().Update(ctx, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func (s *service) GetDuplicates(ctx context.Context) ([]guest.DuplicateCandidate, error) {
+	candidates, err := s.repo.Guest().GetDuplicates(ctx, booking.DefaultPageLimit)
+	if err != nil {
+		return nil, err
+	}
+	if candidates == nil {
+		candidates = []guest.DuplicateCandidate{}
+	}
+	return candidates, nil
+}
+
+func (s *service) MergeGuests(ctx context.Context, req guest.MergeRequest) (*guest.Profile, error) {
+	if req.SourceID == req.TargetID {
+		return nil, ErrInvalidMerge
+	}
+
+	for _, id := range []int64{req.SourceID, req.TargetID} {
+		g, err := s.repo.Guest().GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if g == nil {
+			return nil, ErrGuestNotFound
+		}
+	}
+
+	if err := s.repo.Guest().Merge(ctx, req.SourceID, req.TargetID); err != nil {
+		return nil, err
+	}
+
+	return s.GetProfile(ctx, req.TargetID)
+}
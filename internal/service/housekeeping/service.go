@@ -0,0 +1,182 @@
+package housekeeping
+
+import (
+	"context"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/housekeeping"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+)
+
+var (
+	ErrRoomNotFound      = apperr.NotFound("room_not_found", "room not found")
+	ErrTaskNotFound      = apperr.NotFound("task_not_found", "task not found")
+	ErrInvalidRoomStatus = apperr.Validation("invalid_housekeeping_status", "invalid housekeeping status")
+	ErrInvalidTaskType   = apperr.Validation("invalid_task_type", "invalid task type")
+	ErrInvalidTaskStatus = apperr.Validation("invalid_task_status", "invalid task status")
+	ErrTaskClosed        = apperr.Conflict("task_closed", "task is already done")
+)
+
+type Service interface {
+	GetTasks(ctx context.Context, filter housekeeping.TaskFilter) ([]housekeeping.TaskWithRoom, error)
+	CreateTask(ctx context.Context, req housekeeping.CreateTaskRequest) (*housekeeping.Task, error)
+	UpdateTask(ctx context.Context, id int64, req housekeeping.UpdateTaskRequest) (*housekeeping.Task, error)
+
+	UpdateRoomStatus(ctx context.Context, roomID int64, status booking.HousekeepingStatus) (*booking.Room, error)
+}
+
+type service struct {
+	ctx      context.Context
+	repo     repository.Repository
+	location *time.Location
+}
+
+func NewService(ctx context.Context, repo repository.Repository, location *time.Location) (Service, error) {
+	return &service{
+		ctx:      ctx,
+		repo:     repo,
+		location: location,
+	}, nil
+}
+
+func (s *service) GetTasks(ctx context.Context, filter housekeeping.TaskFilter) ([]housekeeping.TaskWithRoom, error) {
+	return s.repo.Housekeeping().GetTasks(ctx, filter)
+}
+
+func (s *service) CreateTask(ctx context.Context, req housekeeping.CreateTaskRequest) (*housekeeping.Task, error) {
+	switch req.Type {
+	case housekeeping.TaskTypeCleaning, housekeeping.TaskTypeInspection, housekeeping.TaskTypeRepair:
+	default:
+		return nil, ErrInvalidTaskType
+	}
+
+	room, err := s.repo.Room().GetByID(ctx, req.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	task := &housekeeping.Task{
+		RoomID:   req.RoomID,
+		Type:     req.Type,
+		Status:   housekeeping.TaskStatusOpen,
+		Assignee: req.Assignee,
+		Notes:    req.Notes,
+		DueDate:  req.DueDate,
+	}
+	if task.DueDate.IsZero() {
+		task.DueDate = booking.Today(s.location)
+	}
+
+	err = s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		if err := repo.Housekeeping().CreateTask(ctx, task); err != nil {
+			return err
+		}
+		if req.Type == housekeeping.TaskTypeRepair {
+			return repo.Room().UpdateHousekeepingStatus(ctx, req.RoomID, booking.HousekeepingStatusOutOfOrder)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+func (s *service) UpdateTask(ctx context.Context, id int64, req housekeeping.UpdateTaskRequest) (*housekeeping.Task, error) {
+	task, err := s.repo.Housekeeping().GetTaskByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+	if task.Status == housekeeping.TaskStatusDone {
+		return nil, ErrTaskClosed
+	}
+
+	if req.Assignee != nil {
+		task.Assignee = *req.Assignee
+	}
+	if req.Notes != nil {
+		task.Notes = *req.Notes
+	}
+
+	switch req.Status {
+	case "":
+	case housekeeping.TaskStatusOpen, housekeeping.TaskStatusInProgress:
+		task.Status = req.Status
+	case housekeeping.TaskStatusDone:
+		now := time.Now().In(s.location)
+		task.Status = req.Status
+		task.CompletedAt = &now
+	default:
+		return nil, ErrInvalidTaskStatus
+	}
+
+	err = s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		if err := repo.Housekeeping().UpdateTask(ctx, task); err != nil {
+			return err
+		}
+		if task.Status == housekeeping.TaskStatusDone {
+			return s.completeTask(ctx, repo, task)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
+// completeTask moves the room along the housekeeping cycle:
+// repaired rooms need cleaning, cleaned rooms need an inspection and an
+// inspected room is ready for the next arrival. It writes through repo so
+// that it commits together with the task.
+func (s *service) completeTask(ctx context.Context, repo repository.Repository, task *housekeeping.Task) error {
+	switch task.Type {
+	case housekeeping.TaskTypeRepair:
+		return repo.Room().UpdateHousekeepingStatus(ctx, task.RoomID, booking.HousekeepingStatusDirty)
+	case housekeeping.TaskTypeCleaning:
+		if err := repo.Room().UpdateHousekeepingStatus(ctx, task.RoomID, booking.HousekeepingStatusClean); err != nil {
+			return err
+		}
+		return repo.Housekeeping().CreateTask(ctx, &housekeeping.Task{
+			RoomID:    task.RoomID,
+			BookingID: task.BookingID,
+			Type:      housekeeping.TaskTypeInspection,
+			Status:    housekeeping.TaskStatusOpen,
+			DueDate:   booking.Today(s.location),
+		})
+	case housekeeping.TaskTypeInspection:
+		return repo.Room().UpdateHousekeepingStatus(ctx, task.RoomID, booking.HousekeepingStatusInspected)
+	}
+	return nil
+}
+
+func (s *service) UpdateRoomStatus(ctx context.Context, roomID int64, status booking.HousekeepingStatus) (*booking.Room, error) {
+	if !status.IsValid() {
+		return nil, ErrInvalidRoomStatus
+	}
+
+	room, err := s.repo.Room().GetByID(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	if err := s.repo.Room().UpdateHousekeepingStatus(ctx, roomID, status); err != nil {
+		return nil, err
+	}
+
+	room.HousekeepingStatus = status
+	return room, nil
+}
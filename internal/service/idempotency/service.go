@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/idempotency"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+)
+
+var (
+	ErrKeyReused     = apperr.Conflict("idempotency_key_reused", "idempotency key was already used for a different request")
+	ErrKeyInProgress = apperr.Conflict("idempotency_key_in_progress", "a request with this idempotency key is still being processed")
+)
+
+type Service interface {
+	// Begin reserves key for the request identified by fingerprint. When the
+	// same request was already handled it returns the stored record, whose
+	// response must be replayed instead of handling the request again.
+	Begin(ctx context.Context, key, fingerprint string) (*idempotency.Record, error)
+	// Complete stores the response of a request begun with key.
+	Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error
+	// Release frees key without storing a response, so that the client can
+	// retry a request that failed.
+	Release(ctx context.Context, key string) error
+	PurgeExpired(ctx context.Context) (int64, error)
+
+	StartPurgeWorker(ctx context.Context)
+}
+
+type service struct {
+	ctx  context.Context
+	repo repository.Repository
+	ttl  time.Duration
+}
+
+func NewService(ctx context.Context, repo repository.Repository, ttl time.Duration) (Service, error) {
+	return &service{
+		ctx:  ctx,
+		repo: repo,
+		ttl:  ttl,
+	}, nil
+}
+
+func (s *service) Begin(ctx context.Context, key, fingerprint string) (*idempotency.Record, error) {
+	existing, err := s.repo.Idempotency().Reserve(ctx, &idempotency.Record{Key: key, Fingerprint: fingerprint}, s.ttl)
+	if err != nil || existing == nil {
+		return nil, err
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return nil, ErrKeyReused
+	}
+	if !existing.Completed() {
+		return nil, ErrKeyInProgress
+	}
+	return existing, nil
+}
+
+func (s *service) Complete(ctx context.Context, key string, statusCode int, contentType string, body []byte) error {
+	return s.repo.Idempotency().Complete(ctx, key, statusCode, contentType, body)
+}
+
+func (s *service) Release(ctx context.Context, key string) error {
+	return s.repo.Idempotency().Release(ctx, key)
+}
+
+func (s *service) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repo.Idempotency().DeleteExpired(ctx)
+}
+
+func (s *service) StartPurgeWorker(ctx context.Context) {
+	go func() {
+		fmt.Println(" Idempotency key purge worker started")
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+
+		for {
+			purged, err := s.PurgeExpired(ctx)
+			if err != nil {
+				fmt.Printf(" Idempotency key purge failed: %v\n", err)
+			} else if purged > 0 {
+				fmt.Printf(" Idempotency keys purged: %d\n", purged)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				fmt.Println(" Idempotency key purge worker stopping...")
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,182 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/live"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/notification"
+	notificationService "github.com/YurcheuskiRadzivon/booking-system/internal/service/notification"
+)
+
+const (
+	// clientBuffer is how many events a client may fall behind by before it
+	// is disconnected; it catches up on reconnect through Last-Event-ID.
+	clientBuffer = 64
+)
+
+// Subscription is one connected client.
+type Subscription struct {
+	// Missed holds the events the client is allowed to see that were
+	// published after the Last-Event-ID it reconnected with.
+	Missed []live.Event
+	// Stale is set instead of Missed when those events are no longer
+	// buffered, so the client has to reload.
+	Stale bool
+	// LastID is the ID of the latest event at the time of subscribing.
+	LastID int64
+	// Events delivers new events; it is closed when the client falls behind
+	// or the service stops.
+	Events <-chan live.Event
+
+	filter live.Filter
+	events chan live.Event
+}
+
+type Service interface {
+	// Subscribe connects a client. lastEventID is the last event it received
+	// before reconnecting, or negative for a new client.
+	Subscribe(filter live.Filter, lastEventID int64) *Subscription
+	Unsubscribe(sub *Subscription)
+
+	// StartWorker numbers the lifecycle events published on the broker and
+	// fans them out to the connected clients.
+	StartWorker(ctx context.Context)
+}
+
+type service struct {
+	ctx    context.Context
+	broker *notificationService.MessageBroker
+
+	mu      sync.Mutex
+	lastID  int64
+	history []live.Event
+	// historySize is how many recent events are kept for replay.
+	historySize int
+	clients     map[*Subscription]struct{}
+	stopped     bool
+}
+
+func NewService(ctx context.Context, broker *notificationService.MessageBroker, historySize int) (Service, error) {
+	if historySize < 1 {
+		historySize = 1
+	}
+
+	return &service{
+		ctx:         ctx,
+		broker:      broker,
+		historySize: historySize,
+		clients:     make(map[*Subscription]struct{}),
+	}, nil
+}
+
+func (s *service) Subscribe(filter live.Filter, lastEventID int64) *Subscription {
+	events := make(chan live.Event, clientBuffer)
+	sub := &Subscription{Events: events, filter: filter, events: events}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub.LastID = s.lastID
+	if lastEventID >= 0 {
+		// IDs restart with the server, so an ID from the future means the
+		// client saw events of a previous run.
+		oldest := s.lastID - int64(len(s.history)) + 1
+		sub.Stale = lastEventID > s.lastID || lastEventID < oldest-1
+		for _, e := range s.history {
+			if sub.Stale {
+				break
+			}
+			if e.ID > lastEventID && filter.Allows(e) {
+				sub.Missed = append(sub.Missed, e)
+			}
+		}
+	}
+
+	if s.stopped {
+		close(events)
+		return sub
+	}
+	s.clients[sub] = struct{}{}
+	return sub
+}
+
+func (s *service) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.clients[sub]; ok {
+		delete(s.clients, sub)
+		close(sub.events)
+	}
+}
+
+func (s *service) StartWorker(ctx context.Context) {
+	events := s.broker.Subscribe(notification.NotificationChannelLifecycle, 100)
+
+	go func() {
+		fmt.Println(" Live updates worker started")
+		defer s.stop()
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					fmt.Println(" Live updates worker stopped")
+					return
+				}
+				s.publish(event)
+			case <-ctx.Done():
+				fmt.Println(" Live updates worker stopping...")
+				return
+			}
+		}
+	}()
+}
+
+func (s *service) publish(event notification.NotificationEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	e := live.Event{
+		ID:        s.lastID,
+		Type:      event.Type,
+		Data:      event.Data,
+		CreatedAt: event.CreatedAt,
+	}
+	if b, ok := event.Data["booking"].(booking.Booking); ok {
+		e.GuestEmail = b.GuestInfo.Email
+	}
+
+	s.history = append(s.history, e)
+	if len(s.history) > s.historySize {
+		s.history = s.history[len(s.history)-s.historySize:]
+	}
+
+	for sub := range s.clients {
+		if !sub.filter.Allows(e) {
+			continue
+		}
+		select {
+		case sub.events <- e:
+		default:
+			delete(s.clients, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// stop disconnects every client so that open streams end.
+func (s *service) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	for sub := range s.clients {
+		delete(s.clients, sub)
+		close(sub.events)
+	}
+}
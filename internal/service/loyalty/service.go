@@ -0,0 +1,191 @@
+package loyalty
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/guest"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/loyalty"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+)
+
+var (
+	ErrGuestNotFound     = apperr.NotFound("guest_not_found", "guest not found")
+	ErrInvalidAdjustment = apperr.Validation("invalid_adjustment", "adjustment must change the balance and include a reason and actor")
+	ErrNegativeBalance   = apperr.Conflict("negative_balance", "adjustment would make the balance negative")
+)
+
+type Service interface {
+	GetAccount(ctx context.Context, guestID int64) (*loyalty.Account, error)
+	GetAccountByEmail(ctx context.Context, email string) (*loyalty.Account, error)
+	Earning(b *booking.Booking) *loyalty.LedgerEntry
+	Adjust(ctx context.Context, guestID int64, req loyalty.AdjustRequest) (*loyalty.Account, error)
+	ExpirePoints(ctx context.Context) (int64, error)
+
+	StartExpiryWorker(ctx context.Context)
+}
+
+type service struct {
+	ctx      context.Context
+	repo     repository.Repository
+	location *time.Location
+	policy   loyalty.Policy
+}
+
+func NewService(ctx context.Context, repo repository.Repository, location *time.Location, policy loyalty.Policy) (Service, error) {
+	return &service{
+		ctx:      ctx,
+		repo:     repo,
+		location: location,
+		policy:   policy,
+	}, nil
+}
+
+func (s *service) GetAccount(ctx context.Context, guestID int64) (*loyalty.Account, error) {
+	g, err := s.repo.Guest().GetByID(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrGuestNotFound
+	}
+
+	balance, err := s.repo.Loyalty().GetBalance(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := booking.Today(s.location).AddDays(-s.policy.QualifyingDays)
+	qualifying, err := s.repo.Loyalty().GetEarnedSince(ctx, guestID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.Loyalty().GetEntries(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []loyalty.LedgerEntry{}
+	}
+
+	tier := s.policy.Tier(qualifying)
+	nextTier, toNext := s.policy.NextTier(qualifying)
+	return &loyalty.Account{
+		GuestID:          guestID,
+		Balance:          balance,
+		BalanceValue:     s.policy.Value(balance),
+		Tier:             tier,
+		Discount:         s.policy.Discount(tier),
+		QualifyingPoints: qualifying,
+		NextTier:         nextTier,
+		PointsToNextTier: toNext,
+		Entries:          entries,
+	}, nil
+}
+
+func (s *service) GetAccountByEmail(ctx context.Context, email string) (*loyalty.Account, error) {
+	g, err := s.repo.Guest().GetByNormalizedEmail(ctx, guest.NormalizeEmail(email))
+	if err != nil {
+		return nil, err
+	}
+	if g == nil {
+		return nil, ErrGuestNotFound
+	}
+	return s.GetAccount(ctx, g.ID)
+}
+
+// Earning returns the ledger entry crediting the guest with the points the
+// stay earns, or nil when it earns none. It is written at checkout; a booking
+// earns points at most once.
+func (s *service) Earning(b *booking.Booking) *loyalty.LedgerEntry {
+	if b.GuestID == nil {
+		return nil
+	}
+
+	points := s.policy.PointsFor(b.Price - b.PointsAmount)
+	if points <= 0 {
+		return nil
+	}
+
+	expires := s.policy.ExpiresOn(booking.Today(s.location))
+	bookingID := b.ID
+	return &loyalty.LedgerEntry{
+		GuestID:   *b.GuestID,
+		BookingID: &bookingID,
+		Type:      loyalty.EntryTypeEarn,
+		Points:    points,
+		Remaining: points,
+		ExpiresAt: &expires,
+		Reason:    fmt.Sprintf("Prozhivanie #%d", b.ID),
+	}
+}
+
+// Adjust credits or debits points by hand. The entry keeps who made the
+// change and why, so the ledger doubles as the audit trail.
+func (s *service) Adjust(ctx context.Context, guestID int64, req loyalty.AdjustRequest) (*loyalty.Account, error) {
+	if req.Points == 0 || req.Reason == "" || req.Actor == "" {
+		return nil, ErrInvalidAdjustment
+	}
+
+	account, err := s.GetAccount(ctx, guestID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &loyalty.LedgerEntry{
+		GuestID: guestID,
+		Type:    loyalty.EntryTypeAdjust,
+		Points:  req.Points,
+		Reason:  req.Reason,
+		Actor:   req.Actor,
+	}
+
+	if req.Points > 0 {
+		expires := s.policy.ExpiresOn(booking.Today(s.location))
+		entry.Remaining = req.Points
+		entry.ExpiresAt = &expires
+		err = s.repo.Loyalty().AddEntry(ctx, entry)
+	} else {
+		if account.Balance+req.Points < 0 {
+			return nil, ErrNegativeBalance
+		}
+		err = s.repo.Loyalty().Redeem(ctx, entry)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetAccount(ctx, guestID)
+}
+
+func (s *service) ExpirePoints(ctx context.Context) (int64, error) {
+	return s.repo.Loyalty().ExpirePoints(ctx, booking.Today(s.location))
+}
+
+func (s *service) StartExpiryWorker(ctx context.Context) {
+	go func() {
+		fmt.Println(" Loyalty expiry worker started")
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+
+		for {
+			expired, err := s.ExpirePoints(ctx)
+			if err != nil {
+				fmt.Printf(" Loyalty points expiry failed: %v\n", err)
+			} else if expired > 0 {
+				fmt.Printf(" Loyalty points expired: %d lots\n", expired)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				fmt.Println(" Loyalty expiry worker stopping...")
+				return
+			}
+		}
+	}()
+}
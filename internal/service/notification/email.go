@@ -16,6 +16,9 @@ func (h *EmailHandler) Send(event notification.NotificationEvent) bool {
 	fmt.Printf("   Email для %s:\n", event.Recipient)
 	fmt.Printf("   Тема: %s\n", event.Subject)
 	fmt.Printf("   Сообщение: %s\n", event.Message)
+	for _, a := range event.Attachments {
+		fmt.Printf("   Вложение: %s (%s, %d байт)\n", a.Filename, a.ContentType, len(a.Content))
+	}
 	return true
 }
 
@@ -24,8 +24,22 @@ type Service interface {
 	Broadcast(ctx context.Context, channels []notification.NotificationChannel, recipient, subject, message string) ([]notification.NotificationResponse, error)
 
 	NotifyBookingCreated(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room) error
-	NotifyBookingConfirmed(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room) error
+	// NotifyBookingConfirmed confirms the booking on every channel; the email
+	// carries the stay as a calendar event.
+	NotifyBookingConfirmed(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room, calendarEvent *notification.Attachment) error
 	NotifyBookingCancelled(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room) error
+	// NotifyBookingModified tells the guest about new dates or room details.
+	NotifyBookingModified(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room) error
+	// NotifyCheckedOut thanks the guest for the stay and sends the invoice.
+	NotifyCheckedOut(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room, invoice *notification.Attachment) error
+	// NotifyBookingStatusChanged and NotifyRoomStatusChanged only publish
+	// lifecycle events; the guest is not messaged.
+	NotifyBookingStatusChanged(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room) error
+	NotifyRoomStatusChanged(ctx context.Context, room *bookingModel.Room) error
+	// NotifyWaitlistOffer tells a waitlisted guest that a room is held for
+	// them until expiresAt and sends the link to claim it.
+	NotifyWaitlistOffer(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room, holdURL string, expiresAt time.Time) error
+	NotifyWaitlistHoldExpired(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room) error
 
 	GetNotificationTypes(ctx context.Context) ([]notification.NotificationType, error)
 
@@ -90,7 +104,7 @@ func (s *service) processEvent(event notification.NotificationEvent) {
 
 	fmt.Printf("\n--- Processing notification [%s] ---\n", event.Type)
 	handler.Send(event)
-	fmt.Println("--- Notification sent ---\n")
+	fmt.Print("--- Notification sent ---\n\n")
 }
 
 func (s *service) SendEmail(ctx context.Context, recipient, subject, message string) (*notification.NotificationResponse, error) {
@@ -208,23 +222,57 @@ func (s *service) NotifyBookingCreated(ctx context.Context, booking *bookingMode
 	}
 
 	s.broker.Publish(event)
+	s.publishLifecycle(notification.EventTypeBookingCreated, booking, room)
 	return nil
 }
 
-func (s *service) NotifyBookingConfirmed(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room) error {
+// publishLifecycle announces a booking event on the lifecycle channel for
+// webhooks and live updates rather than the guest's inbox.
+func (s *service) publishLifecycle(eventType notification.EventType, booking *bookingModel.Booking, room *bookingModel.Room) {
+	s.broker.Publish(notification.NotificationEvent{
+		ID:      uuid.New().String(),
+		Type:    eventType,
+		Channel: notification.NotificationChannelLifecycle,
+		Data: map[string]any{
+			"booking": *booking,
+			"room":    *room,
+		},
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *service) NotifyBookingConfirmed(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room, calendarEvent *notification.Attachment) error {
 	message := s.formatBookingMessage(
 		"Booking Confirmed! We are waiting for you!",
 		booking,
 		room,
 	)
 
+	email := notification.NotificationEvent{
+		ID:        uuid.New().String(),
+		Type:      notification.EventTypeBookingConfirmed,
+		Channel:   notification.NotificationChannelEmail,
+		Recipient: booking.GuestInfo.Email,
+		Subject:   "Booking Confirmed - Room " + room.RoomNumber,
+		Message:   message,
+		Data: map[string]any{
+			"booking_id": booking.ID,
+			"room_id":    room.ID,
+		},
+		CreatedAt: time.Now(),
+	}
+	if calendarEvent != nil {
+		email.Attachments = []notification.Attachment{*calendarEvent}
+	}
+	s.broker.Publish(email)
+
 	channels := []notification.NotificationChannel{
-		notification.NotificationChannelEmail,
 		notification.NotificationChannelSMS,
 		notification.NotificationChannelViber,
 	}
 
 	s.Broadcast(ctx, channels, booking.GuestInfo.Email, "Booking Confirmed - Room "+room.RoomNumber, message)
+	s.publishLifecycle(notification.EventTypeBookingConfirmed, booking, room)
 	return nil
 }
 
@@ -244,6 +292,129 @@ func (s *service) NotifyBookingCancelled(ctx context.Context, booking *bookingMo
 	}
 
 	s.Broadcast(ctx, channels, booking.GuestInfo.Email, "Booking Cancelled - Room "+room.RoomNumber, message)
+	s.publishLifecycle(notification.EventTypeBookingCancelled, booking, room)
+	return nil
+}
+
+func (s *service) NotifyBookingModified(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room) error {
+	message := s.formatBookingMessage(
+		"Your booking has been updated.",
+		booking,
+		room,
+	)
+
+	event := notification.NotificationEvent{
+		ID:        uuid.New().String(),
+		Type:      notification.EventTypeBookingModified,
+		Channel:   notification.NotificationChannelEmail,
+		Recipient: booking.GuestInfo.Email,
+		Subject:   "Booking Updated - Room " + room.RoomNumber,
+		Message:   message,
+		Data: map[string]any{
+			"booking_id": booking.ID,
+			"room_id":    room.ID,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	s.broker.Publish(event)
+	s.publishLifecycle(notification.EventTypeBookingModified, booking, room)
+	return nil
+}
+
+func (s *service) NotifyBookingStatusChanged(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room) error {
+	s.publishLifecycle(notification.EventTypeBookingStatusChanged, booking, room)
+	return nil
+}
+
+func (s *service) NotifyRoomStatusChanged(ctx context.Context, room *bookingModel.Room) error {
+	s.broker.Publish(notification.NotificationEvent{
+		ID:        uuid.New().String(),
+		Type:      notification.EventTypeRoomStatusChanged,
+		Channel:   notification.NotificationChannelLifecycle,
+		Data:      map[string]any{"room": *room},
+		CreatedAt: time.Now(),
+	})
+	return nil
+}
+
+func (s *service) NotifyWaitlistOffer(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room, holdURL string, expiresAt time.Time) error {
+	message := fmt.Sprintf(
+		"Good news: a room is available for your dates!\n\nRoom: %s\nDates: %s - %s\nTotal: %.2f\n\nIt is held for you until %s. Confirm your booking here:\n%s",
+		room.RoomNumber,
+		booking.StartDate.Format("02.01.2006"),
+		booking.EndDate.Format("02.01.2006"),
+		booking.Price,
+		expiresAt.Format("02.01.2006 15:04"),
+		holdURL,
+	)
+
+	channels := []notification.NotificationChannel{
+		notification.NotificationChannelEmail,
+		notification.NotificationChannelSMS,
+		notification.NotificationChannelViber,
+	}
+
+	s.Broadcast(ctx, channels, booking.GuestInfo.Email, "Room Available - Room "+room.RoomNumber, message)
+	s.publishLifecycle(notification.EventTypeBookingCreated, booking, room)
+	return nil
+}
+
+func (s *service) NotifyWaitlistHoldExpired(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room) error {
+	message := fmt.Sprintf(
+		"The room held for you was released because the booking was not confirmed in time.\nRoom: %s\nDates: %s - %s",
+		room.RoomNumber,
+		booking.StartDate.Format("02.01.2006"),
+		booking.EndDate.Format("02.01.2006"),
+	)
+
+	event := notification.NotificationEvent{
+		ID:        uuid.New().String(),
+		Type:      notification.EventTypeWaitlistHoldExpired,
+		Channel:   notification.NotificationChannelEmail,
+		Recipient: booking.GuestInfo.Email,
+		Subject:   "Room Hold Expired - Room " + room.RoomNumber,
+		Message:   message,
+		Data: map[string]any{
+			"booking_id": booking.ID,
+			"room_id":    room.ID,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	s.broker.Publish(event)
+	s.publishLifecycle(notification.EventTypeBookingCancelled, booking, room)
+	return nil
+}
+
+func (s *service) NotifyCheckedOut(ctx context.Context, booking *bookingModel.Booking, room *bookingModel.Room, invoice *notification.Attachment) error {
+	message := fmt.Sprintf(
+		"Thank you for staying with us!\n\nBooking ID: #%d\nRoom: %s\nDates: %s - %s\n",
+		booking.ID,
+		room.RoomNumber,
+		booking.StartDate.Format("02.01.2006"),
+		booking.EndDate.Format("02.01.2006"),
+	)
+
+	event := notification.NotificationEvent{
+		ID:        uuid.New().String(),
+		Type:      notification.EventTypeCheckedOut,
+		Channel:   notification.NotificationChannelEmail,
+		Recipient: booking.GuestInfo.Email,
+		Subject:   "Your invoice - Room " + room.RoomNumber,
+		Message:   message,
+		Data: map[string]any{
+			"booking_id": booking.ID,
+			"room_id":    room.ID,
+		},
+		CreatedAt: time.Now(),
+	}
+	if invoice != nil {
+		event.Attachments = []notification.Attachment{*invoice}
+	}
+
+	s.broker.Publish(event)
+	s.publishLifecycle(notification.EventTypeCheckedOut, booking, room)
 	return nil
 }
 
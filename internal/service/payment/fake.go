@@ -0,0 +1,154 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/payment"
+)
+
+// Card tokens understood by the fake gateway; any other token is approved.
+const (
+	FakeCardDeclined          = "fake_card_declined"
+	FakeCardInsufficientFunds = "fake_card_insufficient_funds"
+	FakeCardUnreachable       = "fake_card_unreachable"
+)
+
+var errFakeUnreachable = errors.New("fake gateway: provider unreachable")
+
+type fakeAuthorization struct {
+	amount   float64
+	captured float64
+	refunded float64
+	voided   bool
+}
+
+// FakeGateway is an in-process gateway for development and demos. It keeps
+// authorizations in memory, numbers its references sequentially and decides
+// by card token, so the same calls always give the same answers. Its state
+// is lost on restart.
+type FakeGateway struct {
+	secret []byte
+
+	mu             sync.Mutex
+	seq            int64
+	authorizations map[string]*fakeAuthorization
+}
+
+func NewFakeGateway(webhookSecret string) *FakeGateway {
+	return &FakeGateway{
+		secret:         []byte(webhookSecret),
+		authorizations: make(map[string]*fakeAuthorization),
+	}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) nextRef(prefix string) string {
+	g.seq++
+	return fmt.Sprintf("fake_%s_%06d", prefix, g.seq)
+}
+
+func (g *FakeGateway) Authorize(ctx context.Context, paymentMethod string, amount float64, currency string) (GatewayResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	switch paymentMethod {
+	case FakeCardUnreachable:
+		return GatewayResponse{}, errFakeUnreachable
+	case FakeCardDeclined:
+		return GatewayResponse{Ref: g.nextRef("auth"), Declined: true, Reason: "card declined"}, nil
+	case FakeCardInsufficientFunds:
+		return GatewayResponse{Ref: g.nextRef("auth"), Declined: true, Reason: "insufficient funds"}, nil
+	}
+
+	ref := g.nextRef("auth")
+	g.authorizations[ref] = &fakeAuthorization{amount: amount}
+	return GatewayResponse{Ref: ref}, nil
+}
+
+func (g *FakeGateway) Capture(ctx context.Context, ref string, amount float64) (GatewayResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	auth, ok := g.authorizations[ref]
+	switch {
+	case !ok:
+		return GatewayResponse{Declined: true, Reason: "unknown authorization"}, nil
+	case auth.voided:
+		return GatewayResponse{Declined: true, Reason: "authorization voided"}, nil
+	case auth.captured+amount > auth.amount+0.005:
+		return GatewayResponse{Declined: true, Reason: "amount exceeds authorization"}, nil
+	}
+
+	auth.captured += amount
+	return GatewayResponse{Ref: g.nextRef("capture")}, nil
+}
+
+func (g *FakeGateway) Refund(ctx context.Context, ref string, amount float64) (GatewayResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	auth, ok := g.authorizations[ref]
+	switch {
+	case !ok:
+		return GatewayResponse{Declined: true, Reason: "unknown authorization"}, nil
+	case auth.refunded+amount > auth.captured+0.005:
+		return GatewayResponse{Declined: true, Reason: "amount exceeds captured funds"}, nil
+	}
+
+	auth.refunded += amount
+	return GatewayResponse{Ref: g.nextRef("refund")}, nil
+}
+
+func (g *FakeGateway) Void(ctx context.Context, ref string) (GatewayResponse, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	auth, ok := g.authorizations[ref]
+	switch {
+	case !ok:
+		return GatewayResponse{Declined: true, Reason: "unknown authorization"}, nil
+	case auth.captured > 0:
+		return GatewayResponse{Declined: true, Reason: "authorization already captured"}, nil
+	}
+
+	auth.voided = true
+	return GatewayResponse{Ref: g.nextRef("void")}, nil
+}
+
+// Sign returns the signature the fake gateway puts on a webhook payload:
+// hex-encoded HMAC-SHA256 with the webhook secret.
+func (g *FakeGateway) Sign(payload []byte) string {
+	return hex.EncodeToString(g.hmac(payload))
+}
+
+func (g *FakeGateway) ParseWebhook(payload []byte, signature string) (*payment.WebhookEvent, error) {
+	if len(g.secret) == 0 {
+		return nil, ErrWebhookNotConfigured
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, g.hmac(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event payment.WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil || event.ID == "" || event.ProviderRef == "" {
+		return nil, ErrInvalidWebhook
+	}
+	return &event, nil
+}
+
+func (g *FakeGateway) hmac(payload []byte) []byte {
+	mac := hmac.New(sha256.New, g.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
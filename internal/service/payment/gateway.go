@@ -0,0 +1,28 @@
+package payment
+
+import (
+	"context"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/payment"
+)
+
+// Gateway is a card payment provider. A call the provider refuses (e.g. a
+// declined card) is reported with Declined; an error means the provider
+// could not be reached or answered with garbage.
+type Gateway interface {
+	Name() string
+	Authorize(ctx context.Context, paymentMethod string, amount float64, currency string) (GatewayResponse, error)
+	Capture(ctx context.Context, ref string, amount float64) (GatewayResponse, error)
+	Refund(ctx context.Context, ref string, amount float64) (GatewayResponse, error)
+	Void(ctx context.Context, ref string) (GatewayResponse, error)
+	// ParseWebhook verifies the signature of a webhook payload and decodes it.
+	ParseWebhook(payload []byte, signature string) (*payment.WebhookEvent, error)
+}
+
+type GatewayResponse struct {
+	// Ref identifies the authorization for Authorize and the operation
+	// itself for the other calls.
+	Ref      string
+	Declined bool
+	Reason   string
+}
@@ -0,0 +1,509 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/folio"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/payment"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+)
+
+// Amounts closer than this are equal; they are kept in kopecks.
+const epsilon = 0.005
+
+var (
+	ErrBookingNotFound      = apperr.NotFound("booking_not_found", "booking not found")
+	ErrPaymentNotFound      = apperr.NotFound("payment_not_found", "payment not found")
+	ErrBookingNotPayable    = apperr.Conflict("booking_not_payable", "cancelled and no-show bookings can't be paid")
+	ErrNothingDue           = apperr.Conflict("nothing_due", "the booking is already paid in full")
+	ErrAmountExceedsBalance = apperr.Validation("amount_exceeds_balance", "amount exceeds the unpaid balance of the booking")
+	ErrAmountTooLarge       = apperr.Validation("amount_too_large", "amount exceeds what the payment allows")
+	ErrInvalidPaymentState  = apperr.Conflict("invalid_payment_state", "the operation is not allowed in the current payment status")
+	ErrPaymentDeclined      = apperr.Conflict("payment_declined", "payment declined")
+	ErrGatewayUnavailable   = apperr.Unavailable("payment_gateway_unavailable", "payment gateway is unavailable")
+	ErrInvalidSignature     = apperr.Forbidden("invalid_signature", "webhook signature is invalid")
+	ErrInvalidWebhook       = apperr.Validation("invalid_webhook", "webhook payload is invalid")
+	ErrWebhookNotConfigured = apperr.Unavailable("webhook_not_configured", "payment webhooks are not configured")
+)
+
+type Service interface {
+	GetSummary(ctx context.Context, bookingID int64) (*payment.Summary, error)
+	Pay(ctx context.Context, bookingID int64, req payment.CreatePaymentRequest) (*payment.Result, error)
+	Capture(ctx context.Context, paymentID int64, req payment.CaptureRequest) (*payment.Result, error)
+	Refund(ctx context.Context, paymentID int64, req payment.RefundRequest) (*payment.Payment, error)
+	Void(ctx context.Context, paymentID int64) (*payment.Payment, error)
+	// HandleWebhook applies a gateway event. It returns nil for events that
+	// were already applied or concern unknown payments.
+	HandleWebhook(ctx context.Context, payload []byte, signature string) (*payment.Result, error)
+}
+
+type service struct {
+	ctx     context.Context
+	repo    repository.Repository
+	gateway Gateway
+	policy  payment.Policy
+	taxes   folio.TaxPolicy
+}
+
+func NewService(ctx context.Context, repo repository.Repository, gateway Gateway, policy payment.Policy, taxes folio.TaxPolicy) (Service, error) {
+	return &service{
+		ctx:     ctx,
+		repo:    repo,
+		gateway: gateway,
+		policy:  policy,
+		taxes:   taxes,
+	}, nil
+}
+
+func (s *service) getBooking(ctx context.Context, repo repository.Repository, id int64) (*booking.Booking, error) {
+	b, err := repo.Booking().GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrBookingNotFound
+	}
+	return b, nil
+}
+
+func (s *service) lockBooking(ctx context.Context, repo repository.Repository, id int64) (*booking.Booking, error) {
+	b, err := repo.Booking().Lock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, ErrBookingNotFound
+	}
+	return b, nil
+}
+
+func (s *service) lockPayment(ctx context.Context, repo repository.Repository, id int64) (*payment.Payment, error) {
+	p, err := repo.Payment().Lock(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrPaymentNotFound
+	}
+	return p, nil
+}
+
+// inTransaction runs fn in a transaction. A declined or failed gateway call
+// is committed together with the transaction recording it and then returned.
+func (s *service) inTransaction(ctx context.Context, fn func(repo repository.Repository) error) error {
+	var failure error
+	err := s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		failure = fn(repo)
+		if errors.Is(failure, ErrGatewayUnavailable) || errors.Is(failure, ErrPaymentDeclined) {
+			return nil
+		}
+		return failure
+	})
+	if err != nil {
+		return err
+	}
+	return failure
+}
+
+func (s *service) GetSummary(ctx context.Context, bookingID int64) (*payment.Summary, error) {
+	b, err := s.getBooking(ctx, s.repo, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	return s.summary(ctx, s.repo, b)
+}
+
+func (s *service) summary(ctx context.Context, repo repository.Repository, b *booking.Booking) (*payment.Summary, error) {
+	payments, err := repo.Payment().GetByBookingID(ctx, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := repo.Payment().GetTransactionsByBookingID(ctx, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	charges, err := repo.Folio().GetCharges(ctx, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	f := folio.Build(*b, charges, nil, s.taxes)
+
+	summary := &payment.Summary{
+		BookingID: b.ID,
+		Total:     payment.Round(f.Total - b.PointsAmount),
+		Payments:  []payment.Payment{},
+	}
+	for _, p := range payments {
+		for _, t := range transactions {
+			if t.PaymentID == p.ID {
+				p.Transactions = append(p.Transactions, t)
+			}
+		}
+		summary.Paid += p.Paid()
+		if p.Status == payment.StatusAuthorized || p.Status == payment.StatusCaptured {
+			summary.Authorized += p.Amount - p.CapturedAmount
+		}
+		summary.Payments = append(summary.Payments, p)
+	}
+
+	summary.Paid = payment.Round(summary.Paid)
+	summary.Authorized = payment.Round(summary.Authorized)
+	summary.Balance = payment.Round(max(summary.Total-summary.Paid-summary.Authorized, 0))
+	summary.DepositDue = payment.Round(max(s.policy.Deposit(summary.Total)-summary.Paid-summary.Authorized, 0))
+	return summary, nil
+}
+
+// Pay authorizes a card payment for a booking and, if asked to, captures it.
+// A declined or failed authorization is kept as a failed payment. The booking
+// row stays locked until the payment is stored, so concurrent payments can't
+// add up to more than the balance.
+func (s *service) Pay(ctx context.Context, bookingID int64, req payment.CreatePaymentRequest) (*payment.Result, error) {
+	var (
+		b        *booking.Booking
+		p        *payment.Payment
+		captured string
+	)
+	err := s.inTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		b, err = s.lockBooking(ctx, repo, bookingID)
+		if err != nil {
+			return err
+		}
+		if b.Status == booking.BookingStatusCancelled || b.Status == booking.BookingStatusNoShow {
+			return ErrBookingNotPayable
+		}
+
+		summary, err := s.summary(ctx, repo, b)
+		if err != nil {
+			return err
+		}
+		amount := req.Amount
+		if amount == 0 {
+			amount = summary.DepositDue
+		}
+		if amount == 0 {
+			amount = summary.Balance
+		}
+		if amount == 0 {
+			return ErrNothingDue
+		}
+		if amount > summary.Balance+epsilon {
+			return ErrAmountExceedsBalance
+		}
+
+		p = &payment.Payment{
+			BookingID: b.ID,
+			Provider:  s.gateway.Name(),
+			Status:    payment.StatusAuthorized,
+			Amount:    payment.Round(amount),
+			Currency:  s.policy.Currency,
+		}
+		res, gatewayErr := s.gateway.Authorize(ctx, req.PaymentMethod, p.Amount, p.Currency)
+		p.ProviderRef = res.Ref
+		if gatewayErr != nil || res.Declined {
+			p.Status = payment.StatusFailed
+		}
+		if err := repo.Payment().Create(ctx, p); err != nil {
+			return err
+		}
+		if err := s.record(ctx, repo, p, payment.TransactionAuthorize, p.Amount, res, gatewayErr); err != nil {
+			return err
+		}
+
+		if req.Capture {
+			captured, err = s.capture(ctx, repo, p, p.Amount)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &payment.Result{Payment: *p}
+	if req.Capture {
+		s.markApplied(ctx, captured, payment.EventCaptured)
+		result.Confirmed = s.confirmIfPaid(ctx, b)
+	}
+	return result, nil
+}
+
+func (s *service) Capture(ctx context.Context, paymentID int64, req payment.CaptureRequest) (*payment.Result, error) {
+	var (
+		p   *payment.Payment
+		ref string
+	)
+	err := s.inTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		p, err = s.lockPayment(ctx, repo, paymentID)
+		if err != nil {
+			return err
+		}
+		if p.Status != payment.StatusAuthorized && p.Status != payment.StatusCaptured {
+			return ErrInvalidPaymentState
+		}
+
+		uncaptured := payment.Round(p.Amount - p.CapturedAmount)
+		amount := req.Amount
+		if amount == 0 {
+			amount = uncaptured
+		}
+		if amount == 0 {
+			return ErrInvalidPaymentState
+		}
+		if amount > uncaptured+epsilon {
+			return ErrAmountTooLarge
+		}
+
+		ref, err = s.capture(ctx, repo, p, payment.Round(amount))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.markApplied(ctx, ref, payment.EventCaptured)
+
+	b, err := s.getBooking(ctx, s.repo, p.BookingID)
+	if err != nil {
+		return nil, err
+	}
+	return &payment.Result{Payment: *p, Confirmed: s.confirmIfPaid(ctx, b)}, nil
+}
+
+// capture takes amount from a locked payment and returns the gateway's
+// reference of the operation.
+func (s *service) capture(ctx context.Context, repo repository.Repository, p *payment.Payment, amount float64) (string, error) {
+	res, gatewayErr := s.gateway.Capture(ctx, p.ProviderRef, amount)
+	if err := s.record(ctx, repo, p, payment.TransactionCapture, amount, res, gatewayErr); err != nil {
+		return "", err
+	}
+
+	p.CapturedAmount = payment.Round(p.CapturedAmount + amount)
+	p.Status = payment.StatusCaptured
+	if err := repo.Payment().Update(ctx, p); err != nil {
+		return "", err
+	}
+	return res.Ref, nil
+}
+
+func (s *service) Refund(ctx context.Context, paymentID int64, req payment.RefundRequest) (*payment.Payment, error) {
+	var (
+		p   *payment.Payment
+		ref string
+	)
+	err := s.inTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		p, err = s.lockPayment(ctx, repo, paymentID)
+		if err != nil {
+			return err
+		}
+		if p.Status != payment.StatusCaptured {
+			return ErrInvalidPaymentState
+		}
+
+		amount := req.Amount
+		if amount == 0 {
+			amount = p.Paid()
+		}
+		if amount > p.Paid()+epsilon {
+			return ErrAmountTooLarge
+		}
+		amount = payment.Round(amount)
+
+		res, gatewayErr := s.gateway.Refund(ctx, p.ProviderRef, amount)
+		if err := s.record(ctx, repo, p, payment.TransactionRefund, amount, res, gatewayErr); err != nil {
+			return err
+		}
+
+		p.RefundedAmount = payment.Round(p.RefundedAmount + amount)
+		if p.Paid() == 0 {
+			p.Status = payment.StatusRefunded
+		}
+		ref = res.Ref
+		return repo.Payment().Update(ctx, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.markApplied(ctx, ref, payment.EventRefunded)
+	return p, nil
+}
+
+// Void releases an authorization nothing was captured from.
+func (s *service) Void(ctx context.Context, paymentID int64) (*payment.Payment, error) {
+	var (
+		p   *payment.Payment
+		ref string
+	)
+	err := s.inTransaction(ctx, func(repo repository.Repository) error {
+		var err error
+		p, err = s.lockPayment(ctx, repo, paymentID)
+		if err != nil {
+			return err
+		}
+		if p.Status != payment.StatusAuthorized {
+			return ErrInvalidPaymentState
+		}
+
+		res, gatewayErr := s.gateway.Void(ctx, p.ProviderRef)
+		if err := s.record(ctx, repo, p, payment.TransactionVoid, p.Amount, res, gatewayErr); err != nil {
+			return err
+		}
+
+		p.Status = payment.StatusVoided
+		ref = res.Ref
+		return repo.Payment().Update(ctx, p)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.markApplied(ctx, ref, payment.EventVoided)
+	return p, nil
+}
+
+// record stores the outcome of a gateway call and turns a failed call into
+// the error returned to the client.
+func (s *service) record(ctx context.Context, repo repository.Repository, p *payment.Payment, typ payment.TransactionType, amount float64, res GatewayResponse, gatewayErr error) error {
+	t := &payment.Transaction{
+		PaymentID:   p.ID,
+		Type:        typ,
+		Amount:      amount,
+		Succeeded:   gatewayErr == nil && !res.Declined,
+		ProviderRef: res.Ref,
+	}
+	switch {
+	case gatewayErr != nil:
+		t.Error = gatewayErr.Error()
+	case res.Declined:
+		t.Error = res.Reason
+	}
+	if err := repo.Payment().AddTransaction(ctx, t); err != nil {
+		return err
+	}
+
+	switch {
+	case gatewayErr != nil:
+		return ErrGatewayUnavailable.Wrap(gatewayErr)
+	case res.Declined:
+		return ErrPaymentDeclined.Wrap(errors.New(res.Reason))
+	}
+	return nil
+}
+
+// markApplied records an operation made through the API as a seen gateway
+// event, so the webhook the gateway sends about it is not applied again.
+func (s *service) markApplied(ctx context.Context, ref string, typ payment.WebhookEventType) {
+	if ref == "" {
+		return
+	}
+	event := payment.WebhookEvent{ID: ref, Type: typ}
+	if _, err := s.repo.Payment().RecordWebhookEvent(ctx, s.gateway.Name(), event); err != nil {
+		log.Printf("payment: recording gateway operation %s: %v", ref, err)
+	}
+}
+
+// confirmIfPaid confirms a pending booking once the captured money covers
+// the deposit. The payment already went through, so a failure here is only
+// logged; staff can still confirm the booking by hand.
+func (s *service) confirmIfPaid(ctx context.Context, b *booking.Booking) bool {
+	if b.Status != booking.BookingStatusPending {
+		return false
+	}
+	summary, err := s.summary(ctx, s.repo, b)
+	if err != nil {
+		log.Printf("payment: auto-confirming booking %d: %v", b.ID, err)
+		return false
+	}
+	if summary.Paid+epsilon < s.policy.Deposit(summary.Total) {
+		return false
+	}
+	if err := s.repo.Booking().UpdateStatus(ctx, b, booking.BookingStatusConfirmed); err != nil {
+		log.Printf("payment: auto-confirming booking %d: %v", b.ID, err)
+		return false
+	}
+	b.Status = booking.BookingStatusConfirmed
+	return true
+}
+
+func (s *service) HandleWebhook(ctx context.Context, payload []byte, signature string) (*payment.Result, error) {
+	event, err := s.gateway.ParseWebhook(payload, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	// The event is recorded in the transaction that applies it, so a failure
+	// leaves it unrecorded and the gateway's redelivery is applied.
+	var result *payment.Result
+	err = s.repo.Transaction(ctx, func(repo repository.Repository) error {
+		fresh, err := repo.Payment().RecordWebhookEvent(ctx, s.gateway.Name(), *event)
+		if err != nil || !fresh {
+			return err
+		}
+		result, err = s.applyWebhook(ctx, repo, event)
+		return err
+	})
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	if event.Type == payment.EventCaptured {
+		b, err := s.getBooking(ctx, s.repo, result.Payment.BookingID)
+		if err != nil {
+			return nil, err
+		}
+		result.Confirmed = s.confirmIfPaid(ctx, b)
+	}
+	return result, nil
+}
+
+func (s *service) applyWebhook(ctx context.Context, repo repository.Repository, event *payment.WebhookEvent) (*payment.Result, error) {
+	found, err := repo.Payment().GetByProviderRef(ctx, s.gateway.Name(), event.ProviderRef)
+	if err != nil || found == nil {
+		return nil, err
+	}
+	p, err := s.lockPayment(ctx, repo, found.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	typ := payment.TransactionType("")
+	amount := payment.Round(event.Amount)
+	switch event.Type {
+	case payment.EventCaptured:
+		typ = payment.TransactionCapture
+		amount = min(amount, payment.Round(p.Amount-p.CapturedAmount))
+		p.CapturedAmount = payment.Round(p.CapturedAmount + amount)
+		p.Status = payment.StatusCaptured
+	case payment.EventRefunded:
+		typ = payment.TransactionRefund
+		amount = min(amount, p.Paid())
+		p.RefundedAmount = payment.Round(p.RefundedAmount + amount)
+		if p.Paid() == 0 {
+			p.Status = payment.StatusRefunded
+		}
+	case payment.EventVoided, payment.EventFailed:
+		if p.CapturedAmount > 0 {
+			return nil, ErrInvalidPaymentState
+		}
+		typ = payment.TransactionVoid
+		amount = p.Amount
+		p.Status = payment.StatusVoided
+		if event.Type == payment.EventFailed {
+			p.Status = payment.StatusFailed
+		}
+	default:
+		return nil, ErrInvalidWebhook
+	}
+
+	if err := repo.Payment().Update(ctx, p); err != nil {
+		return nil, err
+	}
+	t := &payment.Transaction{PaymentID: p.ID, Type: typ, Amount: amount, Succeeded: true, ProviderRef: event.ID}
+	if err := repo.Payment().AddTransaction(ctx, t); err != nil {
+		return nil, err
+	}
+	return &payment.Result{Payment: *p}, nil
+}
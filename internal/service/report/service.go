@@ -0,0 +1,126 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/report"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+)
+
+const (
+	defaultRangeDays  = 30
+	defaultPickupDays = 7
+)
+
+var (
+	ErrInvalidDates  = apperr.Validation("invalid_dates", "invalid dates: end date must be after start date")
+	ErrRangeTooLong  = apperr.Validation("range_too_long", "date range is too long")
+	ErrInvalidWindow = apperr.Validation("invalid_pickup_window", "pickup window must be between 1 and 365 days")
+)
+
+type Service interface {
+	GetDailyReport(ctx context.Context, from, to booking.Date) (*report.Report[report.DailyMetrics], error)
+	GetRoomTypeReport(ctx context.Context, from, to booking.Date) (*report.Report[report.RoomTypeMetrics], error)
+	GetPickupReport(ctx context.Context, from, to booking.Date, windowDays int) (*report.PickupReport, error)
+}
+
+type service struct {
+	ctx      context.Context
+	repo     repository.Repository
+	location *time.Location
+}
+
+func NewService(ctx context.Context, repo repository.Repository, location *time.Location) (Service, error) {
+	return &service{
+		ctx:      ctx,
+		repo:     repo,
+		location: location,
+	}, nil
+}
+
+// period defaults the report to the next 30 days from today.
+func (s *service) period(from, to booking.Date) (booking.Date, booking.Date, error) {
+	if from.IsZero() {
+		from = booking.Today(s.location)
+	}
+	if to.IsZero() {
+		to = from.AddDays(defaultRangeDays)
+	}
+	if !from.Before(to) {
+		return from, to, ErrInvalidDates
+	}
+	if from.DaysUntil(to) > report.MaxRangeDays {
+		return from, to, ErrRangeTooLong
+	}
+	return from, to, nil
+}
+
+func (s *service) GetDailyReport(ctx context.Context, from, to booking.Date) (*report.Report[report.DailyMetrics], error) {
+	from, to, err := s.period(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.Report().GetDailyMetrics(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &report.Report[report.DailyMetrics]{From: from, To: to, Rows: []report.DailyMetrics{}}
+	for _, row := range rows {
+		result.Total.Add(row.Metrics)
+		row.Derive()
+		result.Rows = append(result.Rows, row)
+	}
+	result.Total.Derive()
+	return result, nil
+}
+
+func (s *service) GetRoomTypeReport(ctx context.Context, from, to booking.Date) (*report.Report[report.RoomTypeMetrics], error) {
+	from, to, err := s.period(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.Report().GetRoomTypeMetrics(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &report.Report[report.RoomTypeMetrics]{From: from, To: to, Rows: []report.RoomTypeMetrics{}}
+	for _, row := range rows {
+		result.Total.Add(row.Metrics)
+		row.Derive()
+		result.Rows = append(result.Rows, row)
+	}
+	result.Total.Derive()
+	return result, nil
+}
+
+func (s *service) GetPickupReport(ctx context.Context, from, to booking.Date, windowDays int) (*report.PickupReport, error) {
+	from, to, err := s.period(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if windowDays == 0 {
+		windowDays = defaultPickupDays
+	}
+	if windowDays < 1 || windowDays > 365 {
+		return nil, ErrInvalidWindow
+	}
+
+	rows, err := s.repo.Report().GetPickup(ctx, from, to, windowDays)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &report.PickupReport{From: from, To: to, WindowDays: windowDays, Rows: []report.Pickup{}}
+	for _, row := range rows {
+		result.Total.Add(row.PickupCounts)
+		result.Rows = append(result.Rows, row)
+	}
+	return result, nil
+}
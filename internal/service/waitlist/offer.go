@@ -0,0 +1,281 @@
+package waitlist
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/loyalty"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/notification"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/waitlist"
+)
+
+// tierRank orders loyalty tiers for TierPriority; unknown guests rank as
+// members.
+var tierRank = map[loyalty.Tier]int{
+	loyalty.TierMember: 0,
+	loyalty.TierSilver: 1,
+	loyalty.TierGold:   2,
+}
+
+func (s *service) Offer(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.offer(ctx)
+}
+
+func (s *service) offer(ctx context.Context) (int, error) {
+	waiting, err := s.repo.Waitlist().List(ctx, waitlist.StatusWaiting)
+	if err != nil {
+		return 0, err
+	}
+
+	today := s.bookings.Today()
+	queue := waiting[:0]
+	for i := range waiting {
+		if waiting[i].CheckIn.Before(today) {
+			waiting[i].Status = waitlist.StatusExpired
+			if err := s.repo.Waitlist().Update(ctx, &waiting[i]); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		queue = append(queue, waiting[i])
+	}
+	if err := s.prioritize(ctx, queue); err != nil {
+		return 0, err
+	}
+
+	offered := 0
+	for i := range queue {
+		ok, err := s.offerEntry(ctx, &queue[i])
+		if err != nil {
+			return offered, err
+		}
+		if ok {
+			offered++
+		}
+	}
+	return offered, nil
+}
+
+// prioritize orders the entries, which come in the order they joined, by
+// loyalty tier when TierPriority is set.
+func (s *service) prioritize(ctx context.Context, entries []waitlist.Entry) error {
+	if !s.config.TierPriority || len(entries) < 2 {
+		return nil
+	}
+
+	ranks := make(map[int64]int, len(entries))
+	for _, e := range entries {
+		account, err := s.loyalty.GetAccountByEmail(ctx, e.GuestInfo.Email)
+		if err != nil {
+			if apperr.KindOf(err) == apperr.KindNotFound {
+				continue
+			}
+			return err
+		}
+		ranks[e.ID] = tierRank[account.Tier]
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return ranks[entries[i].ID] > ranks[entries[j].ID]
+	})
+	return nil
+}
+
+// offerEntry holds a free room for the entry, if there is one, as a pending
+// booking and sends the guest the link to claim it.
+func (s *service) offerEntry(ctx context.Context, entry *waitlist.Entry) (bool, error) {
+	rooms, err := s.freeRooms(ctx, entry.RoomType, entry.CheckIn, entry.CheckOut, entry.Guests)
+	if err != nil {
+		return false, err
+	}
+
+	for _, room := range rooms {
+		created, err := s.bookings.CreateBooking(ctx, booking.CreateBookingRequest{
+			RoomID:    room.ID,
+			StartDate: entry.CheckIn,
+			EndDate:   entry.CheckOut,
+			GuestInfo: entry.GuestInfo,
+			Guests:    entry.Guests,
+		})
+		if err != nil {
+			// Taken in the meantime or not bookable after all; try the
+			// next room.
+			if isPlacementError(err) {
+				continue
+			}
+			return false, err
+		}
+
+		token, err := newHoldToken()
+		if err != nil {
+			return false, err
+		}
+		expires := time.Now().Add(s.config.HoldDuration)
+		entry.Status = waitlist.StatusOffered
+		entry.BookingID = &created.Booking.ID
+		entry.HoldToken = token
+		entry.HoldExpiresAt = &expires
+		if err := s.repo.Waitlist().Update(ctx, entry); err != nil {
+			return false, err
+		}
+
+		s.notifications.NotifyWaitlistOffer(ctx, &created.Booking, &created.Room, s.holdURL(token), expires)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (s *service) ExpireHolds(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	offered, err := s.repo.Waitlist().List(ctx, waitlist.StatusOffered)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	expired := 0
+	for i := range offered {
+		entry := &offered[i]
+		if entry.HoldExpiresAt != nil && now.Before(*entry.HoldExpiresAt) {
+			continue
+		}
+
+		b, cancelled, err := s.release(ctx, entry)
+		if err != nil {
+			return expired, err
+		}
+		if cancelled {
+			s.notifications.NotifyWaitlistHoldExpired(ctx, &b.Booking, &b.Room)
+		}
+		// The guest confirmed or paid through the booking itself.
+		if b != nil && b.Status != booking.BookingStatusCancelled {
+			entry.Status = waitlist.StatusBooked
+		} else {
+			entry.Status = waitlist.StatusExpired
+			expired++
+		}
+		if err := s.repo.Waitlist().Update(ctx, entry); err != nil {
+			return expired, err
+		}
+	}
+
+	if expired > 0 {
+		if _, err := s.offer(ctx); err != nil {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// release cancels the pending booking holding a room for the entry. It
+// returns the booking as it is now, nil when it is gone, and whether it was
+// cancelled; a booking the guest has already confirmed is left alone.
+func (s *service) release(ctx context.Context, entry *waitlist.Entry) (*booking.BookingWithRoom, bool, error) {
+	if entry.BookingID == nil {
+		return nil, false, nil
+	}
+	b, err := s.bookings.GetBookingByID(ctx, *entry.BookingID)
+	if err != nil {
+		if apperr.KindOf(err) == apperr.KindNotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if b.Status != booking.BookingStatusPending {
+		return b, false, nil
+	}
+
+	cancelled, err := s.bookings.CancelBooking(ctx, b.ID, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	b.Booking = *cancelled
+	return b, true, nil
+}
+
+func (s *service) StartWorker(ctx context.Context) {
+	events := s.notifications.GetBroker().Subscribe(notification.NotificationChannelLifecycle, 100)
+
+	go func() {
+		fmt.Println(" Waitlist worker started")
+		ticker := time.NewTicker(s.config.ScanInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					fmt.Println(" Waitlist worker stopped")
+					return
+				}
+				if !freesRooms(event.Type) {
+					continue
+				}
+				if _, err := s.Offer(ctx); err != nil {
+					fmt.Printf(" Waitlist offer failed: %v\n", err)
+				}
+			case <-ticker.C:
+				if _, err := s.ExpireHolds(ctx); err != nil {
+					fmt.Printf(" Waitlist hold expiry failed: %v\n", err)
+				}
+				if _, err := s.Offer(ctx); err != nil {
+					fmt.Printf(" Waitlist offer failed: %v\n", err)
+				}
+			case <-ctx.Done():
+				fmt.Println(" Waitlist worker stopping...")
+				return
+			}
+		}
+	}()
+}
+
+// freesRooms reports whether a lifecycle event may have freed a room: a
+// cancellation, a no-show, a move or a room back in service.
+func freesRooms(eventType notification.EventType) bool {
+	switch eventType {
+	case notification.EventTypeBookingCancelled,
+		notification.EventTypeBookingStatusChanged,
+		notification.EventTypeBookingModified,
+		notification.EventTypeRoomStatusChanged:
+		return true
+	}
+	return false
+}
+
+func (s *service) holdURL(token string) string {
+	u, err := url.Parse(s.config.HoldURL)
+	if err != nil {
+		return s.config.HoldURL + "?hold=" + token
+	}
+	q := u.Query()
+	q.Set("hold", token)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func newHoldToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func isPlacementError(err error) bool {
+	switch apperr.KindOf(err) {
+	case apperr.KindConflict, apperr.KindValidation, apperr.KindNotFound:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,310 @@
+package waitlist
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/booking"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/waitlist"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+	bookingService "github.com/YurcheuskiRadzivon/booking-system/internal/service/booking"
+	loyaltyService "github.com/YurcheuskiRadzivon/booking-system/internal/service/loyalty"
+	notificationService "github.com/YurcheuskiRadzivon/booking-system/internal/service/notification"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/validation"
+)
+
+var (
+	ErrEntryNotFound     = apperr.NotFound("waitlist_entry_not_found", "waitlist entry not found")
+	ErrHoldNotFound      = apperr.NotFound("waitlist_hold_not_found", "hold not found")
+	ErrHoldExpired       = apperr.Conflict("waitlist_hold_expired", "the hold has expired")
+	ErrEntryClosed       = apperr.Conflict("waitlist_entry_closed", "the waitlist entry is closed")
+	ErrAlreadyWaitlisted = apperr.Conflict("already_waitlisted", "guest is already on the waitlist for these dates")
+	ErrRoomsAvailable    = apperr.Conflict("rooms_available", "rooms are available for these dates, book one instead")
+
+	errCheckInPast = validation.Field("check_in", validation.CodeInPast, "must not be in the past")
+)
+
+// Config controls how offers are made.
+type Config struct {
+	// HoldDuration is how long an offered room is held for the guest.
+	HoldDuration time.Duration
+	// TierPriority serves guests of higher loyalty tiers first; otherwise
+	// the waitlist is first come, first served.
+	TierPriority bool
+	// HoldURL is the page the hold link points to; the hold token is added
+	// as the "hold" query parameter.
+	HoldURL string
+	// ScanInterval is how often expired holds are released and the
+	// waitlist is matched against free rooms, on top of the matching done
+	// whenever a booking is cancelled.
+	ScanInterval time.Duration
+}
+
+type Service interface {
+	// Join puts a guest on the waitlist for a room type; it is refused while
+	// rooms of the type are still free for the dates.
+	Join(ctx context.Context, req waitlist.JoinRequest) (*waitlist.Entry, error)
+	// Leave takes a guest off the waitlist, releasing the room held for
+	// them. The email must be the one the guest joined with.
+	Leave(ctx context.Context, id int64, email string) error
+
+	List(ctx context.Context, status waitlist.Status) ([]waitlist.Entry, error)
+	// Remove takes any entry off the waitlist.
+	Remove(ctx context.Context, id int64) error
+
+	GetHold(ctx context.Context, token string) (*waitlist.Hold, error)
+	// ClaimHold confirms the booking holding the room for the guest.
+	ClaimHold(ctx context.Context, token string) (*waitlist.Hold, error)
+	// DeclineHold releases the room to the next guest on the waitlist.
+	DeclineHold(ctx context.Context, token string) error
+
+	// Offer holds a free room for every waiting guest one is free for, in
+	// priority order, and sends them the hold link. It returns how many
+	// offers were made.
+	Offer(ctx context.Context) (int, error)
+	// ExpireHolds releases the rooms held past their expiry and returns how
+	// many were released.
+	ExpireHolds(ctx context.Context) (int, error)
+	StartWorker(ctx context.Context)
+}
+
+type service struct {
+	ctx           context.Context
+	repo          repository.Repository
+	bookings      bookingService.Service
+	loyalty       loyaltyService.Service
+	notifications notificationService.Service
+	config        Config
+
+	// mu serializes offers and changes to holds so that a room is never
+	// offered twice.
+	mu sync.Mutex
+}
+
+func NewService(ctx context.Context, repo repository.Repository, bookings bookingService.Service, loyalty loyaltyService.Service, notifications notificationService.Service, config Config) (Service, error) {
+	return &service{
+		ctx:           ctx,
+		repo:          repo,
+		bookings:      bookings,
+		loyalty:       loyalty,
+		notifications: notifications,
+		config:        config,
+	}, nil
+}
+
+func (s *service) Join(ctx context.Context, req waitlist.JoinRequest) (*waitlist.Entry, error) {
+	if req.CheckIn.Before(s.bookings.Today()) {
+		return nil, errCheckInPast
+	}
+	guests := max(req.Guests, 1)
+
+	free, err := s.freeRooms(ctx, req.RoomType, req.CheckIn, req.CheckOut, guests)
+	if err != nil {
+		return nil, err
+	}
+	if len(free) > 0 {
+		return nil, ErrRoomsAvailable
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	open, err := s.openEntries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range open {
+		if strings.EqualFold(e.GuestInfo.Email, req.GuestInfo.Email) && e.RoomType == req.RoomType &&
+			e.CheckIn.Equal(req.CheckIn) && e.CheckOut.Equal(req.CheckOut) {
+			return nil, ErrAlreadyWaitlisted
+		}
+	}
+
+	entry := &waitlist.Entry{
+		RoomType:  req.RoomType,
+		CheckIn:   req.CheckIn,
+		CheckOut:  req.CheckOut,
+		Guests:    guests,
+		GuestInfo: req.GuestInfo,
+		Status:    waitlist.StatusWaiting,
+	}
+	if err := s.repo.Waitlist().Create(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *service) Leave(ctx context.Context, id int64, email string) error {
+	entry, err := s.repo.Waitlist().GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if entry == nil || !strings.EqualFold(entry.GuestInfo.Email, email) {
+		return ErrEntryNotFound
+	}
+	return s.close(ctx, entry)
+}
+
+func (s *service) List(ctx context.Context, status waitlist.Status) ([]waitlist.Entry, error) {
+	entries, err := s.repo.Waitlist().List(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []waitlist.Entry{}
+	}
+	return entries, nil
+}
+
+func (s *service) Remove(ctx context.Context, id int64) error {
+	entry, err := s.repo.Waitlist().GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if entry == nil {
+		return ErrEntryNotFound
+	}
+	return s.close(ctx, entry)
+}
+
+// close cancels an open entry and hands a room it held to the next guest.
+func (s *service) close(ctx context.Context, entry *waitlist.Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !entry.IsOpen() {
+		return ErrEntryClosed
+	}
+	released := false
+	if entry.Status == waitlist.StatusOffered {
+		b, cancelled, err := s.release(ctx, entry)
+		if err != nil {
+			return err
+		}
+		if cancelled {
+			s.notifications.NotifyBookingCancelled(ctx, &b.Booking, &b.Room)
+			released = true
+		}
+	}
+	entry.Status = waitlist.StatusCancelled
+	if err := s.repo.Waitlist().Update(ctx, entry); err != nil {
+		return err
+	}
+
+	if released {
+		if _, err := s.offer(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) getHold(ctx context.Context, token string) (*waitlist.Hold, error) {
+	entry, err := s.repo.Waitlist().GetByHoldToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || entry.BookingID == nil {
+		return nil, ErrHoldNotFound
+	}
+	b, err := s.bookings.GetBookingByID(ctx, *entry.BookingID)
+	if err != nil {
+		if errors.Is(err, bookingService.ErrBookingNotFound) {
+			return nil, ErrHoldNotFound
+		}
+		return nil, err
+	}
+	return &waitlist.Hold{Entry: *entry, Booking: *b}, nil
+}
+
+func (s *service) GetHold(ctx context.Context, token string) (*waitlist.Hold, error) {
+	return s.getHold(ctx, token)
+}
+
+func (s *service) ClaimHold(ctx context.Context, token string) (*waitlist.Hold, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold, err := s.getHold(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkOffered(&hold.Entry); err != nil {
+		return nil, err
+	}
+
+	confirmed, err := s.bookings.ConfirmBooking(ctx, hold.Booking.ID, 0)
+	if err != nil {
+		return nil, err
+	}
+	hold.Booking.Booking = *confirmed
+
+	hold.Entry.Status = waitlist.StatusBooked
+	if err := s.repo.Waitlist().Update(ctx, &hold.Entry); err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+func (s *service) DeclineHold(ctx context.Context, token string) error {
+	hold, err := s.getHold(ctx, token)
+	if err != nil {
+		return err
+	}
+	if err := s.checkOffered(&hold.Entry); err != nil {
+		return err
+	}
+	return s.close(ctx, &hold.Entry)
+}
+
+func (s *service) checkOffered(entry *waitlist.Entry) error {
+	if entry.Status != waitlist.StatusOffered {
+		if entry.Status == waitlist.StatusExpired {
+			return ErrHoldExpired
+		}
+		return ErrEntryClosed
+	}
+	if entry.HoldExpiresAt != nil && time.Now().After(*entry.HoldExpiresAt) {
+		return ErrHoldExpired
+	}
+	return nil
+}
+
+// freeRooms returns the rooms of the type that are free for the dates and
+// fit the guests.
+func (s *service) freeRooms(ctx context.Context, roomType booking.RoomType, checkIn, checkOut booking.Date, guests int) ([]booking.Room, error) {
+	available, err := s.bookings.FindAvailableRooms(ctx, booking.RoomSearchRequest{
+		CheckIn:  checkIn,
+		CheckOut: checkOut,
+		RoomType: roomType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rooms []booking.Room
+	for _, a := range available {
+		if a.Room.Capacity >= guests && !a.Room.IsArchived() {
+			rooms = append(rooms, a.Room)
+		}
+	}
+	return rooms, nil
+}
+
+// openEntries returns the waiting and offered entries in the order they
+// joined.
+func (s *service) openEntries(ctx context.Context) ([]waitlist.Entry, error) {
+	waiting, err := s.repo.Waitlist().List(ctx, waitlist.StatusWaiting)
+	if err != nil {
+		return nil, err
+	}
+	offered, err := s.repo.Waitlist().List(ctx, waitlist.StatusOffered)
+	if err != nil {
+		return nil, err
+	}
+	return append(waiting, offered...), nil
+}
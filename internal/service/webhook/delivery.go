@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/webhook"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature carries "sha256=" and the hex HMAC-SHA256, keyed with
+	// the subscription secret, of the timestamp header, a dot and the body.
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Sign returns the signature header value for a body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// attempt sends the delivery once and records the outcome on it. After
+// maxAttempts failures the delivery is given up on, otherwise the next
+// attempt is scheduled with exponential backoff.
+func (s *service) attempt(ctx context.Context, sub *webhook.Subscription, d *webhook.Delivery, maxAttempts int) {
+	d.Attempts++
+	status, err := s.post(ctx, sub, d)
+	d.ResponseStatus = status
+
+	now := time.Now()
+	if err == nil {
+		d.Status = webhook.DeliveryStatusDelivered
+		d.LastError = ""
+		d.DeliveredAt = &now
+		d.NextAttemptAt = nil
+		return
+	}
+
+	d.LastError = err.Error()
+	if d.Attempts >= maxAttempts {
+		d.Status = webhook.DeliveryStatusFailed
+		d.NextAttemptAt = nil
+		return
+	}
+	next := now.Add(s.policy.RetryDelay(d.Attempts))
+	d.NextAttemptAt = &next
+}
+
+// post returns the response status, 0 when there was none, and an error
+// unless the endpoint answered with a 2xx.
+func (s *service) post(ctx context.Context, sub *webhook.Subscription, d *webhook.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(d.EventType))
+	req.Header.Set(HeaderDelivery, d.EventID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, timestamp, d.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,293 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/YurcheuskiRadzivon/booking-system/internal/apperr"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/notification"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/models/webhook"
+	"github.com/YurcheuskiRadzivon/booking-system/internal/repository"
+	notificationService "github.com/YurcheuskiRadzivon/booking-system/internal/service/notification"
+)
+
+const (
+	// deliveryBatch is how many due deliveries one worker pass sends.
+	deliveryBatch = 50
+	// deliveryLogLimit is how many deliveries of a subscription are listed.
+	deliveryLogLimit = 100
+)
+
+var ErrSubscriptionNotFound = apperr.NotFound("webhook_subscription_not_found", "webhook subscription not found")
+
+type Service interface {
+	GetSubscriptions(ctx context.Context) ([]webhook.Subscription, error)
+	CreateSubscription(ctx context.Context, req webhook.CreateSubscriptionRequest) (*webhook.Subscription, error)
+	UpdateSubscription(ctx context.Context, id int64, req webhook.UpdateSubscriptionRequest) (*webhook.Subscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+
+	// GetDeliveries returns the latest deliveries of a subscription.
+	GetDeliveries(ctx context.Context, subscriptionID int64) ([]webhook.Delivery, error)
+	// SendTest sends a test event to the subscription right away, once, and
+	// returns how it went. It is logged like any other delivery.
+	SendTest(ctx context.Context, subscriptionID int64) (*webhook.Delivery, error)
+
+	// StartWorker queues the booking events published by the notification
+	// service for the subscriptions that want them and sends them, retrying
+	// failed deliveries with backoff.
+	StartWorker(ctx context.Context)
+}
+
+type service struct {
+	ctx          context.Context
+	repo         repository.Repository
+	broker       *notificationService.MessageBroker
+	policy       webhook.Policy
+	pollInterval time.Duration
+	client       *http.Client
+}
+
+func NewService(ctx context.Context, repo repository.Repository, broker *notificationService.MessageBroker, policy webhook.Policy, pollInterval time.Duration) (Service, error) {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+
+	return &service{
+		ctx:          ctx,
+		repo:         repo,
+		broker:       broker,
+		policy:       policy,
+		pollInterval: pollInterval,
+		client:       &http.Client{Timeout: policy.Timeout},
+	}, nil
+}
+
+func (s *service) GetSubscriptions(ctx context.Context) ([]webhook.Subscription, error) {
+	subs, err := s.repo.Webhook().GetSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if subs == nil {
+		subs = []webhook.Subscription{}
+	}
+	return subs, nil
+}
+
+func (s *service) getSubscription(ctx context.Context, id int64) (*webhook.Subscription, error) {
+	sub, err := s.repo.Webhook().GetSubscriptionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	return sub, nil
+}
+
+func (s *service) CreateSubscription(ctx context.Context, req webhook.CreateSubscriptionRequest) (*webhook.Subscription, error) {
+	sub := &webhook.Subscription{
+		URL:    req.URL,
+		Events: req.Events,
+		Secret: req.Secret,
+		Active: true,
+	}
+	if err := s.repo.Webhook().CreateSubscription(ctx, sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *service) UpdateSubscription(ctx context.Context, id int64, req webhook.UpdateSubscriptionRequest) (*webhook.Subscription, error) {
+	err := s.repo.Webhook().SetSubscriptionActive(ctx, id, *req.Active)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.getSubscription(ctx, id)
+}
+
+func (s *service) DeleteSubscription(ctx context.Context, id int64) error {
+	err := s.repo.Webhook().DeleteSubscription(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrSubscriptionNotFound
+	}
+	return err
+}
+
+func (s *service) GetDeliveries(ctx context.Context, subscriptionID int64) ([]webhook.Delivery, error) {
+	if _, err := s.getSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.repo.Webhook().GetDeliveries(ctx, subscriptionID, deliveryLogLimit)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []webhook.Delivery{}
+	}
+	return deliveries, nil
+}
+
+func (s *service) SendTest(ctx context.Context, subscriptionID int64) (*webhook.Delivery, error) {
+	sub, err := s.getSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := notification.NotificationEvent{
+		ID:        uuid.New().String(),
+		Type:      webhook.EventTypeTest,
+		Data:      map[string]any{"message": "This is a test event"},
+		CreatedAt: time.Now(),
+	}
+	d, err := newDelivery(sub.ID, event)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Webhook().CreateDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+
+	// A test is never retried: the admin sees the outcome and can send
+	// another one.
+	s.attempt(ctx, sub, d, 1)
+	if err := s.repo.Webhook().UpdateDelivery(ctx, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// StartWorker queues deliveries of lifecycle events and sends them. Queueing
+// only writes to the database, so the broker's buffer is drained at that
+// pace; slow endpoints hold up the sending goroutine alone.
+func (s *service) StartWorker(ctx context.Context) {
+	events := s.broker.Subscribe(notification.NotificationChannelLifecycle, 100)
+	// queued wakes the sender up after new deliveries instead of waiting for
+	// the next tick. One pending wake-up is enough.
+	queued := make(chan struct{}, 1)
+
+	go func() {
+		fmt.Println(" Webhook queue worker started")
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					fmt.Println(" Webhook queue worker stopped")
+					return
+				}
+				if err := s.enqueue(ctx, event); err != nil {
+					fmt.Printf(" Failed to queue webhook event %s: %v\n", event.ID, err)
+				}
+				select {
+				case queued <- struct{}{}:
+				default:
+				}
+			case <-ctx.Done():
+				fmt.Println(" Webhook queue worker stopping...")
+				return
+			}
+		}
+	}()
+
+	go func() {
+		fmt.Println(" Webhook delivery worker started")
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-queued:
+				s.sendDue(ctx)
+			case <-ticker.C:
+				s.sendDue(ctx)
+			case <-ctx.Done():
+				fmt.Println(" Webhook delivery worker stopping...")
+				return
+			}
+		}
+	}()
+}
+
+// enqueue creates a pending delivery of the event for every subscription
+// that wants it.
+func (s *service) enqueue(ctx context.Context, event notification.NotificationEvent) error {
+	subs, err := s.repo.Webhook().GetSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if !sub.Wants(event.Type) {
+			continue
+		}
+		d, err := newDelivery(sub.ID, event)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Webhook().CreateDelivery(ctx, d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *service) sendDue(ctx context.Context) {
+	deliveries, err := s.repo.Webhook().GetDueDeliveries(ctx, time.Now(), deliveryBatch)
+	if err != nil {
+		fmt.Printf(" Failed to load webhook deliveries: %v\n", err)
+		return
+	}
+
+	subs := make(map[int64]*webhook.Subscription)
+	for i := range deliveries {
+		d := &deliveries[i]
+		sub, ok := subs[d.SubscriptionID]
+		if !ok {
+			if sub, err = s.repo.Webhook().GetSubscriptionByID(ctx, d.SubscriptionID); err != nil {
+				fmt.Printf(" Failed to load webhook subscription %d: %v\n", d.SubscriptionID, err)
+				continue
+			}
+			subs[d.SubscriptionID] = sub
+		}
+		// Deleted subscriptions take their deliveries with them; paused
+		// ones keep them pending until resumed. The query leaves both out,
+		// this catches those changed since.
+		if sub == nil || !sub.Active {
+			continue
+		}
+
+		s.attempt(ctx, sub, d, s.policy.MaxAttempts)
+		if err := s.repo.Webhook().UpdateDelivery(ctx, d); err != nil {
+			fmt.Printf(" Failed to save webhook delivery %d: %v\n", d.ID, err)
+		}
+	}
+}
+
+func newDelivery(subscriptionID int64, event notification.NotificationEvent) (*webhook.Delivery, error) {
+	payload, err := json.Marshal(webhook.Payload{
+		ID:        event.ID,
+		Type:      event.Type,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &webhook.Delivery{
+		SubscriptionID: subscriptionID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		Payload:        payload,
+		Status:         webhook.DeliveryStatusPending,
+		NextAttemptAt:  &now,
+	}, nil
+}
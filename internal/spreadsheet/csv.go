@@ -0,0 +1,56 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+)
+
+// utf8BOM makes Excel open the file as UTF-8 instead of the system code page.
+const utf8BOM = "\ufeff"
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+	return &csvWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (c *csvWriter) WriteRow(values ...any) error {
+	c.record = c.record[:0]
+	for _, v := range values {
+		c.record = append(c.record, formatText(v))
+	}
+	return c.w.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// readCSV reads comma or semicolon separated values, the latter being what
+// Excel saves in locales with a decimal comma.
+func readCSV(r io.Reader) ([][]string, error) {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(len(utf8BOM)); err == nil && string(bom) == utf8BOM {
+		br.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(br)
+	// Peek returns what is buffered even when the file is shorter.
+	head, _ := br.Peek(4096)
+	line, _, _ := bytes.Cut(head, []byte("\n"))
+	if bytes.Count(line, []byte(";")) > bytes.Count(line, []byte(",")) {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader.ReadAll()
+}
@@ -0,0 +1,160 @@
+// Package spreadsheet reads and writes tabular data as CSV or XLSX.
+package spreadsheet
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+func (f Format) IsValid() bool {
+	return f == FormatCSV || f == FormatXLSX
+}
+
+func (f Format) ContentType() string {
+	if f == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Filename returns name with the extension of the format.
+func (f Format) Filename(name string) string {
+	return name + "." + string(f)
+}
+
+// Writer writes one sheet row by row. Close must be called to flush it.
+type Writer interface {
+	// WriteRow writes a row of strings, numbers, times and values
+	// implementing fmt.Stringer.
+	WriteRow(values ...any) error
+	Close() error
+}
+
+// NewWriter returns a writer of the given format; sheet names the worksheet
+// of an XLSX file.
+func NewWriter(format Format, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet)
+	}
+	return nil, fmt.Errorf("unsupported spreadsheet format %q", format)
+}
+
+// ReadAll returns the rows of a CSV file or of the first sheet of an XLSX
+// file. Cells are returned as written, without number formatting and without
+// the quote WriteRow puts before text that looks like a formula.
+func ReadAll(format Format, r io.Reader) ([][]string, error) {
+	var (
+		rows [][]string
+		err  error
+	)
+	switch format {
+	case FormatCSV:
+		rows, err = readCSV(r)
+	case FormatXLSX:
+		rows, err = readXLSX(r)
+	default:
+		return nil, fmt.Errorf("unsupported spreadsheet format %q", format)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			row[i] = unescapeFormula(cell)
+		}
+	}
+	return rows, err
+}
+
+// formulaPrefixes start a cell that Excel and other spreadsheet programs
+// evaluate as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula quotes text that would otherwise be run as a formula when the
+// file is opened, e.g. a guest named "=HYPERLINK(...)". Text that already
+// looks quoted is quoted again so that ReadAll returns it unchanged.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune(formulaPrefixes, rune(s[0])) || unescapeFormula(s) != s {
+		return "'" + s
+	}
+	return s
+}
+
+func unescapeFormula(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+// ParseNumber parses a cell holding a number, accepting a decimal comma.
+func ParseNumber(value string) (float64, error) {
+	value = strings.ReplaceAll(strings.TrimSpace(value), " ", "")
+	if !strings.Contains(value, ".") {
+		value = strings.Replace(value, ",", ".", 1)
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+var dateLayouts = []string{"2006-01-02", "02.01.2006", "2006-01-02T15:04:05Z07:00"}
+
+// ParseDate parses a cell holding a date: ISO or Russian notation, or an
+// Excel serial day number.
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if serial, err := strconv.ParseFloat(value, 64); err == nil {
+		return excelize.ExcelDateToTime(serial, false)
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// formatText formats a value that is written as text, escaping formulas.
+func formatText(v any) string {
+	switch v.(type) {
+	case int, int64, float64, bool:
+		return formatValue(v)
+	}
+	return escapeFormula(formatValue(v))
+}
+
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(time.RFC3339)
+	case fmt.Stringer:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
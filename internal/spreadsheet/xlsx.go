@@ -0,0 +1,68 @@
+package spreadsheet
+
+import (
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxWriter streams rows to a temporary sheet and assembles the workbook
+// into the output on Close, so memory use does not grow with the row count.
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, sheet string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	if err := file.SetSheetName("Sheet1", sheet); err != nil {
+		file.Close()
+		return nil, err
+	}
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &xlsxWriter{out: w, file: file, stream: stream}, nil
+}
+
+func (x *xlsxWriter) WriteRow(values ...any) error {
+	x.row++
+	cells := make([]any, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case int, int64, float64, bool, nil:
+			cells[i] = v
+		default:
+			cells[i] = formatText(v)
+		}
+	}
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+	return x.stream.SetRow(cell, cells)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := x.file.WriteTo(x.out)
+	return err
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sheet := file.GetSheetName(0)
+	return file.GetRows(sheet, excelize.Options{RawCellValue: true})
+}
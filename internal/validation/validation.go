@@ -0,0 +1,136 @@
+package validation
+
+import (
+	"net/mail"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Code is a stable, machine-readable reason a field was rejected. Clients map
+// codes to their own text; the English message is only a fallback.
+type Code string
+
+const (
+	CodeRequired     Code = "required"
+	CodeInvalid      Code = "invalid"
+	CodeInvalidEmail Code = "invalid_email"
+	CodeInvalidPhone Code = "invalid_phone"
+	CodeTooLong      Code = "too_long"
+	CodeOutOfRange   Code = "out_of_range"
+	CodeUnknownValue Code = "unknown_value"
+	CodeDateOrder    Code = "date_order"
+	CodeInPast       Code = "in_past"
+	CodeDuplicate    Code = "duplicate"
+)
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors is the set of field errors of one request. It is returned as an
+// error by Validate methods and rendered field by field by the server.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Field returns a single field error.
+func Field(field string, code Code, message string) Errors {
+	return Errors{{Field: field, Code: code, Message: message}}
+}
+
+// Validator collects field errors; the zero value is ready to use.
+type Validator struct {
+	errs Errors
+}
+
+func (v *Validator) Add(field string, code Code, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Check adds the error unless ok holds.
+func (v *Validator) Check(ok bool, field string, code Code, message string) {
+	if !ok {
+		v.Add(field, code, message)
+	}
+}
+
+// Err returns the collected errors, or nil when the request is valid.
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, CodeRequired, "is required")
+}
+
+func (v *Validator) MaxLength(field, value string, max int) {
+	v.Check(utf8.RuneCountInString(value) <= max, field, CodeTooLong, "is too long")
+}
+
+// Email checks the format of a non-empty address.
+func (v *Validator) Email(field, value string) {
+	if value == "" {
+		return
+	}
+	addr, err := mail.ParseAddress(value)
+	ok := err == nil && addr.Address == strings.TrimSpace(value) && strings.Contains(addr.Address[strings.LastIndex(addr.Address, "@"):], ".")
+	v.Check(ok, field, CodeInvalidEmail, "is not a valid email address")
+}
+
+// Phone checks a non-empty phone number: digits with the usual separators
+// and an optional leading plus, 7 to 15 digits in total.
+func (v *Validator) Phone(field, value string) {
+	if value == "" {
+		return
+	}
+	digits := 0
+	ok := true
+	for i, r := range strings.TrimSpace(value) {
+		switch {
+		case unicode.IsDigit(r):
+			digits++
+		case r == '+' && i == 0:
+		case r == ' ' || r == '-' || r == '(' || r == ')':
+		default:
+			ok = false
+		}
+	}
+	v.Check(ok && digits >= 7 && digits <= 15, field, CodeInvalidPhone, "is not a valid phone number")
+}
+
+func (v *Validator) Positive(field string, value float64) {
+	v.Check(value > 0, field, CodeOutOfRange, "must be greater than zero")
+}
+
+func (v *Validator) NonNegative(field string, value float64) {
+	v.Check(value >= 0, field, CodeOutOfRange, "must not be negative")
+}
+
+func (v *Validator) Range(field string, value, min, max float64) {
+	v.Check(value >= min && value <= max, field, CodeOutOfRange, "is out of range")
+}
+
+// OneOf checks that a non-empty value is one of allowed.
+func OneOf[T ~string](v *Validator, field string, value T, allowed ...T) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.Add(field, CodeUnknownValue, "has an unknown value")
+}
@@ -73,7 +73,11 @@ INSERT INTO room_types (name, base_price, breakfast, lunch, dinner, fast_wifi, p
 ON CONFLICT (name) DO NOTHING;
 
 -- Insert sample rooms
-INSERT INTO rooms (room_number, room_type, base_price, capacity, status, description) VALUES
+-- Room numbers are only unique among rooms in service since 009, so the
+-- seed checks for the number itself instead of relying on a constraint
+INSERT INTO rooms (room_number, room_type, base_price, capacity, status, description)
+SELECT v.room_number, v.room_type, v.base_price, v.capacity, v.status, v.description
+FROM (VALUES
     ('101', 'standard', 2500.00, 2, 'available', 'Standartnyy nomer na pervom etazhe'),
     ('102', 'standard', 2500.00, 2, 'available', 'Standartnyy nomer s vidom na park'),
     ('201', 'deluxe', 4500.00, 3, 'available', 'Delyuks nomer s vidom na more'),
@@ -82,7 +86,8 @@ INSERT INTO rooms (room_number, room_type, base_price, capacity, status, descrip
     ('302', 'suite', 7500.00, 4, 'available', 'Prezidentskiy lyuks s panoramnym vidom'),
     ('401', 'family', 5500.00, 6, 'available', 'Semeynyy nomer s dvumya spalnyami'),
     ('402', 'family', 5500.00, 6, 'available', 'Semeynyy nomer s detskoy zonoy')
-ON CONFLICT (room_number) DO NOTHING;
+) AS v(room_number, room_type, base_price, capacity, status, description)
+WHERE NOT EXISTS (SELECT 1 FROM rooms r WHERE r.room_number = v.room_number);
 
 -- Insert default notification types
 INSERT INTO notification_types (name, message) VALUES
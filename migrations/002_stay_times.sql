@@ -0,0 +1,11 @@
+-- Hotel Booking System Database Schema
+-- Migration: 002_stay_times
+
+-- Requested arrival/departure times and early check-in / late checkout upsells
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS early_check_in BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS late_check_out BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS check_in_time VARCHAR(5) NOT NULL DEFAULT '14:00';
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS check_out_time VARCHAR(5) NOT NULL DEFAULT '12:00';
+
+CREATE INDEX IF NOT EXISTS idx_bookings_start_date ON bookings(start_date);
+CREATE INDEX IF NOT EXISTS idx_bookings_end_date ON bookings(end_date);
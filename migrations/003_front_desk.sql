@@ -0,0 +1,8 @@
+-- Hotel Booking System Database Schema
+-- Migration: 003_front_desk
+
+-- Actual arrival/departure, captured guest ID and key cards, no-show penalty
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMPTZ;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS checked_out_at TIMESTAMPTZ;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS check_in_details JSONB;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS penalty DECIMAL(10,2) NOT NULL DEFAULT 0;
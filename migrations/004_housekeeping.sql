@@ -0,0 +1,24 @@
+-- Hotel Booking System Database Schema
+-- Migration: 004_housekeeping
+
+-- Physical room state, separate from sales availability
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS housekeeping_status VARCHAR(20) NOT NULL DEFAULT 'inspected';
+
+-- Create housekeeping_tasks table
+CREATE TABLE IF NOT EXISTS housekeeping_tasks (
+    id SERIAL PRIMARY KEY,
+    room_id INTEGER NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    booking_id INTEGER REFERENCES bookings(id) ON DELETE SET NULL,
+    type VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    assignee VARCHAR(100) NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    due_date DATE NOT NULL,
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_housekeeping_tasks_room_id ON housekeeping_tasks(room_id);
+CREATE INDEX IF NOT EXISTS idx_housekeeping_tasks_due ON housekeeping_tasks(due_date, status);
+CREATE INDEX IF NOT EXISTS idx_housekeeping_tasks_assignee ON housekeeping_tasks(assignee);
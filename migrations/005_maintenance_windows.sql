@@ -0,0 +1,25 @@
+-- Hotel Booking System Database Schema
+-- Migration: 005_maintenance_windows
+
+-- Create maintenance_windows table: out-of-order / out-of-service periods per room
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id SERIAL PRIMARY KEY,
+    room_id INTEGER NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    kind VARCHAR(20) NOT NULL DEFAULT 'out_of_order',
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date > start_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_room_dates ON maintenance_windows(room_id, start_date, end_date);
+
+-- Rooms that were flagged with the permanent 'maintenance' status become an
+-- open-ended out-of-order window starting today.
+INSERT INTO maintenance_windows (room_id, start_date, end_date, kind, reason)
+SELECT id, CURRENT_DATE, DATE '9999-12-31', 'out_of_order', 'Migrated from room status'
+FROM rooms
+WHERE status = 'maintenance';
+
+UPDATE rooms SET status = 'available' WHERE status = 'maintenance';
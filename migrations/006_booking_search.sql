@@ -0,0 +1,15 @@
+-- Hotel Booking System Database Schema
+-- Migration: 006_booking_search
+
+-- Trigram indexes for fuzzy guest lookup by name, email and phone
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_guest_name_trgm
+    ON bookings USING GIN (LOWER(guest_info->>'name') gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_guest_email_trgm
+    ON bookings USING GIN (LOWER(guest_info->>'email') gin_trgm_ops);
+
+-- Phone numbers are matched on digits only so "+7 (999) 123" finds "79991234567"
+CREATE INDEX IF NOT EXISTS idx_bookings_guest_phone_trgm
+    ON bookings USING GIN (REGEXP_REPLACE(guest_info->>'phone', '\D', '', 'g') gin_trgm_ops);
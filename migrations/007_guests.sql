@@ -0,0 +1,42 @@
+-- Hotel Booking System Database Schema
+-- Migration: 007_guests
+
+-- Create guests table: one record per person, deduplicated by normalized email / phone
+CREATE TABLE IF NOT EXISTS guests (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    phone VARCHAR(50) NOT NULL DEFAULT '',
+    normalized_email VARCHAR(255) NOT NULL DEFAULT '',
+    normalized_phone VARCHAR(50) NOT NULL DEFAULT '',
+    preferences JSONB NOT NULL DEFAULT '{}',
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guests_normalized_email ON guests(normalized_email) WHERE normalized_email <> '';
+CREATE INDEX IF NOT EXISTS idx_guests_normalized_phone ON guests(normalized_phone) WHERE normalized_phone <> '';
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS guest_id INTEGER REFERENCES guests(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_bookings_guest_id ON bookings(guest_id);
+
+-- Backfill: one guest per normalized email, named after their latest booking
+INSERT INTO guests (name, email, phone, normalized_email, normalized_phone, created_at)
+SELECT DISTINCT ON (LOWER(TRIM(guest_info->>'email')))
+    COALESCE(guest_info->>'name', ''),
+    TRIM(guest_info->>'email'),
+    COALESCE(guest_info->>'phone', ''),
+    LOWER(TRIM(guest_info->>'email')),
+    REGEXP_REPLACE(COALESCE(guest_info->>'phone', ''), '\D', '', 'g'),
+    created_at
+FROM bookings
+WHERE COALESCE(TRIM(guest_info->>'email'), '') <> ''
+ORDER BY LOWER(TRIM(guest_info->>'email')), created_at DESC
+ON CONFLICT DO NOTHING;
+
+UPDATE bookings b
+SET guest_id = g.id
+FROM guests g
+WHERE b.guest_id IS NULL
+AND g.normalized_email = LOWER(TRIM(b.guest_info->>'email'));
@@ -0,0 +1,25 @@
+-- Hotel Booking System Database Schema
+-- Migration: 008_loyalty
+
+-- Points ledger: every earn / redeem / refund / adjust / expire movement per guest
+CREATE TABLE IF NOT EXISTS loyalty_ledger (
+    id SERIAL PRIMARY KEY,
+    guest_id INTEGER NOT NULL REFERENCES guests(id) ON DELETE CASCADE,
+    booking_id INTEGER REFERENCES bookings(id) ON DELETE SET NULL,
+    type VARCHAR(20) NOT NULL,
+    points INTEGER NOT NULL,
+    remaining INTEGER NOT NULL DEFAULT 0,
+    expires_at DATE,
+    reason TEXT NOT NULL DEFAULT '',
+    actor VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_guest ON loyalty_ledger(guest_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_expiry ON loyalty_ledger(expires_at) WHERE remaining > 0;
+-- A booking earns points only once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loyalty_ledger_booking_earn ON loyalty_ledger(booking_id) WHERE type = 'earn';
+
+-- Points redeemed as part of the booking payment
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS points_redeemed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS points_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
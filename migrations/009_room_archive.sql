@@ -0,0 +1,14 @@
+-- Hotel Booking System Database Schema
+-- Migration: 009_room_archive
+
+-- Rooms are archived instead of deleted so their booking history survives
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+-- Room numbers only have to be unique among rooms in service
+ALTER TABLE rooms DROP CONSTRAINT IF EXISTS rooms_room_number_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rooms_room_number_active ON rooms(room_number) WHERE archived_at IS NULL;
+
+-- Deleting a room must never take its bookings with it
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_room_id_fkey;
+ALTER TABLE bookings ADD CONSTRAINT bookings_room_id_fkey
+    FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE RESTRICT;
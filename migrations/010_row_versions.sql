@@ -0,0 +1,8 @@
+-- Hotel Booking System Database Schema
+-- Migration: 010_row_versions
+
+-- Row versions for optimistic concurrency: every update bumps the version and
+-- conditional writes (If-Match) only apply to the version the client has seen
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE special_dates ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
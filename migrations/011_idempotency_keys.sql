@@ -0,0 +1,16 @@
+-- Hotel Booking System Database Schema
+-- Migration: 011_idempotency_keys
+
+-- Responses of requests sent with an Idempotency-Key, replayed when a client
+-- retries the same request. status_code is NULL while the first request runs.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    fingerprint VARCHAR(64) NOT NULL,
+    status_code INTEGER,
+    content_type VARCHAR(255),
+    body BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,45 @@
+-- Hotel Booking System Database Schema
+-- Migration: 012_payments
+
+-- Card payments for bookings: one row per authorization with what was
+-- captured and refunded from it
+CREATE TABLE IF NOT EXISTS payments (
+    id SERIAL PRIMARY KEY,
+    booking_id INTEGER NOT NULL REFERENCES bookings(id) ON DELETE RESTRICT,
+    provider VARCHAR(50) NOT NULL,
+    provider_ref VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    captured_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    refunded_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'RUB',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (captured_amount <= amount AND refunded_amount <= captured_amount)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_booking ON payments(booking_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_provider_ref ON payments(provider, provider_ref) WHERE provider_ref <> '';
+
+-- Every gateway call made for a payment, including declined ones
+CREATE TABLE IF NOT EXISTS payment_transactions (
+    id SERIAL PRIMARY KEY,
+    payment_id INTEGER NOT NULL REFERENCES payments(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    succeeded BOOLEAN NOT NULL,
+    provider_ref VARCHAR(255) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_transactions_payment ON payment_transactions(payment_id, created_at);
+
+-- Gateway webhook events already applied, so redelivered events are ignored
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    received_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, event_id)
+);
@@ -0,0 +1,43 @@
+-- Hotel Booking System Database Schema
+-- Migration: 013_folios_invoices
+
+-- Number of guests staying, for the per-person city tax
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS guests INTEGER NOT NULL DEFAULT 1 CHECK (guests > 0);
+
+-- Folio charges: the room nights and extras a booking was priced with
+CREATE TABLE IF NOT EXISTS folio_charges (
+    id SERIAL PRIMARY KEY,
+    booking_id INTEGER NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    service_date DATE,
+    amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_folio_charges_booking ON folio_charges(booking_id, service_date);
+
+-- Last invoice number issued per year. Numbers are taken in the transaction
+-- that inserts the invoice, so a failed insert leaves no gap.
+CREATE TABLE IF NOT EXISTS invoice_counters (
+    year INTEGER PRIMARY KEY,
+    last_number INTEGER NOT NULL
+);
+
+-- Invoices issued at checkout, with the folio frozen at that moment
+CREATE TABLE IF NOT EXISTS invoices (
+    id SERIAL PRIMARY KEY,
+    number VARCHAR(20) NOT NULL UNIQUE,
+    year INTEGER NOT NULL,
+    sequence INTEGER NOT NULL,
+    booking_id INTEGER NOT NULL UNIQUE REFERENCES bookings(id) ON DELETE RESTRICT,
+    guest_name VARCHAR(255) NOT NULL,
+    guest_email VARCHAR(255) NOT NULL,
+    total DECIMAL(10,2) NOT NULL,
+    vat DECIMAL(10,2) NOT NULL,
+    city_tax DECIMAL(10,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    folio JSONB NOT NULL,
+    issued_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (year, sequence)
+);
@@ -0,0 +1,27 @@
+-- Hotel Booking System Database Schema
+-- Migration: 014_ical_calendars
+
+-- Secret tokens of the per-room iCal export feeds
+CREATE TABLE IF NOT EXISTS ical_export_tokens (
+    room_id INTEGER PRIMARY KEY REFERENCES rooms(id) ON DELETE CASCADE,
+    token VARCHAR(64) UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- External calendars whose events block room dates
+CREATE TABLE IF NOT EXISTS ical_feeds (
+    id SERIAL PRIMARY KEY,
+    room_id INTEGER NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    url TEXT NOT NULL,
+    last_synced_at TIMESTAMP,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (room_id, url)
+);
+
+-- Imported events are maintenance windows of the 'external' kind that
+-- belong to their feed
+ALTER TABLE maintenance_windows ADD COLUMN IF NOT EXISTS ical_feed_id INTEGER REFERENCES ical_feeds(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_ical_feed ON maintenance_windows(ical_feed_id) WHERE ical_feed_id IS NOT NULL;
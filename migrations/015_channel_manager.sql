@@ -0,0 +1,51 @@
+-- Hotel Booking System Database Schema
+-- Migration: 015_channel_manager
+
+-- Room types sold on OTA channels and their codes there
+CREATE TABLE IF NOT EXISTS channel_mappings (
+    id SERIAL PRIMARY KEY,
+    channel VARCHAR(50) NOT NULL,
+    room_type VARCHAR(50) NOT NULL,
+    room_code VARCHAR(50) NOT NULL,
+    rate_code VARCHAR(50) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (channel, room_type),
+    UNIQUE (channel, room_code)
+);
+
+-- Inventory last pushed to each channel, to push only what has changed
+CREATE TABLE IF NOT EXISTS channel_inventory (
+    channel VARCHAR(50) NOT NULL,
+    room_code VARCHAR(50) NOT NULL,
+    rate_code VARCHAR(50) NOT NULL,
+    date DATE NOT NULL,
+    available INTEGER NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    stop_sell BOOLEAN NOT NULL DEFAULT FALSE,
+    pushed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (channel, room_code, rate_code, date)
+);
+
+-- Reservations pulled from channels and the bookings they became
+CREATE TABLE IF NOT EXISTS channel_reservations (
+    id SERIAL PRIMARY KEY,
+    channel VARCHAR(50) NOT NULL,
+    ref VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    room_code VARCHAR(50) NOT NULL,
+    check_in DATE NOT NULL,
+    check_out DATE NOT NULL,
+    guest_info JSONB NOT NULL,
+    guests INTEGER NOT NULL DEFAULT 1,
+    total DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    booking_id INTEGER REFERENCES bookings(id) ON DELETE SET NULL,
+    state VARCHAR(20) NOT NULL CHECK (state IN ('booked', 'cancelled', 'conflict', 'rejected')),
+    conflict TEXT NOT NULL DEFAULT '',
+    fingerprint VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (channel, ref)
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_reservations_state ON channel_reservations(state);
+CREATE INDEX IF NOT EXISTS idx_channel_reservations_booking ON channel_reservations(booking_id) WHERE booking_id IS NOT NULL;
@@ -0,0 +1,22 @@
+-- Hotel Booking System Database Schema
+-- Migration: 017_waitlist
+
+-- Guests waiting for a room type to free up on fully booked dates
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id SERIAL PRIMARY KEY,
+    room_type VARCHAR(50) NOT NULL,
+    check_in DATE NOT NULL,
+    check_out DATE NOT NULL,
+    guests INTEGER NOT NULL DEFAULT 1,
+    guest_info JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'offered', 'booked', 'expired', 'cancelled')),
+    booking_id INTEGER REFERENCES bookings(id) ON DELETE SET NULL,
+    hold_token VARCHAR(64) NOT NULL DEFAULT '',
+    hold_expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (check_out > check_in)
+);
+
+CREATE INDEX IF NOT EXISTS idx_waitlist_entries_status ON waitlist_entries(status, created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_waitlist_entries_hold_token ON waitlist_entries(hold_token) WHERE hold_token <> '';
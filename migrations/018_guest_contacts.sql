@@ -0,0 +1,57 @@
+-- Hotel Booking System Database Schema
+-- Migration: 018_guest_contacts
+
+-- Create guest_contacts table: emails and phones of guests merged into another
+-- guest, still resolving to it so the duplicate is not created again
+CREATE TABLE IF NOT EXISTS guest_contacts (
+    id SERIAL PRIMARY KEY,
+    guest_id INTEGER NOT NULL REFERENCES guests(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('email', 'phone')),
+    value VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_guest_contacts_kind_value ON guest_contacts(kind, value);
+CREATE INDEX IF NOT EXISTS idx_guest_contacts_guest_id ON guest_contacts(guest_id);
+
+-- The 007 backfill made one guest per email. Fold guests sharing a phone into
+-- the oldest of them, as a booking with that phone would have, keeping their
+-- emails as contacts of the remaining guest
+CREATE TEMP TABLE guest_phone_merges AS
+SELECT g.id AS source_id, keep.id AS target_id
+FROM guests g
+JOIN LATERAL (
+    SELECT MIN(k.id) AS id FROM guests k WHERE k.normalized_phone = g.normalized_phone
+) keep ON keep.id <> g.id
+WHERE g.normalized_phone <> '';
+
+INSERT INTO guest_contacts (guest_id, kind, value)
+SELECT m.target_id, 'email', g.normalized_email
+FROM guest_phone_merges m
+JOIN guests g ON g.id = m.source_id
+WHERE g.normalized_email <> ''
+ON CONFLICT (kind, value) DO NOTHING;
+
+UPDATE guests t
+SET preferences = COALESCE(s.preferences, '{}') || t.preferences,
+    notes = CONCAT_WS(E'\n', NULLIF(t.notes, ''), s.notes),
+    updated_at = CURRENT_TIMESTAMP
+FROM (
+    SELECT m.target_id,
+        (SELECT jsonb_object_agg(p.key, p.value)
+         FROM guest_phone_merges m2
+         JOIN guests g2 ON g2.id = m2.source_id
+         CROSS JOIN jsonb_each(g2.preferences) p
+         WHERE m2.target_id = m.target_id) AS preferences,
+        STRING_AGG(NULLIF(g.notes, ''), E'\n') AS notes
+    FROM guest_phone_merges m
+    JOIN guests g ON g.id = m.source_id
+    GROUP BY m.target_id
+) s
+WHERE t.id = s.target_id;
+
+UPDATE bookings b SET guest_id = m.target_id FROM guest_phone_merges m WHERE b.guest_id = m.source_id;
+UPDATE loyalty_ledger l SET guest_id = m.target_id FROM guest_phone_merges m WHERE l.guest_id = m.source_id;
+DELETE FROM guests g USING guest_phone_merges m WHERE g.id = m.source_id;
+
+DROP TABLE guest_phone_merges;
@@ -1,5 +1,15 @@
 let currentTab = 'search';
 let searchParams = {};
+let stayPolicy = null;
+let calendarStart = null;
+let calendarNights = [];
+// One key per opened booking form, so a resubmitted form is not booked twice.
+let bookingIdempotencyKey = null;
+let paymentIdempotencyKey = null;
+
+const CALENDAR_DAYS = 28;
+const TAPE_CHART_DAYS = 14;
+let tapeChartStart = null;
 
 const tabs = document.querySelectorAll('.tab-btn');
 const tabContents = document.querySelectorAll('.tab-content');
@@ -14,7 +24,11 @@ document.addEventListener('DOMContentLoaded', () => {
     setupForms();
     setupModals();
     setDefaultDates();
+    loadStayPolicy();
+    loadAvailabilityCalendar();
     loadAdminData();
+    connectLiveUpdates();
+    openHoldFromLink();
 });
 
 function setupTabs() {
@@ -64,11 +78,33 @@ function setupForms() {
         await searchRooms(searchParams);
     });
 
+    searchForm.querySelectorAll('input[type="date"], select[name="room_type"]').forEach(input => {
+        input.addEventListener('change', () => {
+            if (input.name === 'check_in') calendarStart = null;
+            loadAvailabilityCalendar();
+        });
+    });
+
     bookingForm.addEventListener('submit', async (e) => {
         e.preventDefault();
         await createBooking();
     });
 
+    bookingForm.querySelectorAll('.stay-options input').forEach(input => {
+        input.addEventListener('change', () => {
+            const roomId = parseInt(bookingForm.querySelector('input[name="room_id"]').value);
+            calculatePrice(roomId, searchParams.check_in, searchParams.check_out);
+        });
+    });
+
+    bookingForm.querySelector('input[name="email"]').addEventListener('change', () => {
+        const roomId = parseInt(bookingForm.querySelector('input[name="room_id"]').value);
+        calculatePrice(roomId, searchParams.check_in, searchParams.check_out);
+        loadLoyaltyBalance();
+    });
+
+    bookingForm.querySelector('input[name="redeem_points"]').addEventListener('change', loadLoyaltyBalance);
+
     const addRoomForm = document.getElementById('add-room-form');
     if (addRoomForm) {
         addRoomForm.addEventListener('submit', async (e) => {
@@ -85,6 +121,78 @@ function setupForms() {
         });
     }
 
+    const waitlistForm = document.getElementById('waitlist-form');
+    if (waitlistForm) {
+        waitlistForm.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            await joinWaitlist(new FormData(waitlistForm));
+        });
+    }
+
+    const maintenanceForm = document.getElementById('maintenance-form');
+    if (maintenanceForm) {
+        maintenanceForm.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            await addMaintenanceWindow(new FormData(maintenanceForm));
+        });
+    }
+
+    const channelMappingForm = document.getElementById('channel-mapping-form');
+    if (channelMappingForm) {
+        channelMappingForm.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            await addChannelMapping(new FormData(channelMappingForm));
+        });
+    }
+
+    const webhookForm = document.getElementById('webhook-form');
+    if (webhookForm) {
+        webhookForm.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            await addWebhook(new FormData(webhookForm));
+        });
+    }
+
+    const archiveRoomForm = document.getElementById('archive-room-form');
+    if (archiveRoomForm) {
+        archiveRoomForm.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            await archiveRoom(archiveRoomForm);
+        });
+    }
+
+    const paymentForm = document.getElementById('payment-form');
+    if (paymentForm) {
+        paymentForm.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            await payBooking(new FormData(paymentForm));
+        });
+    }
+
+    const calendarFeedForm = document.getElementById('calendar-feed-form');
+    if (calendarFeedForm) {
+        calendarFeedForm.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            await addCalendarFeed(new FormData(calendarFeedForm));
+        });
+    }
+
+    const guestForm = document.getElementById('guest-form');
+    if (guestForm) {
+        guestForm.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            await saveGuest(new FormData(guestForm));
+        });
+    }
+
+    const loyaltyAdjustForm = document.getElementById('loyalty-adjust-form');
+    if (loyaltyAdjustForm) {
+        loyaltyAdjustForm.addEventListener('submit', async (e) => {
+            e.preventDefault();
+            await adjustLoyalty(new FormData(loyaltyAdjustForm));
+        });
+    }
+
     const addSpecialDateForm = document.getElementById('add-special-date-form');
     if (addSpecialDateForm) {
         addSpecialDateForm.addEventListener('submit', async (e) => {
@@ -107,9 +215,88 @@ async function searchRooms(params) {
     }
 }
 
+async function loadAvailabilityCalendar() {
+    const checkIn = searchForm.querySelector('input[name="check_in"]').value;
+    if (!calendarStart) calendarStart = checkIn || toISODate(new Date());
+
+    const params = new URLSearchParams({
+        from: calendarStart,
+        to: addDays(calendarStart, CALENDAR_DAYS),
+        room_type: searchForm.querySelector('select[name="room_type"]').value
+    });
+
+    try {
+        const res = await fetch(`/booking/availability?${params}`);
+        const data = await res.json();
+        if (!res.ok) throw new Error(problemMessage(data, 'Не удалось загрузить календарь'));
+
+        const byDate = {};
+        data.room_types.forEach(rt => rt.nights.forEach(n => {
+            const day = byDate[n.date] || { date: n.date, available: 0, minPrice: 0 };
+            day.available += n.available;
+            if (n.available > 0 && (day.minPrice === 0 || n.min_price < day.minPrice)) {
+                day.minPrice = n.min_price;
+            }
+            byDate[n.date] = day;
+        }));
+        calendarNights = Object.values(byDate).sort((a, b) => a.date.localeCompare(b.date));
+        renderAvailabilityCalendar();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+function renderAvailabilityCalendar() {
+    const checkIn = searchForm.querySelector('input[name="check_in"]').value;
+    const checkOut = searchForm.querySelector('input[name="check_out"]').value;
+
+    document.getElementById('calendar-range').textContent =
+        `${formatDate(calendarStart)} - ${formatDate(addDays(calendarStart, CALENDAR_DAYS - 1))}`;
+
+    document.getElementById('calendar-days').innerHTML = calendarNights.map(n => {
+        const classes = ['calendar-day'];
+        if (n.available === 0) classes.push('sold-out');
+        if (n.date === checkIn || n.date === checkOut) classes.push('selected');
+        else if (n.date > checkIn && n.date < checkOut) classes.push('in-range');
+
+        const date = parseDate(n.date);
+        return `
+            <div class="${classes.join(' ')}" onclick="selectCalendarDate('${n.date}')">
+                <div>${date.toLocaleDateString('ru-RU', { weekday: 'short' })}</div>
+                <div class="day-number">${date.getDate()}</div>
+                <div class="day-price">${n.available > 0 ? `от ${formatPrice(Math.round(n.minPrice))}` : 'нет мест'}</div>
+            </div>
+        `;
+    }).join('');
+}
+
+function selectCalendarDate(date) {
+    const checkInInput = searchForm.querySelector('input[name="check_in"]');
+    const checkOutInput = searchForm.querySelector('input[name="check_out"]');
+    const night = calendarNights.find(n => n.date === date);
+
+    if (checkInInput.value && checkInInput.value < date && checkOutInput.value === addDays(checkInInput.value, 1)) {
+        checkOutInput.value = date;
+    } else {
+        if (!night || night.available === 0) return;
+        checkInInput.value = date;
+        checkOutInput.value = addDays(date, 1);
+    }
+    renderAvailabilityCalendar();
+}
+
+function shiftAvailabilityCalendar(direction) {
+    calendarStart = addDays(calendarStart, direction * CALENDAR_DAYS);
+    loadAvailabilityCalendar();
+}
+
 function renderRooms(rooms) {
     if (!rooms || rooms.length === 0) {
-        roomsGrid.innerHTML = '<p style="grid-column: 1/-1; text-align: center; padding: 20px;">Нет доступных номеров на выбранные даты</p>';
+        roomsGrid.innerHTML = `
+            <div style="grid-column: 1/-1; text-align: center; padding: 20px;">
+                <p>Нет доступных номеров на выбранные даты</p>
+                <button onclick="openWaitlistModal()" class="btn-secondary">Встать в лист ожидания</button>
+            </div>`;
         return;
     }
 
@@ -154,16 +341,21 @@ function renderRooms(rooms) {
 async function openBookingModal(room) {
     const modal = document.getElementById('booking-modal');
     modal.querySelector('input[name="room_id"]').value = room.id;
+    bookingIdempotencyKey = crypto.randomUUID();
+    modal.querySelectorAll('.stay-options input').forEach(input => input.checked = false);
+    modal.querySelector('input[name="redeem_points"]').value = 0;
+    const guestsInput = modal.querySelector('input[name="guests"]');
+    guestsInput.max = room.capacity;
+    guestsInput.value = Math.min(parseInt(searchParams.capacity) || 1, room.capacity);
+    loadLoyaltyBalance();
     document.getElementById('modal-room-number').textContent = room.room_number;
     document.getElementById('modal-room-type').textContent = getRoomTypeName(room.room_type);
     document.getElementById('modal-room-price').textContent = formatPrice(room.base_price);
 
-    const checkIn = new Date(searchParams.check_in);
-    const checkOut = new Date(searchParams.check_out);
-    const nights = getNights(checkIn, checkOut);
+    const nights = getNights(searchParams.check_in, searchParams.check_out);
 
-    document.getElementById('modal-check-in').textContent = checkIn.toLocaleDateString();
-    document.getElementById('modal-check-out').textContent = checkOut.toLocaleDateString();
+    document.getElementById('modal-check-in').textContent = formatDate(searchParams.check_in);
+    document.getElementById('modal-check-out').textContent = formatDate(searchParams.check_out);
     document.getElementById('modal-nights').textContent = nights;
 
     await calculatePrice(room.id, searchParams.check_in, searchParams.check_out);
@@ -179,7 +371,9 @@ async function calculatePrice(roomId, checkIn, checkOut) {
             body: JSON.stringify({
                 room_id: roomId,
                 check_in: checkIn,
-                check_out: checkOut
+                check_out: checkOut,
+                guest_email: bookingForm.querySelector('input[name="email"]').value,
+                ...getStayOptions()
             })
         });
 
@@ -187,13 +381,21 @@ async function calculatePrice(roomId, checkIn, checkOut) {
 
         const breakdownHtml = data.daily_breakdown.map(day => `
             <div class="breakdown-item">
-                <span>${new Date(day.date).toLocaleDateString()} (${day.reason})</span>
+                <span>${formatDate(day.date)} (${day.reason})</span>
                 <span>${formatPrice(day.day_price)} RUB</span>
             </div>
         `).join('');
 
+        const extrasHtml = (data.extras || []).map(extra => `
+            <div class="breakdown-item">
+                <span>${extra.name}</span>
+                <span>${formatPrice(extra.price)} RUB</span>
+            </div>
+        `).join('');
+
         document.getElementById('price-breakdown').innerHTML = `
             ${breakdownHtml}
+            ${extrasHtml}
             <div class="breakdown-total">
                 <span>Базовая цена:</span>
                 <span>${formatPrice(data.base_price)} RUB/ночь</span>
@@ -210,41 +412,134 @@ async function createBooking() {
     const formData = new FormData(bookingForm);
     const data = {
         room_id: parseInt(formData.get('room_id')),
-        start_date: new Date(searchParams.check_in).toISOString(),
-        end_date: new Date(searchParams.check_out).toISOString(),
+        start_date: searchParams.check_in,
+        end_date: searchParams.check_out,
         guest_info: {
             name: formData.get('name'),
             email: formData.get('email'),
             phone: formData.get('phone')
-        }
+        },
+        guests: parseInt(formData.get('guests')) || 1,
+        redeem_points: parseInt(formData.get('redeem_points')) || 0,
+        ...getStayOptions()
     };
 
     try {
-        const res = await fetch('/booking/', {
+        // Points are spent only by staff, who book through the admin API.
+        const url = data.redeem_points > 0 ? '/admin/bookings' : '/booking/';
+        const res = await fetch(url, {
             method: 'POST',
-            headers: { 'Content-Type': 'application/json' },
+            headers: { 'Content-Type': 'application/json', 'Idempotency-Key': bookingIdempotencyKey },
             body: JSON.stringify(data)
         });
 
-        if (!res.ok) throw new Error('Не удалось создать бронирование');
+        if (!res.ok) {
+            // The rejection is final for this request; a corrected form is a new one.
+            bookingIdempotencyKey = crypto.randomUUID();
+            const err = await res.json();
+            throw new Error(problemMessage(err, 'Не удалось создать бронирование'));
+        }
 
+        const booking = await res.json();
         showToast('Бронирование успешно создано!', 'success');
         closeModals();
         searchRooms(searchParams);
+        openPaymentModal(booking.id);
     } catch (err) {
         showToast(err.message, 'error');
     }
 }
 
+async function loadLoyaltyBalance() {
+    const label = document.getElementById('loyalty-balance');
+    const email = bookingForm.querySelector('input[name="email"]').value;
+    const points = parseInt(bookingForm.querySelector('input[name="redeem_points"]').value) || 0;
+    label.textContent = '';
+    if (!email || points <= 0) return;
+
+    try {
+        const res = await fetch(`/admin/loyalty?email=${encodeURIComponent(email)}`);
+        if (!res.ok) return;
+        const account = await res.json();
+        label.textContent = `(доступно ${account.balance}, ${getTierName(account.tier)})`;
+    } catch (err) {
+        console.error(err);
+    }
+}
+
+function getStayOptions() {
+    return {
+        early_check_in: bookingForm.querySelector('input[name="early_check_in"]').checked,
+        late_check_out: bookingForm.querySelector('input[name="late_check_out"]').checked
+    };
+}
+
+async function loadStayPolicy() {
+    try {
+        const res = await fetch('/booking/policy');
+        stayPolicy = await res.json();
+
+        document.getElementById('policy-early-check-in').textContent = stayPolicy.early_check_in_time;
+        document.getElementById('policy-early-check-in-fee').textContent = formatPrice(stayPolicy.early_check_in_fee);
+        document.getElementById('policy-late-check-out').textContent = stayPolicy.late_check_out_time;
+        document.getElementById('policy-late-check-out-fee').textContent = formatPrice(stayPolicy.late_check_out_fee);
+    } catch (err) {
+        console.error(err);
+    }
+}
+
 async function loadAdminData() {
     await Promise.all([
         loadAdminStats(),
+        loadFrontDesk(),
+        loadTapeChart(),
         loadAdminRooms(),
         loadAdminBookings(),
+        loadHousekeepingTasks(),
+        loadMaintenanceWindows(),
+        loadChannels(),
+        loadWebhooks(),
+        loadWaitlist(),
+        loadGuests(),
         loadSpecialDates()
     ]);
 }
 
+const LIVE_REFRESH_DELAY = 500;
+const liveBookingEvents = ['booking_created', 'booking_confirmed', 'booking_cancelled', 'booking_modified', 'checked_out', 'booking_status_changed'];
+let liveRefreshTimer = null;
+let liveRefreshBookings = false;
+let liveRefreshRooms = false;
+
+// connectLiveUpdates keeps the admin panel current through /admin/events. The
+// browser reconnects by itself and sends Last-Event-ID to catch up.
+function connectLiveUpdates() {
+    if (!window.EventSource) return;
+
+    const source = new EventSource('/admin/events');
+    liveBookingEvents.forEach(type => source.addEventListener(type, () => scheduleLiveRefresh(true, true)));
+    source.addEventListener('room_status_changed', () => scheduleLiveRefresh(false, true));
+    // The events missed while disconnected are gone, so reload everything.
+    source.addEventListener('reset', () => loadAdminData());
+}
+
+// scheduleLiveRefresh batches the reloads of a burst of events.
+function scheduleLiveRefresh(bookings, rooms) {
+    liveRefreshBookings = liveRefreshBookings || bookings;
+    liveRefreshRooms = liveRefreshRooms || rooms;
+    if (liveRefreshTimer) return;
+
+    liveRefreshTimer = setTimeout(() => {
+        const loads = [loadAdminStats(), loadFrontDesk(), loadTapeChart()];
+        if (liveRefreshBookings) loads.push(loadAdminBookings(), loadWaitlist());
+        if (liveRefreshRooms) loads.push(loadAdminRooms());
+        liveRefreshTimer = null;
+        liveRefreshBookings = false;
+        liveRefreshRooms = false;
+        Promise.all(loads);
+    }, LIVE_REFRESH_DELAY);
+}
+
 async function loadAdminStats() {
     try {
         const res = await fetch('/admin/stats');
@@ -273,6 +568,154 @@ async function loadAdminStats() {
     }
 }
 
+async function loadFrontDesk() {
+    try {
+        const res = await fetch('/admin/frontdesk');
+        const day = await res.json();
+
+        const tbody = document.querySelector('#frontdesk-table tbody');
+        const rows = [
+            ...day.arrivals.map(b => ({ ...b, direction: 'Заезд', time: b.check_in_time, extra: b.early_check_in })),
+            ...day.departures.map(b => ({ ...b, direction: 'Выезд', time: b.check_out_time, extra: b.late_check_out }))
+        ];
+
+        if (rows.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="7" style="text-align: center; padding: 20px;">Сегодня нет заездов и выездов</td></tr>';
+            return;
+        }
+
+        tbody.innerHTML = rows.map(b => `
+            <tr>
+                <td>${b.direction}</td>
+                <td>#${b.id}</td>
+                <td>${b.guest_info.name}</td>
+                <td>${b.room.room_number}</td>
+                <td>${b.time}${b.extra ? ' *' : ''}</td>
+                <td><span class="status-badge status-${b.status}">${getStatusName(b.status)}</span></td>
+                <td>${renderFrontDeskActions(b)}</td>
+            </tr>
+        `).join('');
+    } catch (err) {
+        console.error(err);
+    }
+}
+
+async function loadTapeChart() {
+    if (!tapeChartStart) tapeChartStart = toISODate(new Date());
+    const to = addDays(tapeChartStart, TAPE_CHART_DAYS);
+
+    try {
+        const res = await fetch(`/admin/tape-chart?from=${tapeChartStart}&to=${to}`);
+        const chart = await res.json();
+        if (!res.ok) throw new Error(problemMessage(chart, 'Не удалось загрузить шахматку'));
+        renderTapeChart(chart);
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+function renderTapeChart(chart) {
+    const days = getNights(chart.from, chart.to);
+    const dates = Array.from({ length: days }, (_, i) => addDays(chart.from, i));
+    const conflicts = new Set(chart.conflicts);
+
+    document.getElementById('tape-chart-range').textContent =
+        `${formatDate(chart.from)} - ${formatDate(addDays(chart.to, -1))}`;
+
+    // Clamps a stay to the visible range and returns its CSS grid columns.
+    const columns = (start, end) => {
+        const from = Math.max(getNights(chart.from, start), 0);
+        const until = Math.min(getNights(chart.from, end), days);
+        return `${from + 2} / ${until + 2}`;
+    };
+
+    const header = `
+        <div class="tape-row tape-row-header">
+            <div class="tape-room">Номер</div>
+            ${dates.map(d => `<div class="tape-cell">${parseDate(d).toLocaleDateString('ru-RU', { day: 'numeric', month: 'short' })}</div>`).join('')}
+        </div>
+    `;
+
+    const rows = chart.rooms.map(room => {
+        const cells = dates.map(d => `
+            <div class="tape-cell" data-room-id="${room.id}" data-date="${d}"
+                ondragover="onTapeDragOver(event)" ondragleave="onTapeDragLeave(event)" ondrop="onTapeDrop(event)"></div>
+        `).join('');
+
+        const blocks = chart.maintenance.filter(w => w.room_id === room.id).map(w => `
+            <div class="tape-block" style="grid-column: ${columns(w.start_date, w.end_date)}" title="${w.reason || ''}">
+                ${getMaintenanceKindName(w.kind)}
+            </div>
+        `).join('');
+
+        const bars = chart.bookings.filter(b => b.room_id === room.id).map(b => `
+            <div class="tape-bar status-badge status-${b.status} ${conflicts.has(b.id) ? 'conflict' : ''}"
+                style="grid-column: ${columns(b.start_date, b.end_date)}"
+                draggable="true" ondragstart="onTapeDragStart(event, ${b.id}, ${b.version})"
+                title="#${b.id} ${b.guest_info.name}, ${formatDate(b.start_date)} - ${formatDate(b.end_date)}, ${formatPrice(b.price)} RUB">
+                #${b.id} ${b.guest_info.name}
+            </div>
+        `).join('');
+
+        return `
+            <div class="tape-row">
+                <div class="tape-room">${room.room_number}</div>
+                ${cells}${blocks}${bars}
+            </div>
+        `;
+    }).join('');
+
+    const container = document.getElementById('tape-chart');
+    container.style.setProperty('--tape-days', days);
+    container.innerHTML = header + rows;
+}
+
+function onTapeDragStart(e, bookingId, version) {
+    e.dataTransfer.setData('text/plain', `${bookingId}:${version}`);
+}
+
+function onTapeDragOver(e) {
+    e.preventDefault();
+    e.currentTarget.classList.add('drag-over');
+}
+
+function onTapeDragLeave(e) {
+    e.currentTarget.classList.remove('drag-over');
+}
+
+async function onTapeDrop(e) {
+    e.preventDefault();
+    const cell = e.currentTarget;
+    cell.classList.remove('drag-over');
+
+    const [bookingId, version] = e.dataTransfer.getData('text/plain').split(':').map(Number);
+    await moveBooking(bookingId, version, parseInt(cell.dataset.roomId), cell.dataset.date);
+}
+
+async function moveBooking(id, version, roomId, startDate) {
+    try {
+        const res = await fetch(`/admin/bookings/${id}/move`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json', ...ifMatch(version) },
+            body: JSON.stringify({ room_id: roomId, start_date: startDate })
+        });
+        const data = await res.json();
+        if (!res.ok) throw new Error(problemMessage(data, 'Не удалось перенести бронирование'));
+
+        showToast(`Бронирование перенесено в номер ${data.room.room_number}, новая цена ${formatPrice(data.price)} RUB`, 'success');
+        loadTapeChart();
+        loadAdminBookings();
+    } catch (err) {
+        showToast(err.message, 'error');
+        loadTapeChart();
+    }
+}
+
+function shiftTapeChart(direction) {
+    tapeChartStart = addDays(tapeChartStart, direction * TAPE_CHART_DAYS);
+    loadTapeChart();
+}
+
 async function loadSpecialDates() {
     try {
         const res = await fetch('/admin/dates');
@@ -286,7 +729,7 @@ async function loadSpecialDates() {
 
         tbody.innerHTML = dates.map(d => `
             <tr>
-                <td>${new Date(d.date).toLocaleDateString()}</td>
+                <td>${formatDate(d.date)}</td>
                 <td>${d.name}</td>
                 <td>x${d.coefficient}</td>
                 <td>
@@ -337,14 +780,63 @@ async function deleteSpecialDate(id) {
     }
 }
 
+let importTarget = '';
+
+function chooseImportFile(target) {
+    importTarget = target;
+    const input = document.getElementById('import-file');
+    input.value = '';
+    input.click();
+}
+
+// importFile checks the chosen spreadsheet with a dry run and applies it
+// after the manager has seen what will change.
+async function importFile(input) {
+    const file = input.files[0];
+    if (!file) return;
+    const url = `/admin/${importTarget}/import`;
+
+    const upload = async (dryRun) => {
+        const form = new FormData();
+        form.append('file', file);
+        const res = await fetch(`${url}?dry_run=${dryRun}`, { method: 'POST', body: form });
+        const body = await res.json();
+        if (!res.ok && !(body && body.errors && body.rows !== undefined)) {
+            throw new Error(problemMessage(body, 'Не удалось импортировать файл'));
+        }
+        return body;
+    };
+
+    try {
+        const check = await upload(true);
+        if (check.errors.length) {
+            const lines = check.errors.slice(0, 10).map(e =>
+                `Строка ${e.row}: ` + e.errors.map(fe => `${fieldNames[fe.field] || fe.field} — ${validationMessages[fe.code] || fe.message}`).join(', '));
+            if (check.errors.length > 10) lines.push(`…и еще ${check.errors.length - 10}`);
+            alert(`Файл не импортирован, исправьте ошибки:\n${lines.join('\n')}`);
+            return;
+        }
+        if (!confirm(`Будет создано: ${check.created}, изменено: ${check.updated}, без изменений: ${check.unchanged}. Импортировать?`)) return;
+
+        const result = await upload(false);
+        showToast(`Импорт завершен: создано ${result.created}, изменено ${result.updated}`, 'success');
+        if (importTarget === 'rooms') loadAdminRooms();
+        else loadSpecialDates();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
 async function loadAdminRooms() {
     try {
-        const res = await fetch('/admin/rooms');
-        const rooms = await res.json();
+        const archived = document.getElementById('rooms-archived-filter').checked;
+        const res = await fetch(`/admin/rooms?limit=200&archived=${archived}`);
+        const page = await res.json();
+        const rooms = page.items;
 
         const tbody = document.querySelector('#admin-rooms-table tbody');
         if (!rooms || rooms.length === 0) {
-            tbody.innerHTML = '<tr><td colspan="5" style="text-align: center; padding: 20px;">Номеров не найдено</td></tr>';
+            tbody.innerHTML = '<tr><td colspan="6" style="text-align: center; padding: 20px;">Номеров не найдено</td></tr>';
             return;
         }
 
@@ -355,12 +847,24 @@ async function loadAdminRooms() {
                 <td>${formatPrice(room.base_price)}</td>
                 <td><span class="status-badge status-${room.status}">${getStatusName(room.status)}</span></td>
                 <td>
-                    <button onclick="deleteRoom(${room.id})" class="btn-icon" style="color: var(--danger)">
+                    <select onchange="updateHousekeepingStatus(${room.id}, this.value)">
+                        ${['clean', 'dirty', 'inspected', 'out_of_order'].map(st => `
+                            <option value="${st}" ${room.housekeeping_status === st ? 'selected' : ''}>${getHousekeepingName(st)}</option>
+                        `).join('')}
+                    </select>
+                </td>
+                <td>
+                    ${room.archived_at ? `
+                    <button onclick="restoreRoom(${room.id})" class="btn-secondary">Восстановить</button>
+                    ` : `
+                    <button onclick="openRoomCalendar(${room.id}, '${room.room_number}')" class="btn-secondary">Календарь</button>
+                    <button onclick="deleteRoom(${room.id}, '${room.room_number}')" class="btn-icon" style="color: var(--danger)" title="В архив">
                         <svg width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
                             <polyline points="3 6 5 6 21 6"></polyline>
                             <path d="M19 6v14a2 2 0 0 1-2 2H7a2 2 0 0 1-2-2V6m3 0V4a2 2 0 0 1 2-2h4a2 2 0 0 1 2 2v2"></path>
                         </svg>
                     </button>
+                    `}
                 </td>
             </tr>
         `).join('');
@@ -369,59 +873,97 @@ async function loadAdminRooms() {
     }
 }
 
-async function deleteRoom(id) {
-    if (!confirm('Удалить этот номер? Все связанные бронирования будут удалены.')) return;
-
+async function loadMaintenanceWindows() {
     try {
-        const res = await fetch(`/admin/rooms/${id}`, { method: 'DELETE' });
-        if (!res.ok) {
-            const err = await res.json();
-            throw new Error(err.message || 'Не удалось удалить номер');
+        const [windowsRes, roomsRes] = await Promise.all([fetch('/admin/maintenance'), fetch('/booking/rooms')]);
+        const windows = await windowsRes.json();
+        const rooms = await roomsRes.json();
+        const roomNumbers = Object.fromEntries((rooms || []).map(r => [r.id, r.room_number]));
+
+        const tbody = document.querySelector('#maintenance-table tbody');
+        if (!windows || windows.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="5" style="text-align: center; padding: 20px;">Нет закрытых периодов</td></tr>';
+            return;
         }
-        showToast('Номер удален', 'success');
-        loadAdminRooms();
+
+        tbody.innerHTML = windows.map(w => `
+            <tr>
+                <td>${roomNumbers[w.room_id] || w.room_id}</td>
+                <td>${formatDate(w.start_date)} - ${formatDate(w.end_date)}</td>
+                <td>${getMaintenanceKindName(w.kind)}</td>
+                <td>${w.reason || '—'}</td>
+                <td>
+                    <button onclick="deleteMaintenanceWindow(${w.id})" class="btn-icon" style="color: var(--danger)">
+                        <svg width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
+                            <polyline points="3 6 5 6 21 6"></polyline>
+                            <path d="M19 6v14a2 2 0 0 1-2 2H7a2 2 0 0 1-2-2V6m3 0V4a2 2 0 0 1 2-2h4a2 2 0 0 1 2 2v2"></path>
+                        </svg>
+                    </button>
+                </td>
+            </tr>
+        `).join('');
     } catch (err) {
-        showToast(err.message, 'error');
+        console.error(err);
     }
 }
 
-async function loadAdminBookings() {
+async function loadChannels() {
     try {
-        const status = document.getElementById('booking-status-filter').value;
-        const url = status ? `/admin/bookings?status=${status}` : '/admin/bookings';
-        const res = await fetch(url);
-        const bookings = await res.json();
+        const [channelsRes, conflictsRes] = await Promise.all([
+            fetch('/admin/channels'),
+            fetch('/admin/channels/reservations?state=conflict')
+        ]);
+        const channels = await channelsRes.json();
+        const conflicts = await conflictsRes.json();
+        if (!channelsRes.ok) throw new Error(problemMessage(channels, 'Не удалось загрузить каналы'));
+        if (!conflictsRes.ok) throw new Error(problemMessage(conflicts, 'Не удалось загрузить конфликты'));
+
+        document.getElementById('channel-mapping-channel').innerHTML = channels.map(c => `
+            <option value="${c.channel}">${c.channel}</option>
+        `).join('');
 
-        const tbody = document.querySelector('#admin-bookings-table tbody');
-        if (!bookings || bookings.length === 0) {
-            tbody.innerHTML = '<tr><td colspan="6" style="text-align: center; padding: 20px;">Бронирований не найдено</td></tr>';
-            return;
+        const tbody = document.querySelector('#channels-table tbody');
+        if (channels.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="5" style="text-align: center; padding: 20px;">Каналы не подключены</td></tr>';
+        } else {
+            tbody.innerHTML = channels.map(c => `
+                <tr>
+                    <td>${c.channel}</td>
+                    <td>
+                        ${c.mappings.length === 0 ? '—' : c.mappings.map(m => `
+                            <div>
+                                ${getRoomTypeName(m.room_type)} → ${m.room_code}${m.rate_code ? ' / ' + m.rate_code : ''}
+                                <button onclick="deleteChannelMapping(${m.id})" class="btn-icon" style="color: var(--danger)" title="Удалить">&times;</button>
+                            </div>
+                        `).join('')}
+                    </td>
+                    <td>
+                        ${c.last_sync ? `${new Date(c.last_sync.finished_at).toLocaleString('ru-RU')}
+                            <div><small>Новых броней: ${c.last_sync.booked}, изменений: ${c.last_sync.modified}, отмен: ${c.last_sync.cancelled}, отправлено дат: ${c.last_sync.pushed}</small></div>` : '—'}
+                        ${c.last_error ? `<div style="color: var(--danger)"><small>${c.last_error}</small></div>` : ''}
+                    </td>
+                    <td>${c.conflicts}</td>
+                    <td>
+                        <button onclick="syncChannel('${c.channel}')" class="btn-secondary">Синхронизировать</button>
+                    </td>
+                </tr>
+            `).join('');
         }
 
-        tbody.innerHTML = bookings.map(b => `
+        const conflictsBody = document.querySelector('#channel-conflicts-table tbody');
+        if (conflicts.length === 0) {
+            conflictsBody.innerHTML = '<tr><td colspan="5" style="text-align: center; padding: 20px;">Конфликтов нет</td></tr>';
+            return;
+        }
+        conflictsBody.innerHTML = conflicts.map(r => `
             <tr>
-                <td>#${b.id}</td>
-                <td>
-                    <div>${b.guest_info.name}</div>
-                    <small style="color: var(--text-light)">${b.guest_info.email}</small>
-                </td>
-                <td>${b.room.room_number}</td>
-                <td>${new Date(b.start_date).toLocaleDateString()} - ${new Date(b.end_date).toLocaleDateString()}</td>
-                <td><span class="status-badge status-${b.status}">${getStatusName(b.status)}</span></td>
+                <td>${r.channel} ${r.ref}<div><small>${r.room_code}</small></div></td>
+                <td>${r.guest.name || '—'}<div><small>Гостей: ${r.guests}</small></div></td>
+                <td>${formatDate(r.check_in)} - ${formatDate(r.check_out)}</td>
+                <td><small>${r.conflict}</small></td>
                 <td>
-                    ${b.status === 'pending' ? `
-                        <button onclick="confirmBooking(${b.id})" class="btn-icon" style="color: var(--success)" title="Подтвердить">
-                            <svg width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
-                                <polyline points="20 6 9 17 4 12"></polyline>
-                            </svg>
-                        </button>
-                        <button onclick="cancelBooking(${b.id})" class="btn-icon" style="color: var(--danger)" title="Отменить">
-                            <svg width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
-                                <line x1="18" y1="6" x2="6" y2="18"></line>
-                                <line x1="6" y1="6" x2="18" y2="18"></line>
-                            </svg>
-                        </button>
-                    ` : ''}
+                    <button onclick="resolveChannelReservation(${r.id})" class="btn-secondary">Разместить</button>
+                    <button onclick="rejectChannelReservation(${r.id})" class="btn-secondary">Отклонить</button>
                 </td>
             </tr>
         `).join('');
@@ -430,79 +972,1398 @@ async function loadAdminBookings() {
     }
 }
 
-async function confirmBooking(id) {
-    if (!confirm('Подтвердить бронирование?')) return;
+async function syncChannel(name) {
     try {
-        const res = await fetch(`/booking/${id}/confirm`, { method: 'PUT' });
-        if (!res.ok) throw new Error('Не удалось подтвердить');
-        showToast('Бронирование подтверждено', 'success');
+        const res = await fetch(`/admin/channels/${encodeURIComponent(name)}/sync`, { method: 'POST' });
+        const result = await res.json();
+        if (!res.ok) throw new Error(problemMessage(result, 'Не удалось синхронизировать канал'));
+
+        if (result.conflicts > 0) {
+            showToast(`Синхронизировано, конфликтов: ${result.conflicts}`, 'error');
+        } else {
+            showToast('Канал синхронизирован', 'success');
+        }
         loadAdminBookings();
     } catch (err) {
         showToast(err.message, 'error');
     }
+    loadChannels();
 }
 
-async function cancelBooking(id) {
-    if (!confirm('Отменить бронирование?')) return;
+async function addChannelMapping(formData) {
     try {
-        const res = await fetch(`/booking/${id}/cancel`, { method: 'PUT' });
-        if (!res.ok) throw new Error('Не удалось отменить');
-        showToast('Бронирование отменено', 'success');
-        loadAdminBookings();
+        const res = await fetch('/admin/channels/mappings', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(Object.fromEntries(formData.entries()))
+        });
+        if (!res.ok) throw new Error(problemMessage(await res.json(), 'Не удалось сопоставить тип номера'));
+
+        document.getElementById('channel-mapping-form').reset();
+        showToast('Тип номера сопоставлен', 'success');
+        loadChannels();
     } catch (err) {
         showToast(err.message, 'error');
     }
 }
 
-async function addRoom(formData) {
-    try {
-        const data = Object.fromEntries(formData.entries());
-        data.base_price = parseFloat(data.base_price);
-        data.capacity = parseInt(data.capacity);
-
-        const res = await fetch('/admin/rooms', {
-            method: 'POST',
-            headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify(data)
-        });
-
-        if (!res.ok) throw new Error('Не удалось создать номер');
+async function deleteChannelMapping(id) {
+    if (!confirm('Снять тип номера с продажи в канале?')) return;
 
-        showToast('Номер создан', 'success');
-        closeModals();
-        loadAdminRooms();
+    try {
+        const res = await fetch(`/admin/channels/mappings/${id}`, { method: 'DELETE' });
+        if (!res.ok) throw new Error(problemMessage(await res.json(), 'Не удалось удалить сопоставление'));
+        loadChannels();
     } catch (err) {
         showToast(err.message, 'error');
     }
 }
 
-async function sendNotification(formData) {
+async function resolveChannelReservation(id) {
+    const roomNumber = prompt('Номер комнаты для размещения');
+    if (!roomNumber) return;
+
     try {
-        const data = Object.fromEntries(formData.entries());
-        const res = await fetch('/notification/send', {
+        const roomsRes = await fetch('/booking/rooms');
+        const rooms = await roomsRes.json();
+        const room = (rooms || []).find(r => r.room_number === roomNumber.trim());
+        if (!room) throw new Error('Номер не найден');
+
+        const res = await fetch(`/admin/channels/reservations/${id}/resolve`, {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify(data)
+            body: JSON.stringify({ room_id: room.id })
         });
+        if (!res.ok) throw new Error(problemMessage(await res.json(), 'Не удалось разместить бронь'));
 
-        if (!res.ok) throw new Error('Не удалось отправить');
-
-        showToast('Уведомление отправлено', 'success');
-        document.getElementById('notification-form').reset();
+        showToast('Бронь размещена', 'success');
+        loadChannels();
+        loadAdminBookings();
     } catch (err) {
         showToast(err.message, 'error');
     }
 }
 
-function getRoomTypeName(type) {
-    const types = {
-        'standard': 'Стандарт',
-        'deluxe': 'Делюкс',
-        'suite': 'Люкс',
-        'family': 'Семейный'
-    };
-    return types[type] || type;
-}
+async function rejectChannelReservation(id) {
+    if (!confirm('Отклонить бронь? Гостя придется переселить через канал.')) return;
+
+    try {
+        const res = await fetch(`/admin/channels/reservations/${id}/reject`, { method: 'POST' });
+        if (!res.ok) throw new Error(problemMessage(await res.json(), 'Не удалось отклонить бронь'));
+        loadChannels();
+        loadAdminBookings();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+const webhookEventNames = {
+    'booking_created': 'Создание',
+    'booking_confirmed': 'Подтверждение',
+    'booking_cancelled': 'Отмена',
+    'booking_modified': 'Изменение',
+    'checked_out': 'Выезд',
+    'webhook_test': 'Тест'
+};
+
+const webhookDeliveryStatusNames = {
+    'pending': 'Ожидает',
+    'delivered': 'Доставлено',
+    'failed': 'Не доставлено'
+};
+
+async function loadWebhooks() {
+    try {
+        const res = await fetch('/admin/webhooks');
+        const webhooks = await res.json();
+        if (!res.ok) throw new Error(problemMessage(webhooks, 'Не удалось загрузить вебхуки'));
+
+        const tbody = document.querySelector('#webhooks-table tbody');
+        if (webhooks.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="4" style="text-align: center; padding: 20px;">Вебхуков нет</td></tr>';
+            return;
+        }
+        tbody.innerHTML = webhooks.map(w => `
+            <tr>
+                <td>${w.url}</td>
+                <td>${w.events.map(e => webhookEventNames[e] || e).join(', ')}</td>
+                <td>${w.active ? 'Активен' : 'Приостановлен'}</td>
+                <td>
+                    <button onclick="loadWebhookDeliveries(${w.id})" class="btn-secondary">Журнал</button>
+                    <button onclick="testWebhook(${w.id})" class="btn-secondary">Тест</button>
+                    <button onclick="setWebhookActive(${w.id}, ${!w.active})" class="btn-secondary">${w.active ? 'Приостановить' : 'Возобновить'}</button>
+                    <button onclick="deleteWebhook(${w.id})" class="btn-icon" style="color: var(--danger)" title="Удалить">&times;</button>
+                </td>
+            </tr>
+        `).join('');
+    } catch (err) {
+        console.error(err);
+    }
+}
+
+async function addWebhook(formData) {
+    try {
+        const res = await fetch('/admin/webhooks', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+                url: formData.get('url'),
+                secret: formData.get('secret'),
+                events: formData.getAll('events')
+            })
+        });
+        if (!res.ok) throw new Error(problemMessage(await res.json(), 'Не удалось добавить вебхук'));
+
+        document.getElementById('webhook-form').reset();
+        showToast('Вебхук добавлен', 'success');
+        loadWebhooks();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function setWebhookActive(id, active) {
+    try {
+        const res = await fetch(`/admin/webhooks/${id}`, {
+            method: 'PATCH',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ active })
+        });
+        if (!res.ok) throw new Error(problemMessage(await res.json(), 'Не удалось изменить вебхук'));
+        loadWebhooks();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function deleteWebhook(id) {
+    if (!confirm('Удалить вебхук вместе с журналом доставки?')) return;
+
+    try {
+        const res = await fetch(`/admin/webhooks/${id}`, { method: 'DELETE' });
+        if (!res.ok) throw new Error(problemMessage(await res.json(), 'Не удалось удалить вебхук'));
+        document.querySelector('#webhook-deliveries-table tbody').innerHTML = '';
+        loadWebhooks();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function testWebhook(id) {
+    try {
+        const res = await fetch(`/admin/webhooks/${id}/test`, { method: 'POST' });
+        const delivery = await res.json();
+        if (!res.ok) throw new Error(problemMessage(delivery, 'Не удалось отправить тестовое событие'));
+
+        if (delivery.status === 'delivered') {
+            showToast('Тестовое событие доставлено', 'success');
+        } else {
+            showToast(`Тестовое событие не доставлено: ${delivery.last_error}`, 'error');
+        }
+        loadWebhookDeliveries(id);
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function loadWebhookDeliveries(id) {
+    try {
+        const res = await fetch(`/admin/webhooks/${id}/deliveries`);
+        const deliveries = await res.json();
+        if (!res.ok) throw new Error(problemMessage(deliveries, 'Не удалось загрузить журнал доставки'));
+
+        const tbody = document.querySelector('#webhook-deliveries-table tbody');
+        if (deliveries.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="5" style="text-align: center; padding: 20px;">Доставок еще не было</td></tr>';
+            return;
+        }
+        tbody.innerHTML = deliveries.map(d => `
+            <tr>
+                <td>${webhookEventNames[d.event_type] || d.event_type}</td>
+                <td>
+                    ${webhookDeliveryStatusNames[d.status] || d.status}
+                    ${d.status === 'pending' && d.next_attempt_at ? `<div><small>Следующая попытка: ${new Date(d.next_attempt_at).toLocaleString('ru-RU')}</small></div>` : ''}
+                </td>
+                <td>${d.attempts}</td>
+                <td>
+                    ${d.response_status || '—'}
+                    ${d.last_error ? `<div style="color: var(--danger)"><small>${d.last_error}</small></div>` : ''}
+                </td>
+                <td>${new Date(d.created_at).toLocaleString('ru-RU')}</td>
+            </tr>
+        `).join('');
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+const waitlistStatusNames = {
+    'waiting': 'Ожидает',
+    'offered': 'Предложен номер',
+    'booked': 'Забронировано',
+    'expired': 'Истекло',
+    'cancelled': 'Отменено'
+};
+
+let waitlistHoldToken = null;
+
+function openWaitlistModal() {
+    const form = document.getElementById('waitlist-form');
+    form.check_in.value = searchParams.check_in || '';
+    form.check_out.value = searchParams.check_out || '';
+    form.room_type.value = searchParams.room_type || 'standard';
+    form.guests.value = parseInt(searchParams.capacity) || 1;
+    document.getElementById('waitlist-modal').classList.add('active');
+}
+
+async function joinWaitlist(formData) {
+    try {
+        const data = Object.fromEntries(formData.entries());
+        const res = await fetch('/waitlist', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+                room_type: data.room_type,
+                check_in: data.check_in,
+                check_out: data.check_out,
+                guests: parseInt(data.guests),
+                guest_info: { name: data.name, email: data.email, phone: data.phone }
+            })
+        });
+        if (!res.ok) throw new Error(problemMessage(await res.json(), 'Не удалось встать в лист ожидания'));
+
+        showToast('Вы в листе ожидания. Мы сообщим, когда номер освободится', 'success');
+        closeModals();
+        document.getElementById('waitlist-form').reset();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+// openHoldFromLink shows the offer a guest follows from the waitlist
+// notification (?hold=<token>).
+async function openHoldFromLink() {
+    const token = new URLSearchParams(window.location.search).get('hold');
+    if (!token) return;
+
+    try {
+        const res = await fetch(`/waitlist/holds/${encodeURIComponent(token)}`);
+        const hold = await res.json();
+        if (!res.ok) throw new Error(problemMessage(hold, 'Предложение не найдено'));
+
+        waitlistHoldToken = token;
+        const booking = hold.booking;
+        document.getElementById('hold-details').innerHTML = `
+            <div class="breakdown-item"><span>Номер</span><span>${getRoomTypeName(booking.room.room_type)} №${booking.room.room_number}</span></div>
+            <div class="breakdown-item"><span>Даты</span><span>${formatDate(booking.start_date)} - ${formatDate(booking.end_date)}</span></div>
+            <div class="breakdown-item"><span>Гостей</span><span>${booking.guests}</span></div>
+            <div class="breakdown-item"><span>Подтвердить до</span><span>${new Date(hold.entry.hold_expires_at).toLocaleString('ru-RU')}</span></div>
+            <div class="breakdown-total"><span>Стоимость</span><span>${formatPrice(booking.price)} RUB</span></div>
+        `;
+        document.getElementById('hold-modal').classList.add('active');
+    } catch (err) {
+        showToast(err.message, 'error');
+        clearHoldLink();
+    }
+}
+
+async function claimHold() {
+    try {
+        const res = await fetch(`/waitlist/holds/${encodeURIComponent(waitlistHoldToken)}/claim`, { method: 'POST' });
+        const hold = await res.json();
+        if (!res.ok) throw new Error(problemMessage(hold, 'Не удалось подтвердить бронирование'));
+
+        showToast(`Бронирование #${hold.booking.id} подтверждено`, 'success');
+        closeModals();
+        clearHoldLink();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function declineHold() {
+    if (!confirm('Отказаться от номера? Он будет предложен следующему гостю.')) return;
+
+    try {
+        const res = await fetch(`/waitlist/holds/${encodeURIComponent(waitlistHoldToken)}/decline`, { method: 'POST' });
+        if (!res.ok) throw new Error(problemMessage(await res.json(), 'Не удалось отказаться от номера'));
+
+        showToast('Вы отказались от номера', 'success');
+        closeModals();
+        clearHoldLink();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+function clearHoldLink() {
+    waitlistHoldToken = null;
+    window.history.replaceState(null, '', window.location.pathname);
+}
+
+async function loadWaitlist() {
+    try {
+        const status = document.getElementById('waitlist-status-filter').value;
+        const res = await fetch(`/admin/waitlist${status ? `?status=${status}` : ''}`);
+        const entries = await res.json();
+        if (!res.ok) throw new Error(problemMessage(entries, 'Не удалось загрузить лист ожидания'));
+
+        const tbody = document.querySelector('#waitlist-table tbody');
+        if (entries.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="6" style="text-align: center; padding: 20px;">Лист ожидания пуст</td></tr>';
+            return;
+        }
+        tbody.innerHTML = entries.map(e => `
+            <tr>
+                <td>${e.guest_info.name}<div><small>${e.guest_info.email}</small></div></td>
+                <td>${getRoomTypeName(e.room_type)}, ${e.guests} гост.</td>
+                <td>${formatDate(e.check_in)} - ${formatDate(e.check_out)}</td>
+                <td>${waitlistStatusNames[e.status] || e.status}${e.booking_id ? ` (#${e.booking_id})` : ''}</td>
+                <td>${e.status === 'offered' && e.hold_expires_at ? new Date(e.hold_expires_at).toLocaleString('ru-RU') : ''}</td>
+                <td>
+                    ${e.status === 'waiting' || e.status === 'offered' ? `<button onclick="removeWaitlistEntry(${e.id})" class="btn-icon" style="color: var(--danger)" title="Удалить">&times;</button>` : ''}
+                </td>
+            </tr>
+        `).join('');
+    } catch (err) {
+        console.error(err);
+    }
+}
+
+async function offerWaitlist() {
+    try {
+        const res = await fetch('/admin/waitlist/offer', { method: 'POST' });
+        const result = await res.json();
+        if (!res.ok) throw new Error(problemMessage(result, 'Не удалось предложить номера'));
+
+        showToast(result.offered > 0 ? `Предложено номеров: ${result.offered}` : 'Подходящих свободных номеров нет', 'success');
+        loadWaitlist();
+        loadAdminBookings();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function removeWaitlistEntry(id) {
+    if (!confirm('Удалить гостя из листа ожидания? Удерживаемый номер будет освобожден.')) return;
+
+    try {
+        const res = await fetch(`/admin/waitlist/${id}`, { method: 'DELETE' });
+        if (!res.ok) throw new Error(problemMessage(await res.json(), 'Не удалось удалить заявку'));
+        loadWaitlist();
+        loadAdminBookings();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function openMaintenanceModal() {
+    try {
+        const res = await fetch('/booking/rooms');
+        const rooms = await res.json();
+        document.getElementById('maintenance-room-select').innerHTML = (rooms || []).map(r => `
+            <option value="${r.id}">${r.room_number} (${getRoomTypeName(r.room_type)})</option>
+        `).join('');
+        document.getElementById('maintenance-modal').classList.add('active');
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function postMaintenanceWindow(data) {
+    const res = await fetch('/admin/maintenance', {
+        method: 'POST',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(data)
+    });
+    const body = await res.json();
+    if (!res.ok) throw new Error(problemMessage(body, 'Не удалось закрыть номер'));
+    return body;
+}
+
+async function addMaintenanceWindow(formData) {
+    try {
+        const data = Object.fromEntries(formData.entries());
+        data.room_id = parseInt(data.room_id);
+
+        const preview = await postMaintenanceWindow({ ...data, dry_run: true });
+        if (preview.conflicts.length > 0) {
+            const details = preview.conflicts.map(c => {
+                const options = c.candidates.map(r => r.room_number).join(', ') || 'нет свободных номеров';
+                return `#${c.booking.id} ${c.booking.guest_info.name}: можно переселить в ${options}`;
+            }).join('\n');
+            if (!confirm(`В этот период есть бронирования:\n${details}\n\nВсе равно закрыть номер?`)) return;
+        }
+
+        await postMaintenanceWindow(data);
+        showToast('Номер закрыт на период', 'success');
+        closeModals();
+        loadMaintenanceWindows();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function deleteMaintenanceWindow(id) {
+    if (!confirm('Открыть номер для продажи?')) return;
+    try {
+        const res = await fetch(`/admin/maintenance/${id}`, { method: 'DELETE' });
+        if (!res.ok) throw new Error('Не удалось удалить период');
+        loadMaintenanceWindows();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function loadGuests() {
+    try {
+        const q = document.getElementById('guest-search').value;
+        const res = await fetch(`/admin/guests?limit=20&q=${encodeURIComponent(q)}`);
+        const page = await res.json();
+        if (!res.ok) throw new Error(problemMessage(page, 'Не удалось загрузить гостей'));
+
+        const tbody = document.querySelector('#guests-table tbody');
+        if (page.items.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="4" style="text-align: center; padding: 20px;">Гостей не найдено</td></tr>';
+            return;
+        }
+
+        tbody.innerHTML = page.items.map(g => `
+            <tr>
+                <td>
+                    <div>${g.name}</div>
+                    <small style="color: var(--text-light)">${g.email}</small>
+                </td>
+                <td>${g.phone || '—'}</td>
+                <td>${g.notes || '—'}</td>
+                <td><button onclick="openGuestProfile(${g.id})" class="btn-secondary">Профиль</button></td>
+            </tr>
+        `).join('');
+    } catch (err) {
+        console.error(err);
+    }
+}
+
+async function loadDuplicateGuests() {
+    try {
+        const res = await fetch('/admin/guests/duplicates');
+        const candidates = await res.json();
+        if (!res.ok) throw new Error(problemMessage(candidates, 'Не удалось найти дубликаты'));
+
+        const tbody = document.querySelector('#guests-table tbody');
+        if (candidates.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="4" style="text-align: center; padding: 20px;">Дубликатов не найдено</td></tr>';
+            return;
+        }
+
+        tbody.innerHTML = candidates.map(c => `
+            <tr>
+                <td>
+                    <div>${c.guest.name} / ${c.duplicate.name}</div>
+                    <small style="color: var(--text-light)">${c.guest.email} / ${c.duplicate.email}</small>
+                </td>
+                <td>${c.guest.phone || '—'} / ${c.duplicate.phone || '—'}</td>
+                <td>Совпадает ${c.reason === 'phone' ? 'телефон' : 'имя'}</td>
+                <td><button onclick="mergeGuests(${c.duplicate.id}, ${c.guest.id})" class="btn-secondary">Объединить</button></td>
+            </tr>
+        `).join('');
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function mergeGuests(sourceId, targetId) {
+    if (!confirm('Объединить профили гостей? Бронирования перейдут в основной профиль.')) return;
+    try {
+        const res = await fetch('/admin/guests/merge', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ source_id: sourceId, target_id: targetId })
+        });
+        const data = await res.json();
+        if (!res.ok) throw new Error(problemMessage(data, 'Не удалось объединить профили'));
+
+        showToast('Профили объединены', 'success');
+        loadDuplicateGuests();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function openGuestProfile(id) {
+    try {
+        const res = await fetch(`/admin/guests/${id}`);
+        const profile = await res.json();
+        if (!res.ok) throw new Error(problemMessage(profile, 'Не удалось загрузить профиль'));
+
+        document.getElementById('guest-modal-name').textContent = profile.name;
+        document.getElementById('guest-modal-summary').innerHTML = `
+            <div class="breakdown-item"><span>${profile.email}</span><span>${profile.phone || ''}</span></div>
+            <div class="breakdown-item"><span>Проживаний</span><span>${profile.stay_count} (${profile.nights} ночей)</span></div>
+            <div class="breakdown-item"><span>Принес отелю</span><span>${formatPrice(profile.lifetime_value)} RUB</span></div>
+            <div class="breakdown-item"><span>Будущие брони</span><span>${formatPrice(profile.upcoming_value)} RUB</span></div>
+            ${profile.last_stay ? `<div class="breakdown-item"><span>Последний заезд</span><span>${formatDate(profile.last_stay)}</span></div>` : ''}
+        `;
+
+        document.querySelector('#guest-stays-table tbody').innerHTML = profile.stays.map(b => `
+            <tr>
+                <td>#${b.id}</td>
+                <td>${b.room.room_number}</td>
+                <td>${formatDate(b.start_date)} - ${formatDate(b.end_date)}</td>
+                <td>${formatPrice(b.price)} RUB</td>
+                <td><span class="status-badge status-${b.status}">${getStatusName(b.status)}</span></td>
+            </tr>
+        `).join('');
+
+        const form = document.getElementById('guest-form');
+        form.querySelector('input[name="id"]').value = profile.id;
+        form.querySelector('textarea[name="preferences"]').value =
+            Object.entries(profile.preferences || {}).map(([key, value]) => `${key}: ${value}`).join('\n');
+        form.querySelector('textarea[name="notes"]').value = profile.notes;
+
+        document.getElementById('loyalty-adjust-form').querySelector('input[name="guest_id"]').value = profile.id;
+        await loadGuestLoyalty(profile.id);
+
+        document.getElementById('guest-modal').classList.add('active');
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function loadGuestLoyalty(guestId) {
+    const res = await fetch(`/admin/guests/${guestId}/loyalty`);
+    const account = await res.json();
+    if (!res.ok) throw new Error(problemMessage(account, 'Не удалось загрузить баллы'));
+    renderGuestLoyalty(account);
+}
+
+function renderGuestLoyalty(account) {
+    document.getElementById('guest-loyalty-summary').innerHTML = `
+        <div class="breakdown-item"><span>Уровень</span><span>${getTierName(account.tier)}${account.discount ? ` (скидка ${Math.round(account.discount * 100)}%)` : ''}</span></div>
+        <div class="breakdown-item"><span>Баланс</span><span>${account.balance} баллов (${formatPrice(account.balance_value)} RUB)</span></div>
+        ${account.next_tier ? `<div class="breakdown-item"><span>До уровня ${getTierName(account.next_tier)}</span><span>${account.points_to_next_tier} баллов</span></div>` : ''}
+    `;
+
+    document.querySelector('#guest-loyalty-table tbody').innerHTML = account.entries.map(e => `
+        <tr>
+            <td>${new Date(e.created_at).toLocaleDateString()}</td>
+            <td>${getLedgerEntryName(e.type)}</td>
+            <td>${e.points > 0 ? '+' : ''}${e.points}</td>
+            <td>${e.expires_at ? formatDate(e.expires_at) : ''}</td>
+            <td>${e.reason}${e.actor ? ` (${e.actor})` : ''}</td>
+        </tr>
+    `).join('');
+}
+
+async function adjustLoyalty(formData) {
+    try {
+        const res = await fetch(`/admin/guests/${formData.get('guest_id')}/loyalty/adjust`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({
+                points: parseInt(formData.get('points')),
+                reason: formData.get('reason'),
+                actor: formData.get('actor')
+            })
+        });
+        const account = await res.json();
+        if (!res.ok) throw new Error(problemMessage(account, 'Не удалось скорректировать баллы'));
+
+        showToast('Баллы скорректированы', 'success');
+        renderGuestLoyalty(account);
+        const form = document.getElementById('loyalty-adjust-form');
+        form.querySelector('input[name="points"]').value = '';
+        form.querySelector('input[name="reason"]').value = '';
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function saveGuest(formData) {
+    const preferences = {};
+    formData.get('preferences').split('\n').forEach(line => {
+        const [key, ...value] = line.split(':');
+        if (key.trim()) preferences[key.trim()] = value.join(':').trim();
+    });
+
+    try {
+        const res = await fetch(`/admin/guests/${formData.get('id')}`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ preferences, notes: formData.get('notes') })
+        });
+        const data = await res.json();
+        if (!res.ok) throw new Error(problemMessage(data, 'Не удалось сохранить профиль'));
+
+        showToast('Профиль гостя сохранен', 'success');
+        closeModals();
+        loadGuests();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function updateHousekeepingStatus(roomId, status) {
+    try {
+        const res = await fetch(`/housekeeping/rooms/${roomId}/status`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ status })
+        });
+        if (!res.ok) throw new Error('Не удалось обновить статус уборки');
+        showToast('Статус уборки обновлен', 'success');
+    } catch (err) {
+        showToast(err.message, 'error');
+        loadAdminRooms();
+    }
+}
+
+async function loadHousekeepingTasks() {
+    try {
+        const status = document.getElementById('housekeeping-status-filter').value;
+        const url = status ? `/housekeeping/tasks?status=${status}` : '/housekeeping/tasks';
+        const res = await fetch(url);
+        const tasks = await res.json();
+
+        const tbody = document.querySelector('#housekeeping-tasks-table tbody');
+        if (!tasks || tasks.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="6" style="text-align: center; padding: 20px;">Задач нет</td></tr>';
+            return;
+        }
+
+        tbody.innerHTML = tasks.map(t => `
+            <tr>
+                <td>${t.room.room_number}</td>
+                <td>${getTaskTypeName(t.type)}</td>
+                <td>${t.assignee || '—'}</td>
+                <td>${formatDate(t.due_date)}</td>
+                <td><span class="status-badge status-${t.status}">${getTaskStatusName(t.status)}</span></td>
+                <td>
+                    ${t.status !== 'done' ? `
+                        <button onclick="assignHousekeepingTask(${t.id})" class="btn-secondary">Назначить</button>
+                        <button onclick="completeHousekeepingTask(${t.id})" class="btn-secondary">Готово</button>
+                    ` : ''}
+                </td>
+            </tr>
+        `).join('');
+    } catch (err) {
+        console.error(err);
+    }
+}
+
+async function updateHousekeepingTask(id, data) {
+    const res = await fetch(`/housekeeping/tasks/${id}`, {
+        method: 'PUT',
+        headers: { 'Content-Type': 'application/json' },
+        body: JSON.stringify(data)
+    });
+    if (!res.ok) {
+        const err = await res.json();
+        throw new Error(problemMessage(err, 'Не удалось обновить задачу'));
+    }
+}
+
+async function assignHousekeepingTask(id) {
+    const assignee = prompt('Исполнитель');
+    if (!assignee) return;
+    try {
+        await updateHousekeepingTask(id, { assignee, status: 'in_progress' });
+        loadHousekeepingTasks();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function completeHousekeepingTask(id) {
+    try {
+        await updateHousekeepingTask(id, { status: 'done' });
+        showToast('Задача выполнена', 'success');
+        loadHousekeepingTasks();
+        loadAdminRooms();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function deleteRoom(id, roomNumber) {
+    if (!confirm('Перенести номер в архив? История бронирований сохранится.')) return;
+
+    try {
+        const res = await fetch(`/admin/rooms/${id}`, { method: 'DELETE' });
+        if (!res.ok) {
+            const err = await res.json();
+            if (err.code === 'room_has_bookings') {
+                await openArchiveRoomModal(id, roomNumber);
+                return;
+            }
+            throw new Error(problemMessage(err, 'Не удалось архивировать номер'));
+        }
+        showToast('Номер перенесен в архив', 'success');
+        loadAdminRooms();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function openArchiveRoomModal(id, roomNumber) {
+    const res = await fetch(`/admin/rooms/${id}/archive-plan`);
+    const plan = await res.json();
+    if (!res.ok) throw new Error(problemMessage(plan, 'Не удалось получить бронирования номера'));
+
+    const form = document.getElementById('archive-room-form');
+    form.querySelector('input[name="room_id"]').value = id;
+    document.getElementById('archive-room-number').textContent = roomNumber;
+    document.getElementById('archive-relocations').innerHTML = plan.map(p => `
+        <div class="form-group">
+            <label>#${p.booking.id} ${p.booking.guest_info.name}, ${formatDate(p.booking.start_date)} - ${formatDate(p.booking.end_date)}</label>
+            <select name="${p.booking.id}" required>
+                ${p.candidates.length === 0 ? '<option value="">Нет свободных номеров</option>' : ''}
+                ${p.candidates.map(r => `
+                    <option value="${r.id}">${r.room_number} (${getRoomTypeName(r.room_type)})</option>
+                `).join('')}
+            </select>
+        </div>
+    `).join('');
+    document.getElementById('archive-room-modal').classList.add('active');
+}
+
+async function archiveRoom(form) {
+    try {
+        const id = form.querySelector('input[name="room_id"]').value;
+        const relocations = [...form.querySelectorAll('#archive-relocations select')].map(sel => ({
+            booking_id: parseInt(sel.name),
+            room_id: parseInt(sel.value)
+        }));
+
+        const res = await fetch(`/admin/rooms/${id}/archive`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ relocations })
+        });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(problemMessage(err, 'Не удалось архивировать номер'));
+        }
+        showToast('Гости переселены, номер перенесен в архив', 'success');
+        closeModals();
+        loadAdminRooms();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function restoreRoom(id) {
+    try {
+        const res = await fetch(`/admin/rooms/${id}/restore`, { method: 'POST' });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(problemMessage(err, 'Не удалось восстановить номер'));
+        }
+        showToast('Номер восстановлен', 'success');
+        loadAdminRooms();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+let bookingsCursor = '';
+
+// bookingFilterParams returns the sort and filters chosen above the bookings table.
+function bookingFilterParams() {
+    const [sort, order] = document.getElementById('booking-sort').value.split(':');
+    const params = new URLSearchParams({ sort, order });
+    const filters = {
+        status: document.getElementById('booking-status-filter').value,
+        email: document.getElementById('booking-email-filter').value,
+        from: document.getElementById('booking-from-filter').value,
+        to: document.getElementById('booking-to-filter').value
+    };
+    Object.entries(filters).forEach(([key, value]) => value && params.set(key, value));
+    return params;
+}
+
+function exportAdminBookings(format) {
+    const params = bookingFilterParams();
+    params.set('format', format);
+    window.location.href = `/admin/bookings/export?${params}`;
+}
+
+async function loadAdminBookings(more = false) {
+    try {
+        const params = bookingFilterParams();
+        params.set('limit', 20);
+        if (more && bookingsCursor) params.set('cursor', bookingsCursor);
+
+        const res = await fetch(`/admin/bookings?${params}`);
+        const page = await res.json();
+        if (!res.ok) throw new Error(problemMessage(page, 'Не удалось загрузить бронирования'));
+
+        bookingsCursor = page.next_cursor || '';
+        document.getElementById('admin-bookings-more').style.display = bookingsCursor ? '' : 'none';
+        document.getElementById('admin-bookings-total').textContent = `Всего: ${page.total}`;
+
+        const tbody = document.querySelector('#admin-bookings-table tbody');
+        if (!more && page.items.length === 0) {
+            tbody.innerHTML = '<tr><td colspan="6" style="text-align: center; padding: 20px;">Бронирований не найдено</td></tr>';
+            return;
+        }
+
+        const rows = page.items.map(renderAdminBookingRow).join('');
+
+        if (more) {
+            tbody.insertAdjacentHTML('beforeend', rows);
+        } else {
+            tbody.innerHTML = rows;
+        }
+    } catch (err) {
+        console.error(err);
+    }
+}
+
+function renderAdminBookingRow(b) {
+    return `
+        <tr>
+            <td>#${b.id}</td>
+            <td>
+                <div>${b.guest_id ? `<a href="#" onclick="openGuestProfile(${b.guest_id}); return false;">${b.guest_info.name}</a>` : b.guest_info.name}</div>
+                <small style="color: var(--text-light)">${b.guest_info.email}</small>
+            </td>
+            <td>${b.room.room_number}</td>
+            <td>${formatDate(b.start_date)} - ${formatDate(b.end_date)}</td>
+            <td><span class="status-badge status-${b.status}">${getStatusName(b.status)}</span></td>
+            <td>
+                ${renderFrontDeskActions(b)}
+                ${b.status === 'checked_out' ? `<a href="/booking/${b.id}/invoice.pdf" target="_blank" class="btn-secondary">Счет</a>` : ''}
+                ${b.status !== 'cancelled' && b.status !== 'no_show' ? `<button onclick="openPaymentModal(${b.id})" class="btn-secondary">Оплата</button>` : ''}
+                ${b.status === 'pending' ? `
+                    <button onclick="confirmBooking(${b.id}, ${b.version})" class="btn-icon" style="color: var(--success)" title="Подтвердить">
+                        <svg width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
+                            <polyline points="20 6 9 17 4 12"></polyline>
+                        </svg>
+                    </button>
+                    <button onclick="cancelBooking(${b.id}, ${b.version})" class="btn-icon" style="color: var(--danger)" title="Отменить">
+                        <svg width="16" height="16" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
+                            <line x1="18" y1="6" x2="6" y2="18"></line>
+                            <line x1="6" y1="6" x2="18" y2="18"></line>
+                        </svg>
+                    </button>
+                ` : ''}
+            </td>
+        </tr>
+    `;
+}
+
+let searchTimer = null;
+
+function searchAdminBookings(query) {
+    clearTimeout(searchTimer);
+    searchTimer = setTimeout(async () => {
+        if (query.trim().length < 2) {
+            loadAdminBookings();
+            return;
+        }
+
+        try {
+            const res = await fetch(`/admin/bookings/search?q=${encodeURIComponent(query)}`);
+            const results = await res.json();
+            if (!res.ok) throw new Error(problemMessage(results, 'Ошибка поиска'));
+
+            document.getElementById('admin-bookings-more').style.display = 'none';
+            document.getElementById('admin-bookings-total').textContent = `Найдено: ${results.length}`;
+
+            const tbody = document.querySelector('#admin-bookings-table tbody');
+            tbody.innerHTML = results.length > 0
+                ? results.map(renderAdminBookingRow).join('')
+                : '<tr><td colspan="6" style="text-align: center; padding: 20px;">Ничего не найдено</td></tr>';
+        } catch (err) {
+            showToast(err.message, 'error');
+        }
+    }, 300);
+}
+
+function renderFrontDeskActions(b) {
+    if (b.status === 'confirmed') {
+        return `<button onclick="checkInBooking(${b.id}, ${b.version})" class="btn-secondary">Заселить</button>`;
+    }
+    if (b.status === 'checked_in') {
+        return `<button onclick="checkOutBooking(${b.id}, ${b.version})" class="btn-secondary">Выселить</button>`;
+    }
+    return '';
+}
+
+async function checkInBooking(id, version) {
+    const documentType = prompt('Тип документа', 'Паспорт');
+    if (!documentType) return;
+    const documentNumber = prompt('Номер документа');
+    if (!documentNumber) return;
+    const keyCards = prompt('Номера ключ-карт (через запятую)', '') || '';
+
+    try {
+        const res = await fetch(`/admin/bookings/${id}/check-in`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json', ...ifMatch(version) },
+            body: JSON.stringify({
+                document_type: documentType,
+                document_number: documentNumber,
+                key_cards: keyCards.split(',').map(k => k.trim()).filter(Boolean)
+            })
+        });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(problemMessage(err, 'Не удалось заселить гостя'));
+        }
+        showToast('Гость заселен', 'success');
+        loadAdminData();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function checkOutBooking(id, version) {
+    if (!confirm('Выселить гостя?')) return;
+    try {
+        const res = await fetch(`/admin/bookings/${id}/check-out`, { method: 'PUT', headers: ifMatch(version) });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(problemMessage(err, 'Не удалось выселить гостя'));
+        }
+        showToast('Гость выселен', 'success');
+        loadAdminData();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function confirmBooking(id, version) {
+    if (!confirm('Подтвердить бронирование?')) return;
+    try {
+        const res = await fetch(`/admin/bookings/${id}/confirm`, { method: 'PUT', headers: ifMatch(version) });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(problemMessage(err, 'Не удалось подтвердить'));
+        }
+        showToast('Бронирование подтверждено', 'success');
+        loadAdminBookings();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function cancelBooking(id, version) {
+    if (!confirm('Отменить бронирование?')) return;
+    try {
+        const res = await fetch(`/booking/${id}/cancel`, { method: 'PUT', headers: ifMatch(version) });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(problemMessage(err, 'Не удалось отменить'));
+        }
+        showToast('Бронирование отменено', 'success');
+        loadAdminBookings();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+let calendarRoomId = null;
+
+async function openRoomCalendar(roomId, roomNumber) {
+    calendarRoomId = roomId;
+    document.getElementById('room-calendar-number').textContent = roomNumber;
+    document.querySelector('#calendar-feed-form input[name="room_id"]').value = roomId;
+    await loadRoomCalendar();
+    document.getElementById('room-calendar-modal').classList.add('active');
+}
+
+function renderRoomCalendar(calendar) {
+    document.getElementById('room-calendar-export').value = location.origin + calendar.export_path;
+
+    const tbody = document.querySelector('#calendar-feeds-table tbody');
+    if (calendar.feeds.length === 0) {
+        tbody.innerHTML = '<tr><td colspan="4" style="text-align: center; padding: 20px;">Внешние календари не подключены</td></tr>';
+        return;
+    }
+    tbody.innerHTML = calendar.feeds.map(f => `
+        <tr>
+            <td title="${f.url}">${f.name}</td>
+            <td>${f.blocks}</td>
+            <td>
+                ${f.last_synced_at ? new Date(f.last_synced_at).toLocaleString('ru-RU') : '—'}
+                ${f.last_error ? `<div style="color: var(--danger)"><small>${f.last_error}</small></div>` : ''}
+            </td>
+            <td>
+                <button onclick="syncCalendarFeed(${f.id})" class="btn-secondary">Обновить</button>
+                <button onclick="deleteCalendarFeed(${f.id})" class="btn-secondary">Отключить</button>
+            </td>
+        </tr>
+    `).join('');
+}
+
+async function loadRoomCalendar() {
+    try {
+        const res = await fetch(`/admin/rooms/${calendarRoomId}/calendar`);
+        const calendar = await res.json();
+        if (!res.ok) throw new Error(problemMessage(calendar, 'Не удалось загрузить календарь'));
+        renderRoomCalendar(calendar);
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function rotateRoomCalendar() {
+    if (!confirm('Старая ссылка перестанет работать. Выпустить новую?')) return;
+
+    try {
+        const res = await fetch(`/admin/rooms/${calendarRoomId}/calendar/rotate`, { method: 'POST' });
+        const calendar = await res.json();
+        if (!res.ok) throw new Error(problemMessage(calendar, 'Не удалось выпустить ссылку'));
+        renderRoomCalendar(calendar);
+        showToast('Новая ссылка выпущена', 'success');
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function addCalendarFeed(formData) {
+    try {
+        const data = Object.fromEntries(formData.entries());
+        data.room_id = parseInt(data.room_id);
+
+        const res = await fetch('/admin/calendar/feeds', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(data)
+        });
+        const feed = await res.json();
+        if (!res.ok) throw new Error(problemMessage(feed, 'Не удалось подключить календарь'));
+
+        if (feed.last_error) {
+            showToast(`Календарь подключен, но загрузить его не удалось: ${feed.last_error}`, 'error');
+        } else {
+            showToast('Календарь подключен', 'success');
+        }
+        document.getElementById('calendar-feed-form').reset();
+        loadRoomCalendar();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function syncCalendarFeed(id) {
+    try {
+        const res = await fetch(`/admin/calendar/feeds/${id}/sync`, { method: 'POST' });
+        const body = await res.json();
+        if (!res.ok) throw new Error(problemMessage(body, 'Не удалось обновить календарь'));
+        showToast('Календарь обновлен', 'success');
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+    loadRoomCalendar();
+}
+
+async function deleteCalendarFeed(id) {
+    if (!confirm('Отключить календарь? Занятые им даты освободятся.')) return;
+
+    try {
+        const res = await fetch(`/admin/calendar/feeds/${id}`, { method: 'DELETE' });
+        if (!res.ok) throw new Error(problemMessage(await res.json(), 'Не удалось отключить календарь'));
+        loadRoomCalendar();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function openPaymentModal(bookingId) {
+    const form = document.getElementById('payment-form');
+    form.querySelector('input[name="booking_id"]').value = bookingId;
+    form.querySelector('input[name="amount"]').value = '';
+    paymentIdempotencyKey = crypto.randomUUID();
+    document.getElementById('payment-booking-id').textContent = bookingId;
+    await loadPayments(bookingId);
+    document.getElementById('payment-modal').classList.add('active');
+}
+
+async function loadPayments(bookingId) {
+    try {
+        const res = await fetch(`/booking/${bookingId}/payments`);
+        const summary = await res.json();
+        if (!res.ok) throw new Error(problemMessage(summary, 'Не удалось загрузить платежи'));
+
+        document.getElementById('payment-summary').innerHTML = `
+            <div class="breakdown-item"><span>К оплате:</span><span>${formatPrice(summary.total)} RUB</span></div>
+            <div class="breakdown-item"><span>Оплачено:</span><span>${formatPrice(summary.paid)} RUB</span></div>
+            <div class="breakdown-item"><span>Заблокировано на карте:</span><span>${formatPrice(summary.authorized)} RUB</span></div>
+            <div class="breakdown-item"><span>Предоплата к внесению:</span><span>${formatPrice(summary.deposit_due)} RUB</span></div>
+            <div class="breakdown-total"><span>Остаток:</span><span>${formatPrice(summary.balance)} RUB</span></div>
+        `;
+        document.getElementById('payment-form').style.display = summary.balance > 0 ? '' : 'none';
+
+        const tbody = document.querySelector('#payments-table tbody');
+        tbody.innerHTML = summary.payments.length > 0
+            ? summary.payments.map(p => `
+                <tr>
+                    <td>#${p.id}</td>
+                    <td>${formatPrice(p.amount)}</td>
+                    <td>${formatPrice(p.captured_amount)}</td>
+                    <td>${formatPrice(p.refunded_amount)}</td>
+                    <td>${getPaymentStatusName(p.status)}</td>
+                    <td>
+                        ${p.status === 'authorized' ? `
+                            <button onclick="paymentAction(${bookingId}, ${p.id}, 'capture')" class="btn-secondary">Списать</button>
+                            <button onclick="paymentAction(${bookingId}, ${p.id}, 'void')" class="btn-secondary">Отменить</button>
+                        ` : ''}
+                        ${p.status === 'captured' && p.amount > p.captured_amount ? `<button onclick="paymentAction(${bookingId}, ${p.id}, 'capture')" class="btn-secondary">Списать остаток</button>` : ''}
+                        ${p.status === 'captured' ? `<button onclick="paymentAction(${bookingId}, ${p.id}, 'refund')" class="btn-secondary">Вернуть</button>` : ''}
+                    </td>
+                </tr>
+            `).join('')
+            : '<tr><td colspan="6" style="text-align: center; padding: 20px;">Платежей нет</td></tr>';
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function payBooking(formData) {
+    const bookingId = formData.get('booking_id');
+    const data = {
+        payment_method: formData.get('payment_method'),
+        amount: parseFloat(formData.get('amount')) || 0,
+        capture: formData.get('capture') === 'on'
+    };
+
+    try {
+        const res = await fetch(`/booking/${bookingId}/payments`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json', 'Idempotency-Key': paymentIdempotencyKey },
+            body: JSON.stringify(data)
+        });
+        const result = await res.json();
+        paymentIdempotencyKey = crypto.randomUUID();
+        if (!res.ok) throw new Error(problemMessage(result, 'Не удалось провести оплату'));
+
+        showToast(result.confirmed ? 'Оплата прошла, бронирование подтверждено' : 'Оплата прошла', 'success');
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+    loadPayments(bookingId);
+    loadAdminBookings();
+}
+
+async function paymentAction(bookingId, paymentId, action) {
+    let body = {};
+    if (action === 'refund') {
+        const amount = prompt('Сумма возврата (пусто — всё)', '');
+        if (amount === null) return;
+        body = { amount: parseFloat(amount) || 0 };
+    }
+
+    try {
+        const res = await fetch(`/admin/payments/${paymentId}/${action}`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(body)
+        });
+        const result = await res.json();
+        if (!res.ok) throw new Error(problemMessage(result, 'Операция не выполнена'));
+
+        showToast(result.confirmed ? 'Платеж списан, бронирование подтверждено' : 'Готово', 'success');
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+    loadPayments(bookingId);
+    loadAdminBookings();
+}
+
+async function addRoom(formData) {
+    try {
+        const data = Object.fromEntries(formData.entries());
+        data.base_price = parseFloat(data.base_price);
+        data.capacity = parseInt(data.capacity);
+
+        const res = await fetch('/admin/rooms', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(data)
+        });
+
+        if (!res.ok) throw new Error('Не удалось создать номер');
+
+        showToast('Номер создан', 'success');
+        closeModals();
+        loadAdminRooms();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+async function sendNotification(formData) {
+    try {
+        const data = Object.fromEntries(formData.entries());
+        const res = await fetch('/notification/send', {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify(data)
+        });
+
+        if (!res.ok) throw new Error('Не удалось отправить');
+
+        showToast('Уведомление отправлено', 'success');
+        document.getElementById('notification-form').reset();
+    } catch (err) {
+        showToast(err.message, 'error');
+    }
+}
+
+function getRoomTypeName(type) {
+    const types = {
+        'standard': 'Стандарт',
+        'deluxe': 'Делюкс',
+        'suite': 'Люкс',
+        'family': 'Семейный'
+    };
+    return types[type] || type;
+}
+
+const validationMessages = {
+    'required': 'обязательное поле',
+    'invalid': 'некорректное значение',
+    'invalid_email': 'некорректный email',
+    'invalid_phone': 'некорректный номер телефона',
+    'too_long': 'слишком длинное значение',
+    'out_of_range': 'значение вне допустимого диапазона',
+    'unknown_value': 'недопустимое значение',
+    'date_order': 'дата выезда должна быть позже даты заезда',
+    'in_past': 'дата уже прошла',
+    'duplicate': 'повторяется в файле'
+};
+
+const fieldNames = {
+    'room_id': 'Номер',
+    'start_date': 'Дата заезда',
+    'end_date': 'Дата выезда',
+    'check_in': 'Дата заезда',
+    'check_out': 'Дата выезда',
+    'from': 'Начало периода',
+    'to': 'Конец периода',
+    'guest_info.name': 'ФИО',
+    'guest_info.email': 'Email',
+    'guest_info.phone': 'Телефон',
+    'email': 'Email',
+    'phone': 'Телефон',
+    'name': 'Название',
+    'room_number': 'Номер комнаты',
+    'room_type': 'Тип номера',
+    'base_price': 'Цена',
+    'capacity': 'Вместимость',
+    'coefficient': 'Коэффициент',
+    'redeem_points': 'Баллы',
+    'points': 'Баллы',
+    'reason': 'Причина',
+    'actor': 'Сотрудник',
+    'guests': 'Количество гостей',
+    'payment_method': 'Токен карты',
+    'amount': 'Сумма',
+    'date': 'Дата',
+    'status': 'Статус',
+    'description': 'Описание',
+    'format': 'Формат',
+    'file': 'Файл',
+    'url': 'Ссылка',
+    'channel': 'Канал',
+    'room_code': 'Код номера',
+    'rate_code': 'Код тарифа',
+    'state': 'Состояние',
+    'events': 'События',
+    'secret': 'Секрет',
+    'active': 'Активность',
+    'role': 'Роль',
+    'last_event_id': 'Последнее событие'
+};
+
+const problemMessages = {
+    'room_not_found': 'Номер не найден',
+    'booking_not_found': 'Бронирование не найдено',
+    'guest_not_found': 'Гость не найден',
+    'room_not_available': 'Номер занят на выбранные даты',
+    'room_taken': 'Номер уже забронировали на эти даты, обновите план и повторите',
+    'room_has_bookings': 'У номера есть текущие или будущие бронирования',
+    'room_archived': 'Номер находится в архиве',
+    'room_not_archived': 'Номер не в архиве',
+    'booking_closed': 'Бронирование уже нельзя изменить',
+    'booking_not_movable': 'Перенести можно только ожидающие или подтвержденные бронирования',
+    'not_enough_points': 'Недостаточно баллов',
+    'booking_not_payable': 'Это бронирование нельзя оплатить',
+    'nothing_due': 'Бронирование уже полностью оплачено',
+    'amount_exceeds_balance': 'Сумма больше остатка к оплате',
+    'amount_too_large': 'Сумма больше допустимой для платежа',
+    'invalid_payment_state': 'Операция недоступна для платежа в этом статусе',
+    'payment_declined': 'Платеж отклонен',
+    'payment_gateway_unavailable': 'Платежная система недоступна, попробуйте позже',
+    'invoice_not_found': 'Счет еще не выставлен',
+    'idempotency_key_reused': 'Повторный запрос не совпадает с исходным',
+    'idempotency_key_in_progress': 'Запрос уже обрабатывается, подождите',
+    'version_mismatch': 'Запись уже изменена другим пользователем, обновите данные',
+    'precondition_failed': 'Запись уже изменена другим пользователем, обновите данные',
+    'duplicate': 'Такая запись уже существует',
+    'unavailable': 'Сервис временно недоступен, попробуйте позже',
+    'database_unavailable': 'Сервис временно недоступен, попробуйте позже',
+    'empty_file': 'Файл пуст',
+    'too_many_rows': 'В файле слишком много строк',
+    'unreadable_file': 'Не удалось прочитать файл, нужен CSV или XLSX',
+    'calendar_not_found': 'Календарь не найден',
+    'calendar_feed_not_found': 'Внешний календарь не найден',
+    'calendar_feed_unavailable': 'Внешний календарь недоступен',
+    'channel_not_found': 'Канал не подключен',
+    'channel_mapping_not_found': 'Сопоставление не найдено',
+    'channel_reservation_not_found': 'Бронь канала не найдена',
+    'channel_reservation_not_conflict': 'Бронь уже не в конфликте',
+    'channel_unavailable': 'Канал недоступен, попробуйте позже',
+    'webhook_subscription_not_found': 'Вебхук не найден',
+    'redemption_staff_only': 'Списать баллы можно только у администратора',
+    'points_refunded': 'Баллы за отмененное бронирование уже возвращены, создайте новое бронирование',
+    'invalid_status_change': 'Нельзя перевести бронирование в этот статус',
+    'waitlist_entry_not_found': 'Заявка в листе ожидания не найдена',
+    'waitlist_hold_not_found': 'Предложение не найдено',
+    'waitlist_hold_expired': 'Время на подтверждение истекло',
+    'waitlist_entry_closed': 'Заявка в листе ожидания уже закрыта',
+    'already_waitlisted': 'Вы уже в листе ожидания на эти даты',
+    'rooms_available': 'На эти даты есть свободные номера, забронируйте один из них',
+    'internal_error': 'Внутренняя ошибка сервера'
+};
+
+// ifMatch makes a change conditional on the version the page has shown.
+function ifMatch(version) {
+    return version ? { 'If-Match': `"${version}"` } : {};
+}
+
+// problemMessage turns a problem+json response into text for a toast.
+function problemMessage(problem, fallback) {
+    if (problem && problem.errors && problem.errors.length) {
+        return problem.errors
+            .map(e => `${fieldNames[e.field] || e.field}: ${validationMessages[e.code] || e.message}`)
+            .join('; ');
+    }
+    return (problem && (problemMessages[problem.code] || problem.detail)) || fallback;
+}
+
+function getTierName(tier) {
+    const tiers = {
+        'member': 'Участник',
+        'silver': 'Серебро',
+        'gold': 'Золото'
+    };
+    return tiers[tier] || tier;
+}
+
+function getLedgerEntryName(type) {
+    const types = {
+        'earn': 'Начисление',
+        'redeem': 'Списание',
+        'refund': 'Возврат',
+        'adjust': 'Корректировка',
+        'expire': 'Сгорание'
+    };
+    return types[type] || type;
+}
 
 function getStatusName(status) {
     const statuses = {
@@ -511,7 +2372,58 @@ function getStatusName(status) {
         'maintenance': 'Ремонт',
         'pending': 'Ожидает',
         'confirmed': 'Подтверждено',
-        'cancelled': 'Отменено'
+        'cancelled': 'Отменено',
+        'checked_in': 'Проживает',
+        'checked_out': 'Выехал',
+        'no_show': 'Не заехал'
+    };
+    return statuses[status] || status;
+}
+
+function getPaymentStatusName(status) {
+    const names = {
+        'authorized': 'Заблокирован',
+        'captured': 'Списан',
+        'refunded': 'Возвращен',
+        'voided': 'Отменен',
+        'failed': 'Ошибка'
+    };
+    return names[status] || status;
+}
+
+function getMaintenanceKindName(kind) {
+    const kinds = {
+        'out_of_order': 'Неисправен',
+        'out_of_service': 'Не продается',
+        'external': 'Внешний календарь'
+    };
+    return kinds[kind] || kind;
+}
+
+function getHousekeepingName(status) {
+    const statuses = {
+        'clean': 'Чистый',
+        'dirty': 'Грязный',
+        'inspected': 'Проверен',
+        'out_of_order': 'Неисправен'
+    };
+    return statuses[status] || status;
+}
+
+function getTaskTypeName(type) {
+    const types = {
+        'cleaning': 'Уборка',
+        'inspection': 'Проверка',
+        'repair': 'Ремонт'
+    };
+    return types[type] || type;
+}
+
+function getTaskStatusName(status) {
+    const statuses = {
+        'open': 'Открыта',
+        'in_progress': 'В работе',
+        'done': 'Выполнена'
     };
     return statuses[status] || status;
 }
@@ -520,8 +2432,29 @@ function formatPrice(price) {
     return new Intl.NumberFormat('ru-RU').format(price);
 }
 
+function parseDate(value) {
+    const [year, month, day] = value.split('-').map(Number);
+    return new Date(year, month - 1, day);
+}
+
+function formatDate(value) {
+    return parseDate(value).toLocaleDateString();
+}
+
+function toISODate(date) {
+    const month = String(date.getMonth() + 1).padStart(2, '0');
+    const day = String(date.getDate()).padStart(2, '0');
+    return `${date.getFullYear()}-${month}-${day}`;
+}
+
+function addDays(value, days) {
+    const date = parseDate(value);
+    date.setDate(date.getDate() + days);
+    return toISODate(date);
+}
+
 function getNights(d1, d2) {
-    return Math.ceil((d2 - d1) / (1000 * 60 * 60 * 24));
+    return Math.round((parseDate(d2) - parseDate(d1)) / (1000 * 60 * 60 * 24));
 }
 
 function showToast(message, type = 'success') {